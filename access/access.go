@@ -0,0 +1,91 @@
+// Package access checks the rules that decide who can use a command.
+package access
+
+import (
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/pkg/errors"
+)
+
+// Chat types of the access rules.
+const (
+	Private = "private"
+	Group   = "group"
+)
+
+// ErrDenied is returned when a caller can't use a command.
+var ErrDenied = errors.New("command not allowed")
+
+// Check returns a non-nil error wrapping ErrDenied if the caller can't
+// use the command. Commands without access rules can be used by anyone,
+// even without a caller, while the ones with access rules can't be used
+// without a caller nor by callers whose chat or user is unknown to the
+// rules that deny them.
+func Check(cmd *proto.BotCommand, c *proto.Caller) error {
+	a := cmd.GetAccess()
+	if a == nil {
+		return nil
+	}
+
+	switch {
+	case c == nil:
+		return errors.Wrap(ErrDenied, "no caller")
+	case c.GetChatId() == "" && (len(a.GetDeniedChats()) > 0 || a.GetChatType() != ""):
+		return errors.Wrap(ErrDenied, "unknown chat")
+	case c.GetUserId() == "" && len(a.GetDeniedUsers()) > 0:
+		return errors.Wrap(ErrDenied, "unknown user")
+	case contains(a.GetDeniedChats(), c.GetChatId()):
+		return errors.Wrapf(ErrDenied, "chat %q is denied", c.GetChatId())
+	case contains(a.GetDeniedUsers(), c.GetUserId()):
+		return errors.Wrapf(ErrDenied, "user %q is denied", c.GetUserId())
+	case len(a.GetAllowedChats()) > 0 && !contains(a.GetAllowedChats(), c.GetChatId()):
+		return errors.Wrapf(ErrDenied, "chat %q is not allowed", c.GetChatId())
+	case len(a.GetAllowedUsers()) > 0 && !contains(a.GetAllowedUsers(), c.GetUserId()):
+		return errors.Wrapf(ErrDenied, "user %q is not allowed", c.GetUserId())
+	case len(a.GetRoles()) > 0 && !containsAny(a.GetRoles(), c.GetRoles()):
+		return errors.Wrapf(ErrDenied, "user %q has none of the allowed roles", c.GetUserId())
+	case a.GetAdminsOnly() && !c.GetAdmin():
+		return errors.Wrapf(ErrDenied, "user %q is not an administrator", c.GetUserId())
+	case a.GetChatType() == Private && !c.GetPrivate():
+		return errors.Wrap(ErrDenied, "only allowed on direct messages")
+	case a.GetChatType() == Group && c.GetPrivate():
+		return errors.Wrap(ErrDenied, "not allowed on direct messages")
+	}
+
+	return nil
+}
+
+// Validate checks that the chat type of the
+// access rules of the command, if any, is known.
+func Validate(cmd *proto.BotCommand) error {
+	switch t := cmd.GetAccess().GetChatType(); t {
+	case "", Private, Group:
+		return nil
+	default:
+		return errors.Errorf("unknown chat type %q", t)
+	}
+}
+
+func contains(list []string, s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsAny(list, of []string) bool {
+	for _, s := range of {
+		if contains(list, s) {
+			return true
+		}
+	}
+
+	return false
+}
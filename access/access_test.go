@@ -0,0 +1,130 @@
+package access
+
+import (
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/pkg/errors"
+)
+
+func TestCheck(t *testing.T) {
+	tt := []struct {
+		name           string
+		access         *proto.Access
+		caller         *proto.Caller
+		expectedToFail bool
+	}{
+		{
+			name:   "without rules",
+			caller: &proto.Caller{ChatId: "1", UserId: "2"},
+		},
+		{
+			name:           "denied chat",
+			access:         &proto.Access{DeniedChats: []string{"1"}},
+			caller:         &proto.Caller{ChatId: "1", UserId: "2"},
+			expectedToFail: true,
+		},
+		{
+			name:           "denied user on an allowed chat",
+			access:         &proto.Access{AllowedChats: []string{"1"}, DeniedUsers: []string{"2"}},
+			caller:         &proto.Caller{ChatId: "1", UserId: "2"},
+			expectedToFail: true,
+		},
+		{
+			name:   "allowed chat",
+			access: &proto.Access{AllowedChats: []string{"1", "3"}},
+			caller: &proto.Caller{ChatId: "3", UserId: "2"},
+		},
+		{
+			name:           "not allowed chat",
+			access:         &proto.Access{AllowedChats: []string{"1"}},
+			caller:         &proto.Caller{ChatId: "3", UserId: "2"},
+			expectedToFail: true,
+		},
+		{
+			name:           "not allowed user",
+			access:         &proto.Access{AllowedUsers: []string{"5"}},
+			caller:         &proto.Caller{ChatId: "1", UserId: "2"},
+			expectedToFail: true,
+		},
+		{
+			name:   "with role",
+			access: &proto.Access{Roles: []string{"mods", "admins"}},
+			caller: &proto.Caller{UserId: "2", Roles: []string{"members", "mods"}},
+		},
+		{
+			name:           "without role",
+			access:         &proto.Access{Roles: []string{"mods"}},
+			caller:         &proto.Caller{UserId: "2", Roles: []string{"members"}},
+			expectedToFail: true,
+		},
+		{
+			name:   "admin",
+			access: &proto.Access{AdminsOnly: true},
+			caller: &proto.Caller{UserId: "2", Admin: true},
+		},
+		{
+			name:           "not admin",
+			access:         &proto.Access{AdminsOnly: true},
+			caller:         &proto.Caller{UserId: "2"},
+			expectedToFail: true,
+		},
+		{
+			name:   "direct message",
+			access: &proto.Access{ChatType: Private},
+			caller: &proto.Caller{ChatId: "2", UserId: "2", Private: true},
+		},
+		{
+			name:           "group on private command",
+			access:         &proto.Access{ChatType: Private},
+			caller:         &proto.Caller{ChatId: "1", UserId: "2"},
+			expectedToFail: true,
+		},
+		{
+			name:           "direct message on group command",
+			access:         &proto.Access{ChatType: Group},
+			caller:         &proto.Caller{ChatId: "2", UserId: "2", Private: true},
+			expectedToFail: true,
+		},
+		{
+			name: "without caller and rules",
+		},
+		{
+			name:           "without caller",
+			access:         &proto.Access{AllowedChats: []string{"1"}},
+			expectedToFail: true,
+		},
+		{
+			name:           "unknown chat on denied chats",
+			access:         &proto.Access{DeniedChats: []string{"1"}},
+			caller:         &proto.Caller{UserId: "2"},
+			expectedToFail: true,
+		},
+		{
+			name:           "unknown chat on group command",
+			access:         &proto.Access{ChatType: Group},
+			caller:         &proto.Caller{UserId: "2"},
+			expectedToFail: true,
+		},
+		{
+			name:           "unknown user on denied users",
+			access:         &proto.Access{DeniedUsers: []string{"2"}},
+			caller:         &proto.Caller{ChatId: "1"},
+			expectedToFail: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Check(&proto.BotCommand{Access: tc.access}, tc.caller)
+			if tc.expectedToFail && errors.Cause(err) != ErrDenied {
+				t.Fatalf("expected %v. got=%v", ErrDenied, err)
+			}
+
+			if !tc.expectedToFail && err != nil {
+				t.Fatalf("expected caller to be allowed. got=%v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+// Package audit exports a Sink interface to record
+// the changes made to the commands by the server.
+package audit
+
+import (
+	"os"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNotListable is returned by the sinks that
+// can't read back the events written to them.
+var ErrNotListable = errors.New("audit events can't be listed")
+
+// Sink represents the destination of the audit events. Events are
+// append-only, so a Sink has no way to change or remove them.
+type Sink interface {
+	Write(ev *proto.AuditEvent) error
+	List(f *proto.AuditFilter) (*proto.AuditEvents, error)
+}
+
+// Create follows the Factory pattern to return a Sink
+// depending on the received kind parameter.
+func Create(kind string) Sink {
+	switch kind {
+	case "db":
+		return &DB{}
+	case "file":
+		return &File{}
+	case "stdout":
+		return &Writer{Out: os.Stdout}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+)
+
+func TestCreate(t *testing.T) {
+	for _, kind := range []string{"db", "file", "stdout"} {
+		if Create(kind) == nil {
+			t.Fatalf("expected a Sink for kind %q", kind)
+		}
+	}
+
+	if Create("kafka") != nil {
+		t.Fatalf("expected no Sink for an unknown kind")
+	}
+}
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "botio")
+	if err != nil {
+		t.Fatalf("while creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.jsonl")
+	f := &File{Path: path}
+	for _, action := range []string{"add", "delete"} {
+		if err := f.Write(&proto.AuditEvent{Action: action, Command: "start"}); err != nil {
+			t.Fatalf("while writing audit event: %v", err)
+		}
+	}
+
+	// A new File keeps numbering the events of the same file.
+	reopened := &File{Path: path}
+	ev := &proto.AuditEvent{Action: "add", Command: "stop"}
+	if err := reopened.Write(ev); err != nil {
+		t.Fatalf("while writing audit event: %v", err)
+	}
+
+	if ev.GetId() != 3 {
+		t.Fatalf("expected audit event id %v. got=%v", 3, ev.GetId())
+	}
+
+	events, err := reopened.List(&proto.AuditFilter{Command: "start"})
+	if err != nil {
+		t.Fatalf("while listing audit events: %v", err)
+	}
+
+	if len(events.GetEvents()) != 2 || events.GetEvents()[1].GetAction() != "delete" {
+		t.Fatalf("expected the 2 events of command %q. got=%v", "start", events.GetEvents())
+	}
+}
@@ -0,0 +1,34 @@
+package audit
+
+import (
+	"github.com/danielkvist/botio/db"
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/pkg/errors"
+)
+
+// DB is a Sink that keeps the audit events
+// on the same database as the commands.
+type DB struct {
+	Store db.DB
+}
+
+// Write stores the received event on the database,
+// which assigns its id.
+func (d *DB) Write(ev *proto.AuditEvent) error {
+	if d.Store == nil {
+		return errors.New("while writing audit event: no database provided")
+	}
+
+	return d.Store.AddAuditEvent(ev)
+}
+
+// List returns the events stored on the database
+// that meet the conditions of the filter.
+func (d *DB) List(f *proto.AuditFilter) (*proto.AuditEvents, error) {
+	if d.Store == nil {
+		return nil, errors.New("while listing audit events: no database provided")
+	}
+
+	return d.Store.AuditEvents(f)
+}
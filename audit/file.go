@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/danielkvist/botio/db"
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/pkg/errors"
+)
+
+// File is a Sink that appends the audit events to the file
+// on Path as JSON objects, one per line. The events are numbered
+// after the lines that the file already has.
+type File struct {
+	Path string
+	mu   sync.Mutex
+	last int64
+	read bool
+}
+
+// Write appends the received event to the file.
+func (f *File) Write(ev *proto.AuditEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.read {
+		events, err := f.events()
+		if err != nil {
+			return err
+		}
+
+		f.last = int64(len(events))
+		f.read = true
+	}
+
+	ev.Id = f.last + 1
+	line, err := (&jsonpb.Marshaler{OrigName: true}).MarshalToString(ev)
+	if err != nil {
+		return errors.Wrapf(err, "while encoding audit event %v", ev.GetId())
+	}
+
+	file, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "while opening audit file %q", f.Path)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(line + "\n"); err != nil {
+		return errors.Wrapf(err, "while writing audit event %v to %q", ev.GetId(), f.Path)
+	}
+
+	f.last = ev.Id
+	return nil
+}
+
+// List reads the events from the file and returns
+// the ones that meet the conditions of the filter.
+func (f *File) List(filter *proto.AuditFilter) (*proto.AuditEvents, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	events, err := f.events()
+	if err != nil {
+		return nil, err
+	}
+
+	return db.FilterAuditEvents(events, filter), nil
+}
+
+func (f *File) events() ([]*proto.AuditEvent, error) {
+	file, err := os.Open(f.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "while opening audit file %q", f.Path)
+	}
+	defer file.Close()
+
+	var events []*proto.AuditEvent
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		ev := &proto.AuditEvent{}
+		if err := jsonpb.UnmarshalString(line, ev); err != nil {
+			return nil, errors.Wrapf(err, "while decoding audit event %v of %q", len(events)+1, f.Path)
+		}
+
+		events = append(events, ev)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "while reading audit file %q", f.Path)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/pkg/errors"
+)
+
+// Writer is a Sink that writes the audit events to Out as JSON
+// objects, one per line, like the standard output of the server to be
+// collected by another system. The events written can't be listed.
+type Writer struct {
+	Out  io.Writer
+	mu   sync.Mutex
+	last int64
+}
+
+// Write writes the received event numbering it
+// after the last one written since the server started.
+func (w *Writer) Write(ev *proto.AuditEvent) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	ev.Id = w.last + 1
+	line, err := (&jsonpb.Marshaler{OrigName: true}).MarshalToString(ev)
+	if err != nil {
+		return errors.Wrapf(err, "while encoding audit event %v", ev.GetId())
+	}
+
+	if _, err := fmt.Fprintln(w.Out, line); err != nil {
+		return errors.Wrapf(err, "while writing audit event %v", ev.GetId())
+	}
+
+	w.last = ev.Id
+	return nil
+}
+
+// List always returns ErrNotListable.
+func (w *Writer) List(_ *proto.AuditFilter) (*proto.AuditEvents, error) {
+	return nil, ErrNotListable
+}
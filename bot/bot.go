@@ -9,17 +9,26 @@ import (
 )
 
 // Bot is an interface to manage bots for differentes platforms.
+//
+// Use registers middlewares that the bot's Router will run,
+// in order, for every received message. It must be called before Connect.
 type Bot interface {
 	Connect(c client.Client, addr string, token string, cap int, defaultResponse string) error
+	Use(mws ...Middleware)
 	Start() error
 	Listen() error
 	Stop() error
 }
 
-// Response represents a bot response.
+// Response represents a bot response. If messageID or inlineMessageID
+// are not empty the response replaces the text of that message instead
+// of being sent as a new message.
 type Response struct {
-	id   string
-	text string
+	id              string
+	text            string
+	buttons         []Button
+	messageID       int
+	inlineMessageID string
 }
 
 // Create returns a bot that satisfies the Bot interface
@@ -0,0 +1,148 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/danielkvist/botio/client"
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/pkg/errors"
+)
+
+// activeConversationTTL is the time after which a dialog without
+// messages is no longer considered in progress by the bot when the
+// server doesn't say when it ends. It matches the server's default.
+const activeConversationTTL = 10 * time.Minute
+
+// Conversations returns a Middleware that passes the received messages
+// to the conversations kept by the botio's server, which answers them
+// while the user is in the middle of a dialog. Messages that are not
+// part of a dialog continue down the chain, and if they are answered
+// with a command with a flow the dialog of the command is started.
+//
+// Messages that don't mention the bot are only passed to the server
+// while their user has a dialog in progress in the same chat, so answers
+// to the prompts don't need to mention the bot, until the dialog ends
+// or the time after which the server ends it passes. If the server fails
+// to advance a dialog the message continues down the chain.
+func Conversations(c client.Client) Middleware {
+	active := newActiveConversations(activeConversationTTL)
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, m *Message) (*Reply, error) {
+			key := m.Platform + ":" + m.ChatID + ":" + m.UserID
+			if m.UserID == "" {
+				return next(ctx, m)
+			}
+
+			if active.has(key) {
+				if reply, err := converse(ctx, c, active, key, m); err == nil {
+					return reply, nil
+				}
+			}
+
+			reply, err := next(ctx, m)
+			if err != nil || reply == nil || !reply.flow {
+				return reply, err
+			}
+
+			if started, err := converse(ctx, c, active, key, m); err == nil {
+				return started, nil
+			}
+
+			return reply, nil
+		}
+	}
+}
+
+// converse passes the message to the conversation of its user,
+// keeping track of whether the dialog is still in progress.
+func converse(ctx context.Context, c client.Client, active *activeConversations, key string, m *Message) (*Reply, error) {
+	resp, err := c.Converse(ctx, &proto.ConverseRequest{
+		Caller: m.caller(),
+		Input:  m.Text,
+	})
+	if err != nil {
+		active.remove(key)
+		return nil, errors.Wrap(err, "while conversing")
+	}
+
+	if resp.GetDone() {
+		active.remove(key)
+	} else {
+		ttl, err := ptypes.Duration(resp.GetTtl())
+		if err != nil || ttl <= 0 {
+			ttl = active.ttl
+		}
+
+		active.add(key, ttl)
+	}
+
+	if resp.GetResponse().GetResponse() == "" {
+		return nil, nil
+	}
+
+	return &Reply{
+		Text:    resp.GetResponse().GetResponse(),
+		Buttons: buttons(resp.GetResponse()),
+	}, nil
+}
+
+// activeConversations keeps the keys of the users with a dialog in
+// progress until they don't send a message for the TTL of their dialog,
+// which is ttl if the server doesn't send it.
+type activeConversations struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	keys      map[string]time.Time
+	lastSweep time.Time
+}
+
+func newActiveConversations(ttl time.Duration) *activeConversations {
+	return &activeConversations{
+		ttl:       ttl,
+		keys:      make(map[string]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+func (a *activeConversations) has(key string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	expires, ok := a.keys[key]
+	if ok && time.Now().After(expires) {
+		delete(a.keys, key)
+		return false
+	}
+
+	return ok
+}
+
+func (a *activeConversations) add(key string, ttl time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(a.lastSweep) > a.ttl {
+		for k, expires := range a.keys {
+			if now.After(expires) {
+				delete(a.keys, k)
+			}
+		}
+
+		a.lastSweep = now
+	}
+
+	a.keys[key] = now.Add(ttl)
+}
+
+func (a *activeConversations) remove(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.keys, key)
+}
@@ -0,0 +1,131 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestConversations(t *testing.T) {
+	c := testClient(map[string]string{"start": "hi"})
+	c.listed = []*proto.BotCommand{{Cmd: &proto.Command{Command: "greet"}, Flow: &proto.Flow{Start: "name"}}}
+
+	// A fake conversation that asks for a name and then greets.
+	step := map[string]int{}
+	calls := 0
+	c.converse = func(req *proto.ConverseRequest) (*proto.ConverseResponse, error) {
+		calls++
+		key := req.GetCaller().GetUserId()
+		switch {
+		case step[key] == 0 && req.GetInput() == "/greet":
+			step[key] = 1
+			return &proto.ConverseResponse{Response: &proto.Response{Response: "What's your name?"}}, nil
+		case step[key] == 1:
+			step[key] = 0
+			return &proto.ConverseResponse{Response: &proto.Response{Response: "Hi " + req.GetInput()}, Done: true}, nil
+		default:
+			return nil, status.Error(codes.NotFound, "no conversation in progress")
+		}
+	}
+
+	tt := []struct {
+		message       *Message
+		expectedReply string
+		expectedCalls int
+	}{
+		{message: &Message{Platform: "discord", ChatID: "1", UserID: "a", Text: "Alice"}},
+		{message: &Message{Platform: "discord", ChatID: "1", UserID: "a", Text: "/greet", Mention: true}, expectedReply: "What's your name?", expectedCalls: 1},
+		{message: &Message{Platform: "discord", ChatID: "1", UserID: "b", Text: "Bob"}, expectedCalls: 1},
+		{message: &Message{Platform: "discord", ChatID: "1", UserID: "a", Text: "Alice"}, expectedReply: "Hi Alice", expectedCalls: 2},
+		{message: &Message{Platform: "discord", ChatID: "1", UserID: "a", Text: "Alice"}, expectedCalls: 2},
+		{message: &Message{Platform: "discord", ChatID: "1", UserID: "a", Text: "/start", Mention: true}, expectedReply: "hi", expectedCalls: 2},
+		{message: &Message{Platform: "discord", ChatID: "1", UserID: "a", Text: "/greet", Mention: true}, expectedReply: "What's your name?", expectedCalls: 3},
+		// The conversation fails, so the message is handled as a command.
+		{message: &Message{Platform: "discord", ChatID: "1", UserID: "a", Text: "/start", Mention: true}, expectedReply: "hi", expectedCalls: 4},
+	}
+
+	r := NewRouter(c, "default")
+	r.Use(Conversations(c))
+	for i, tc := range tt {
+		reply, err := r.Route(context.TODO(), tc.message)
+		if err != nil {
+			t.Fatalf("(%v) while routing message: %v", i, err)
+		}
+
+		var text string
+		if reply != nil {
+			text = reply.Text
+		}
+
+		if text != tc.expectedReply {
+			t.Fatalf("(%v) expected reply %q. got=%q", i, tc.expectedReply, text)
+		}
+
+		if calls != tc.expectedCalls {
+			t.Fatalf("(%v) expected %v calls to Converse. got=%v", i, tc.expectedCalls, calls)
+		}
+
+		if i == len(tt)-2 {
+			c.converse = func(*proto.ConverseRequest) (*proto.ConverseResponse, error) {
+				calls++
+				return nil, status.Error(codes.Unavailable, "server unavailable")
+			}
+		}
+	}
+}
+
+func TestActiveConversations(t *testing.T) {
+	a := newActiveConversations(time.Hour)
+	a.add("discord:1:a", 50*time.Millisecond)
+	a.add("discord:1:b", time.Hour)
+
+	if !a.has("discord:1:a") || !a.has("discord:1:b") {
+		t.Fatalf("expected conversations to be active")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if a.has("discord:1:a") {
+		t.Fatalf("expected conversation to expire")
+	}
+
+	if !a.has("discord:1:b") {
+		t.Fatalf("expected conversation with a longer TTL to be active")
+	}
+}
+
+func TestConversationTTL(t *testing.T) {
+	c := testClient(map[string]string{})
+	c.listed = []*proto.BotCommand{{Cmd: &proto.Command{Command: "greet"}, Flow: &proto.Flow{Start: "name"}}}
+
+	calls := 0
+	c.converse = func(req *proto.ConverseRequest) (*proto.ConverseResponse, error) {
+		calls++
+		return &proto.ConverseResponse{
+			Response: &proto.Response{Response: "What's your name?"},
+			Ttl:      ptypes.DurationProto(50 * time.Millisecond),
+		}, nil
+	}
+
+	r := NewRouter(c, "default")
+	r.Use(Conversations(c))
+	if _, err := r.Route(context.TODO(), &Message{Platform: "discord", ChatID: "1", UserID: "a", Text: "/greet", Mention: true}); err != nil {
+		t.Fatalf("while routing message: %v", err)
+	}
+
+	// After the TTL sent by the server the dialog has
+	// ended, so answers without mention are not passed.
+	time.Sleep(100 * time.Millisecond)
+	if _, err := r.Route(context.TODO(), &Message{Platform: "discord", ChatID: "1", UserID: "a", Text: "Alice"}); err != nil {
+		t.Fatalf("while routing message: %v", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("expected %v calls to Converse. got=%v", 1, calls)
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"time"
 
 	"github.com/danielkvist/botio/client"
-	"github.com/danielkvist/botio/proto"
 	"github.com/pkg/errors"
 
 	dg "github.com/bwmarrin/discordgo"
@@ -18,15 +17,54 @@ import (
 
 // Discord is a wrapper for a bwmawwin/discordgo session
 // that satisfies the Bot interface.
+//
+// HelpCommand is the name of the command that lists the available
+// commands, an empty name disables it. Mistyped commands are answered
+// suggesting similar ones with the Suggestions format, like "Did you
+// mean %s?", an empty format disables the suggestions. Commands that
+// don't exist are answered with UnknownCommand, or with the default
+// response if it is empty. Commands that the access rules don't allow
+// the user to use are answered with DeniedCommand, or with the default
+// response if it is empty. The commands are registered as slash commands
+// on the guild with GuildID, or globally if it is empty, at startup,
+// every SyncInterval if it is greater than zero and when the change
+// token of the botio's server, checked every SyncCheckInterval if it
+// is greater than zero, changes.
+// If Schedules is true the bot sends the messages scheduled on the
+// botio's server for its channels and its Name, claiming each sending
+// so only one of the replicas started with the same Name sends it. If
+// UsageInterval is greater than zero the outcome of the lookups of the
+// commands is reported to the botio's server every UsageInterval.
+// If Ephemeral is true the answers to slash commands are only shown
+// to the user that used them.
 type Discord struct {
-	id              string
-	session         *dg.Session
-	responses       chan *Response
-	defaultResponse string
-	cancel          chan struct{}
-	log             *logrus.Logger
-	wg              sync.WaitGroup
-	client          client.Client
+	HelpCommand       string
+	Suggestions       string
+	UnknownCommand    string
+	DeniedCommand     string
+	SyncInterval      time.Duration
+	SyncCheckInterval time.Duration
+	Schedules         bool
+	Name              string
+	UsageInterval     time.Duration
+	GuildID           string
+	Ephemeral         bool
+	id                string
+	session           *dg.Session
+	responses         chan *Response
+	cancel            chan struct{}
+	log               *logrus.Logger
+	wg                sync.WaitGroup
+	scheduling        sync.WaitGroup
+	reporting         sync.WaitGroup
+	router            *Router
+	middlewares       []Middleware
+}
+
+// Use registers the received middlewares to be run by the
+// Discord bot's Router.
+func (d *Discord) Use(mws ...Middleware) {
+	d.middlewares = append(d.middlewares, mws...)
 }
 
 // Connect receives a token with which tries to identify, setups
@@ -38,7 +76,6 @@ func (d *Discord) Connect(c client.Client, addr string, token string, cap int, d
 		return fmt.Errorf("while creating a new Discord session: %v", err)
 	}
 
-	d.client = c
 	id, err := session.User("@me")
 	if err != nil {
 		return fmt.Errorf("while extracting the current user ID for the bot: %v", err)
@@ -50,7 +87,6 @@ func (d *Discord) Connect(c client.Client, addr string, token string, cap int, d
 	d.id = id.ID
 	d.session = session
 	d.responses = responses
-	d.defaultResponse = defaultResponse
 	d.cancel = cancel
 
 	d.log = logrus.New()
@@ -62,75 +98,279 @@ func (d *Discord) Connect(c client.Client, addr string, token string, cap int, d
 	})
 	d.log.Out = os.Stdout
 
-	d.wg.Add(1)
-	go func() {
-		for r := range d.responses {
-			d.session.ChannelMessageSend(r.id, r.text)
+	d.router = NewRouter(c, defaultResponse)
+	d.router.Use(Logging(d.log))
+	d.router.Use(d.middlewares...)
+	d.router.Use(Conversations(c))
+	if d.UnknownCommand != "" {
+		d.router.Use(Unknown(d.UnknownCommand))
+	}
+	if d.DeniedCommand != "" {
+		d.router.Use(Denied(d.DeniedCommand))
+	}
+	if d.HelpCommand != "" {
+		d.router.Use(Help(c, d.HelpCommand))
+	}
+	if d.Suggestions != "" {
+		d.router.Use(Suggestions(c, d.Suggestions))
+	}
+	if d.UsageInterval > 0 {
+		reporter := &usageReporter{
+			client:   c,
+			platform: "discord",
+			key:      []byte(token),
+			log:      d.log,
+		}
+		d.router.Use(reporter.middleware())
+
+		d.reporting.Add(1)
+		go func() {
+			reporter.run(d.UsageInterval, d.cancel)
+			d.reporting.Done()
+		}()
+	}
+
+	// discordgo already waits for the rate limit buckets
+	// returned by Discord but sending more than five messages every
+	// five seconds to the same channel would exhaust them anyway.
+	limiter := newSendLimiter(50, 50, 1, 5)
+	sender := newSender(limiter, func(r *Response) {
+		for i, text := range splitText(r.text, messageLimit("discord")) {
+			if i > 0 {
+				limiter.wait(r.id)
+			}
+
+			d.session.ChannelMessageSend(r.id, text)
 		}
+	})
 
+	d.wg.Add(1)
+	go func() {
+		sender.run(d.responses)
 		d.wg.Done()
 	}()
 
+	syncer := &menuSyncer{
+		client:         c,
+		help:           d.HelpCommand,
+		maxCommands:    100,
+		maxDescription: 100,
+		sync:           d.overwriteCommands,
+	}
+	go syncer.run(d.log, "discord", d.SyncInterval, d.SyncCheckInterval, d.cancel)
+
+	if d.Schedules {
+		sched := &scheduler{
+			client:   c,
+			platform: "discord",
+			bot:      d.Name,
+			log:      d.log,
+			send:     func(r *Response) { d.responses <- r },
+		}
+
+		d.scheduling.Add(1)
+		go func() {
+			sched.run(d.cancel)
+			d.scheduling.Done()
+		}()
+	}
+
 	return nil
 }
 
+// overwriteCommands replaces the slash commands of the Discord bot.
+func (d *Discord) overwriteCommands(menu []menuCommand) error {
+	if _, err := d.session.ApplicationCommandBulkOverwrite(d.id, d.GuildID, applicationCommands(menu)); err != nil {
+		return errors.Wrap(err, "while overwriting Discord's application commands")
+	}
+
+	return nil
+}
+
+// argsOption is the name of the optional slash command
+// option through which users pass arguments to a command.
+const argsOption = "args"
+
+func applicationCommands(menu []menuCommand) []*dg.ApplicationCommand {
+	commands := make([]*dg.ApplicationCommand, 0, len(menu))
+	for _, c := range menu {
+		commands = append(commands, &dg.ApplicationCommand{
+			Type:        dg.ChatApplicationCommand,
+			Name:        c.name,
+			Description: c.description,
+			Options: []*dg.ApplicationCommandOption{
+				{
+					Type:        dg.ApplicationCommandOptionString,
+					Name:        argsOption,
+					Description: "Arguments for the command",
+				},
+			},
+		})
+	}
+
+	return commands
+}
+
 // Listen handles all the messages sent to the Discord bot
-// and tries to get the response for the asked command from the botio's server
-// and submit it to the responses channel, which eventually
-// should send the response back to the client.
+// and passes them to the bot's Router, which tries to get the response
+// for the asked command from the botio's server. The reply is submitted
+// to the responses channel, which eventually should send the response
+// back to the client.
 func (d *Discord) Listen() error {
-	d.session.AddHandler(func(s *dg.Session, m *dg.MessageCreate) {
-		start := time.Now()
+	d.session.AddHandler(d.handleMessage)
+	d.session.AddHandler(d.handleInteraction)
+	return nil
+}
 
-		if m.Author.Bot {
-			return
+func (d *Discord) handleMessage(s *dg.Session, m *dg.MessageCreate) {
+	if m.Author.Bot {
+		return
+	}
+
+	msg := &Message{
+		Platform: "discord",
+		ChatID:   m.ChannelID,
+		UserID:   m.Author.ID,
+		Lang:     d.messageLocale(m),
+		Text:     m.Content,
+		Private:  m.GuildID == "",
+	}
+
+	if m.Member != nil {
+		msg.Roles = m.Member.Roles
+	}
+
+	if !msg.Private && d.session != nil && d.session.State != nil {
+		perms, err := d.session.State.UserChannelPermissions(m.Author.ID, m.ChannelID)
+		msg.Admin = err == nil && perms&dg.PermissionAdministrator != 0
+	}
+
+	for _, mention := range []string{"<@" + d.id + ">", "<@!" + d.id + ">"} {
+		if strings.HasPrefix(m.Content, mention) {
+			msg.Text = strings.TrimSpace(strings.TrimPrefix(m.Content, mention))
+			msg.Mention = true
+			break
 		}
+	}
 
-		resp := &Response{id: m.ChannelID}
-		msg := strings.Fields(m.Content)
-		if len(msg) != 2 {
-			return
+	reply, _ := d.router.Route(context.Background(), msg)
+	if reply == nil {
+		return
+	}
+
+	d.responses <- &Response{
+		id:   m.ChannelID,
+		text: reply.Text,
+	}
+}
+
+// handleInteraction answers the slash commands used on Discord. Unlike
+// messages, interactions must always be acknowledged, so the ones that
+// get no reply are acknowledged and their response deleted right away.
+func (d *Discord) handleInteraction(s *dg.Session, i *dg.InteractionCreate) {
+	msg := interactionMessage(i)
+	if msg == nil {
+		return
+	}
+
+	start := time.Now()
+	reply, _ := d.router.Route(context.Background(), msg)
+	if reply == nil {
+		if err := s.InteractionRespond(i.Interaction, &dg.InteractionResponse{
+			Type: dg.InteractionResponseDeferredChannelMessageWithSource,
+			Data: &dg.InteractionResponseData{Flags: uint64(dg.MessageFlagsEphemeral)},
+		}); err == nil {
+			s.InteractionResponseDelete(i.Interaction)
 		}
+		return
+	}
+
+	var flags uint64
+	if d.Ephemeral || reply.Ephemeral {
+		flags = uint64(dg.MessageFlagsEphemeral)
+	}
 
-		botID := "<@" + d.id + ">"
-		if msg[0] != botID {
+	chunks := splitText(reply.Text, messageLimit("discord"))
+	if err := s.InteractionRespond(i.Interaction, &dg.InteractionResponse{
+		Type: dg.InteractionResponseChannelMessageWithSource,
+		Data: &dg.InteractionResponseData{
+			Content: chunks[0],
+			Flags:   flags,
+		},
+	}); err != nil {
+		logError(d.log, "discord", "bot", "handleInteraction", msg.ChatID, msg.Text, err.Error(), "error while responding to interaction")
+		return
+	}
+
+	for _, chunk := range chunks[1:] {
+		if _, err := s.FollowupMessageCreate(i.Interaction, true, &dg.WebhookParams{
+			Content: chunk,
+			Flags:   flags,
+		}); err != nil {
+			logError(d.log, "discord", "bot", "handleInteraction", msg.ChatID, msg.Text, err.Error(), "error while sending followup message")
 			return
 		}
+	}
 
-		cmd, err := d.client.GetCommand(context.TODO(), &proto.Command{Command: msg[1]})
-		if err != nil {
-			resp.text = d.defaultResponse
-			d.responses <- resp
-
-			logError(
-				d.log,
-				"Discord",
-				"client",
-				"GetCommand",
-				m.ChannelID,
-				m.Content,
-				err.Error(),
-				"error while responding to command",
-			)
-			return
+	logInfo(d.log, "discord", msg.ChatID, msg.Text, reply.Text, "interaction responded successfully", time.Since(start))
+}
+
+// interactionMessage returns the *Message for a slash command
+// interaction. It returns nil for other kinds of interactions.
+func interactionMessage(i *dg.InteractionCreate) *Message {
+	if i.Interaction == nil || i.Type != dg.InteractionApplicationCommand {
+		return nil
+	}
+
+	data := i.ApplicationCommandData()
+	text := data.Name
+	for _, o := range data.Options {
+		if o.Name == argsOption && o.Type == dg.ApplicationCommandOptionString {
+			text += " " + o.StringValue()
 		}
+	}
 
-		resp.text = cmd.GetResp().GetResponse()
-		d.responses <- resp
-
-		logInfo(
-			d.log,
-			"Discord",
-			m.ChannelID,
-			m.Content,
-			resp.text,
-			"command responded successfully",
-			time.Since(start),
-		)
-		return
-	})
+	msg := &Message{
+		Platform: "discord",
+		ChatID:   i.ChannelID,
+		Lang:     string(i.Locale),
+		Text:     strings.TrimSpace(text),
+		Mention:  true,
+		Private:  i.GuildID == "",
+	}
 
-	return nil
+	switch {
+	case i.Member != nil && i.Member.User != nil:
+		msg.UserID = i.Member.User.ID
+	case i.User != nil:
+		msg.UserID = i.User.ID
+	}
+
+	if i.Member != nil {
+		msg.Roles = i.Member.Roles
+		msg.Admin = i.Member.Permissions&dg.PermissionAdministrator != 0
+	}
+
+	return msg
+}
+
+// messageLocale returns the locale of the author of the message if
+// Discord shares it or else the preferred locale of the guild.
+func (d *Discord) messageLocale(m *dg.MessageCreate) string {
+	if m.Author.Locale != "" {
+		return m.Author.Locale
+	}
+
+	if m.GuildID == "" || d.session == nil || d.session.State == nil {
+		return ""
+	}
+
+	guild, err := d.session.State.Guild(m.GuildID)
+	if err != nil {
+		return ""
+	}
+
+	return guild.PreferredLocale
 }
 
 // Start opens the connection to Discord.
@@ -146,8 +386,10 @@ func (d *Discord) Start() error {
 // Stop waits until the responses channel is closed
 // and then closes the Discord session.
 func (d *Discord) Stop() error {
-	close(d.responses)
 	close(d.cancel)
+	d.scheduling.Wait()
+	d.reporting.Wait()
+	close(d.responses)
 	d.wg.Wait()
 	if err := d.session.Close(); err != nil {
 		return errors.Wrap(err, "while closing a Discord session")
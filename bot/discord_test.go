@@ -0,0 +1,102 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+
+	dg "github.com/bwmarrin/discordgo"
+)
+
+func TestInteractionMessage(t *testing.T) {
+	tt := []struct {
+		name            string
+		interaction     *dg.Interaction
+		expectedMessage *Message
+	}{
+		{
+			name: "slash command in guild",
+			interaction: &dg.Interaction{
+				Type:      dg.InteractionApplicationCommand,
+				GuildID:   "g",
+				ChannelID: "1",
+				Member:    &dg.Member{User: &dg.User{ID: "a"}},
+				Data:      dg.ApplicationCommandInteractionData{Name: "start"},
+			},
+			expectedMessage: &Message{Platform: "discord", ChatID: "1", UserID: "a", Text: "start", Mention: true},
+		},
+		{
+			name: "slash command by an administrator with roles",
+			interaction: &dg.Interaction{
+				Type:      dg.InteractionApplicationCommand,
+				GuildID:   "g",
+				ChannelID: "1",
+				Member: &dg.Member{
+					User:        &dg.User{ID: "a"},
+					Roles:       []string{"r1", "r2"},
+					Permissions: dg.PermissionAdministrator | dg.PermissionSendMessages,
+				},
+				Data: dg.ApplicationCommandInteractionData{Name: "deploy"},
+			},
+			expectedMessage: &Message{
+				Platform: "discord",
+				ChatID:   "1",
+				UserID:   "a",
+				Text:     "deploy",
+				Mention:  true,
+				Roles:    []string{"r1", "r2"},
+				Admin:    true,
+			},
+		},
+		{
+			name: "slash command with arguments in DM",
+			interaction: &dg.Interaction{
+				Type:      dg.InteractionApplicationCommand,
+				ChannelID: "2",
+				User:      &dg.User{ID: "b"},
+				Data: dg.ApplicationCommandInteractionData{
+					Name: "help",
+					Options: []*dg.ApplicationCommandInteractionDataOption{
+						{Name: argsOption, Type: dg.ApplicationCommandOptionString, Value: "2"},
+					},
+				},
+			},
+			expectedMessage: &Message{Platform: "discord", ChatID: "2", UserID: "b", Text: "help 2", Mention: true, Private: true},
+		},
+		{
+			name:        "other interaction",
+			interaction: &dg.Interaction{Type: dg.InteractionMessageComponent},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := interactionMessage(&dg.InteractionCreate{Interaction: tc.interaction})
+			if tc.expectedMessage == nil {
+				if msg != nil {
+					t.Fatalf("expected no message. got=%+v", msg)
+				}
+				return
+			}
+
+			if msg == nil || !reflect.DeepEqual(msg, tc.expectedMessage) {
+				t.Fatalf("expected message %+v. got=%+v", tc.expectedMessage, msg)
+			}
+		})
+	}
+}
+
+func TestApplicationCommands(t *testing.T) {
+	commands := applicationCommands([]menuCommand{{name: "start", description: "Says hi"}})
+	if len(commands) != 1 {
+		t.Fatalf("expected %v application commands. got=%v", 1, len(commands))
+	}
+
+	c := commands[0]
+	if c.Name != "start" || c.Description != "Says hi" || c.Type != dg.ChatApplicationCommand {
+		t.Fatalf("unexpected application command %+v", c)
+	}
+
+	if len(c.Options) != 1 || c.Options[0].Name != argsOption || c.Options[0].Required {
+		t.Fatalf("expected application command to have an optional %q option. got=%+v", argsOption, c.Options)
+	}
+}
@@ -0,0 +1,151 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/danielkvist/botio/access"
+	"github.com/danielkvist/botio/client"
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+)
+
+// messageLimits are the maximum number of characters
+// that each platform allows on a single message.
+var messageLimits = map[string]int{
+	"telegram": 4096,
+	"discord":  2000,
+}
+
+const defaultMessageLimit = 2000
+
+func messageLimit(platform string) int {
+	if l, ok := messageLimits[platform]; ok {
+		return l
+	}
+
+	return defaultMessageLimit
+}
+
+// Help returns a Middleware that answers the command with the
+// received name listing the visible commands stored on the botio's
+// server that the user is allowed to use on the chat with their
+// descriptions. The list is split into pages that
+// fit into a single message of the platform, which can be requested
+// passing the page number as argument, like "/help 2".
+func Help(c client.Client, name string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, m *Message) (*Reply, error) {
+			if !m.Mention || m.Command() != name {
+				return next(ctx, m)
+			}
+
+			commands, err := c.ListCommands(ctx, &empty.Empty{})
+			if err != nil {
+				return nil, errors.Wrap(err, "while listing commands for help")
+			}
+
+			page := 1
+			if args := m.Args(); len(args) > 0 {
+				if n, err := strconv.Atoi(args[0]); err == nil {
+					page = n
+				}
+			}
+
+			return &Reply{Text: helpPage(visibleCommands(commands.GetCommands(), m.caller()), name, page, messageLimit(m.Platform))}, nil
+		}
+	}
+}
+
+// visibleCommands returns the commands that are not hidden and that
+// the caller is allowed to use sorted by name.
+func visibleCommands(commands []*proto.BotCommand, caller *proto.Caller) []*proto.BotCommand {
+	var visible []*proto.BotCommand
+	for _, cmd := range commands {
+		if cmd.GetHidden() || cmd.GetCmd().GetCommand() == "" {
+			continue
+		}
+
+		if caller != nil && access.Check(cmd, caller) != nil {
+			continue
+		}
+
+		visible = append(visible, cmd)
+	}
+
+	sort.Slice(visible, func(i, j int) bool {
+		return visible[i].GetCmd().GetCommand() < visible[j].GetCmd().GetCommand()
+	})
+
+	return visible
+}
+
+// helpPage returns the requested page of the help for the received
+// commands. Pages out of range return the nearest existing page.
+func helpPage(commands []*proto.BotCommand, name string, page int, limit int) string {
+	if len(commands) == 0 {
+		return "There are no commands available."
+	}
+
+	lines := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		line := "/" + cmd.GetCmd().GetCommand()
+		if aliases := cmd.GetAliases(); len(aliases) > 0 {
+			line += " (/" + strings.Join(aliases, ", /") + ")"
+		}
+
+		if d := cmd.GetDescription(); d != "" {
+			line += " - " + d
+		}
+
+		lines = append(lines, line)
+	}
+
+	// Leave room for the footer that links to the next page.
+	footer := "\n\nPage %d/%d. Send \"/%s %d\" for more."
+	pages := splitText(strings.Join(lines, "\n"), limit-len(footer)-len(name)-20)
+
+	switch {
+	case page < 1:
+		page = 1
+	case page > len(pages):
+		page = len(pages)
+	}
+
+	text := pages[page-1]
+	if page < len(pages) {
+		text += fmt.Sprintf(footer, page, len(pages), name, page+1)
+	}
+
+	return text
+}
+
+// splitText splits the received text into chunks of at most limit
+// characters, cutting preferably at line breaks.
+func splitText(text string, limit int) []string {
+	if limit < 1 {
+		limit = 1
+	}
+
+	var chunks []string
+	runes := []rune(text)
+	for len(runes) > limit {
+		cut := limit
+		for i := limit; i > 0; i-- {
+			if runes[i] == '\n' {
+				cut = i
+				break
+			}
+		}
+
+		chunks = append(chunks, string(runes[:cut]))
+		runes = []rune(strings.TrimPrefix(string(runes[cut:]), "\n"))
+	}
+
+	return append(chunks, string(runes))
+}
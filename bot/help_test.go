@@ -0,0 +1,109 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+)
+
+func TestHelp(t *testing.T) {
+	c := testClient(map[string]string{"start": "hi"})
+	c.listed = []*proto.BotCommand{
+		{Cmd: &proto.Command{Command: "start"}, Resp: &proto.Response{Response: "hi"}, Description: "Says hi"},
+		{Cmd: &proto.Command{Command: "about"}, Aliases: []string{"info", "faq"}},
+		{Cmd: &proto.Command{Command: "secret"}, Hidden: true},
+		{Cmd: &proto.Command{Command: "ban"}, Access: &proto.Access{AdminsOnly: true}},
+	}
+
+	tt := []struct {
+		name          string
+		message       *Message
+		expectedReply string
+	}{
+		{
+			name:          "help",
+			message:       &Message{Text: "/help", Mention: true},
+			expectedReply: "/about (/info, /faq)\n/start - Says hi",
+		},
+		{
+			name:          "help for an administrator",
+			message:       &Message{Text: "/help", Mention: true, Admin: true},
+			expectedReply: "/about (/info, /faq)\n/ban\n/start - Says hi",
+		},
+		{
+			name:          "help with invalid page",
+			message:       &Message{Text: "/help two", Mention: true},
+			expectedReply: "/about (/info, /faq)\n/start - Says hi",
+		},
+		{
+			name:          "other command",
+			message:       &Message{Text: "/start", Mention: true},
+			expectedReply: "hi",
+		},
+	}
+
+	r := NewRouter(c, "default")
+	r.Use(Help(c, "help"))
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			reply, err := r.Route(context.TODO(), tc.message)
+			if err != nil {
+				t.Fatalf("while routing message: %v", err)
+			}
+
+			if reply.Text != tc.expectedReply {
+				t.Fatalf("expected reply %q. got=%q", tc.expectedReply, reply.Text)
+			}
+		})
+	}
+}
+
+func TestHelpPages(t *testing.T) {
+	var commands []*proto.BotCommand
+	for i := 0; i < 200; i++ {
+		commands = append(commands, &proto.BotCommand{
+			Cmd:         &proto.Command{Command: fmt.Sprintf("command%03d", i)},
+			Description: strings.Repeat("x", 20),
+		})
+	}
+
+	limit := messageLimit("discord")
+	seen := 0
+	for page := 1; ; page++ {
+		text := helpPage(commands, "help", page, limit)
+		if len(text) > limit {
+			t.Fatalf("page %v has %v characters, more than the limit of %v", page, len(text), limit)
+		}
+
+		seen += strings.Count(text, "/command")
+		if !strings.Contains(text, fmt.Sprintf("/help %d", page+1)) {
+			break
+		}
+	}
+
+	if seen != len(commands) {
+		t.Fatalf("expected %v commands listed across pages. got=%v", len(commands), seen)
+	}
+}
+
+func TestSplitText(t *testing.T) {
+	tt := []struct {
+		text           string
+		limit          int
+		expectedChunks []string
+	}{
+		{text: "hello", limit: 10, expectedChunks: []string{"hello"}},
+		{text: "hello\nworld", limit: 8, expectedChunks: []string{"hello", "world"}},
+		{text: "helloworld", limit: 5, expectedChunks: []string{"hello", "world"}},
+	}
+
+	for _, tc := range tt {
+		chunks := splitText(tc.text, tc.limit)
+		if strings.Join(chunks, "|") != strings.Join(tc.expectedChunks, "|") {
+			t.Fatalf("expected chunks %q for %q. got=%q", tc.expectedChunks, tc.text, chunks)
+		}
+	}
+}
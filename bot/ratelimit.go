@@ -0,0 +1,317 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimitConfig represents the limits applied to the messages
+// received by a bot. Rates are expressed in messages per second
+// and a rate of zero disables the respective limit.
+type RateLimitConfig struct {
+	UserRate         float64
+	UserBurst        int
+	ChatRate         float64
+	ChatBurst        int
+	GlobalRate       float64
+	GlobalBurst      int
+	Cooldown         time.Duration
+	SlowDownResponse string
+}
+
+// RateLimit returns a Middleware that applies token-bucket rate limits
+// per user, per chat and globally to the received messages, as well as
+// a cooldown per command and chat. Messages exceeding the limits are
+// dropped before reaching the botio's server, including the ones that
+// don't mention the bot, which have their own buckets and no cooldown. If the configuration has
+// a SlowDownResponse it is sent once to the user or chat that exceeded
+// the limit until the limit allows new messages again.
+func RateLimit(cfg RateLimitConfig) Middleware {
+	users := newLimiters(cfg.UserRate, cfg.UserBurst)
+	chats := newLimiters(cfg.ChatRate, cfg.ChatBurst)
+	global := newLimiters(cfg.GlobalRate, cfg.GlobalBurst)
+	cooldowns := newCooldowns(cfg.Cooldown)
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, m *Message) (*Reply, error) {
+			// Messages that don't mention the bot are only matched
+			// against the triggers. They are limited on their own
+			// buckets, so the chatter of a channel doesn't use up the
+			// limits of its commands, and dropped without warning.
+			prefix := ""
+			if !m.Mention {
+				prefix = "match:"
+			}
+
+			allowed := true
+			warn := false
+			for _, l := range []struct {
+				limiters *limiters
+				key      string
+			}{
+				{users, prefix + m.Platform + ":" + m.UserID},
+				{chats, prefix + m.Platform + ":" + m.ChatID},
+				{global, prefix + m.Platform},
+			} {
+				ok, warned := l.limiters.allow(l.key)
+				if !ok {
+					allowed = false
+					warn = warn || !warned
+					break
+				}
+			}
+
+			if !m.Mention {
+				if !allowed {
+					return nil, nil
+				}
+
+				return next(ctx, m)
+			}
+
+			if allowed && !cooldowns.allow(m.Platform+":"+m.ChatID+":"+m.Command()) {
+				return nil, nil
+			}
+
+			if !allowed {
+				if warn && cfg.SlowDownResponse != "" {
+					return &Reply{Text: cfg.SlowDownResponse, Ephemeral: true}, nil
+				}
+
+				return nil, nil
+			}
+
+			return next(ctx, m)
+		}
+	}
+}
+
+// limiters keeps a token bucket for each key. Buckets that
+// have not been used in a while are removed to not grow forever.
+type limiters struct {
+	mu        sync.Mutex
+	limit     rate.Limit
+	burst     int
+	entries   map[string]*limiterEntry
+	lastSweep time.Time
+}
+
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+	warned   bool
+}
+
+const limitersIdleTTL = 10 * time.Minute
+
+func newLimiters(r float64, burst int) *limiters {
+	if r <= 0 {
+		return nil
+	}
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &limiters{
+		limit:     rate.Limit(r),
+		burst:     burst,
+		entries:   make(map[string]*limiterEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// allow reports if a new event for the received key is allowed
+// and, if not, whether the key was already warned about it.
+func (l *limiters) allow(key string) (ok bool, warned bool) {
+	if l == nil {
+		return true, false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e := l.entry(key)
+	if e.limiter.Allow() {
+		e.warned = false
+		return true, false
+	}
+
+	warned = e.warned
+	e.warned = true
+	return false, warned
+}
+
+// wait blocks until an event for the received key is allowed.
+func (l *limiters) wait(key string) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	r := l.entry(key).limiter.Reserve()
+	l.mu.Unlock()
+
+	time.Sleep(r.Delay())
+}
+
+func (l *limiters) entry(key string) *limiterEntry {
+	now := time.Now()
+	if now.Sub(l.lastSweep) > limitersIdleTTL {
+		for k, e := range l.entries {
+			if now.Sub(e.lastSeen) > limitersIdleTTL {
+				delete(l.entries, k)
+			}
+		}
+
+		l.lastSweep = now
+	}
+
+	e, ok := l.entries[key]
+	if !ok {
+		e = &limiterEntry{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.entries[key] = e
+	}
+
+	e.lastSeen = now
+	return e
+}
+
+// cooldowns keeps track of the last time a key was allowed.
+type cooldowns struct {
+	mu        sync.Mutex
+	duration  time.Duration
+	last      map[string]time.Time
+	lastSweep time.Time
+}
+
+func newCooldowns(d time.Duration) *cooldowns {
+	if d <= 0 {
+		return nil
+	}
+
+	return &cooldowns{
+		duration:  d,
+		last:      make(map[string]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+func (c *cooldowns) allow(key string) bool {
+	if c == nil {
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := c.last[key]; ok && now.Sub(last) < c.duration {
+		return false
+	}
+
+	if now.Sub(c.lastSweep) > c.duration {
+		for k, last := range c.last {
+			if now.Sub(last) >= c.duration {
+				delete(c.last, k)
+			}
+		}
+
+		c.lastSweep = now
+	}
+
+	c.last[key] = now
+	return true
+}
+
+// sendLimiter respects the limits imposed by a platform
+// to the messages sent by a bot, globally and per chat.
+type sendLimiter struct {
+	global *limiters
+	chats  *limiters
+}
+
+func newSendLimiter(globalRate float64, globalBurst int, chatRate float64, chatBurst int) *sendLimiter {
+	return &sendLimiter{
+		global: newLimiters(globalRate, globalBurst),
+		chats:  newLimiters(chatRate, chatBurst),
+	}
+}
+
+// wait blocks until a new message can be sent to the received chat.
+func (s *sendLimiter) wait(chatID string) {
+	s.chats.wait(chatID)
+	s.global.wait("")
+}
+
+// sender sends the responses of a bot through send respecting the
+// limits of its sendLimiter. Each chat has its own queue drained by
+// its own goroutine, so a chat that exceeds its limit only delays
+// its own responses, while the global limit is shared by all of them.
+type sender struct {
+	limiter *sendLimiter
+	send    func(r *Response)
+	mu      sync.Mutex
+	queues  map[string][]*Response
+	wg      sync.WaitGroup
+}
+
+func newSender(limiter *sendLimiter, send func(r *Response)) *sender {
+	return &sender{
+		limiter: limiter,
+		send:    send,
+		queues:  make(map[string][]*Response),
+	}
+}
+
+// run queues the responses received from the channel until it's
+// closed and then waits until all the queued responses are sent.
+func (s *sender) run(responses <-chan *Response) {
+	for r := range responses {
+		s.enqueue(r)
+	}
+
+	s.wg.Wait()
+}
+
+// enqueue adds the response to the queue of its chat
+// starting to drain it if it was empty.
+func (s *sender) enqueue(r *Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue, draining := s.queues[r.id]
+	s.queues[r.id] = append(queue, r)
+	if draining {
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		s.drain(r.id)
+		s.wg.Done()
+	}()
+}
+
+// drain sends the responses queued for the chat until its queue is empty.
+func (s *sender) drain(chatID string) {
+	for {
+		s.mu.Lock()
+		queue := s.queues[chatID]
+		if len(queue) == 0 {
+			delete(s.queues, chatID)
+			s.mu.Unlock()
+			return
+		}
+
+		r := queue[0]
+		s.queues[chatID] = queue[1:]
+		s.mu.Unlock()
+
+		s.limiter.wait(chatID)
+		s.send(r)
+	}
+}
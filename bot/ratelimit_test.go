@@ -0,0 +1,132 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	tt := []struct {
+		name            string
+		config          RateLimitConfig
+		messages        []*Message
+		expectedReplies []string
+	}{
+		{
+			name:   "per user",
+			config: RateLimitConfig{UserRate: 0.001, UserBurst: 2},
+			messages: []*Message{
+				{UserID: "a", ChatID: "1", Text: "start", Mention: true},
+				{UserID: "a", ChatID: "1", Text: "start", Mention: true},
+				{UserID: "a", ChatID: "1", Text: "start", Mention: true},
+				{UserID: "b", ChatID: "1", Text: "start", Mention: true},
+			},
+			expectedReplies: []string{"hi", "hi", "", "hi"},
+		},
+		{
+			name:   "per chat with slow down response",
+			config: RateLimitConfig{ChatRate: 0.001, ChatBurst: 1, SlowDownResponse: "slow down"},
+			messages: []*Message{
+				{UserID: "a", ChatID: "1", Text: "start", Mention: true},
+				{UserID: "b", ChatID: "1", Text: "start", Mention: true},
+				{UserID: "c", ChatID: "1", Text: "start", Mention: true},
+				{UserID: "a", ChatID: "2", Text: "start", Mention: true},
+			},
+			expectedReplies: []string{"hi", "slow down", "", "hi"},
+		},
+		{
+			name:   "global",
+			config: RateLimitConfig{GlobalRate: 0.001, GlobalBurst: 1},
+			messages: []*Message{
+				{UserID: "a", ChatID: "1", Text: "start", Mention: true},
+				{UserID: "b", ChatID: "2", Text: "start", Mention: true},
+			},
+			expectedReplies: []string{"hi", ""},
+		},
+		{
+			name:   "messages without mention",
+			config: RateLimitConfig{ChatRate: 0.001, ChatBurst: 2, SlowDownResponse: "slow down"},
+			messages: []*Message{
+				{UserID: "a", ChatID: "1", Text: "hello there"},
+				{UserID: "b", ChatID: "1", Text: "hello there"},
+				{UserID: "c", ChatID: "1", Text: "hello there"},
+				{UserID: "d", ChatID: "1", Text: "hello there"},
+				{UserID: "a", ChatID: "1", Text: "start", Mention: true},
+				{UserID: "a", ChatID: "2", Text: "hello there"},
+			},
+			expectedReplies: []string{"hey", "hey", "", "", "hi", "hey"},
+		},
+		{
+			name:   "cooldown",
+			config: RateLimitConfig{Cooldown: time.Hour},
+			messages: []*Message{
+				{UserID: "a", ChatID: "1", Text: "start", Mention: true},
+				{UserID: "b", ChatID: "1", Text: "start", Mention: true},
+				{UserID: "a", ChatID: "1", Text: "help", Mention: true},
+				{UserID: "a", ChatID: "2", Text: "start", Mention: true},
+			},
+			expectedReplies: []string{"hi", "", "help", "hi"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c := testClient(map[string]string{"start": "hi", "help": "help"})
+			c.triggers = map[string]string{"hello": "hey"}
+			r := NewRouter(c, "default")
+			r.Use(RateLimit(tc.config))
+
+			for i, m := range tc.messages {
+				reply, err := r.Route(context.TODO(), m)
+				if err != nil {
+					t.Fatalf("(%v) while routing message: %v", i, err)
+				}
+
+				var text string
+				if reply != nil {
+					text = reply.Text
+				}
+
+				if text != tc.expectedReplies[i] {
+					t.Fatalf("(%v) expected reply %q. got=%q", i, tc.expectedReplies[i], text)
+				}
+			}
+		})
+	}
+}
+
+func TestSender(t *testing.T) {
+	var mu sync.Mutex
+	var sent []string
+	s := newSender(newSendLimiter(1000, 1000, 5, 1), func(r *Response) {
+		mu.Lock()
+		sent = append(sent, r.id+":"+r.text)
+		mu.Unlock()
+	})
+
+	responses := make(chan *Response, 4)
+	responses <- &Response{id: "1", text: "a"}
+	responses <- &Response{id: "1", text: "b"}
+	responses <- &Response{id: "1", text: "c"}
+	responses <- &Response{id: "2", text: "d"}
+	close(responses)
+
+	start := time.Now()
+	s.run(responses)
+
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Fatalf("expected the responses to the same chat to be limited. took %v", elapsed)
+	}
+
+	// The responses to the second chat don't wait for the first one.
+	expected := []string{"1:a", "2:d", "1:b", "1:c"}
+	if len(sent) != len(expected) {
+		t.Fatalf("expected %v responses sent. got=%v", len(expected), sent)
+	}
+
+	if sent[2] != expected[2] || sent[3] != expected[3] || (sent[0] != "1:a" && sent[0] != "2:d") || (sent[1] != "1:a" && sent[1] != "2:d") {
+		t.Fatalf("expected responses sent in order %v. got=%v", expected, sent)
+	}
+}
@@ -0,0 +1,77 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/danielkvist/botio/client"
+	"github.com/danielkvist/botio/proto"
+)
+
+// maxSuggestions is the maximum number of similar
+// commands suggested for a mistyped one.
+const maxSuggestions = 3
+
+// Suggestions returns a Middleware that, when a command is not stored
+// on the botio's server, asks the botio's server to resolve it. If the command matches
+// a stored one ignoring the case it's answered with the stored command,
+// otherwise the similar commands are suggested using the received format,
+// like "Did you mean %s?", with a button for each one of them.
+func Suggestions(c client.Client, format string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, m *Message) (*Reply, error) {
+			reply, err := next(ctx, m)
+			if !notFound(err) || !m.Mention || m.Command() == "" {
+				return reply, err
+			}
+
+			resp, rerr := c.ResolveCommand(ctx, &proto.ResolveRequest{
+				Command: m.Command(),
+				Limit:   maxSuggestions,
+				Lang:    m.Lang,
+				Caller:  m.caller(),
+				Args:    m.Args(),
+			})
+			if rerr != nil {
+				return reply, err
+			}
+
+			if match := resp.GetMatch(); match != nil {
+				return &Reply{
+					Text:    match.GetResp().GetResponse(),
+					Buttons: buttons(match.GetResp()),
+					flow:    match.GetFlow() != nil,
+					command: match.GetCmd().GetCommand(),
+				}, nil
+			}
+
+			suggestions := resp.GetSuggestions()
+			if len(suggestions) == 0 {
+				return reply, err
+			}
+
+			suggested := &Reply{Text: fmt.Sprintf(format, suggestionList(suggestions))}
+			for _, s := range suggestions {
+				suggested.Buttons = append(suggested.Buttons, Button{Text: "/" + s, Command: s})
+			}
+
+			return suggested, nil
+		}
+	}
+}
+
+// suggestionList joins the received commands
+// like "/start, /stop or /status".
+func suggestionList(commands []string) string {
+	names := make([]string, len(commands))
+	for i, c := range commands {
+		names[i] = "/" + c
+	}
+
+	if len(names) == 1 {
+		return names[0]
+	}
+
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSuggestions(t *testing.T) {
+	c := testClient(map[string]string{
+		"start":  "hi",
+		"status": "ok",
+		"stop":   "bye",
+	})
+
+	tt := []struct {
+		name            string
+		message         *Message
+		expectedReply   string
+		expectedButtons []Button
+		expectedToFail  bool
+	}{
+		{
+			name:          "existing command",
+			message:       &Message{Text: "/start", Mention: true},
+			expectedReply: "hi",
+		},
+		{
+			name:          "command with different case",
+			message:       &Message{Text: "/STOP", Mention: true},
+			expectedReply: "bye",
+		},
+		{
+			name:          "command with different case and args",
+			message:       &Message{Text: "/Stop now", Mention: true},
+			expectedReply: "bye now",
+		},
+		{
+			name:            "one suggestion",
+			message:         &Message{Text: "/sto", Mention: true},
+			expectedReply:   "Did you mean /stop?",
+			expectedButtons: []Button{{Text: "/stop", Command: "stop"}},
+		},
+		{
+			name:          "several suggestions",
+			message:       &Message{Text: "/st", Mention: true},
+			expectedReply: "Did you mean /start, /status or /stop?",
+			expectedButtons: []Button{
+				{Text: "/start", Command: "start"},
+				{Text: "/status", Command: "status"},
+				{Text: "/stop", Command: "stop"},
+			},
+		},
+		{
+			name:           "without suggestions",
+			message:        &Message{Text: "/weather", Mention: true},
+			expectedReply:  "default",
+			expectedToFail: true,
+		},
+	}
+
+	r := NewRouter(c, "default")
+	r.Use(Suggestions(c, "Did you mean %s?"))
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			reply, err := r.Route(context.TODO(), tc.message)
+			if err != nil && !tc.expectedToFail {
+				t.Fatalf("while routing message: %v", err)
+			}
+
+			if err == nil && tc.expectedToFail {
+				t.Fatalf("routing message not failed as expected")
+			}
+
+			if reply.Text != tc.expectedReply {
+				t.Fatalf("expected reply %q. got=%q", tc.expectedReply, reply.Text)
+			}
+
+			if len(reply.Buttons) != len(tc.expectedButtons) {
+				t.Fatalf("expected %v buttons. got=%v", len(tc.expectedButtons), len(reply.Buttons))
+			}
+
+			for i, b := range reply.Buttons {
+				if b != tc.expectedButtons[i] {
+					t.Fatalf("expected button %v. got=%v", tc.expectedButtons[i], b)
+				}
+			}
+		})
+	}
+}
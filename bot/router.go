@@ -0,0 +1,244 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/danielkvist/botio/client"
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Message represents a platform-independent message received by a bot.
+// Lang holds the languages of the user as BCP 47 tags, if known.
+// Roles, Admin and Private describe the user and the chat so the
+// botio's server can apply the access rules of the commands.
+type Message struct {
+	Platform string
+	ChatID   string
+	UserID   string
+	Lang     string
+	Text     string
+	Mention  bool
+	Roles    []string
+	Admin    bool
+	Private  bool
+}
+
+// Command returns the command requested on the Message, which is
+// its first word without the leading slash.
+func (m *Message) Command() string {
+	fields := strings.Fields(m.Text)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	return strings.TrimPrefix(fields[0], "/")
+}
+
+// Args returns the words that follow the command on the Message.
+func (m *Message) Args() []string {
+	fields := strings.Fields(m.Text)
+	if len(fields) < 2 {
+		return nil
+	}
+
+	return fields[1:]
+}
+
+func (m *Message) caller() *proto.Caller {
+	return &proto.Caller{
+		Platform: m.Platform,
+		ChatId:   m.ChatID,
+		UserId:   m.UserID,
+		Roles:    m.Roles,
+		Admin:    m.Admin,
+		Private:  m.Private,
+	}
+}
+
+// Reply represents the answer of a bot to a Message. Ephemeral
+// replies are only shown to the user that sent the Message on
+// the platforms that support it.
+type Reply struct {
+	Text      string
+	Ephemeral bool
+	Buttons   []Button
+	// flow reports whether the Reply is the response of a command
+	// with a flow, whose dialog is started by the Conversations.
+	flow bool
+	// command is the name of the command that answered the
+	// Message, which differs from its Command for aliases,
+	// mistyped commands and triggers.
+	command string
+}
+
+// Button represents a button attached to a Reply
+// that sends Command to the bot when pressed.
+type Button struct {
+	Text    string
+	Command string
+}
+
+// Handler resolves a Message into a Reply. A nil Reply means
+// that the Message should not be answered.
+type Handler func(ctx context.Context, m *Message) (*Reply, error)
+
+// Middleware wraps a Handler to run code before and after it.
+type Middleware func(next Handler) Handler
+
+// Router resolves the messages received by any platform
+// running them through a chain of middlewares before asking
+// the botio's server for the requested command or, if there
+// is none or the message doesn't mention the bot, for the
+// command whose trigger matches the message.
+type Router struct {
+	client          client.Client
+	defaultResponse string
+	middlewares     []Middleware
+}
+
+// NewRouter returns a *Router that resolves the commands using
+// the received client and answers with defaultResponse when
+// something goes wrong.
+func NewRouter(c client.Client, defaultResponse string) *Router {
+	return &Router{
+		client:          c,
+		defaultResponse: defaultResponse,
+	}
+}
+
+// Use appends the received middlewares to the Router's chain. The
+// middlewares are run in the same order in which they were added.
+func (r *Router) Use(mws ...Middleware) {
+	r.middlewares = append(r.middlewares, mws...)
+}
+
+// Route runs the received Message through the chain of middlewares
+// and returns the Reply for it. If something goes wrong it returns
+// a Reply with the default response alongside a non-nil error.
+func (r *Router) Route(ctx context.Context, m *Message) (*Reply, error) {
+	h := r.resolve
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		h = r.middlewares[i](h)
+	}
+
+	reply, err := h(ctx, m)
+	if err != nil {
+		return &Reply{Text: r.defaultResponse}, err
+	}
+
+	return reply, nil
+}
+
+func (r *Router) resolve(ctx context.Context, m *Message) (*Reply, error) {
+	if !m.Mention {
+		return r.match(ctx, m)
+	}
+
+	command := m.Command()
+	if command == "" {
+		return nil, nil
+	}
+
+	cmd, err := r.client.GetCommand(ctx, &proto.Command{
+		Command: command,
+		Lang:    m.Lang,
+		Args:    m.Args(),
+		Caller:  m.caller(),
+	})
+	if notFound(err) {
+		if reply, merr := r.match(ctx, m); merr == nil && reply != nil {
+			return reply, nil
+		}
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "while getting command %q", command)
+	}
+
+	return &Reply{
+		Text:    cmd.GetResp().GetResponse(),
+		Buttons: buttons(cmd.GetResp()),
+		flow:    cmd.GetFlow() != nil,
+		command: cmd.GetCmd().GetCommand(),
+	}, nil
+}
+
+// match answers the Message with the response of the command whose
+// trigger matches it. Messages that match no trigger are not answered.
+func (r *Router) match(ctx context.Context, m *Message) (*Reply, error) {
+	if strings.TrimSpace(m.Text) == "" {
+		return nil, nil
+	}
+
+	cmd, err := r.client.MatchMessage(ctx, &proto.MatchRequest{
+		Text:   m.Text,
+		Lang:   m.Lang,
+		Caller: m.caller(),
+	})
+	if notFound(err) || denied(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "while matching message")
+	}
+
+	return &Reply{
+		Text:    cmd.GetResp().GetResponse(),
+		Buttons: buttons(cmd.GetResp()),
+		command: cmd.GetCmd().GetCommand(),
+	}, nil
+}
+
+func buttons(resp *proto.Response) []Button {
+	var buttons []Button
+	for _, b := range resp.GetButtons() {
+		buttons = append(buttons, Button{Text: b.GetText(), Command: b.GetCommand()})
+	}
+
+	return buttons
+}
+
+// Logging returns a Middleware that logs with the received
+// logger every Message handled and its outcome.
+func Logging(log *logrus.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, m *Message) (*Reply, error) {
+			start := time.Now()
+
+			reply, err := next(ctx, m)
+			if err != nil {
+				logError(
+					log,
+					m.Platform,
+					"bot",
+					"Route",
+					m.ChatID,
+					m.Text,
+					err.Error(),
+					"error while responding to command",
+				)
+				return reply, err
+			}
+
+			if reply != nil {
+				logInfo(
+					log,
+					m.Platform,
+					m.ChatID,
+					m.Text,
+					reply.Text,
+					"command responded successfully",
+					time.Since(start),
+				)
+			}
+
+			return reply, nil
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package bot
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/danielkvist/botio/access"
+	"github.com/danielkvist/botio/client"
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRoute(t *testing.T) {
+	tt := []struct {
+		name          string
+		message       *Message
+		expectedReply string
+		expectedNil   bool
+		expectedError bool
+	}{
+		{
+			name:          "existing command",
+			message:       &Message{Text: "/start", Mention: true},
+			expectedReply: "hi",
+		},
+		{
+			name:          "existing command with arguments",
+			message:       &Message{Text: "start now", Mention: true},
+			expectedReply: "hi",
+		},
+		{
+			name:          "non-existing command",
+			message:       &Message{Text: "/end", Mention: true},
+			expectedReply: "default",
+			expectedError: true,
+		},
+		{
+			name:        "without mention",
+			message:     &Message{Text: "/start"},
+			expectedNil: true,
+		},
+		{
+			name:        "empty message",
+			message:     &Message{Text: " ", Mention: true},
+			expectedNil: true,
+		},
+		{
+			name:          "trigger without mention",
+			message:       &Message{Text: "should we deploy?"},
+			expectedReply: "deploying",
+		},
+		{
+			name:          "trigger with mention",
+			message:       &Message{Text: "deploy now", Mention: true},
+			expectedReply: "deploying",
+		},
+	}
+
+	c := testClient(map[string]string{"start": "hi"})
+	c.triggers = map[string]string{"deploy": "deploying"}
+
+	r := NewRouter(c, "default")
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			reply, err := r.Route(context.TODO(), tc.message)
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("expected error to be %v. got=%v", tc.expectedError, err)
+			}
+
+			if tc.expectedNil {
+				if reply != nil {
+					t.Fatalf("expected no reply. got=%q", reply.Text)
+				}
+				return
+			}
+
+			if reply == nil {
+				t.Fatalf("expected reply %q. got=nil", tc.expectedReply)
+			}
+
+			if reply.Text != tc.expectedReply {
+				t.Fatalf("expected reply %q. got=%q", tc.expectedReply, reply.Text)
+			}
+		})
+	}
+}
+
+func TestRouteMiddlewares(t *testing.T) {
+	var order []string
+	mw := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, m *Message) (*Reply, error) {
+				order = append(order, name)
+				return next(ctx, m)
+			}
+		}
+	}
+
+	blocker := func(next Handler) Handler {
+		return func(ctx context.Context, m *Message) (*Reply, error) {
+			if m.UserID == "banned" {
+				return &Reply{Text: "blocked"}, nil
+			}
+
+			return next(ctx, m)
+		}
+	}
+
+	r := NewRouter(testClient(map[string]string{"start": "hi"}), "default")
+	r.Use(mw("first"), mw("second"), blocker)
+
+	reply, err := r.Route(context.TODO(), &Message{Text: "/start", Mention: true})
+	if err != nil {
+		t.Fatalf("while routing message: %v", err)
+	}
+
+	if reply.Text != "hi" {
+		t.Fatalf("expected reply %q. got=%q", "hi", reply.Text)
+	}
+
+	if len(order) != 2 || order[0] != "first" || order[1] != "second" {
+		t.Fatalf("expected middlewares to run in order [first second]. got=%v", order)
+	}
+
+	reply, err = r.Route(context.TODO(), &Message{Text: "/start", UserID: "banned", Mention: true})
+	if err != nil {
+		t.Fatalf("while routing message: %v", err)
+	}
+
+	if reply.Text != "blocked" {
+		t.Fatalf("expected reply %q. got=%q", "blocked", reply.Text)
+	}
+}
+
+func TestMessageCommand(t *testing.T) {
+	tt := []struct {
+		text            string
+		expectedCommand string
+		expectedArgs    int
+	}{
+		{text: "/start", expectedCommand: "start"},
+		{text: "start", expectedCommand: "start"},
+		{text: "/weather madrid today", expectedCommand: "weather", expectedArgs: 2},
+		{text: ""},
+	}
+
+	for _, tc := range tt {
+		m := &Message{Text: tc.text}
+		if m.Command() != tc.expectedCommand {
+			t.Fatalf("expected command %q for text %q. got=%q", tc.expectedCommand, tc.text, m.Command())
+		}
+
+		if len(m.Args()) != tc.expectedArgs {
+			t.Fatalf("expected %v args for text %q. got=%v", tc.expectedArgs, tc.text, len(m.Args()))
+		}
+	}
+}
+
+type fakeClient struct {
+	client.Client
+	commands map[string]string
+	listed   []*proto.BotCommand
+	converse func(*proto.ConverseRequest) (*proto.ConverseResponse, error)
+	triggers map[string]string
+	token    string
+}
+
+func testClient(commands map[string]string) *fakeClient {
+	return &fakeClient{commands: commands}
+}
+
+func (c *fakeClient) GetCommand(_ context.Context, cmd *proto.Command) (*proto.BotCommand, error) {
+	for _, l := range c.listed {
+		if !hasName(l, cmd.GetCommand()) {
+			continue
+		}
+
+		if err := access.Check(l, cmd.GetCaller()); err != nil {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+
+		if text, ok := l.GetResp().GetTranslations()[cmd.GetLang()]; ok {
+			return &proto.BotCommand{Cmd: l.GetCmd(), Resp: &proto.Response{Response: text}}, nil
+		}
+
+		return l, nil
+	}
+
+	resp, ok := c.commands[cmd.GetCommand()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "command %q not found", cmd.GetCommand())
+	}
+
+	return &proto.BotCommand{
+		Cmd:  &proto.Command{Command: cmd.GetCommand()},
+		Resp: &proto.Response{Response: resp},
+	}, nil
+}
+
+// hasName reports whether the command has the name or the alias.
+func hasName(cmd *proto.BotCommand, name string) bool {
+	if cmd.GetCmd().GetCommand() == name {
+		return true
+	}
+
+	for _, alias := range cmd.GetAliases() {
+		if alias == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *fakeClient) MatchMessage(_ context.Context, req *proto.MatchRequest) (*proto.BotCommand, error) {
+	for keyword, resp := range c.triggers {
+		if strings.Contains(req.GetText(), keyword) {
+			return &proto.BotCommand{Resp: &proto.Response{Response: resp}}, nil
+		}
+	}
+
+	return nil, status.Error(codes.NotFound, "no command matches the message")
+}
+
+func (c *fakeClient) ListCommands(_ context.Context, _ *empty.Empty) (*proto.BotCommands, error) {
+	return &proto.BotCommands{Commands: c.listed}, nil
+}
+
+func (c *fakeClient) GetChangeToken(_ context.Context, _ *empty.Empty) (*proto.ChangeToken, error) {
+	return &proto.ChangeToken{Token: c.token}, nil
+}
+
+func (c *fakeClient) SearchCommands(_ context.Context, req *proto.SearchRequest) (*proto.BotCommands, error) {
+	var found []*proto.BotCommand
+	for _, l := range c.listed {
+		if strings.Contains(l.GetCmd().GetCommand(), req.GetQuery()) {
+			found = append(found, l)
+		}
+	}
+
+	return &proto.BotCommands{Commands: found}, nil
+}
+
+func (c *fakeClient) ResolveCommand(_ context.Context, req *proto.ResolveRequest) (*proto.ResolveResponse, error) {
+	resp := &proto.ResolveResponse{}
+	for name, r := range c.commands {
+		switch {
+		case strings.EqualFold(name, req.GetCommand()):
+			resp.Match = &proto.BotCommand{
+				Cmd:  &proto.Command{Command: name},
+				Resp: &proto.Response{Response: strings.Join(append([]string{r}, req.GetArgs()...), " ")},
+			}
+		case strings.HasPrefix(name, req.GetCommand()):
+			resp.Suggestions = append(resp.Suggestions, name)
+		}
+	}
+
+	sort.Strings(resp.Suggestions)
+	return resp, nil
+}
+
+func (c *fakeClient) Converse(_ context.Context, req *proto.ConverseRequest) (*proto.ConverseResponse, error) {
+	if c.converse == nil {
+		return nil, status.Error(codes.NotFound, "no conversation in progress")
+	}
+
+	return c.converse(req)
+}
@@ -0,0 +1,133 @@
+package bot
+
+import (
+	"context"
+	"time"
+
+	"github.com/danielkvist/botio/client"
+	"github.com/danielkvist/botio/proto"
+	"github.com/danielkvist/botio/schedule"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// scheduler sends the messages scheduled on the
+// botio's server for the chats of a platform
+// and for a bot, whose replicas share its name.
+type scheduler struct {
+	client   client.Client
+	platform string
+	bot      string
+	log      *logrus.Logger
+	send     func(r *Response)
+}
+
+// run sends the scheduled messages at the start
+// of every minute until done is closed.
+func (sc *scheduler) run(done <-chan struct{}) {
+	for {
+		now := time.Now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+
+		select {
+		case <-time.After(next.Sub(now)):
+		case <-done:
+			return
+		}
+
+		if err := sc.tick(context.Background(), next); err != nil {
+			logError(sc.log, sc.platform, "bot", "schedule", "", "", err.Error(), "error while sending the scheduled messages")
+		}
+	}
+}
+
+// tick sends the messages of the platform and the bot whose cron
+// expression matches t, if no other replica of the bot claimed them
+// first, and the ones without a cron expression, which are removed
+// from the botio's server before being sent once.
+func (sc *scheduler) tick(ctx context.Context, t time.Time) error {
+	schedules, err := sc.client.ListSchedules(ctx, &empty.Empty{})
+	if err != nil {
+		return errors.Wrap(err, "while listing the scheduled messages")
+	}
+
+	for _, sch := range schedules.GetSchedules() {
+		if sch.GetPlatform() != sc.platform || sch.GetBot() != sc.bot {
+			continue
+		}
+
+		if sch.GetCron() == "" {
+			// Another replica of the bot may have already sent it.
+			if _, err := sc.client.DeleteSchedule(ctx, sch); err != nil {
+				if status.Code(errors.Cause(err)) != codes.NotFound {
+					logError(sc.log, sc.platform, "client", "DeleteSchedule", "", sch.GetId(), err.Error(), "error while removing a scheduled message sent once")
+				}
+				continue
+			}
+		} else if c, err := schedule.Parse(sch.GetCron()); err != nil || !c.Match(t) {
+			continue
+		} else if !sc.claim(ctx, sch, t) {
+			continue
+		}
+
+		for _, chat := range sch.GetChats() {
+			r, err := sc.message(ctx, sch, chat)
+			if err != nil {
+				if !denied(err) {
+					logError(sc.log, sc.platform, "client", "GetCommand", chat, sch.GetCommand(), err.Error(), "error while getting the response of a scheduled message")
+				}
+				continue
+			}
+
+			sc.send(r)
+		}
+	}
+
+	return nil
+}
+
+// claim reports whether the sending of the schedule at t was claimed
+// for this replica of the bot. If the claim fails for any other reason
+// than another replica claiming it first the message is not sent either,
+// since sending it twice is worse than missing it once.
+func (sc *scheduler) claim(ctx context.Context, sch *proto.Schedule, t time.Time) bool {
+	minute, err := ptypes.TimestampProto(t)
+	if err != nil {
+		return false
+	}
+
+	_, err = sc.client.ClaimSchedule(ctx, &proto.ScheduleClaim{Id: sch.GetId(), Minute: minute})
+	switch status.Code(errors.Cause(err)) {
+	case codes.OK:
+		return true
+	case codes.AlreadyExists, codes.NotFound:
+	default:
+		logError(sc.log, sc.platform, "client", "ClaimSchedule", "", sch.GetId(), err.Error(), "error while claiming a scheduled message")
+	}
+
+	return false
+}
+
+// message returns the response of the command of the schedule, if any,
+// as it is sent to the chat, or else the response of the schedule. The
+// command is gotten for every chat so its access rules are checked.
+func (sc *scheduler) message(ctx context.Context, sch *proto.Schedule, chat string) (*Response, error) {
+	if sch.GetCommand() == "" {
+		return &Response{id: chat, text: sch.GetResponse()}, nil
+	}
+
+	cmd, err := sc.client.GetCommand(ctx, &proto.Command{
+		Command: sch.GetCommand(),
+		Caller:  &proto.Caller{Platform: sc.platform, ChatId: chat},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "while getting command %q", sch.GetCommand())
+	}
+
+	return &Response{id: chat, text: cmd.GetResp().GetResponse(), buttons: buttons(cmd.GetResp())}, nil
+}
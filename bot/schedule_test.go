@@ -0,0 +1,139 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type scheduleClient struct {
+	*fakeClient
+	schedules []*proto.Schedule
+	claims    map[string]time.Time
+}
+
+func (c *scheduleClient) ClaimSchedule(_ context.Context, claim *proto.ScheduleClaim) (*empty.Empty, error) {
+	minute, err := ptypes.Timestamp(claim.GetMinute())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if c.claims == nil {
+		c.claims = make(map[string]time.Time)
+	}
+
+	if last, ok := c.claims[claim.GetId()]; ok && !minute.After(last) {
+		return nil, status.Errorf(codes.AlreadyExists, "schedule %q already claimed", claim.GetId())
+	}
+
+	c.claims[claim.GetId()] = minute
+	return &empty.Empty{}, nil
+}
+
+func (c *scheduleClient) ListSchedules(_ context.Context, _ *empty.Empty) (*proto.Schedules, error) {
+	return &proto.Schedules{Schedules: c.schedules}, nil
+}
+
+func (c *scheduleClient) DeleteSchedule(_ context.Context, sch *proto.Schedule) (*empty.Empty, error) {
+	for i, s := range c.schedules {
+		if s.GetId() == sch.GetId() {
+			c.schedules = append(c.schedules[:i], c.schedules[i+1:]...)
+			return &empty.Empty{}, nil
+		}
+	}
+
+	return nil, status.Errorf(codes.NotFound, "schedule %q not found", sch.GetId())
+}
+
+func TestSchedulerTick(t *testing.T) {
+	// Monday, 7 December 2020 at 9:00.
+	monday := time.Date(2020, time.December, 7, 9, 0, 0, 0, time.UTC)
+
+	c := &scheduleClient{
+		fakeClient: testClient(map[string]string{"announcement": "New version released!"}),
+		schedules: []*proto.Schedule{
+			{Id: "restricted", Cron: "0 9 * * *", Platform: "telegram", Chats: []string{"6", "7"}, Command: "staff"},
+			{Id: "weekly", Cron: "0 9 * * mon", Platform: "telegram", Chats: []string{"1", "2"}, Response: "Good morning"},
+			{Id: "later", Cron: "0 10 * * *", Platform: "telegram", Chats: []string{"1"}, Response: "Hello"},
+			{Id: "other platform", Cron: "* * * * *", Platform: "discord", Chats: []string{"3"}, Response: "Hi"},
+			{Id: "other bot", Cron: "* * * * *", Platform: "telegram", Bot: "support", Chats: []string{"8"}, Response: "Hi"},
+			{Id: "once", Platform: "telegram", Chats: []string{"4"}, Command: "announcement"},
+			{Id: "missing command", Platform: "telegram", Chats: []string{"5"}, Command: "missing"},
+		},
+	}
+
+	c.listed = []*proto.BotCommand{{
+		Cmd:    &proto.Command{Command: "staff"},
+		Resp:   &proto.Response{Response: "Meeting at 10"},
+		Access: &proto.Access{AllowedChats: []string{"7"}},
+	}}
+
+	var sent []*Response
+	log := logrus.New()
+	log.Out = &bytes.Buffer{}
+
+	sc := &scheduler{
+		client:   c,
+		platform: "telegram",
+		log:      log,
+		send:     func(r *Response) { sent = append(sent, r) },
+	}
+
+	if err := sc.tick(context.Background(), monday); err != nil {
+		t.Fatalf("while sending scheduled messages: %v", err)
+	}
+
+	expected := []struct {
+		id   string
+		text string
+	}{
+		{id: "7", text: "Meeting at 10"},
+		{id: "1", text: "Good morning"},
+		{id: "2", text: "Good morning"},
+		{id: "4", text: "New version released!"},
+	}
+
+	if len(sent) != len(expected) {
+		t.Fatalf("expected %v responses. got=%v", len(expected), len(sent))
+	}
+
+	for i, e := range expected {
+		if sent[i].id != e.id || sent[i].text != e.text {
+			t.Fatalf("expected response %q to chat %q. got=%q to %q", e.text, e.id, sent[i].text, sent[i].id)
+		}
+	}
+
+	for _, sch := range c.schedules {
+		if sch.GetCron() == "" {
+			t.Fatalf("expected scheduled messages without cron expression to be removed. got=%v", sch)
+		}
+	}
+
+	// Another replica of the bot doesn't send them again.
+	sent = nil
+	replica := *sc
+	if err := replica.tick(context.Background(), monday); err != nil {
+		t.Fatalf("while sending scheduled messages: %v", err)
+	}
+
+	if len(sent) != 0 {
+		t.Fatalf("expected no responses from another replica. got=%v", len(sent))
+	}
+
+	if err := sc.tick(context.Background(), monday.Add(time.Minute)); err != nil {
+		t.Fatalf("while sending scheduled messages: %v", err)
+	}
+
+	if len(sent) != 0 {
+		t.Fatalf("expected no responses a minute later. got=%v", len(sent))
+	}
+}
@@ -0,0 +1,172 @@
+package bot
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/danielkvist/botio/client"
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// menuCommand represents a command shown on the command
+// menu that a platform offers to its users.
+type menuCommand struct {
+	name        string
+	description string
+}
+
+// validMenuName matches the command names that both
+// Telegram and Discord accept on their command menus.
+var validMenuName = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// menuCommands returns the visible commands that can be shown on
+// a command menu, alongside the help command if it has a name.
+// Descriptions are truncated to maxDescription characters.
+func menuCommands(commands []*proto.BotCommand, help string, maxDescription int) []menuCommand {
+	var menu []menuCommand
+	if help != "" {
+		menu = append(menu, menuCommand{name: help, description: "Lists the available commands"})
+	}
+
+	for _, cmd := range visibleCommands(commands, nil) {
+		name := cmd.GetCmd().GetCommand()
+		if name == help || !validMenuName.MatchString(name) {
+			continue
+		}
+
+		description := cmd.GetDescription()
+		if description == "" {
+			description = "/" + name
+		}
+
+		menu = append(menu, menuCommand{name: name, description: description})
+	}
+
+	for i := range menu {
+		if d := []rune(menu[i].description); len(d) > maxDescription {
+			menu[i].description = string(d[:maxDescription])
+		}
+	}
+
+	return menu
+}
+
+// menuSyncer keeps the command menu of a platform up to date
+// with the commands stored on the botio's server.
+type menuSyncer struct {
+	client         client.Client
+	help           string
+	maxCommands    int
+	maxDescription int
+	sync           func(menu []menuCommand) error
+	fingerprint    string
+	token          string
+}
+
+// run syncs the command menu and keeps it up to date until done is
+// closed. Every check it gets the change token of the server and syncs
+// the menu if it changed. Every interval it syncs the menu anyway, which
+// covers the changes made through other servers. If both are zero the
+// menu is only synced at startup.
+func (ms *menuSyncer) run(log *logrus.Logger, platform string, interval, check time.Duration, done <-chan struct{}) {
+	syncMenu := func() {
+		if err := ms.syncOnce(context.Background()); err != nil {
+			logError(log, platform, "bot", "syncCommands", "", "", err.Error(), "error while syncing the command menu")
+		}
+	}
+
+	syncMenu()
+	if interval <= 0 && check <= 0 {
+		return
+	}
+
+	wait := check
+	if wait <= 0 || (interval > 0 && interval < wait) {
+		wait = interval
+	}
+
+	last := time.Now()
+	for {
+		select {
+		case <-time.After(wait):
+		case <-done:
+			return
+		}
+
+		if interval > 0 && time.Since(last) >= interval {
+			syncMenu()
+			last = time.Now()
+			continue
+		}
+
+		changed, err := ms.changed(context.Background())
+		if err != nil {
+			logError(log, platform, "bot", "syncCommands", "", "", err.Error(), "error while checking for changes of the commands")
+			continue
+		}
+
+		if changed {
+			syncMenu()
+		}
+	}
+}
+
+// changed reports whether the change token of the server
+// differs from the one of the last successful sync.
+func (ms *menuSyncer) changed(ctx context.Context) (bool, error) {
+	token, err := ms.client.GetChangeToken(ctx, &empty.Empty{})
+	if err != nil {
+		return false, errors.Wrap(err, "while getting the change token")
+	}
+
+	return token.GetToken() != ms.token, nil
+}
+
+// syncOnce updates the command menu if the commands
+// have changed since the last time it was synced.
+func (ms *menuSyncer) syncOnce(ctx context.Context) error {
+	// The token is gotten before the commands so a change
+	// made in between is noticed on the next check.
+	token, err := ms.client.GetChangeToken(ctx, &empty.Empty{})
+	if err != nil {
+		return errors.Wrap(err, "while getting the change token")
+	}
+
+	commands, err := ms.client.ListCommands(ctx, &empty.Empty{})
+	if err != nil {
+		return errors.Wrap(err, "while listing commands")
+	}
+
+	menu := menuCommands(commands.GetCommands(), ms.help, ms.maxDescription)
+	if ms.maxCommands > 0 && len(menu) > ms.maxCommands {
+		menu = menu[:ms.maxCommands]
+	}
+
+	fingerprint := menuFingerprint(menu)
+	if fingerprint != ms.fingerprint {
+		if err := ms.sync(menu); err != nil {
+			return err
+		}
+	}
+
+	ms.fingerprint = fingerprint
+	ms.token = token.GetToken()
+	return nil
+}
+
+func menuFingerprint(menu []menuCommand) string {
+	var b strings.Builder
+	for _, c := range menu {
+		fmt.Fprintf(&b, "%s\x00%s\x00", c.name, c.description)
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(b.String())))
+}
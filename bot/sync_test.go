@@ -0,0 +1,80 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+)
+
+func TestMenuSyncer(t *testing.T) {
+	c := testClient(nil)
+	c.listed = []*proto.BotCommand{
+		{Cmd: &proto.Command{Command: "start"}, Description: "Says hi"},
+		{Cmd: &proto.Command{Command: "Invalid-Name"}},
+		{Cmd: &proto.Command{Command: "secret"}, Hidden: true},
+	}
+
+	var synced [][]menuCommand
+	ms := &menuSyncer{
+		client:         c,
+		help:           "help",
+		maxDescription: 5,
+		sync: func(menu []menuCommand) error {
+			synced = append(synced, menu)
+			return nil
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := ms.syncOnce(context.TODO()); err != nil {
+			t.Fatalf("while syncing command menu: %v", err)
+		}
+	}
+
+	if len(synced) != 1 {
+		t.Fatalf("expected menu to be synced once while commands don't change. got=%v", len(synced))
+	}
+
+	expected := []menuCommand{{"help", "Lists"}, {"start", "Says "}}
+	if len(synced[0]) != len(expected) {
+		t.Fatalf("expected menu %v. got=%v", expected, synced[0])
+	}
+	for i := range expected {
+		if synced[0][i] != expected[i] {
+			t.Fatalf("expected menu %v. got=%v", expected, synced[0])
+		}
+	}
+
+	changed, err := ms.changed(context.TODO())
+	if err != nil {
+		t.Fatalf("while checking for changes: %v", err)
+	}
+
+	if changed {
+		t.Fatalf("expected no changes while the change token is the same")
+	}
+
+	c.listed = append(c.listed, &proto.BotCommand{Cmd: &proto.Command{Command: "about"}})
+	c.token = "1"
+	changed, err = ms.changed(context.TODO())
+	if err != nil {
+		t.Fatalf("while checking for changes: %v", err)
+	}
+
+	if !changed {
+		t.Fatalf("expected changes after the change token changed")
+	}
+
+	if err := ms.syncOnce(context.TODO()); err != nil {
+		t.Fatalf("while syncing command menu: %v", err)
+	}
+
+	if len(synced) != 2 {
+		t.Fatalf("expected menu to be synced again after commands changed. got=%v syncs", len(synced))
+	}
+
+	if changed, _ := ms.changed(context.TODO()); changed {
+		t.Fatalf("expected no changes after syncing the command menu")
+	}
+}
@@ -1,8 +1,14 @@
 package bot
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -10,20 +16,73 @@ import (
 	"github.com/danielkvist/botio/client"
 	"github.com/danielkvist/botio/proto"
 
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/yanzay/tbot/v2"
 )
 
+const telegramAPI = "https://api.telegram.org"
+
 // Telegram is a wrapper for a yanzay/tbot client
 // that satifies the Bot interface.
+//
+// HelpCommand is the name of the command that lists the available
+// commands, an empty name disables it. Mistyped commands are answered
+// suggesting similar ones with the Suggestions format, like "Did you
+// mean %s?", an empty format disables the suggestions. Commands that
+// don't exist are answered with UnknownCommand, or with the default
+// response if it is empty. Commands that the access rules don't allow
+// the user to use are answered with DeniedCommand, or with the default
+// response if it is empty. The command menu of the bot is synced at
+// startup, every SyncInterval if it is greater than zero and when the
+// change token of the botio's server, checked every SyncCheckInterval
+// if it is greater than zero, changes.
+// If Schedules is true the bot sends the messages scheduled on the
+// botio's server for its chats and its Name, claiming each sending so
+// only one of the replicas started with the same Name sends it. If
+// UsageInterval is greater than zero the outcome of the lookups of the
+// commands is reported to the botio's server every UsageInterval.
+//
+// By default the bot gets its updates using long polling. If WebhookURL
+// is not empty the bot registers it as its webhook instead and serves the
+// updates on ListenAddr, see Webhook. APIURL replaces the address of the
+// Telegram Bot API, for the updates too, which is useful for testing or
+// for local Bot API servers.
 type Telegram struct {
-	tclient         *tbot.Client
-	session         *tbot.Server
-	responses       chan *Response
-	defaultResponse string
-	log             *logrus.Logger
-	wg              sync.WaitGroup
-	client          client.Client
+	HelpCommand       string
+	Suggestions       string
+	UnknownCommand    string
+	DeniedCommand     string
+	SyncInterval      time.Duration
+	SyncCheckInterval time.Duration
+	Schedules         bool
+	Name              string
+	UsageInterval     time.Duration
+	WebhookURL        string
+	ListenAddr        string
+	Webhook           WebhookConfig
+	APIURL            string
+	token             string
+	username          string
+	client            client.Client
+	tclient           *tbot.Client
+	session           *tbot.Server
+	webhook           *http.Server
+	responses         chan *Response
+	done              chan struct{}
+	log               *logrus.Logger
+	wg                sync.WaitGroup
+	scheduling        sync.WaitGroup
+	reporting         sync.WaitGroup
+	router            *Router
+	middlewares       []Middleware
+	admins            *chatAdmins
+}
+
+// Use registers the received middlewares to be run by the
+// Telegram bot's Router.
+func (t *Telegram) Use(mws ...Middleware) {
+	t.middlewares = append(t.middlewares, mws...)
 }
 
 // Connect receives a token with which tries to indentify,
@@ -31,15 +90,39 @@ type Telegram struct {
 // to send the responses from the responses channel to the respective
 // clients.
 func (t *Telegram) Connect(c client.Client, addr string, token string, cap int, defaultResponse string) error {
-	session := tbot.New(token)
-	tclient := session.Client()
-	responses := make(chan *Response, cap)
-
-	t.session = session
-	t.tclient = tclient
-	t.responses = responses
-	t.defaultResponse = defaultResponse
+	if t.WebhookURL == "" {
+		var opts []tbot.ServerOption
+		if t.apiURL() != telegramAPI {
+			base, err := url.Parse(t.apiURL())
+			if err != nil || base.Host == "" {
+				return errors.Errorf("invalid Telegram Bot API URL %q", t.APIURL)
+			}
+
+			opts = append(opts, tbot.WithHTTPClient(&http.Client{
+				Transport: &rebase{base: base, next: http.DefaultTransport},
+			}))
+		}
+
+		t.session = tbot.New(token, opts...)
+	}
+
+	t.token = token
 	t.client = c
+	t.tclient = tbot.NewClient(token, http.DefaultClient, t.apiURL())
+
+	me, err := t.tclient.GetMe()
+	if err != nil {
+		return errors.Wrap(err, "while getting the username of the Telegram bot")
+	}
+	t.username = me.Username
+
+	t.admins = &chatAdmins{
+		ttl:   time.Minute,
+		get:   t.tclient.GetChatAdministrators,
+		chats: make(map[string]cachedAdmins),
+	}
+	t.responses = make(chan *Response, cap)
+	t.done = make(chan struct{})
 
 	t.log = logrus.New()
 	t.log.SetFormatter(&logrus.TextFormatter{
@@ -49,77 +132,439 @@ func (t *Telegram) Connect(c client.Client, addr string, token string, cap int,
 	})
 	t.log.Out = os.Stdout
 
+	t.router = NewRouter(c, defaultResponse)
+	t.router.Use(Logging(t.log))
+	t.router.Use(t.middlewares...)
+	t.router.Use(Conversations(c))
+	if t.UnknownCommand != "" {
+		t.router.Use(Unknown(t.UnknownCommand))
+	}
+	if t.DeniedCommand != "" {
+		t.router.Use(Denied(t.DeniedCommand))
+	}
+	if t.HelpCommand != "" {
+		t.router.Use(Help(c, t.HelpCommand))
+	}
+	if t.Suggestions != "" {
+		t.router.Use(Suggestions(c, t.Suggestions))
+	}
+	if t.UsageInterval > 0 {
+		reporter := &usageReporter{
+			client:   c,
+			platform: "telegram",
+			key:      []byte(token),
+			log:      t.log,
+		}
+		t.router.Use(reporter.middleware())
+
+		t.reporting.Add(1)
+		go func() {
+			reporter.run(t.UsageInterval, t.done)
+			t.reporting.Done()
+		}()
+	}
+
+	// Telegram allows up to 30 messages per second
+	// and about one message per second to the same chat.
+	sender := newSender(newSendLimiter(30, 30, 1, 3), t.send)
+
 	t.wg.Add(1)
 	go func() {
-		for r := range t.responses {
-			t.tclient.SendMessage(r.id, r.text)
-		}
+		sender.run(t.responses)
 		t.wg.Done()
 	}()
 
+	syncer := &menuSyncer{
+		client:         c,
+		help:           t.HelpCommand,
+		maxCommands:    100,
+		maxDescription: 256,
+		sync:           t.setMyCommands,
+	}
+	go syncer.run(t.log, "telegram", t.SyncInterval, t.SyncCheckInterval, t.done)
+
+	if t.Schedules {
+		sched := &scheduler{
+			client:   c,
+			platform: "telegram",
+			bot:      t.Name,
+			log:      t.log,
+			send:     func(r *Response) { t.responses <- r },
+		}
+
+		t.scheduling.Add(1)
+		go func() {
+			sched.run(t.done)
+			t.scheduling.Done()
+		}()
+	}
+
+	return nil
+}
+
+// setMyCommands replaces the command menu of the Telegram bot.
+func (t *Telegram) setMyCommands(menu []menuCommand) error {
+	type botCommand struct {
+		Command     string `json:"command"`
+		Description string `json:"description"`
+	}
+
+	commands := make([]botCommand, 0, len(menu))
+	for _, c := range menu {
+		commands = append(commands, botCommand{Command: c.name, Description: c.description})
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"commands": commands})
+	if err != nil {
+		return errors.Wrap(err, "while encoding Telegram's command menu")
+	}
+
+	endpoint := fmt.Sprintf("%s/bot%s/setMyCommands", t.apiURL(), t.token)
+	resp, err := http.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "while setting Telegram's command menu")
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return errors.Wrap(err, "while decoding Telegram's response to setMyCommands")
+	}
+
+	if !result.OK {
+		return errors.Errorf("while setting Telegram's command menu: %s", result.Description)
+	}
+
 	return nil
 }
 
 // Listen handles all the messages sent to the Telegram bot
-// and tries to get the response for the asked command from the botio's server
-// and submit it to the responses channel, which eventually should send
+// and passes them to the bot's Router, which tries to get
+// the response for the asked command from the botio's server. The reply
+// is submitted to the responses channel, which eventually should send
 // the response back to the client.
 func (t *Telegram) Listen() error {
-	t.session.HandleMessage(".", func(m *tbot.Message) {
-		start := time.Now()
+	if t.session != nil {
+		t.session.HandleMessage(".", t.handleMessage)
+		t.session.HandleInlineQuery(t.handleInlineQuery)
+		t.session.HandleCallback(t.handleCallback)
+	}
 
-		msg := strings.TrimPrefix(m.Text, "/")
-		resp := &Response{
-			id: m.Chat.ID,
-		}
+	return nil
+}
+
+func (t *Telegram) handleMessage(m *tbot.Message) {
+	text, ok := t.stripUsername(m.Text)
+	if !ok {
+		return
+	}
+
+	msg := &Message{
+		Platform: "telegram",
+		ChatID:   m.Chat.ID,
+		Text:     text,
+		Mention:  true,
+		Private:  m.Chat.Type == "private",
+	}
+
+	if m.From != nil {
+		msg.UserID = strconv.Itoa(m.From.ID)
+		msg.Lang = m.From.LanguageCode
+		msg.Admin = t.isAdmin(m.Chat, msg.UserID)
+	}
+
+	reply, _ := t.router.Route(context.Background(), msg)
+	if reply == nil {
+		return
+	}
+
+	t.responses <- &Response{
+		id:      m.Chat.ID,
+		text:    reply.Text,
+		buttons: reply.Buttons,
+	}
+}
+
+// stripUsername removes the username of the bot from the command
+// of the text, like "/start@botio_bot", which Telegram adds to the
+// commands picked from the menu of a group. It returns false if the
+// command is addressed to another bot, which must not be answered.
+func (t *Telegram) stripUsername(text string) (string, bool) {
+	if !strings.HasPrefix(text, "/") {
+		return text, true
+	}
+
+	end := strings.IndexAny(text, " \t\n")
+	if end < 0 {
+		end = len(text)
+	}
+
+	at := strings.Index(text[:end], "@")
+	if at < 0 {
+		return text, true
+	}
+
+	if !strings.EqualFold(text[at+1:end], t.username) {
+		return text, false
+	}
+
+	return text[:at] + text[end:], true
+}
+
+// isAdmin reports whether the user is an administrator of the chat,
+// which only groups have.
+func (t *Telegram) isAdmin(chat tbot.Chat, userID string) bool {
+	if userID == "" || (chat.Type != "group" && chat.Type != "supergroup") {
+		return false
+	}
+
+	admin, err := t.admins.has(chat.ID, userID)
+	if err != nil {
+		logError(t.log, "telegram", "bot", "isAdmin", userID, "", err.Error(), "error while getting chat administrators")
+	}
+
+	return admin
+}
+
+// chatAdmins keeps the administrators of each chat for ttl
+// so Telegram is not asked for them on every message.
+type chatAdmins struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	get   func(chatID string) ([]*tbot.ChatMember, error)
+	chats map[string]cachedAdmins
+}
 
-		cmd, err := t.client.GetCommand(context.TODO(), &proto.Command{Command: msg})
+type cachedAdmins struct {
+	users   map[string]bool
+	expires time.Time
+}
+
+// has reports whether the user is an administrator of the chat.
+func (a *chatAdmins) has(chatID, userID string) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	cached, ok := a.chats[chatID]
+	if !ok || time.Now().After(cached.expires) {
+		members, err := a.get(chatID)
 		if err != nil {
-			resp.text = t.defaultResponse
-			t.responses <- resp
-
-			logError(
-				t.log,
-				"Telegram",
-				"client",
-				"GetCommand",
-				m.Chat.ID,
-				msg,
-				err.Error(),
-				"error while responding to command",
-			)
-			return
+			return false, errors.Wrapf(err, "while getting administrators of chat %q", chatID)
 		}
 
-		resp.text = cmd.GetResp().GetResponse()
-		t.responses <- resp
-
-		logInfo(
-			t.log,
-			"Telegram",
-			m.Chat.ID,
-			msg,
-			resp.text,
-			"command responded successfully",
-			time.Since(start),
-		)
-		return
+		cached = cachedAdmins{users: make(map[string]bool), expires: time.Now().Add(a.ttl)}
+		for _, m := range members {
+			cached.users[strconv.Itoa(m.User.ID)] = true
+		}
+
+		a.chats[chatID] = cached
+	}
+
+	return cached.users[userID], nil
+}
+
+// maxInlineResults is the maximum number of
+// results that Telegram accepts for an inline query.
+const maxInlineResults = 50
+
+// handleInlineQuery answers the inline queries sent to the Telegram bot
+// with the commands whose name or description match the query.
+func (t *Telegram) handleInlineQuery(q *tbot.InlineQuery) {
+	// The chat on which the result is sent is unknown,
+	// so the commands restricted to some chats are left out.
+	caller := &proto.Caller{Platform: "telegram"}
+	var lang string
+	if q.From != nil {
+		caller.UserId = strconv.Itoa(q.From.ID)
+		lang = q.From.LanguageCode
+	}
+
+	commands, err := t.client.SearchCommands(context.Background(), &proto.SearchRequest{
+		Query:  q.Query,
+		Limit:  maxInlineResults,
+		Caller: caller,
+		Lang:   lang,
 	})
+	if err != nil {
+		logError(t.log, "telegram", "client", "SearchCommands", "", q.Query, err.Error(), "error while searching commands for inline query")
+		return
+	}
 
-	return nil
+	if err := t.tclient.AnswerInlineQuery(q.ID, inlineResults(commands.GetCommands())); err != nil {
+		logError(t.log, "telegram", "bot", "handleInlineQuery", "", q.Query, err.Error(), "error while answering inline query")
+	}
 }
 
-// Start opens a connection to Telegram.
+// inlineResults returns the results of an inline query for the
+// commands, leaving out the ones without a response since Telegram
+// rejects the whole answer if any result has an empty message.
+func inlineResults(commands []*proto.BotCommand) []tbot.InlineQueryResult {
+	results := make([]tbot.InlineQueryResult, 0, len(commands))
+	for _, cmd := range commands {
+		if strings.TrimSpace(cmd.GetResp().GetResponse()) == "" {
+			continue
+		}
+
+		name := cmd.GetCmd().GetCommand()
+		text := splitText(cmd.GetResp().GetResponse(), messageLimit("telegram"))[0]
+		results = append(results, tbot.InlineQueryResultArticle{
+			Type:                "article",
+			ID:                  name,
+			Title:               "/" + name,
+			Description:         cmd.GetDescription(),
+			InputMessageContent: tbot.InputTextMessageContent{MessageText: text},
+			ReplyMarkup:         inlineKeyboard(buttons(cmd.GetResp())),
+		})
+	}
+
+	return results
+}
+
+// handleCallback handles the presses of the buttons attached to the
+// responses. The command of the button is passed to the bot's Router
+// and its reply replaces the message to which the button belongs.
+func (t *Telegram) handleCallback(cq *tbot.CallbackQuery) {
+	t.tclient.AnswerCallbackQuery(cq.ID)
+
+	msg := &Message{
+		Platform: "telegram",
+		Text:     cq.Data,
+		Mention:  true,
+	}
+
+	if cq.From != nil {
+		msg.UserID = strconv.Itoa(cq.From.ID)
+		msg.Lang = cq.From.LanguageCode
+	}
+
+	r := &Response{inlineMessageID: cq.InlineMessageID}
+	if cq.Message != nil {
+		msg.ChatID = cq.Message.Chat.ID
+		msg.Private = cq.Message.Chat.Type == "private"
+		msg.Admin = t.isAdmin(cq.Message.Chat, msg.UserID)
+		r.id = cq.Message.Chat.ID
+		r.messageID = cq.Message.MessageID
+	}
+
+	reply, _ := t.router.Route(context.Background(), msg)
+	if reply == nil {
+		return
+	}
+
+	r.text = reply.Text
+	r.buttons = reply.Buttons
+	t.responses <- r
+}
+
+// send sends a response to Telegram, splitting it into several
+// messages if it is too long. Buttons are attached to the last message.
+func (t *Telegram) send(r *Response) {
+	chunks := splitText(r.text, messageLimit("telegram"))
+	for i, text := range chunks {
+		opt := func(url.Values) {}
+		if markup := inlineKeyboard(r.buttons); markup != nil && i == len(chunks)-1 {
+			opt = tbot.OptInlineKeyboardMarkup(markup)
+		}
+
+		switch {
+		case i == 0 && r.inlineMessageID != "":
+			t.tclient.EditInlineMessageText(r.inlineMessageID, text, opt)
+		case i == 0 && r.messageID != 0:
+			t.tclient.EditMessageText(r.id, r.messageID, text, opt)
+		case r.id != "":
+			t.tclient.SendMessage(r.id, text, opt)
+		}
+	}
+}
+
+// maxCallbackData is the maximum size in bytes
+// of the data attached to a Telegram button.
+const maxCallbackData = 64
+
+// inlineKeyboard returns a keyboard with a row for each button. Buttons
+// whose command doesn't fit into Telegram's callback data are ignored.
+func inlineKeyboard(buttons []Button) *tbot.InlineKeyboardMarkup {
+	var rows [][]tbot.InlineKeyboardButton
+	for _, b := range buttons {
+		if b.Text == "" || b.Command == "" || len(b.Command) > maxCallbackData {
+			continue
+		}
+
+		rows = append(rows, []tbot.InlineKeyboardButton{{Text: b.Text, CallbackData: b.Command}})
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return &tbot.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// Start opens a connection to Telegram or, in webhook mode,
+// registers the webhook and starts serving the updates.
 func (t *Telegram) Start() error {
+	if t.session == nil {
+		return t.serveWebhook()
+	}
+
 	t.session.Start()
 	return nil
 }
 
 // Stop waits until the responses channel is closed
-// and then stops the Telegram session.
+// and then stops the Telegram session or the webhook server.
+// The webhook is kept registered so that Telegram keeps
+// delivering the updates to it while the bot is stopped.
 func (t *Telegram) Stop() error {
+	if t.webhook != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		t.webhook.Shutdown(ctx)
+	}
+
+	close(t.done)
+	t.scheduling.Wait()
+	t.reporting.Wait()
 	close(t.responses)
 	t.wg.Wait()
-	t.session.Stop()
+	if t.session != nil {
+		t.session.Stop()
+	}
+
 	return nil
 }
+
+// rebase is an http.RoundTripper that sends the requests made to the
+// Telegram Bot API to base instead, since tbot always gets the updates
+// from the official address when it uses long polling.
+type rebase struct {
+	base *url.URL
+	next http.RoundTripper
+}
+
+func (rb *rebase) RoundTrip(req *http.Request) (*http.Response, error) {
+	api, _ := url.Parse(telegramAPI)
+	if req.URL.Host != api.Host {
+		return rb.next.RoundTrip(req)
+	}
+
+	// The request must not be modified, and tbot reuses it for every poll.
+	r := req.Clone(req.Context())
+	r.URL.Scheme = rb.base.Scheme
+	r.URL.Host = rb.base.Host
+	r.URL.Path = strings.TrimSuffix(rb.base.Path, "/") + req.URL.Path
+	r.URL.RawPath = ""
+	r.Host = ""
+	return rb.next.RoundTrip(r)
+}
+
+func (t *Telegram) apiURL() string {
+	if t.APIURL != "" {
+		return strings.TrimSuffix(t.APIURL, "/")
+	}
+
+	return telegramAPI
+}
@@ -0,0 +1,196 @@
+package bot
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+)
+
+func TestTelegramUpdates(t *testing.T) {
+	c := testClient(map[string]string{"next": "done"})
+	c.listed = []*proto.BotCommand{
+		{
+			Cmd: &proto.Command{Command: "menu"},
+			Resp: &proto.Response{
+				Response: "pick one",
+				Buttons:  []*proto.Button{{Text: "Next", Command: "next"}},
+			},
+			Description: "Shows a menu",
+		},
+		{
+			Cmd: &proto.Command{Command: "greet"},
+			Resp: &proto.Response{
+				Response:     "hi",
+				Translations: map[string]string{"es": "hola"},
+			},
+		},
+	}
+
+	tt := []struct {
+		name            string
+		update          string
+		expectedMethod  string
+		expectedParams  map[string]string
+		expectedContain map[string]string
+		ignored         bool
+	}{
+		{
+			name:           "message with buttons",
+			update:         `{"update_id":1,"message":{"message_id":1,"from":{"id":7},"chat":{"id":42},"text":"/menu"}}`,
+			expectedMethod: "sendMessage",
+			expectedParams: map[string]string{"chat_id": "42", "text": "pick one"},
+			expectedContain: map[string]string{
+				"reply_markup": `"callback_data":"next"`,
+			},
+		},
+		{
+			name:           "message in the user's language",
+			update:         `{"update_id":4,"message":{"message_id":1,"from":{"id":7,"language_code":"es"},"chat":{"id":42},"text":"/greet"}}`,
+			expectedMethod: "sendMessage",
+			expectedParams: map[string]string{"chat_id": "42", "text": "hola"},
+		},
+		{
+			name:           "command with the bot's username",
+			update:         `{"update_id":5,"message":{"message_id":1,"from":{"id":7},"chat":{"id":42,"type":"group"},"text":"/greet@Botio_Bot now"}}`,
+			expectedMethod: "sendMessage",
+			expectedParams: map[string]string{"chat_id": "42", "text": "hi"},
+		},
+		{
+			name:           "command for another bot",
+			update:         `{"update_id":6,"message":{"message_id":1,"from":{"id":7},"chat":{"id":42,"type":"group"},"text":"/greet@other_bot"}}`,
+			expectedMethod: "sendMessage",
+			ignored:        true,
+		},
+		{
+			name:           "callback query",
+			update:         `{"update_id":2,"callback_query":{"id":"cb","from":{"id":7},"message":{"message_id":5,"chat":{"id":42}},"data":"next"}}`,
+			expectedMethod: "editMessageText",
+			expectedParams: map[string]string{"chat_id": "42", "message_id": "5", "text": "done"},
+		},
+		{
+			name:           "inline query",
+			update:         `{"update_id":3,"inline_query":{"id":"q","from":{"id":7},"query":"me"}}`,
+			expectedMethod: "answerInlineQuery",
+			expectedParams: map[string]string{"inline_query_id": "q"},
+			expectedContain: map[string]string{
+				"results": `"title":"/menu"`,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			f, srv := newFakeTelegram(t)
+			defer srv.Close()
+
+			tg := &Telegram{WebhookURL: "https://bot.example.com/telegram", APIURL: srv.URL}
+			if err := tg.Connect(c, "", "token", 10, "default"); err != nil {
+				t.Fatalf("while connecting Telegram bot: %v", err)
+			}
+			tg.log.Out = ioutil.Discard
+
+			rec := httptest.NewRecorder()
+			tg.webhookHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/telegram", strings.NewReader(tc.update)))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status code %v. got=%v", http.StatusOK, rec.Code)
+			}
+
+			tg.Stop()
+
+			reqs := f.received(tc.expectedMethod)
+			if tc.ignored {
+				if len(reqs) != 0 {
+					t.Fatalf("expected no %s requests. got=%v", tc.expectedMethod, len(reqs))
+				}
+
+				return
+			}
+
+			if len(reqs) != 1 {
+				t.Fatalf("expected %v %s requests. got=%v", 1, tc.expectedMethod, len(reqs))
+			}
+
+			for k, v := range tc.expectedParams {
+				if reqs[0].FormValue(k) != v {
+					t.Fatalf("expected parameter %q to be %q. got=%q", k, v, reqs[0].FormValue(k))
+				}
+			}
+
+			for k, v := range tc.expectedContain {
+				if !strings.Contains(reqs[0].FormValue(k), v) {
+					t.Fatalf("expected parameter %q to contain %q. got=%q", k, v, reqs[0].FormValue(k))
+				}
+			}
+		})
+	}
+}
+
+func TestInlineKeyboard(t *testing.T) {
+	markup := inlineKeyboard([]Button{
+		{Text: "Next", Command: "next"},
+		{Text: "Too long", Command: strings.Repeat("x", maxCallbackData+1)},
+		{Text: "", Command: "empty"},
+	})
+
+	if markup == nil || len(markup.InlineKeyboard) != 1 || markup.InlineKeyboard[0][0].CallbackData != "next" {
+		t.Fatalf("expected keyboard with only the valid button. got=%+v", markup)
+	}
+
+	if inlineKeyboard(nil) != nil {
+		t.Fatalf("expected no keyboard without buttons")
+	}
+}
+
+func TestInlineResults(t *testing.T) {
+	results := inlineResults([]*proto.BotCommand{
+		{Cmd: &proto.Command{Command: "start"}, Resp: &proto.Response{Response: "hi"}},
+		{Cmd: &proto.Command{Command: "empty"}, Resp: &proto.Response{Response: " "}},
+		{Cmd: &proto.Command{Command: "none"}},
+	})
+
+	if len(results) != 1 {
+		t.Fatalf("expected only the results with a response. got=%v", len(results))
+	}
+}
+
+func TestRebase(t *testing.T) {
+	var paths []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path+"?"+r.URL.RawQuery)
+	}))
+	defer api.Close()
+
+	base, err := url.Parse(api.URL + "/local/")
+	if err != nil {
+		t.Fatalf("while parsing URL: %v", err)
+	}
+
+	c := &http.Client{Transport: &rebase{base: base, next: http.DefaultTransport}}
+	req, err := http.NewRequest(http.MethodGet, telegramAPI+"/bot123:abc/getUpdates?offset=1", nil)
+	if err != nil {
+		t.Fatalf("while creating request: %v", err)
+	}
+
+	// The request is reused for every poll, as tbot does.
+	for i := 0; i < 2; i++ {
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatalf("while polling updates: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	expected := "/local/bot123:abc/getUpdates?offset=1"
+	if len(paths) != 2 || paths[0] != expected || paths[1] != expected {
+		t.Fatalf("expected two requests to %q. got=%v", expected, paths)
+	}
+
+	if req.URL.Host != "api.telegram.org" {
+		t.Fatalf("expected the request to be left untouched. got=%v", req.URL)
+	}
+}
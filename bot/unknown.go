@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Unknown returns a Middleware that answers with the received
+// text the commands that are not stored on the botio's server,
+// so they are not mistaken for a failure of the bot or the server,
+// which are still answered with the default response.
+func Unknown(text string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, m *Message) (*Reply, error) {
+			reply, err := next(ctx, m)
+			if !notFound(err) {
+				return reply, err
+			}
+
+			return &Reply{Text: text}, nil
+		}
+	}
+}
+
+// notFound reports whether the received error was
+// caused by a command that the botio's server doesn't have.
+func notFound(err error) bool {
+	return err != nil && status.Code(errors.Cause(err)) == codes.NotFound
+}
+
+// Denied returns a Middleware that answers with the received
+// text the commands that the access rules stored on the botio's
+// server don't allow the user to use on the chat.
+func Denied(text string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, m *Message) (*Reply, error) {
+			reply, err := next(ctx, m)
+			if !denied(err) {
+				return reply, err
+			}
+
+			return &Reply{Text: text}, nil
+		}
+	}
+}
+
+// denied reports whether the received error was caused by a
+// command that the user is not allowed to use on the chat.
+func denied(err error) bool {
+	return err != nil && status.Code(errors.Cause(err)) == codes.PermissionDenied
+}
@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnknown(t *testing.T) {
+	tt := []struct {
+		name          string
+		err           error
+		expectedReply string
+		expectedError bool
+	}{
+		{
+			name:          "existing command",
+			expectedReply: "hi",
+		},
+		{
+			name:          "non-existing command",
+			err:           errors.Wrap(status.Error(codes.NotFound, "command not found"), "while getting command"),
+			expectedReply: "unknown",
+		},
+		{
+			name:          "server unavailable",
+			err:           errors.Wrap(status.Error(codes.Unavailable, "connection refused"), "while getting command"),
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			h := Unknown("unknown")(func(ctx context.Context, m *Message) (*Reply, error) {
+				if tc.err != nil {
+					return nil, tc.err
+				}
+
+				return &Reply{Text: "hi"}, nil
+			})
+
+			reply, err := h(context.TODO(), &Message{Text: "/start", Mention: true})
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("expected error to be %v. got=%v", tc.expectedError, err)
+			}
+
+			if tc.expectedError {
+				return
+			}
+
+			if reply.Text != tc.expectedReply {
+				t.Fatalf("expected reply %q. got=%q", tc.expectedReply, reply.Text)
+			}
+		})
+	}
+}
+
+func TestDenied(t *testing.T) {
+	tt := []struct {
+		name          string
+		err           error
+		expectedReply string
+		expectedError bool
+	}{
+		{
+			name:          "allowed command",
+			expectedReply: "hi",
+		},
+		{
+			name:          "denied command",
+			err:           errors.Wrap(status.Error(codes.PermissionDenied, "command not allowed"), "while getting command"),
+			expectedReply: "not allowed",
+		},
+		{
+			name:          "non-existing command",
+			err:           errors.Wrap(status.Error(codes.NotFound, "command not found"), "while getting command"),
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			h := Denied("not allowed")(func(ctx context.Context, m *Message) (*Reply, error) {
+				if tc.err != nil {
+					return nil, tc.err
+				}
+
+				return &Reply{Text: "hi"}, nil
+			})
+
+			reply, err := h(context.TODO(), &Message{Text: "/ban", Mention: true})
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("expected error to be %v. got=%v", tc.expectedError, err)
+			}
+
+			if tc.expectedError {
+				return
+			}
+
+			if reply.Text != tc.expectedReply {
+				t.Fatalf("expected reply %q. got=%q", tc.expectedReply, reply.Text)
+			}
+		})
+	}
+}
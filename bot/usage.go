@@ -0,0 +1,141 @@
+package bot
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/danielkvist/botio/client"
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// maxUsageEvents is the maximum number of usage events kept while
+// they can't be reported, after which the oldest are dropped.
+const maxUsageEvents = 10000
+
+// usageReporter records the outcome of the lookups of the commands
+// and reports them in batches to the botio's server. Chat IDs are
+// anonymized with an HMAC keyed with key, so they can be counted
+// but not recovered.
+type usageReporter struct {
+	client   client.Client
+	platform string
+	key      []byte
+	log      *logrus.Logger
+	mu       sync.Mutex
+	events   []*proto.UsageEvent
+}
+
+// middleware returns a Middleware that records the outcome of the lookups
+// made by the Handlers that it wraps under the name of the command that
+// answered them, so aliases count for their command. It should be the last
+// one used by a Router so it only sees the lookups of the commands on the
+// botio's server.
+func (ur *usageReporter) middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, m *Message) (*Reply, error) {
+			start := time.Now()
+			reply, err := next(ctx, m)
+
+			outcome := "hit"
+			switch {
+			case notFound(err):
+				outcome = "miss"
+			case denied(err):
+				return reply, err
+			case err != nil:
+				outcome = "error"
+			case reply == nil:
+				return reply, err
+			}
+
+			command := m.Command()
+			if reply != nil && reply.command != "" {
+				command = reply.command
+			}
+
+			ur.record(&proto.UsageEvent{
+				Command:   command,
+				Outcome:   outcome,
+				LatencyMs: int64(time.Since(start) / time.Millisecond),
+				Platform:  ur.platform,
+				Chat:      ur.anonymize(m.ChatID),
+				Time:      ptypes.TimestampNow(),
+			})
+
+			return reply, err
+		}
+	}
+}
+
+// run reports the recorded events every interval until
+// done is closed, reporting then the remaining ones.
+func (ur *usageReporter) run(interval time.Duration, done <-chan struct{}) {
+	for {
+		select {
+		case <-time.After(interval):
+		case <-done:
+			if err := ur.flush(context.Background()); err != nil {
+				logError(ur.log, ur.platform, "bot", "reportUsage", "", "", err.Error(), "error while reporting the usage of the commands")
+			}
+			return
+		}
+
+		if err := ur.flush(context.Background()); err != nil {
+			logError(ur.log, ur.platform, "bot", "reportUsage", "", "", err.Error(), "error while reporting the usage of the commands")
+		}
+	}
+}
+
+func (ur *usageReporter) record(ev *proto.UsageEvent) {
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	ur.events = append(ur.events, ev)
+	if len(ur.events) > maxUsageEvents {
+		ur.events = ur.events[len(ur.events)-maxUsageEvents:]
+	}
+}
+
+// flush reports the recorded events, keeping
+// them to be reported again if it fails.
+func (ur *usageReporter) flush(ctx context.Context) error {
+	ur.mu.Lock()
+	events := ur.events
+	ur.events = nil
+	ur.mu.Unlock()
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	if _, err := ur.client.ReportUsage(ctx, &proto.UsageReport{Events: events}); err != nil {
+		ur.mu.Lock()
+		ur.events = append(events, ur.events...)
+		if len(ur.events) > maxUsageEvents {
+			ur.events = ur.events[len(ur.events)-maxUsageEvents:]
+		}
+		ur.mu.Unlock()
+
+		return errors.Wrapf(err, "while reporting %v usage events", len(events))
+	}
+
+	return nil
+}
+
+func (ur *usageReporter) anonymize(chatID string) string {
+	if chatID == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, ur.key)
+	mac.Write([]byte(ur.platform + ":" + chatID))
+	return hex.EncodeToString(mac.Sum(nil))[:16]
+}
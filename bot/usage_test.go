@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+)
+
+type usageClient struct {
+	*fakeClient
+	reports []*proto.UsageReport
+	fail    bool
+}
+
+func (c *usageClient) ReportUsage(_ context.Context, report *proto.UsageReport) (*empty.Empty, error) {
+	if c.fail {
+		return nil, errors.New("server unavailable")
+	}
+
+	c.reports = append(c.reports, report)
+	return &empty.Empty{}, nil
+}
+
+func TestUsageReporter(t *testing.T) {
+	c := &usageClient{fakeClient: testClient(map[string]string{"start": "Hi!"})}
+	c.listed = []*proto.BotCommand{
+		{Cmd: &proto.Command{Command: "help"}, Resp: &proto.Response{Response: "Help!"}, Aliases: []string{"h"}},
+	}
+	ur := &usageReporter{client: c, platform: "telegram", key: []byte("token")}
+
+	r := NewRouter(c, "default")
+	r.Use(Unknown("unknown"))
+	r.Use(ur.middleware())
+
+	for _, text := range []string{"/start", "/strat", "/start", "/h", "hello"} {
+		r.Route(context.Background(), &Message{Platform: "telegram", ChatID: "42", Text: text, Mention: text != "hello"})
+	}
+
+	c.fail = true
+	if err := ur.flush(context.Background()); err == nil {
+		t.Fatalf("expected an error reporting to an unavailable server")
+	}
+
+	c.fail = false
+	if err := ur.flush(context.Background()); err != nil {
+		t.Fatalf("while reporting usage: %v", err)
+	}
+
+	if len(c.reports) != 1 {
+		t.Fatalf("expected the events to be reported in a single batch. got=%v", len(c.reports))
+	}
+
+	expected := []struct {
+		command string
+		outcome string
+	}{
+		{command: "start", outcome: "hit"},
+		{command: "strat", outcome: "miss"},
+		{command: "start", outcome: "hit"},
+		{command: "help", outcome: "hit"},
+	}
+
+	events := c.reports[0].GetEvents()
+	if len(events) != len(expected) {
+		t.Fatalf("expected %v events. got=%v", len(expected), events)
+	}
+
+	for i, e := range expected {
+		ev := events[i]
+		if ev.GetCommand() != e.command || ev.GetOutcome() != e.outcome || ev.GetPlatform() != "telegram" {
+			t.Fatalf("expected %s of %q on telegram. got=%v", e.outcome, e.command, ev)
+		}
+
+		if ev.GetChat() == "" || ev.GetChat() == "42" || ev.GetChat() != events[0].GetChat() {
+			t.Fatalf("expected the same anonymized chat ID on every event. got=%q", ev.GetChat())
+		}
+	}
+
+	if err := ur.flush(context.Background()); err != nil || len(c.reports) != 1 {
+		t.Fatalf("expected nothing to be reported twice. got %v reports and error %v", len(c.reports), err)
+	}
+}
@@ -0,0 +1,189 @@
+package bot
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/yanzay/tbot/v2"
+)
+
+// WebhookConfig represents the configuration of the webhook
+// through which a Telegram bot receives its updates.
+//
+// If Secret is not empty Telegram sends it on every update and the
+// updates without it are rejected. If CertFile and KeyFile are not empty
+// the updates are served over HTTPS, otherwise it is expected that a
+// reverse proxy terminates the TLS connections. If UploadCert is true
+// CertFile is uploaded to Telegram so it trusts a self-signed certificate.
+type WebhookConfig struct {
+	Secret     string
+	CertFile   string
+	KeyFile    string
+	UploadCert bool
+}
+
+// secretHeader is the header in which Telegram sends the secret token
+// registered alongside the webhook.
+const secretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// serveWebhook registers the bot's webhook on Telegram and serves
+// the updates that Telegram sends to it until the bot is stopped.
+func (t *Telegram) serveWebhook() error {
+	u, err := url.Parse(t.WebhookURL)
+	if err != nil {
+		return errors.Wrapf(err, "while parsing webhook URL %q", t.WebhookURL)
+	}
+
+	if err := t.setWebhook(); err != nil {
+		return err
+	}
+
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(path, t.webhookHandler())
+
+	l, err := net.Listen("tcp", t.ListenAddr)
+	if err != nil {
+		return errors.Wrapf(err, "while creating a new tcp listener for addr %q", t.ListenAddr)
+	}
+
+	t.webhook = &http.Server{Handler: mux}
+	if t.Webhook.CertFile != "" && t.Webhook.KeyFile != "" {
+		err = t.webhook.ServeTLS(l, t.Webhook.CertFile, t.Webhook.KeyFile)
+	} else {
+		err = t.webhook.Serve(l)
+	}
+
+	if err != nil && err != http.ErrServerClosed {
+		return errors.Wrapf(err, "while serving Telegram updates on %q", t.ListenAddr)
+	}
+
+	return nil
+}
+
+// webhookHandler returns an http.Handler that receives the updates sent
+// by Telegram. The updates are handled before answering so Telegram
+// doesn't send more updates than the bot is able to handle and so the
+// pending ones are not lost when the bot is stopped.
+func (t *Telegram) webhookHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if t.Webhook.Secret != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get(secretHeader)), []byte(t.Webhook.Secret)) != 1 {
+			logWarning(t.log, "telegram", "bot", "webhookHandler", "", "", "invalid secret token", "update rejected")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		update := &tbot.Update{}
+		if err := json.NewDecoder(r.Body).Decode(update); err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		switch {
+		case update.Message != nil:
+			t.handleMessage(update.Message)
+		case update.InlineQuery != nil:
+			t.handleInlineQuery(update.InlineQuery)
+		case update.CallbackQuery != nil:
+			t.handleCallback(update.CallbackQuery)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// setWebhook registers the bot's webhook on Telegram.
+func (t *Telegram) setWebhook() error {
+	params := map[string]string{"url": t.WebhookURL}
+	if t.Webhook.Secret != "" {
+		params["secret_token"] = t.Webhook.Secret
+	}
+
+	var body bytes.Buffer
+	contentType := "application/x-www-form-urlencoded"
+	if t.Webhook.UploadCert && t.Webhook.CertFile != "" {
+		w := multipart.NewWriter(&body)
+		for k, v := range params {
+			if err := w.WriteField(k, v); err != nil {
+				return errors.Wrap(err, "while encoding webhook parameters")
+			}
+		}
+
+		if err := writeFile(w, "certificate", t.Webhook.CertFile); err != nil {
+			return err
+		}
+
+		if err := w.Close(); err != nil {
+			return errors.Wrap(err, "while encoding webhook parameters")
+		}
+
+		contentType = w.FormDataContentType()
+	} else {
+		values := url.Values{}
+		for k, v := range params {
+			values.Set(k, v)
+		}
+
+		body.WriteString(values.Encode())
+	}
+
+	endpoint := fmt.Sprintf("%s/bot%s/setWebhook", t.apiURL(), t.token)
+	resp, err := http.Post(endpoint, contentType, &body)
+	if err != nil {
+		return errors.Wrap(err, "while setting Telegram's webhook")
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return errors.Wrap(err, "while decoding Telegram's response to setWebhook")
+	}
+
+	if !result.OK {
+		return errors.Errorf("while setting Telegram's webhook: %s", strings.TrimSpace(result.Description))
+	}
+
+	return nil
+}
+
+func writeFile(w *multipart.Writer, field, filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return errors.Wrapf(err, "while opening file %q", filename)
+	}
+	defer f.Close()
+
+	part, err := w.CreateFormFile(field, filepath.Base(filename))
+	if err != nil {
+		return errors.Wrapf(err, "while encoding file %q", filename)
+	}
+
+	if _, err := io.Copy(part, f); err != nil {
+		return errors.Wrapf(err, "while encoding file %q", filename)
+	}
+
+	return nil
+}
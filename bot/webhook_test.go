@@ -0,0 +1,204 @@
+package bot
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeTelegram is a fake Telegram Bot API server that
+// records the requests it receives by method.
+type fakeTelegram struct {
+	mu       sync.Mutex
+	requests map[string][]*http.Request
+}
+
+func newFakeTelegram(t *testing.T) (*fakeTelegram, *httptest.Server) {
+	t.Helper()
+
+	f := &fakeTelegram{requests: make(map[string][]*http.Request)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+			r.ParseMultipartForm(1 << 20)
+		} else {
+			r.ParseForm()
+		}
+
+		method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		f.mu.Lock()
+		f.requests[method] = append(f.requests[method], r)
+		f.mu.Unlock()
+
+		if method == "getMe" {
+			w.Write([]byte(`{"ok":true,"result":{"id":1,"is_bot":true,"username":"botio_bot"}}`))
+			return
+		}
+
+		w.Write([]byte(`{"ok":true,"result":{}}`))
+	}))
+
+	return f, srv
+}
+
+func (f *fakeTelegram) received(method string) []*http.Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.requests[method]
+}
+
+func TestSetWebhook(t *testing.T) {
+	dir, err := ioutil.TempDir("", "botio")
+	if err != nil {
+		t.Fatalf("while creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cert := filepath.Join(dir, "cert.pem")
+	if err := ioutil.WriteFile(cert, []byte("certificate"), 0600); err != nil {
+		t.Fatalf("while writing certificate: %v", err)
+	}
+
+	tt := []struct {
+		name         string
+		config       WebhookConfig
+		expectedCert bool
+	}{
+		{
+			name:   "with secret",
+			config: WebhookConfig{Secret: "secret"},
+		},
+		{
+			name:         "with self-signed certificate",
+			config:       WebhookConfig{Secret: "secret", CertFile: cert, UploadCert: true},
+			expectedCert: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			f, srv := newFakeTelegram(t)
+			defer srv.Close()
+
+			tg := &Telegram{
+				WebhookURL: "https://bot.example.com/telegram",
+				Webhook:    tc.config,
+				APIURL:     srv.URL,
+				token:      "token",
+			}
+
+			if err := tg.setWebhook(); err != nil {
+				t.Fatalf("while setting webhook: %v", err)
+			}
+
+			reqs := f.received("setWebhook")
+			if len(reqs) != 1 {
+				t.Fatalf("expected %v setWebhook requests. got=%v", 1, len(reqs))
+			}
+
+			r := reqs[0]
+			if r.URL.Path != "/bottoken/setWebhook" {
+				t.Fatalf("expected request to path %q. got=%q", "/bottoken/setWebhook", r.URL.Path)
+			}
+
+			if r.FormValue("url") != tg.WebhookURL || r.FormValue("secret_token") != "secret" {
+				t.Fatalf("unexpected webhook parameters %v", r.Form)
+			}
+
+			if hasCert := r.MultipartForm != nil && len(r.MultipartForm.File["certificate"]) == 1; hasCert != tc.expectedCert {
+				t.Fatalf("expected certificate to be uploaded to be %v. got=%v", tc.expectedCert, hasCert)
+			}
+		})
+	}
+}
+
+func TestWebhookHandler(t *testing.T) {
+	f, srv := newFakeTelegram(t)
+	defer srv.Close()
+
+	tg := &Telegram{
+		WebhookURL: "https://bot.example.com/telegram",
+		Webhook:    WebhookConfig{Secret: "secret"},
+		APIURL:     srv.URL,
+	}
+
+	if err := tg.Connect(testClient(map[string]string{"start": "hi"}), "", "token", 10, "default"); err != nil {
+		t.Fatalf("while connecting Telegram bot: %v", err)
+	}
+	tg.log.Out = ioutil.Discard
+
+	update := `{"update_id":1,"message":{"message_id":1,"from":{"id":7},"chat":{"id":42},"text":"/start"}}`
+	tt := []struct {
+		name         string
+		method       string
+		secret       string
+		body         string
+		expectedCode int
+	}{
+		{
+			name:         "without secret",
+			method:       http.MethodPost,
+			body:         update,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "with wrong secret",
+			method:       http.MethodPost,
+			secret:       "wrong",
+			body:         update,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "not a POST",
+			method:       http.MethodGet,
+			secret:       "secret",
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:         "bad update",
+			method:       http.MethodPost,
+			secret:       "secret",
+			body:         "{",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "valid update",
+			method:       http.MethodPost,
+			secret:       "secret",
+			body:         update,
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	h := tg.webhookHandler()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/telegram", strings.NewReader(tc.body))
+			if tc.secret != "" {
+				req.Header.Set(secretHeader, tc.secret)
+			}
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tc.expectedCode {
+				t.Fatalf("expected status code %v. got=%v", tc.expectedCode, rec.Code)
+			}
+		})
+	}
+
+	tg.Stop()
+
+	sent := f.received("sendMessage")
+	if len(sent) != 1 {
+		t.Fatalf("expected %v sent messages. got=%v", 1, len(sent))
+	}
+
+	if sent[0].FormValue("chat_id") != "42" || sent[0].FormValue("text") != "hi" {
+		t.Fatalf("unexpected message sent %v", sent[0].Form)
+	}
+}
@@ -4,9 +4,14 @@ import (
 	"github.com/danielkvist/botio/proto"
 
 	"github.com/dgraph-io/ristretto"
+	pb "github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
 )
 
+// removed replaces the value of the removed keys until ristretto,
+// which deletes them asynchronously, has deleted them.
+type removed struct{}
+
 type ristrettoCache struct {
 	cache *ristretto.Cache
 }
@@ -27,9 +32,11 @@ func (r *ristrettoCache) Init(cap int) error {
 	return nil
 }
 
-// Add adds to the cache a new *proto.BotCommand. It returns a non-nill error
-// if the received *proto.BotCommand has a Command or a Response empty or if
-// something went wrong while adding the command to the cache itself.
+// Add adds to the cache a new *proto.BotCommand under its Command and its
+// aliases, keeping every variant of its Response. It returns a non-nill error
+// if the received *proto.BotCommand has a Command empty or a Response empty,
+// without variants, callout or script to make it, or if something went wrong
+// while adding the command to the cache itself.
 func (r *ristrettoCache) Add(cmd *proto.BotCommand) error {
 	command := cmd.GetCmd().GetCommand()
 	resp := cmd.GetResp().GetResponse()
@@ -37,13 +44,14 @@ func (r *ristrettoCache) Add(cmd *proto.BotCommand) error {
 	switch {
 	case command == "":
 		return errors.Errorf("command cannot be an empty string")
-	case resp == "":
+	case resp == "" && len(cmd.GetResp().GetVariants()) == 0 && cmd.GetCallout() == nil && cmd.GetScript() == "":
 		return errors.Errorf("command's response cannot be an empty string")
 	}
 
-	ok := r.cache.Set(command, resp, 1)
-	if !ok {
-		return errors.Errorf("error while adding command %q with response %q to cache", command, resp)
+	for _, key := range append([]string{command}, cmd.GetAliases()...) {
+		if ok := r.cache.Set(key, pb.Clone(cmd), 1); !ok {
+			return errors.Errorf("error while adding command %q with response %q to cache", key, resp)
+		}
 	}
 
 	return nil
@@ -56,28 +64,34 @@ func (r *ristrettoCache) Get(cmd *proto.Command) (*proto.BotCommand, error) {
 	el := cmd.GetCommand()
 
 	val, ok := r.cache.Get(el)
-	if !ok {
+	if _, gone := val.(removed); !ok || gone {
 		return nil, errors.Errorf("command %q not found on cache", el)
 	}
 
-	resp, ok := val.(string)
+	command, ok := val.(*proto.BotCommand)
 	if !ok {
-		return nil, errors.Errorf("while converting received value as response to command %q from cache to string", el)
+		return nil, errors.Errorf("while converting received value for command %q from cache to *proto.BotCommand", el)
 	}
 
-	return &proto.BotCommand{
-		Cmd: &proto.Command{
-			Command: el,
-		},
-		Resp: &proto.Response{
-			Response: resp,
-		},
-	}, nil
+	return pb.Clone(command).(*proto.BotCommand), nil
 }
 
-// Remove deletes a *proto.BotCommand from the cache. It never
-// returns a non-nil error.
+// Remove deletes a *proto.BotCommand and its aliases from the cache.
+// Since ristretto deletes the keys asynchronously their values are
+// replaced first, so the command isn't gotten again right after it's
+// removed. It never returns a non-nil error.
 func (r *ristrettoCache) Remove(cmd *proto.Command) error {
-	r.cache.Del(cmd.GetCommand())
+	keys := []string{cmd.GetCommand()}
+	if val, ok := r.cache.Get(cmd.GetCommand()); ok {
+		if command, ok := val.(*proto.BotCommand); ok {
+			keys = append(keys, command.GetAliases()...)
+		}
+	}
+
+	for _, key := range keys {
+		r.cache.Set(key, removed{}, 1)
+		r.cache.Del(key)
+	}
+
 	return nil
 }
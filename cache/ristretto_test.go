@@ -56,6 +56,35 @@ func TestAdd(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "bot command with variants",
+			cmd: &proto.BotCommand{
+				Cmd: &proto.Command{
+					Command: "start",
+				},
+				Resp: &proto.Response{
+					Variants: []*proto.Variant{{Response: "hi"}, {Response: "hello"}},
+				},
+			},
+		},
+		{
+			name: "bot command with callout",
+			cmd: &proto.BotCommand{
+				Cmd: &proto.Command{
+					Command: "weather",
+				},
+				Callout: &proto.Callout{Url: "http://localhost/weather"},
+			},
+		},
+		{
+			name: "bot command with script",
+			cmd: &proto.BotCommand{
+				Cmd: &proto.Command{
+					Command: "roll",
+				},
+				Script: "def respond(ctx):\n    return random.randint(1, 6)",
+			},
+		},
 		{
 			name: "invalid bot command",
 			cmd: &proto.BotCommand{
@@ -162,8 +191,56 @@ func TestRemove(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	if _, err := rc.Get(cmd.GetCmd()); err == nil {
+		t.Fatalf("command %q should have triggered an error right after being removed", cmd.GetCmd().GetCommand())
+	}
+
 	time.Sleep(10 * time.Millisecond)
 	if _, err := rc.Get(cmd.GetCmd()); err == nil {
 		t.Fatalf("command %q should have triggered an error", cmd.GetCmd().GetCommand())
 	}
 }
+
+func TestAliases(t *testing.T) {
+	cmd := &proto.BotCommand{
+		Cmd: &proto.Command{
+			Command: "start",
+		},
+		Resp: &proto.Response{
+			Response: "hi",
+		},
+		Aliases: []string{"hello", "hi"},
+	}
+
+	rc := Create("ristretto")
+	if err := rc.Init(1 << 30); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := rc.Add(cmd); err != nil {
+		t.Fatalf("while adding command for testing: %v", err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	for _, alias := range cmd.GetAliases() {
+		command, err := rc.Get(&proto.Command{Command: alias})
+		if err != nil {
+			t.Fatalf("while getting command by alias %q: %v", alias, err)
+		}
+
+		if command.GetCmd().GetCommand() != cmd.GetCmd().GetCommand() {
+			t.Fatalf("expected to get command %q by alias %q. got=%q", cmd.GetCmd().GetCommand(), alias, command.GetCmd().GetCommand())
+		}
+	}
+
+	if err := rc.Remove(cmd.GetCmd()); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	for _, alias := range cmd.GetAliases() {
+		if _, err := rc.Get(&proto.Command{Command: alias}); err == nil {
+			t.Fatalf("alias %q should have triggered an error", alias)
+		}
+	}
+}
@@ -20,8 +20,27 @@ type Client interface {
 	AddCommand(context.Context, *proto.BotCommand) (*empty.Empty, error)
 	GetCommand(context.Context, *proto.Command) (*proto.BotCommand, error)
 	ListCommands(context.Context, *empty.Empty) (*proto.BotCommands, error)
+	GetChangeToken(context.Context, *empty.Empty) (*proto.ChangeToken, error)
+	SearchCommands(context.Context, *proto.SearchRequest) (*proto.BotCommands, error)
+	ResolveCommand(context.Context, *proto.ResolveRequest) (*proto.ResolveResponse, error)
+	Converse(context.Context, *proto.ConverseRequest) (*proto.ConverseResponse, error)
 	UpdateCommand(context.Context, *proto.BotCommand) (*empty.Empty, error)
 	DeleteCommand(context.Context, *proto.Command) (*empty.Empty, error)
+	ListCommandRevisions(context.Context, *proto.Command) (*proto.Revisions, error)
+	RollbackCommand(context.Context, *proto.RollbackRequest) (*proto.BotCommand, error)
+	ListAuditEvents(context.Context, *proto.AuditFilter) (*proto.AuditEvents, error)
+	AddWebhook(context.Context, *proto.Webhook) (*proto.Webhook, error)
+	ListWebhooks(context.Context, *empty.Empty) (*proto.Webhooks, error)
+	DeleteWebhook(context.Context, *proto.Webhook) (*empty.Empty, error)
+	ListWebhookDeliveries(context.Context, *proto.DeliveryFilter) (*proto.WebhookDeliveries, error)
+	RedeliverWebhook(context.Context, *proto.WebhookDelivery) (*proto.WebhookDelivery, error)
+	AddSchedule(context.Context, *proto.Schedule) (*proto.Schedule, error)
+	ListSchedules(context.Context, *empty.Empty) (*proto.Schedules, error)
+	DeleteSchedule(context.Context, *proto.Schedule) (*empty.Empty, error)
+	ClaimSchedule(context.Context, *proto.ScheduleClaim) (*empty.Empty, error)
+	ReportUsage(context.Context, *proto.UsageReport) (*empty.Empty, error)
+	GetUsageStats(context.Context, *proto.UsageStatsRequest) (*proto.UsageStats, error)
+	MatchMessage(context.Context, *proto.MatchRequest) (*proto.BotCommand, error)
 }
 
 type client struct {
@@ -133,6 +152,26 @@ func (c *client) ListCommands(ctx context.Context, _ *empty.Empty) (*proto.BotCo
 	return c.client.ListCommands(ctx, &empty.Empty{})
 }
 
+func (c *client) GetChangeToken(ctx context.Context, _ *empty.Empty) (*proto.ChangeToken, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "token", c.jwt)
+	return c.client.GetChangeToken(ctx, &empty.Empty{})
+}
+
+func (c *client) SearchCommands(ctx context.Context, req *proto.SearchRequest) (*proto.BotCommands, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "token", c.jwt)
+	return c.client.SearchCommands(ctx, req)
+}
+
+func (c *client) ResolveCommand(ctx context.Context, req *proto.ResolveRequest) (*proto.ResolveResponse, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "token", c.jwt)
+	return c.client.ResolveCommand(ctx, req)
+}
+
+func (c *client) Converse(ctx context.Context, req *proto.ConverseRequest) (*proto.ConverseResponse, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "token", c.jwt)
+	return c.client.Converse(ctx, req)
+}
+
 func (c *client) UpdateCommand(ctx context.Context, cmd *proto.BotCommand) (*empty.Empty, error) {
 	command := cmd.GetCmd().GetCommand()
 	response := cmd.GetResp().GetResponse()
@@ -153,3 +192,78 @@ func (c *client) DeleteCommand(ctx context.Context, cmd *proto.Command) (*empty.
 	ctx = metadata.AppendToOutgoingContext(ctx, "token", c.jwt)
 	return c.client.DeleteCommand(ctx, cmd)
 }
+
+func (c *client) ListCommandRevisions(ctx context.Context, cmd *proto.Command) (*proto.Revisions, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "token", c.jwt)
+	return c.client.ListCommandRevisions(ctx, cmd)
+}
+
+func (c *client) RollbackCommand(ctx context.Context, req *proto.RollbackRequest) (*proto.BotCommand, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "token", c.jwt)
+	return c.client.RollbackCommand(ctx, req)
+}
+
+func (c *client) ListAuditEvents(ctx context.Context, f *proto.AuditFilter) (*proto.AuditEvents, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "token", c.jwt)
+	return c.client.ListAuditEvents(ctx, f)
+}
+
+func (c *client) AddWebhook(ctx context.Context, h *proto.Webhook) (*proto.Webhook, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "token", c.jwt)
+	return c.client.AddWebhook(ctx, h)
+}
+
+func (c *client) ListWebhooks(ctx context.Context, _ *empty.Empty) (*proto.Webhooks, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "token", c.jwt)
+	return c.client.ListWebhooks(ctx, &empty.Empty{})
+}
+
+func (c *client) DeleteWebhook(ctx context.Context, h *proto.Webhook) (*empty.Empty, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "token", c.jwt)
+	return c.client.DeleteWebhook(ctx, h)
+}
+
+func (c *client) ListWebhookDeliveries(ctx context.Context, f *proto.DeliveryFilter) (*proto.WebhookDeliveries, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "token", c.jwt)
+	return c.client.ListWebhookDeliveries(ctx, f)
+}
+
+func (c *client) RedeliverWebhook(ctx context.Context, dl *proto.WebhookDelivery) (*proto.WebhookDelivery, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "token", c.jwt)
+	return c.client.RedeliverWebhook(ctx, dl)
+}
+
+func (c *client) AddSchedule(ctx context.Context, sch *proto.Schedule) (*proto.Schedule, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "token", c.jwt)
+	return c.client.AddSchedule(ctx, sch)
+}
+
+func (c *client) ListSchedules(ctx context.Context, _ *empty.Empty) (*proto.Schedules, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "token", c.jwt)
+	return c.client.ListSchedules(ctx, &empty.Empty{})
+}
+
+func (c *client) DeleteSchedule(ctx context.Context, sch *proto.Schedule) (*empty.Empty, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "token", c.jwt)
+	return c.client.DeleteSchedule(ctx, sch)
+}
+
+func (c *client) ClaimSchedule(ctx context.Context, claim *proto.ScheduleClaim) (*empty.Empty, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "token", c.jwt)
+	return c.client.ClaimSchedule(ctx, claim)
+}
+
+func (c *client) ReportUsage(ctx context.Context, report *proto.UsageReport) (*empty.Empty, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "token", c.jwt)
+	return c.client.ReportUsage(ctx, report)
+}
+
+func (c *client) GetUsageStats(ctx context.Context, req *proto.UsageStatsRequest) (*proto.UsageStats, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "token", c.jwt)
+	return c.client.GetUsageStats(ctx, req)
+}
+
+func (c *client) MatchMessage(ctx context.Context, req *proto.MatchRequest) (*proto.BotCommand, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "token", c.jwt)
+	return c.client.MatchMessage(ctx, req)
+}
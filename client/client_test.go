@@ -3,6 +3,7 @@ package client
 import (
 	"bytes"
 	"context"
+	"net"
 	"testing"
 
 	"github.com/danielkvist/botio/proto"
@@ -282,10 +283,11 @@ func testClient(t *testing.T, closeCh <-chan struct{}) Client {
 		t.Fatalf("while signing authentication token for testing: %v", err)
 	}
 
+	addr := freeAddr(t)
 	s, err := server.New(
 		server.WithTestDB(),
 		server.WithRistrettoCache(262144000),
-		server.WithListener(":33333"), // FIXME:
+		server.WithListener(addr),
 		server.WithInsecureGRPCServer(),
 		server.WithTextLogger(&bytes.Buffer{}),
 		server.WithJWTAuthToken("testing"),
@@ -304,6 +306,20 @@ func testClient(t *testing.T, closeCh <-chan struct{}) Client {
 		s.CloseList()
 	}()
 
-	c, err := New(":33333", tokenStr, WithInsecureConn(":33333"))
+	c, err := New(addr, tokenStr, WithInsecureConn(addr))
 	return c
 }
+
+// freeAddr returns the address of a free TCP port so that the
+// tests don't have to wait for the previous Server to close.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("while looking for a free port for testing: %v", err)
+	}
+	defer l.Close()
+
+	return l.Addr().String()
+}
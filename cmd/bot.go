@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"log"
+	"time"
 
 	"github.com/danielkvist/botio/bot"
 
@@ -12,15 +13,41 @@ import (
 // Bot returns a *cobra.Command
 func Bot() *cobra.Command {
 	var addr string
+	var chatBurst int
+	var chatRate float64
+	var cooldown time.Duration
 	var defaultResp string
+	var deniedResp string
+	var discordEphemeral bool
+	var discordGuild string
+	var globalBurst int
+	var globalRate float64
 	var goroutines int
+	var helpCommand string
+	var suggestions string
 	var jwtToken string
+	var listen string
+	var name string
 	var platform string
 	var serverName string
 	var sslca string
 	var sslcrt string
 	var sslkey string
+	var slowDownResp string
+	var schedules bool
+	var syncCheckInterval time.Duration
+	var syncInterval time.Duration
+	var telegramAPIURL string
+	var webhookCert string
+	var webhookKey string
+	var webhookSecret string
+	var webhookUploadCert bool
+	var webhookURL string
 	var token string
+	var unknownResp string
+	var usageInterval time.Duration
+	var userBurst int
+	var userRate float64
 
 	b := &cobra.Command{
 		Use:     "bot",
@@ -42,13 +69,58 @@ func Bot() *cobra.Command {
 				return errors.Wrapf(err, "while creating a new chatbot for platform %q: %v", platform, err)
 			}
 
+			switch b := b.(type) {
+			case *bot.Telegram:
+				b.HelpCommand = helpCommand
+				b.Suggestions = suggestions
+				b.UnknownCommand = unknownResp
+				b.DeniedCommand = deniedResp
+				b.SyncInterval = syncInterval
+				b.SyncCheckInterval = syncCheckInterval
+				b.Schedules = schedules
+				b.Name = name
+				b.UsageInterval = usageInterval
+				b.WebhookURL = webhookURL
+				b.ListenAddr = listen
+				b.APIURL = telegramAPIURL
+				b.Webhook = bot.WebhookConfig{
+					Secret:     webhookSecret,
+					CertFile:   webhookCert,
+					KeyFile:    webhookKey,
+					UploadCert: webhookUploadCert,
+				}
+			case *bot.Discord:
+				b.HelpCommand = helpCommand
+				b.Suggestions = suggestions
+				b.UnknownCommand = unknownResp
+				b.DeniedCommand = deniedResp
+				b.SyncInterval = syncInterval
+				b.SyncCheckInterval = syncCheckInterval
+				b.Schedules = schedules
+				b.Name = name
+				b.UsageInterval = usageInterval
+				b.GuildID = discordGuild
+				b.Ephemeral = discordEphemeral
+			}
+
+			b.Use(bot.RateLimit(bot.RateLimitConfig{
+				UserRate:         userRate,
+				UserBurst:        userBurst,
+				ChatRate:         chatRate,
+				ChatBurst:        chatBurst,
+				GlobalRate:       globalRate,
+				GlobalBurst:      globalBurst,
+				Cooldown:         cooldown,
+				SlowDownResponse: slowDownResp,
+			}))
+
 			b.Connect(c, u, token, goroutines, defaultResp)
 			b.Listen()
 			defer b.Stop()
 
 			log.Printf("chatbot for platform %q initialized!\n", platform)
 			if err := b.Start(); err != nil {
-				return errors.Wrapf(b.Start(), "while starting chatbot for platform %q", platform)
+				return errors.Wrapf(err, "while starting chatbot for platform %q", platform)
 			}
 
 			return nil
@@ -56,14 +128,40 @@ func Bot() *cobra.Command {
 		SilenceUsage: true,
 	}
 
+	b.Flags().DurationVar(&cooldown, "cooldown", 0, "minimum time between two answers to the same command in the same chat")
+	b.Flags().BoolVar(&discordEphemeral, "discord-ephemeral", false, "answer Discord slash commands only to the user that used them")
+	b.Flags().BoolVar(&schedules, "schedules", true, "send the messages scheduled on botio's server for the platform")
+	b.Flags().BoolVar(&webhookUploadCert, "telegram-webhook-upload-cert", false, "upload the webhook certificate to Telegram (for self-signed certificates)")
+	b.Flags().DurationVar(&syncCheckInterval, "sync-check-interval", 10*time.Second, "interval between checks for changes of the commands on botio's server to sync the platform's command menu (0 disables them)")
+	b.Flags().DurationVar(&syncInterval, "sync-interval", 5*time.Minute, "interval between syncs of the platform's command menu (0 syncs it only at startup)")
+	b.Flags().DurationVar(&usageInterval, "usage-interval", time.Minute, "interval between reports of the usage of the commands to botio's server (0 disables them)")
+	b.Flags().Float64Var(&chatRate, "chat-rate", 0, "messages per second allowed per chat (0 disables the limit)")
+	b.Flags().Float64Var(&globalRate, "global-rate", 0, "messages per second allowed for the whole bot (0 disables the limit)")
+	b.Flags().Float64Var(&userRate, "user-rate", 1, "messages per second allowed per user (0 disables the limit)")
+	b.Flags().IntVar(&chatBurst, "chat-burst", 10, "maximum burst of messages allowed per chat")
+	b.Flags().IntVar(&globalBurst, "global-burst", 100, "maximum burst of messages allowed for the whole bot")
 	b.Flags().IntVar(&goroutines, "goroutines", 10, "number of goroutines")
+	b.Flags().IntVar(&userBurst, "user-burst", 5, "maximum burst of messages allowed per user")
 	b.Flags().StringVar(&addr, "addr", ":9091", "botio's gRPC server address")
 	b.Flags().StringVar(&defaultResp, "resp", "I'm sorry but something's happened and I can't answer that command rigth now", "default response for when the bot fails to respond to a command")
+	b.Flags().StringVar(&deniedResp, "denied-resp", "You are not allowed to use that command here", "response for the commands that the user is not allowed to use (empty uses the default response)")
+	b.Flags().StringVar(&unknownResp, "unknown-resp", "I'm sorry but I don't know that command", "response for the commands that don't exist (empty uses the default response)")
+	b.Flags().StringVar(&discordGuild, "discord-guild", "", "Discord guild where slash commands are registered (empty to register them globally)")
+	b.Flags().StringVar(&helpCommand, "help-command", "help", "name of the command that lists the available commands (empty to disable it)")
+	b.Flags().StringVar(&suggestions, "suggestions", "Did you mean %s?", "format of the reply that suggests commands similar to a mistyped one (empty to disable it)")
 	b.Flags().StringVar(&jwtToken, "jwt", "", "authenticaton token")
+	b.Flags().StringVar(&name, "name", "", "name of the bot, shared by its replicas, whose scheduled messages it sends")
+	b.Flags().StringVar(&listen, "listen", ":8443", "address on which the Telegram webhook listens for updates")
 	b.Flags().StringVar(&platform, "platform", "", "platform (discord or telegram)")
+	b.Flags().StringVar(&slowDownResp, "slowdown-resp", "", "response sent once to users or chats that exceed the rate limits (empty to stay silent)")
 	b.Flags().StringVar(&sslca, "sslca", "", "ssl client certification file")
 	b.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
 	b.Flags().StringVar(&sslcrt, "sslkey", "", "ssl certification key file")
+	b.Flags().StringVar(&telegramAPIURL, "telegram-api-url", "https://api.telegram.org", "Telegram Bot API URL")
+	b.Flags().StringVar(&webhookCert, "telegram-webhook-cert", "", "certificate file to serve the Telegram webhook over HTTPS")
+	b.Flags().StringVar(&webhookKey, "telegram-webhook-key", "", "certificate key file to serve the Telegram webhook over HTTPS")
+	b.Flags().StringVar(&webhookSecret, "telegram-webhook-secret", "", "secret token that Telegram must send with every update")
+	b.Flags().StringVar(&webhookURL, "telegram-webhook-url", "", "public URL of the Telegram webhook (empty to use long polling)")
 	b.Flags().StringVar(&token, "token", "", "bot's token")
 
 	return b
@@ -3,19 +3,26 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/danielkvist/botio/client"
 	"github.com/danielkvist/botio/proto"
 	"github.com/pkg/errors"
 
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/spf13/cobra"
 )
 
 // Client returns a *cobra.Command with multiple subcommands.
 func Client() *cobra.Command {
-	return clientCmd(add(), print(), list(), update(), delete())
+	return clientCmd(add(), print(), list(), search(), update(), delete(), history(), rollback(), audit(), webhook(), schedule(), broadcast(), stats())
 }
 
 func clientCmd(commands ...*cobra.Command) *cobra.Command {
@@ -32,14 +39,27 @@ func clientCmd(commands ...*cobra.Command) *cobra.Command {
 }
 
 func add() *cobra.Command {
+	var access accessFlags
 	var addr string
+	var aliases []string
+	var buttons []string
+	var callout calloutFlags
 	var command string
+	var description string
+	var flowFile string
+	var hidden bool
+	var lang string
 	var response string
+	var scriptFile string
+	var selection string
 	var serverName string
 	var sslca string
 	var sslcrt string
 	var sslkey string
 	var token string
+	var triggers []string
+	var variants []string
+	var weights []int
 
 	add := &cobra.Command{
 		Use:     "add",
@@ -51,13 +71,55 @@ func add() *cobra.Command {
 				return err
 			}
 
+			bs, err := parseButtons(buttons)
+			if err != nil {
+				return err
+			}
+
+			flow, err := readFlow(flowFile)
+			if err != nil {
+				return err
+			}
+
+			script, err := readScript(scriptFile)
+			if err != nil {
+				return err
+			}
+
+			vs, err := parseVariants(variants, weights)
+			if err != nil {
+				return err
+			}
+
+			ts, err := parseTriggers(triggers)
+			if err != nil {
+				return err
+			}
+
+			var translations map[string]string
+			if lang != "" {
+				translations = map[string]string{lang: response}
+			}
+
 			if _, err := c.AddCommand(context.TODO(), &proto.BotCommand{
 				Cmd: &proto.Command{
 					Command: command,
 				},
 				Resp: &proto.Response{
-					Response: response,
+					Response:     response,
+					Buttons:      bs,
+					Translations: translations,
+					Variants:     vs,
+					Selection:    selection,
 				},
+				Description: description,
+				Hidden:      hidden,
+				Flow:        flow,
+				Aliases:     aliases,
+				Callout:     callout.callout(),
+				Script:      script,
+				Triggers:    ts,
+				Access:      access.access(),
 			}); err != nil {
 				return errors.Wrapf(err, "while adding command %q with response %q", command, response)
 			}
@@ -69,12 +131,25 @@ func add() *cobra.Command {
 	}
 
 	add.Flags().StringVar(&addr, "addr", ":9091", "botio's gRPC server address")
+	add.Flags().StringSliceVar(&aliases, "alias", nil, "other name that resolves to the command (can be repeated)")
+	add.Flags().StringSliceVar(&buttons, "button", nil, "button shown below the response as TEXT=COMMAND (can be repeated)")
+	access.register(add)
+	callout.register(add)
 	add.Flags().StringVar(&command, "command", "", "command to add")
+	add.Flags().StringVar(&flowFile, "flow", "", "JSON file with the dialog started by the command")
+	add.Flags().StringVar(&description, "description", "", "short explanation of what the command does")
+	add.Flags().BoolVar(&hidden, "hidden", false, "hide the command from help and command menus")
+	add.Flags().StringVar(&lang, "lang", "", "language of the response as a BCP 47 tag, like \"es\"")
 	add.Flags().StringVar(&response, "response", "", "command's response")
+	add.Flags().StringVar(&selection, "selection", "", "how a variant is picked: random, round-robin, weighted or sticky")
+	add.Flags().StringVar(&scriptFile, "script", "", "Starlark file with the script that computes the response of the command")
 	add.Flags().StringVar(&sslca, "sslca", "", "ssl client certification file")
 	add.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
 	add.Flags().StringVar(&sslkey, "sslkey", "", "ssl certification key file")
 	add.Flags().StringVar(&token, "token", "", "authentication token")
+	add.Flags().StringArrayVar(&triggers, "trigger", nil, "message that the command answers as TYPE:PATTERN, with TYPE exact, prefix, keyword or regex (can be repeated)")
+	add.Flags().StringArrayVar(&variants, "variant", nil, "alternative response picked instead of the response (can be repeated)")
+	add.Flags().IntSliceVar(&weights, "weights", nil, "weights of the variants, in the same order, for the weighted and sticky selections")
 
 	return add
 }
@@ -82,6 +157,8 @@ func add() *cobra.Command {
 func print() *cobra.Command {
 	var addr string
 	var command string
+	var lang string
+	var raw bool
 	var serverName string
 	var sslca string
 	var sslcrt string
@@ -100,6 +177,8 @@ func print() *cobra.Command {
 
 			botCommand, err := c.GetCommand(context.TODO(), &proto.Command{
 				Command: command,
+				Lang:    lang,
+				Raw:     raw,
 			})
 			if err != nil {
 				return errors.Wrapf(err, "while getting command %q", command)
@@ -113,6 +192,8 @@ func print() *cobra.Command {
 
 	print.Flags().StringVar(&addr, "addr", ":9091", "botio's gRPC server address")
 	print.Flags().StringVar(&command, "command", "", "command to print")
+	print.Flags().StringVar(&lang, "lang", "", "languages in which to print the response, like \"es-AR, en\"")
+	print.Flags().BoolVar(&raw, "raw", false, "print the command as it is stored, without resolving its response")
 	print.Flags().StringVar(&sslca, "sslca", "", "ssl client certification file")
 	print.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
 	print.Flags().StringVar(&sslkey, "sslkey", "", "ssl certification key file")
@@ -162,15 +243,77 @@ func list() *cobra.Command {
 	return list
 }
 
+func search() *cobra.Command {
+	var addr string
+	var limit int32
+	var query string
+	var serverName string
+	var sslca string
+	var sslcrt string
+	var sslkey string
+	var token string
+
+	search := &cobra.Command{
+		Use:     "search",
+		Short:   "Searches the visible commands by name or description.",
+		Example: "botio client search --query weather --token <jwt-token>",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := getClient(addr, token, serverName, sslcrt, sslkey, sslca)
+			if err != nil {
+				return err
+			}
+
+			botCommands, err := c.SearchCommands(context.TODO(), &proto.SearchRequest{
+				Query: query,
+				Limit: limit,
+			})
+			if err != nil {
+				return errors.Wrapf(err, "while searching commands for %q", query)
+			}
+
+			for _, bc := range botCommands.GetCommands() {
+				printCommand(bc)
+			}
+
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	search.Flags().Int32Var(&limit, "limit", 0, "maximum number of commands returned (0 for no limit)")
+	search.Flags().StringVar(&addr, "addr", ":9091", "botio's gRPC server address")
+	search.Flags().StringVar(&query, "query", "", "text to search in the commands' names and descriptions")
+	search.Flags().StringVar(&sslca, "sslca", "", "ssl client certification file")
+	search.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
+	search.Flags().StringVar(&sslkey, "sslkey", "", "ssl certification key file")
+	search.Flags().StringVar(&token, "token", "", "authentication token")
+
+	return search
+}
+
 func update() *cobra.Command {
+	var access accessFlags
 	var addr string
+	var aliases []string
+	var buttons []string
+	var callout calloutFlags
 	var command string
+	var description string
+	var flowFile string
+	var hidden bool
+	var lang string
 	var response string
+	var scriptFile string
+	var selection string
 	var serverName string
 	var sslca string
 	var sslcrt string
 	var sslkey string
 	var token string
+	var triggers []string
+	var variants []string
+	var version int64
+	var weights []int
 
 	update := &cobra.Command{
 		Use:     "update",
@@ -182,14 +325,73 @@ func update() *cobra.Command {
 				return err
 			}
 
-			if _, err := c.UpdateCommand(context.TODO(), &proto.BotCommand{
+			bs, err := parseButtons(buttons)
+			if err != nil {
+				return err
+			}
+
+			flow, err := readFlow(flowFile)
+			if err != nil {
+				return err
+			}
+
+			script, err := readScript(scriptFile)
+			if err != nil {
+				return err
+			}
+
+			vs, err := parseVariants(variants, weights)
+			if err != nil {
+				return err
+			}
+
+			ts, err := parseTriggers(triggers)
+			if err != nil {
+				return err
+			}
+
+			botCommand := &proto.BotCommand{
 				Cmd: &proto.Command{
 					Command: command,
 				},
 				Resp: &proto.Response{
-					Response: response,
+					Response:  response,
+					Buttons:   bs,
+					Variants:  vs,
+					Selection: selection,
 				},
-			}); err != nil {
+				Description: description,
+				Hidden:      hidden,
+				Flow:        flow,
+				Aliases:     aliases,
+				Callout:     callout.callout(),
+				Script:      script,
+				Triggers:    ts,
+				Access:      access.access(),
+			}
+
+			if lang != "" {
+				// Only the translation changes if the command exists.
+				if stored, err := c.GetCommand(context.TODO(), &proto.Command{Command: command, Raw: true}); err == nil {
+					botCommand = stored
+				}
+
+				if response != "" || len(variants) == 0 {
+					setTranslation(botCommand, lang, response)
+				}
+
+				if len(variants) > 0 {
+					if err := setVariantTranslations(botCommand, lang, variants); err != nil {
+						return err
+					}
+				}
+			}
+
+			if version != 0 {
+				botCommand.Version = version
+			}
+
+			if _, err := c.UpdateCommand(context.TODO(), botCommand); err != nil {
 				return errors.Wrapf(err, "while updating command %q with response %q", command, response)
 			}
 
@@ -200,12 +402,26 @@ func update() *cobra.Command {
 	}
 
 	update.Flags().StringVar(&addr, "addr", ":9091", "botio's gRPC server address")
+	update.Flags().StringSliceVar(&aliases, "alias", nil, "other name that resolves to the command (can be repeated)")
+	update.Flags().StringSliceVar(&buttons, "button", nil, "button shown below the response as TEXT=COMMAND (can be repeated)")
+	access.register(update)
+	callout.register(update)
 	update.Flags().StringVar(&command, "command", "", "command to update")
+	update.Flags().StringVar(&flowFile, "flow", "", "JSON file with the dialog started by the command")
+	update.Flags().StringVar(&description, "description", "", "short explanation of what the command does")
+	update.Flags().BoolVar(&hidden, "hidden", false, "hide the command from help and command menus")
+	update.Flags().StringVar(&lang, "lang", "", "language of the response as a BCP 47 tag, like \"es\" (only updates that translation)")
 	update.Flags().StringVar(&response, "response", "", "command's new response")
+	update.Flags().StringVar(&selection, "selection", "", "how a variant is picked: random, round-robin, weighted or sticky")
+	update.Flags().StringVar(&scriptFile, "script", "", "Starlark file with the script that computes the response of the command")
 	update.Flags().StringVar(&sslca, "sslca", "", "ssl client certification file")
 	update.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
 	update.Flags().StringVar(&sslkey, "sslkey", "", "ssl certification key file")
 	update.Flags().StringVar(&token, "token", "", "authentication token")
+	update.Flags().StringArrayVar(&triggers, "trigger", nil, "message that the command answers as TYPE:PATTERN, with TYPE exact, prefix, keyword or regex (can be repeated)")
+	update.Flags().StringArrayVar(&variants, "variant", nil, "alternative response picked instead of the response, or its translation in the same order with --lang (can be repeated)")
+	update.Flags().IntSliceVar(&weights, "weights", nil, "weights of the variants, in the same order, for the weighted and sticky selections")
+	update.Flags().Int64Var(&version, "version", 0, "version that the command is expected to have (0 skips the check)")
 
 	return update
 }
@@ -218,6 +434,7 @@ func delete() *cobra.Command {
 	var sslcrt string
 	var sslkey string
 	var token string
+	var version int64
 
 	delete := &cobra.Command{
 		Use:     "delete",
@@ -231,6 +448,7 @@ func delete() *cobra.Command {
 
 			if _, err := c.DeleteCommand(context.TODO(), &proto.Command{
 				Command: command,
+				Version: version,
 			}); err != nil {
 				return errors.Wrapf(err, "while deleting command %q", command)
 			}
@@ -247,10 +465,102 @@ func delete() *cobra.Command {
 	delete.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
 	delete.Flags().StringVar(&sslkey, "sslkey", "", "ssl certification key file")
 	delete.Flags().StringVar(&token, "token", "t", "authentication token")
+	delete.Flags().Int64Var(&version, "version", 0, "version that the command is expected to have (0 skips the check)")
 
 	return delete
 }
 
+func history() *cobra.Command {
+	var addr string
+	var command string
+	var serverName string
+	var sslca string
+	var sslcrt string
+	var sslkey string
+	var token string
+
+	history := &cobra.Command{
+		Use:     "history",
+		Short:   "Lists the revisions of the requested command.",
+		Example: "botio client history --command start --token <jwt-token>",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := getClient(addr, token, serverName, sslcrt, sslkey, sslca)
+			if err != nil {
+				return err
+			}
+
+			revisions, err := c.ListCommandRevisions(context.TODO(), &proto.Command{
+				Command: command,
+			})
+			if err != nil {
+				return errors.Wrapf(err, "while listing revisions of command %q", command)
+			}
+
+			for _, rev := range revisions.GetRevisions() {
+				printRevision(rev)
+			}
+
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	history.Flags().StringVar(&addr, "addr", ":9091", "botio's gRPC server address")
+	history.Flags().StringVar(&command, "command", "", "command whose revisions to list")
+	history.Flags().StringVar(&sslca, "sslca", "", "ssl client certification file")
+	history.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
+	history.Flags().StringVar(&sslkey, "sslkey", "", "ssl certification key file")
+	history.Flags().StringVar(&token, "token", "", "authentication token")
+
+	return history
+}
+
+func rollback() *cobra.Command {
+	var addr string
+	var command string
+	var revision int64
+	var serverName string
+	var sslca string
+	var sslcrt string
+	var sslkey string
+	var token string
+
+	rollback := &cobra.Command{
+		Use:     "rollback",
+		Short:   "Restores the requested command as it was after one of its revisions.",
+		Example: "botio client rollback --command start --revision 2 --token <jwt-token>",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := getClient(addr, token, serverName, sslcrt, sslkey, sslca)
+			if err != nil {
+				return err
+			}
+
+			botCommand, err := c.RollbackCommand(context.TODO(), &proto.RollbackRequest{
+				Command:  command,
+				Revision: revision,
+			})
+			if err != nil {
+				return errors.Wrapf(err, "while rolling back command %q to revision %v", command, revision)
+			}
+
+			log.Printf("command %q rolled back to revision %v successfully!", command, revision)
+			printCommand(botCommand)
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	rollback.Flags().StringVar(&addr, "addr", ":9091", "botio's gRPC server address")
+	rollback.Flags().StringVar(&command, "command", "", "command to roll back")
+	rollback.Flags().Int64Var(&revision, "revision", 0, "number of the revision to restore")
+	rollback.Flags().StringVar(&sslca, "sslca", "", "ssl client certification file")
+	rollback.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
+	rollback.Flags().StringVar(&sslkey, "sslkey", "", "ssl certification key file")
+	rollback.Flags().StringVar(&token, "token", "", "authentication token")
+
+	return rollback
+}
+
 func getClient(url, token, server, crt, key, ca string) (client.Client, error) {
 	var c client.Client
 	var u string
@@ -283,6 +593,453 @@ func securedClient(url, token, server, crt, key, ca string) (client.Client, erro
 }
 
 // FIXME:
+func audit() *cobra.Command {
+	var action string
+	var addr string
+	var command string
+	var limit int32
+	var serverName string
+	var since time.Duration
+	var sslca string
+	var sslcrt string
+	var sslkey string
+	var subject string
+	var token string
+
+	audit := &cobra.Command{
+		Use:     "audit",
+		Short:   "Lists the changes made to the commands.",
+		Example: "botio client audit --command start --since 24h --token <jwt-token>",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := getClient(addr, token, serverName, sslcrt, sslkey, sslca)
+			if err != nil {
+				return err
+			}
+
+			filter := &proto.AuditFilter{
+				Command: command,
+				Subject: subject,
+				Action:  action,
+				Limit:   limit,
+			}
+
+			if since > 0 {
+				filter.Since, err = ptypes.TimestampProto(time.Now().Add(-since))
+				if err != nil {
+					return errors.Wrapf(err, "while filtering audit events since %v ago", since)
+				}
+			}
+
+			events, err := c.ListAuditEvents(context.TODO(), filter)
+			if err != nil {
+				return errors.Wrap(err, "while listing audit events")
+			}
+
+			for _, ev := range events.GetEvents() {
+				printAuditEvent(ev)
+			}
+
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	audit.Flags().DurationVar(&since, "since", 0, "only list the changes made in this period of time, like 24h (0 lists all of them)")
+	audit.Flags().Int32Var(&limit, "limit", 0, "maximum number of changes to list, the newest ones (0 lists all of them)")
+	audit.Flags().StringVar(&action, "action", "", "only list the changes of this kind (add, update, delete or rollback)")
+	audit.Flags().StringVar(&addr, "addr", ":9091", "botio's gRPC server address")
+	audit.Flags().StringVar(&command, "command", "", "only list the changes made to this command")
+	audit.Flags().StringVar(&sslca, "sslca", "", "ssl client certification file")
+	audit.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
+	audit.Flags().StringVar(&sslkey, "sslkey", "", "ssl certification key file")
+	audit.Flags().StringVar(&subject, "subject", "", "only list the changes made with JWTs of this subject")
+	audit.Flags().StringVar(&token, "token", "", "authentication token")
+
+	return audit
+}
+
+func stats() *cobra.Command {
+	var addr string
+	var days int32
+	var limit int32
+	var serverName string
+	var sslca string
+	var sslcrt string
+	var sslkey string
+	var token string
+
+	stats := &cobra.Command{
+		Use:     "stats",
+		Short:   "Shows the most used and the most missed commands.",
+		Example: "botio client stats --days 30 --limit 5 --token <jwt-token>",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := getClient(addr, token, serverName, sslcrt, sslkey, sslca)
+			if err != nil {
+				return err
+			}
+
+			usage, err := c.GetUsageStats(context.TODO(), &proto.UsageStatsRequest{
+				Days:  days,
+				Limit: limit,
+			})
+			if err != nil {
+				return errors.Wrap(err, "while getting usage stats")
+			}
+
+			fmt.Println("top commands:")
+			for _, u := range usage.GetTopCommands() {
+				fmt.Printf("\t%q: %v hits from %v chats in %vms on average\n", u.GetCommand(), u.GetHits(), u.GetChats(), u.GetAvgLatencyMs())
+			}
+
+			fmt.Println("top missing commands:")
+			for _, u := range usage.GetTopMissing() {
+				fmt.Printf("\t%q: %v misses from %v chats\n", u.GetCommand(), u.GetMisses(), u.GetChats())
+			}
+
+			fmt.Println("days:")
+			for _, d := range usage.GetDays() {
+				fmt.Printf("\t%s: %v hits, %v misses, %v errors\n", d.GetDay(), d.GetHits(), d.GetMisses(), d.GetErrors())
+			}
+
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	stats.Flags().Int32Var(&days, "days", 7, "number of days, including today, whose usage is shown")
+	stats.Flags().Int32Var(&limit, "limit", 10, "maximum number of commands shown on each list")
+	stats.Flags().StringVar(&addr, "addr", ":9091", "botio's gRPC server address")
+	stats.Flags().StringVar(&sslca, "sslca", "", "ssl client certification file")
+	stats.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
+	stats.Flags().StringVar(&sslkey, "sslkey", "", "ssl certification key file")
+	stats.Flags().StringVar(&token, "token", "", "authentication token")
+
+	return stats
+}
+
 func printCommand(cmd *proto.BotCommand) {
 	fmt.Printf("%q: %q\n", cmd.GetCmd().GetCommand(), cmd.GetResp().GetResponse())
+	if v := cmd.GetVersion(); v != 0 {
+		fmt.Printf("\tversion: %v\n", v)
+	}
+	if d := cmd.GetDescription(); d != "" {
+		fmt.Printf("\tdescription: %q\n", d)
+	}
+	if cmd.GetHidden() {
+		fmt.Printf("\thidden: true\n")
+	}
+	if aliases := cmd.GetAliases(); len(aliases) > 0 {
+		fmt.Printf("\taliases: %s\n", strings.Join(aliases, ", "))
+	}
+	if c := cmd.GetCallout(); c != nil {
+		fmt.Printf("\tcallout: %s", c.GetUrl())
+		if t := c.GetTemplate(); t != "" {
+			fmt.Printf(" rendered with %q", t)
+		}
+		if f := c.GetFallback(); f != "" {
+			fmt.Printf(" (fallback %q)", f)
+		}
+		fmt.Println()
+	}
+	if script := cmd.GetScript(); script != "" {
+		fmt.Printf("\tscript: %v lines\n", strings.Count(strings.TrimSpace(script), "\n")+1)
+	}
+	if flow := cmd.GetFlow(); flow != nil {
+		fmt.Printf("\tflow: %v steps starting at %q\n", len(flow.GetSteps()), flow.GetStart())
+	}
+	if vs := cmd.GetResp().GetVariants(); len(vs) > 0 {
+		selection := cmd.GetResp().GetSelection()
+		if selection == "" {
+			selection = "random"
+		}
+		fmt.Printf("\tvariants (%s):\n", selection)
+		for _, v := range vs {
+			if w := v.GetWeight(); w != 0 {
+				fmt.Printf("\t\t%q (weight %v)\n", v.GetResponse(), w)
+			} else {
+				fmt.Printf("\t\t%q\n", v.GetResponse())
+			}
+
+			for _, lang := range sortedKeys(v.GetTranslations()) {
+				fmt.Printf("\t\t\t%s: %q\n", lang, v.GetTranslations()[lang])
+			}
+		}
+	}
+	for _, t := range cmd.GetTriggers() {
+		fmt.Printf("\ttrigger: %s %q\n", t.GetType(), t.GetPattern())
+	}
+	if a := cmd.GetAccess(); a != nil {
+		printAccess(a)
+	}
+	for _, b := range cmd.GetResp().GetButtons() {
+		fmt.Printf("\tbutton: %q -> %q\n", b.GetText(), b.GetCommand())
+	}
+
+	for _, lang := range sortedKeys(cmd.GetResp().GetTranslations()) {
+		fmt.Printf("\t%s: %q\n", lang, cmd.GetResp().GetTranslations()[lang])
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func printRevision(rev *proto.Revision) {
+	author := rev.GetAuthor()
+	if author == "" {
+		author = "unknown"
+	}
+
+	when := "unknown time"
+	if t, err := ptypes.Timestamp(rev.GetTime()); err == nil {
+		when = t.Local().Format(time.RFC1123)
+	}
+
+	fmt.Printf("revision %v: %s by %s on %s\n", rev.GetNumber(), rev.GetAction(), author, when)
+	for _, line := range strings.Split(rev.GetDiff(), "\n") {
+		if line != "" {
+			fmt.Printf("\t%s\n", line)
+		}
+	}
+}
+
+func printAuditEvent(ev *proto.AuditEvent) {
+	subject := ev.GetSubject()
+	if subject == "" {
+		subject = "unknown"
+	}
+
+	when := "unknown time"
+	if t, err := ptypes.Timestamp(ev.GetTime()); err == nil {
+		when = t.Local().Format(time.RFC1123)
+	}
+
+	fmt.Printf("event %v: %s %q by %s from %s on %s (request %s)\n", ev.GetId(), ev.GetAction(), ev.GetCommand(), subject, ev.GetPeer(), when, ev.GetRequestId())
+	if before := ev.GetBefore(); before != nil {
+		fmt.Printf("\tbefore: %q\n", before.GetResp().GetResponse())
+	}
+	if after := ev.GetAfter(); after != nil {
+		fmt.Printf("\tafter: %q\n", after.GetResp().GetResponse())
+	}
+}
+
+// setTranslation sets the response of the command for the received language.
+func setTranslation(cmd *proto.BotCommand, lang, response string) {
+	if cmd.Resp == nil {
+		cmd.Resp = &proto.Response{Response: response}
+	}
+
+	if cmd.Resp.Translations == nil {
+		cmd.Resp.Translations = make(map[string]string)
+	}
+
+	cmd.Resp.Translations[lang] = response
+}
+
+// setVariantTranslations sets the translations for the received
+// language of the variants of the command, in the same order.
+func setVariantTranslations(cmd *proto.BotCommand, lang string, translations []string) error {
+	vs := cmd.GetResp().GetVariants()
+	if len(vs) != len(translations) {
+		return errors.Errorf("%v translations provided for %v variants", len(translations), len(vs))
+	}
+
+	for i, v := range vs {
+		if v.Translations == nil {
+			v.Translations = make(map[string]string)
+		}
+
+		v.Translations[lang] = translations[i]
+	}
+
+	return nil
+}
+
+// readFlow reads a *proto.Flow from a JSON file. It
+// returns a nil *proto.Flow if it receives no filename.
+func readFlow(filename string) (*proto.Flow, error) {
+	if filename == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while opening flow file %q", filename)
+	}
+	defer f.Close()
+
+	flow := &proto.Flow{}
+	if err := jsonpb.Unmarshal(f, flow); err != nil {
+		return nil, errors.Wrapf(err, "while parsing flow file %q", filename)
+	}
+
+	return flow, nil
+}
+
+// readScript returns the content of the script file, if any.
+func readScript(filename string) (string, error) {
+	if filename == "" {
+		return "", nil
+	}
+
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", errors.Wrapf(err, "while reading script file %q", filename)
+	}
+
+	return string(b), nil
+}
+
+// calloutFlags are the flags that set the callout of a command.
+type calloutFlags struct {
+	url      string
+	template string
+	timeout  time.Duration
+	ttl      time.Duration
+	fallback string
+	shared   bool
+}
+
+func (cf *calloutFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&cf.url, "callout-url", "", "endpoint called to get the response of the command")
+	cmd.Flags().StringVar(&cf.template, "callout-template", "", "Go template that renders the JSON returned by the callout endpoint")
+	cmd.Flags().DurationVar(&cf.timeout, "callout-timeout", 5*time.Second, "time to wait for the callout endpoint")
+	cmd.Flags().DurationVar(&cf.ttl, "callout-ttl", 0, "time during which the responses of the callout endpoint are reused")
+	cmd.Flags().StringVar(&cf.fallback, "callout-fallback", "", "response used when the callout endpoint fails")
+	cmd.Flags().BoolVar(&cf.shared, "callout-shared-cache", false, "reuse the responses of the callout endpoint for every platform, chat and user")
+}
+
+// callout returns the callout set by the flags or nil if there's no callout URL.
+func (cf *calloutFlags) callout() *proto.Callout {
+	if cf.url == "" {
+		return nil
+	}
+
+	return &proto.Callout{
+		Url:         cf.url,
+		Template:    cf.template,
+		TimeoutMs:   int64(cf.timeout / time.Millisecond),
+		CacheTtl:    int64(cf.ttl / time.Second),
+		Fallback:    cf.fallback,
+		SharedCache: cf.shared,
+	}
+}
+
+type accessFlags struct {
+	allowedChats []string
+	deniedChats  []string
+	allowedUsers []string
+	deniedUsers  []string
+	roles        []string
+	adminsOnly   bool
+	chatType     string
+}
+
+func (af *accessFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&af.allowedChats, "allow-chat", nil, "ID of a chat where the command can be used (can be repeated)")
+	cmd.Flags().StringSliceVar(&af.deniedChats, "deny-chat", nil, "ID of a chat where the command can't be used (can be repeated)")
+	cmd.Flags().StringSliceVar(&af.allowedUsers, "allow-user", nil, "ID of a user that can use the command (can be repeated)")
+	cmd.Flags().StringSliceVar(&af.deniedUsers, "deny-user", nil, "ID of a user that can't use the command (can be repeated)")
+	cmd.Flags().StringSliceVar(&af.roles, "role", nil, "ID of a Discord role that can use the command (can be repeated)")
+	cmd.Flags().BoolVar(&af.adminsOnly, "admins-only", false, "only allow the administrators of the chat to use the command")
+	cmd.Flags().StringVar(&af.chatType, "chat-type", "", "only allow the command on direct messages (private) or on groups (group)")
+}
+
+// access returns the access rules set by the flags or nil if there are none.
+func (af *accessFlags) access() *proto.Access {
+	a := &proto.Access{
+		AllowedChats: af.allowedChats,
+		DeniedChats:  af.deniedChats,
+		AllowedUsers: af.allowedUsers,
+		DeniedUsers:  af.deniedUsers,
+		Roles:        af.roles,
+		AdminsOnly:   af.adminsOnly,
+		ChatType:     af.chatType,
+	}
+
+	if len(a.AllowedChats)+len(a.DeniedChats)+len(a.AllowedUsers)+len(a.DeniedUsers)+len(a.Roles) == 0 && !a.AdminsOnly && a.ChatType == "" {
+		return nil
+	}
+
+	return a
+}
+
+func printAccess(a *proto.Access) {
+	rules := []struct {
+		name string
+		ids  []string
+	}{
+		{"allowed chats", a.GetAllowedChats()},
+		{"denied chats", a.GetDeniedChats()},
+		{"allowed users", a.GetAllowedUsers()},
+		{"denied users", a.GetDeniedUsers()},
+		{"roles", a.GetRoles()},
+	}
+
+	for _, r := range rules {
+		if len(r.ids) > 0 {
+			fmt.Printf("\t%s: %s\n", r.name, strings.Join(r.ids, ", "))
+		}
+	}
+	if a.GetAdminsOnly() {
+		fmt.Printf("\tadmins only: true\n")
+	}
+	if t := a.GetChatType(); t != "" {
+		fmt.Printf("\tchat type: %s\n", t)
+	}
+}
+
+// parseVariants pairs the variants with their weights, if any.
+func parseVariants(variants []string, weights []int) ([]*proto.Variant, error) {
+	if len(weights) > 0 && len(weights) != len(variants) {
+		return nil, errors.Errorf("%v weights provided for %v variants", len(weights), len(variants))
+	}
+
+	var vs []*proto.Variant
+	for i, v := range variants {
+		variant := &proto.Variant{Response: v}
+		if len(weights) > 0 {
+			variant.Weight = int64(weights[i])
+		}
+
+		vs = append(vs, variant)
+	}
+
+	return vs, nil
+}
+
+// parseTriggers parses triggers in the form TYPE:PATTERN.
+func parseTriggers(triggers []string) ([]*proto.Trigger, error) {
+	var ts []*proto.Trigger
+	for _, t := range triggers {
+		i := strings.Index(t, ":")
+		if i <= 0 || i == len(t)-1 {
+			return nil, errors.Errorf("invalid trigger %q, expected TYPE:PATTERN", t)
+		}
+
+		ts = append(ts, &proto.Trigger{Type: t[:i], Pattern: t[i+1:]})
+	}
+
+	return ts, nil
+}
+
+// parseButtons parses buttons in the form TEXT=COMMAND.
+func parseButtons(buttons []string) ([]*proto.Button, error) {
+	var bs []*proto.Button
+	for _, b := range buttons {
+		i := strings.LastIndex(b, "=")
+		if i <= 0 || i == len(b)-1 {
+			return nil, errors.Errorf("invalid button %q, expected TEXT=COMMAND", b)
+		}
+
+		bs = append(bs, &proto.Button{Text: b[:i], Command: b[i+1:]})
+	}
+
+	return bs, nil
 }
@@ -1,6 +1,10 @@
 package cmd
 
-import "testing"
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
 
 func TestCheckURL(t *testing.T) {
 	tt := []struct {
@@ -44,3 +48,73 @@ func TestCheckURL(t *testing.T) {
 		})
 	}
 }
+
+func TestParseButtons(t *testing.T) {
+	tt := []struct {
+		name           string
+		buttons        []string
+		expectedText   string
+		expectedCmd    string
+		expectedToFail bool
+	}{
+		{
+			name:         "valid button",
+			buttons:      []string{"Next page=help 2"},
+			expectedText: "Next page",
+			expectedCmd:  "help 2",
+		},
+		{
+			name:           "without command",
+			buttons:        []string{"Next="},
+			expectedToFail: true,
+		},
+		{
+			name:           "without separator",
+			buttons:        []string{"Next"},
+			expectedToFail: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			bs, err := parseButtons(tc.buttons)
+			if (err != nil) != tc.expectedToFail {
+				t.Fatalf("expected error to be %v. got=%v", tc.expectedToFail, err)
+			}
+
+			if tc.expectedToFail {
+				return
+			}
+
+			if len(bs) != 1 || bs[0].GetText() != tc.expectedText || bs[0].GetCommand() != tc.expectedCmd {
+				t.Fatalf("expected button %q=%q. got=%v", tc.expectedText, tc.expectedCmd, bs)
+			}
+		})
+	}
+}
+
+func TestReadFlow(t *testing.T) {
+	f, err := ioutil.TempFile("", "flow")
+	if err != nil {
+		t.Fatalf("while creating temporary file: %v", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(`{"start": "ask", "steps": {"ask": {"prompt": "Yes or no?", "branches": {"yes": "end"}}, "end": {"prompt": "Bye"}}}`); err != nil {
+		t.Fatalf("while writing flow: %v", err)
+	}
+	f.Close()
+
+	flow, err := readFlow(f.Name())
+	if err != nil {
+		t.Fatalf("while reading flow: %v", err)
+	}
+
+	if flow.GetStart() != "ask" || flow.GetSteps()["ask"].GetBranches()["yes"] != "end" {
+		t.Fatalf("unexpected flow %v", flow)
+	}
+
+	if flow, err := readFlow(""); flow != nil || err != nil {
+		t.Fatalf("expected no flow without filename. got=%v, %v", flow, err)
+	}
+}
@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// schedule returns a *cobra.Command with the
+// subcommands to manage the scheduled messages.
+func schedule() *cobra.Command {
+	schedule := &cobra.Command{
+		Use:   "schedule",
+		Short: "Manages the messages that the bots send periodically.",
+	}
+
+	for _, cmd := range []*cobra.Command{addSchedule(), listSchedules(), deleteSchedule()} {
+		schedule.AddCommand(cmd)
+	}
+
+	return schedule
+}
+
+func addSchedule() *cobra.Command {
+	var addr string
+	var bot string
+	var chats []string
+	var command string
+	var cron string
+	var platform string
+	var response string
+	var serverName string
+	var sslca string
+	var sslcrt string
+	var sslkey string
+	var token string
+
+	add := &cobra.Command{
+		Use:     "add",
+		Short:   "Schedules a message to be sent periodically.",
+		Example: "botio client schedule add --cron \"0 9 * * mon-fri\" --platform telegram --chats 12345,67890 --command standup --token <jwt-token>",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cron == "" {
+				return errors.New("no cron expression provided, use broadcast to send a message once")
+			}
+
+			c, err := getClient(addr, token, serverName, sslcrt, sslkey, sslca)
+			if err != nil {
+				return err
+			}
+
+			sch, err := c.AddSchedule(context.TODO(), &proto.Schedule{
+				Cron:     cron,
+				Platform: platform,
+				Bot:      bot,
+				Chats:    chats,
+				Response: response,
+				Command:  command,
+			})
+			if err != nil {
+				return errors.Wrap(err, "while adding schedule")
+			}
+
+			printSchedule(sch)
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	add.Flags().StringSliceVar(&chats, "chats", nil, "chats to which the message is sent")
+	add.Flags().StringVar(&addr, "addr", ":9091", "botio's gRPC server address")
+	add.Flags().StringVar(&bot, "bot", "", "name of the bot that sends the message (empty for the bots without name)")
+	add.Flags().StringVar(&command, "command", "", "command whose response is sent")
+	add.Flags().StringVar(&cron, "cron", "", "cron expression with minute, hour, day of month, month and day of week")
+	add.Flags().StringVar(&platform, "platform", "telegram", "platform of the chats (discord or telegram)")
+	add.Flags().StringVar(&response, "response", "", "message sent if no command is provided")
+	add.Flags().StringVar(&sslca, "sslca", "", "ssl client certification file")
+	add.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
+	add.Flags().StringVar(&sslkey, "sslkey", "", "ssl certification key file")
+	add.Flags().StringVar(&token, "token", "", "authentication token")
+
+	return add
+}
+
+func listSchedules() *cobra.Command {
+	var addr string
+	var serverName string
+	var sslca string
+	var sslcrt string
+	var sslkey string
+	var token string
+
+	list := &cobra.Command{
+		Use:     "list",
+		Short:   "Lists the scheduled messages.",
+		Example: "botio client schedule list --token <jwt-token>",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := getClient(addr, token, serverName, sslcrt, sslkey, sslca)
+			if err != nil {
+				return err
+			}
+
+			schedules, err := c.ListSchedules(context.TODO(), &empty.Empty{})
+			if err != nil {
+				return errors.Wrap(err, "while listing schedules")
+			}
+
+			for _, sch := range schedules.GetSchedules() {
+				printSchedule(sch)
+			}
+
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	list.Flags().StringVar(&addr, "addr", ":9091", "botio's gRPC server address")
+	list.Flags().StringVar(&sslca, "sslca", "", "ssl client certification file")
+	list.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
+	list.Flags().StringVar(&sslkey, "sslkey", "", "ssl certification key file")
+	list.Flags().StringVar(&token, "token", "", "authentication token")
+
+	return list
+}
+
+func deleteSchedule() *cobra.Command {
+	var addr string
+	var id string
+	var serverName string
+	var sslca string
+	var sslcrt string
+	var sslkey string
+	var token string
+
+	delete := &cobra.Command{
+		Use:     "delete",
+		Short:   "Deletes the requested scheduled message.",
+		Example: "botio client schedule delete --id 5f3a9c1d2b7e4a60 --token <jwt-token>",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := getClient(addr, token, serverName, sslcrt, sslkey, sslca)
+			if err != nil {
+				return err
+			}
+
+			if _, err := c.DeleteSchedule(context.TODO(), &proto.Schedule{Id: id}); err != nil {
+				return errors.Wrapf(err, "while deleting schedule %q", id)
+			}
+
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	delete.Flags().StringVar(&addr, "addr", ":9091", "botio's gRPC server address")
+	delete.Flags().StringVar(&id, "id", "", "scheduled message to delete")
+	delete.Flags().StringVar(&sslca, "sslca", "", "ssl client certification file")
+	delete.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
+	delete.Flags().StringVar(&sslkey, "sslkey", "", "ssl certification key file")
+	delete.Flags().StringVar(&token, "token", "", "authentication token")
+
+	return delete
+}
+
+func broadcast() *cobra.Command {
+	var addr string
+	var bot string
+	var chats []string
+	var command string
+	var platform string
+	var response string
+	var serverName string
+	var sslca string
+	var sslcrt string
+	var sslkey string
+	var token string
+
+	broadcast := &cobra.Command{
+		Use:     "broadcast",
+		Short:   "Sends a message once to the requested chats.",
+		Example: "botio client broadcast --platform telegram --chats 12345,67890 --command announcement --token <jwt-token>",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := getClient(addr, token, serverName, sslcrt, sslkey, sslca)
+			if err != nil {
+				return err
+			}
+
+			if _, err := c.AddSchedule(context.TODO(), &proto.Schedule{
+				Platform: platform,
+				Bot:      bot,
+				Chats:    chats,
+				Response: response,
+				Command:  command,
+			}); err != nil {
+				return errors.Wrap(err, "while broadcasting message")
+			}
+
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	broadcast.Flags().StringSliceVar(&chats, "chats", nil, "chats to which the message is sent")
+	broadcast.Flags().StringVar(&addr, "addr", ":9091", "botio's gRPC server address")
+	broadcast.Flags().StringVar(&bot, "bot", "", "name of the bot that sends the message (empty for the bots without name)")
+	broadcast.Flags().StringVar(&command, "command", "", "command whose response is sent")
+	broadcast.Flags().StringVar(&platform, "platform", "telegram", "platform of the chats (discord or telegram)")
+	broadcast.Flags().StringVar(&response, "response", "", "message sent if no command is provided")
+	broadcast.Flags().StringVar(&sslca, "sslca", "", "ssl client certification file")
+	broadcast.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
+	broadcast.Flags().StringVar(&sslkey, "sslkey", "", "ssl certification key file")
+	broadcast.Flags().StringVar(&token, "token", "", "authentication token")
+
+	return broadcast
+}
+
+func printSchedule(sch *proto.Schedule) {
+	message := fmt.Sprintf("%q", sch.GetResponse())
+	if sch.GetCommand() != "" {
+		message = "/" + sch.GetCommand()
+	}
+
+	when := sch.GetCron()
+	if when == "" {
+		when = "once"
+	}
+
+	platform := sch.GetPlatform()
+	if sch.GetBot() != "" {
+		platform = fmt.Sprintf("%s bot %q", platform, sch.GetBot())
+	}
+
+	fmt.Printf("%s: %s to %s chats %s (%s)\n", sch.GetId(), message, platform, strings.Join(sch.GetChats(), ", "), when)
+}
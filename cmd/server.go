@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/danielkvist/botio/server"
@@ -29,16 +31,34 @@ func serverCmd(commands ...*cobra.Command) *cobra.Command {
 }
 
 func serverWithBoltDB() *cobra.Command {
+	var auditFile string
+	var auditSink string
 	var cacheCap int
+	var conversationTTL time.Duration
+	var resolveAlgorithm string
+	var resolveThreshold int
 	var collection string
 	var database string
 	var httpPort string
 	var jsonOutput bool
 	var key string
 	var port string
+	var rateBurst int
+	var rateLimit float64
+	var rpcRateLimits []string
+	var schedulesFile string
+	var scriptSteps uint64
+	var scriptTimeout time.Duration
+	var usageFile string
+	var usageRetention int
 	var sslca string
 	var sslcrt string
 	var sslkey string
+	var webhookBackoff time.Duration
+	var webhookRetries int
+	var swaggerUI bool
+	var webhooksFile string
+	var webhookDeliveriesFile string
 
 	s := &cobra.Command{
 		Use:     "bolt",
@@ -48,10 +68,18 @@ func serverWithBoltDB() *cobra.Command {
 			serverOptions := []server.Option{
 				server.WithBoltDB(database, collection),
 				server.WithHTTPPort(httpPort),
+				server.WithSwaggerUI(swaggerUI),
 				server.WithListener(port),
 				server.WithRistrettoCache(cacheCap),
 				server.WithTextLogger(os.Stdout),
 				server.WithJWTAuthToken(key),
+				server.WithConversationTTL(conversationTTL),
+				server.WithResolver(resolveAlgorithm, resolveThreshold),
+				server.WithAudit(auditSink, auditFile),
+				server.WithWebhooks(webhooksFile, webhookDeliveriesFile, webhookRetries, webhookBackoff),
+				server.WithSchedules(schedulesFile),
+				server.WithUsage(usageFile, usageRetention),
+				server.WithScriptLimits(scriptSteps, scriptTimeout),
 			}
 
 			if sslcrt == "" || sslkey == "" || sslca == "" {
@@ -64,6 +92,12 @@ func serverWithBoltDB() *cobra.Command {
 				serverOptions = append(serverOptions, server.WithJSONLogger(os.Stdout))
 			}
 
+			limitOptions, err := rateLimitOptions(rateLimit, rateBurst, rpcRateLimits)
+			if err != nil {
+				return err
+			}
+			serverOptions = append(serverOptions, limitOptions...)
+
 			s, err := server.New(serverOptions...)
 			if err != nil {
 				return errors.Wrap(err, "while creating a new Botio server with BoltDB")
@@ -83,21 +117,44 @@ func serverWithBoltDB() *cobra.Command {
 	}
 
 	s.Flags().BoolVar(&jsonOutput, "json", false, "enables JSON formatted logs")
+	s.Flags().DurationVar(&conversationTTL, "conversation-ttl", 10*time.Minute, "time after which a conversation without answers ends")
+	s.Flags().StringVar(&resolveAlgorithm, "resolve-algorithm", "levenshtein", "algorithm used to suggest commands similar to a mistyped one (levenshtein or damerau)")
+	s.Flags().IntVar(&resolveThreshold, "resolve-threshold", 2, "maximum number of edits between a mistyped command and the suggested ones (0 to disable suggestions)")
+	s.Flags().Float64Var(&rateLimit, "rate-limit", 0, "requests per second allowed per client and RPC (0 disables the limit)")
 	s.Flags().IntVar(&cacheCap, "cache", 262144000, "capacity of the in-memory cache in bytes")
+	s.Flags().IntVar(&rateBurst, "rate-burst", 10, "maximum burst of requests allowed per client and RPC")
 	s.Flags().StringVar(&collection, "collection", "commands", "collection used to store commands")
 	s.Flags().StringVar(&database, "database", "./data/botio.db", "database path")
 	s.Flags().StringVar(&httpPort, "http", ":8081", "port for HTTP server")
+	s.Flags().BoolVar(&swaggerUI, "swagger-ui", false, "serve a Swagger UI for the HTTP endpoints on /api/docs/")
+	s.Flags().StringVar(&auditSink, "audit", "db", "where the changes made to the commands are recorded (db, file or stdout)")
+	s.Flags().StringVar(&auditFile, "audit-file", "./data/audit.jsonl", "file on which the changes made to the commands are recorded with --audit file")
 	s.Flags().StringVar(&key, "key", "", "key to generate a JWT token for authentication")
 	s.Flags().StringVar(&port, "port", ":9091", "port for gRPC server")
+	s.Flags().IntVar(&usageRetention, "usage-retention", 90, "days for which the usage of the commands is kept (0 keeps it forever)")
+	s.Flags().StringVar(&usageFile, "usage-file", "./data/usage.jsonl", "file on which the usage of the commands is kept")
+	s.Flags().StringVar(&schedulesFile, "schedules-file", "./data/schedules.jsonl", "file on which the scheduled messages are kept")
+	s.Flags().Uint64Var(&scriptSteps, "script-steps", 100000, "steps that the script of a command can execute")
+	s.Flags().DurationVar(&scriptTimeout, "script-timeout", time.Second, "time that the script of a command can run")
+	s.Flags().StringSliceVar(&rpcRateLimits, "rpc-rate-limit", nil, "rate limit for a specific RPC in the form RPC=rate:burst (e.g. AddCommand=0.5:2)")
 	s.Flags().StringVar(&sslca, "sslca", "", "ssl client certification file")
 	s.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
 	s.Flags().StringVar(&sslkey, "sslkey", "", "ssl certification key file")
+	s.Flags().DurationVar(&webhookBackoff, "webhook-backoff", time.Second, "time to wait before retrying a failed webhook delivery, doubled after every retry")
+	s.Flags().StringVar(&webhookDeliveriesFile, "webhook-deliveries-file", "./data/webhook-deliveries.jsonl", "file on which the webhook deliveries are kept")
+	s.Flags().IntVar(&webhookRetries, "webhook-retries", 3, "number of times a failed webhook delivery is retried")
+	s.Flags().StringVar(&webhooksFile, "webhooks-file", "./data/webhooks.jsonl", "file on which the registered webhooks are kept")
 
 	return s
 }
 
 func serverWithPostgresDB() *cobra.Command {
+	var auditFile string
+	var auditSink string
 	var cacheCap int
+	var conversationTTL time.Duration
+	var resolveAlgorithm string
+	var resolveThreshold int
 	var database string
 	var host string
 	var httpPort string
@@ -108,9 +165,22 @@ func serverWithPostgresDB() *cobra.Command {
 	var password string
 	var port string
 	var pport string
+	var rateBurst int
+	var rateLimit float64
+	var rpcRateLimits []string
+	var schedulesFile string
+	var scriptSteps uint64
+	var scriptTimeout time.Duration
+	var usageFile string
+	var usageRetention int
 	var sslca string
 	var sslcrt string
 	var sslkey string
+	var webhookBackoff time.Duration
+	var webhookRetries int
+	var swaggerUI bool
+	var webhooksFile string
+	var webhookDeliveriesFile string
 	var table string
 	var user string
 
@@ -121,12 +191,20 @@ func serverWithPostgresDB() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			serverOptions := []server.Option{
 				server.WithHTTPPort(httpPort),
+				server.WithSwaggerUI(swaggerUI),
 				server.WithListener(port),
 				// TODO: Clean pport
 				server.WithPostgresDB(host, pport, database, table, user, password, maxConns, maxConnLifetime),
 				server.WithRistrettoCache(cacheCap),
 				server.WithTextLogger(os.Stdout),
 				server.WithJWTAuthToken(key),
+				server.WithConversationTTL(conversationTTL),
+				server.WithResolver(resolveAlgorithm, resolveThreshold),
+				server.WithAudit(auditSink, auditFile),
+				server.WithWebhooks(webhooksFile, webhookDeliveriesFile, webhookRetries, webhookBackoff),
+				server.WithSchedules(schedulesFile),
+				server.WithUsage(usageFile, usageRetention),
+				server.WithScriptLimits(scriptSteps, scriptTimeout),
 			}
 
 			if sslcrt == "" || sslkey == "" || sslca == "" {
@@ -139,6 +217,12 @@ func serverWithPostgresDB() *cobra.Command {
 				serverOptions = append(serverOptions, server.WithJSONLogger(os.Stdout))
 			}
 
+			limitOptions, err := rateLimitOptions(rateLimit, rateBurst, rpcRateLimits)
+			if err != nil {
+				return err
+			}
+			serverOptions = append(serverOptions, limitOptions...)
+
 			s, err := server.New(serverOptions...)
 			if err != nil {
 				return errors.Wrap(err, "while creating a new Botio server with PostgreSQL")
@@ -158,19 +242,37 @@ func serverWithPostgresDB() *cobra.Command {
 	}
 
 	s.Flags().BoolVar(&jsonOutput, "json", false, "enables JSON formatted logs")
+	s.Flags().DurationVar(&conversationTTL, "conversation-ttl", 10*time.Minute, "time after which a conversation without answers ends")
+	s.Flags().StringVar(&resolveAlgorithm, "resolve-algorithm", "levenshtein", "algorithm used to suggest commands similar to a mistyped one (levenshtein or damerau)")
+	s.Flags().IntVar(&resolveThreshold, "resolve-threshold", 2, "maximum number of edits between a mistyped command and the suggested ones (0 to disable suggestions)")
 	s.Flags().DurationVar(&maxConnLifetime, "maxConnLifetime", 2*time.Minute, "sets the lifetime of idle connections")
+	s.Flags().Float64Var(&rateLimit, "rate-limit", 0, "requests per second allowed per client and RPC (0 disables the limit)")
 	s.Flags().IntVar(&cacheCap, "cache", 262144000, "capacity of the in-memory cache in bytes")
+	s.Flags().IntVar(&rateBurst, "rate-burst", 10, "maximum burst of requests allowed per client and RPC")
 	s.Flags().IntVar(&maxConns, "maxConns", 5, "maximum number of open connections")
 	s.Flags().StringVar(&database, "database", "botio", "PostgreSQL database name")
 	s.Flags().StringVar(&host, "host", "postgres", "host of the PostgreSQL database")
 	s.Flags().StringVar(&httpPort, "http", ":8081", "port for HTTP server")
+	s.Flags().BoolVar(&swaggerUI, "swagger-ui", false, "serve a Swagger UI for the HTTP endpoints on /api/docs/")
+	s.Flags().StringVar(&auditSink, "audit", "db", "where the changes made to the commands are recorded (db, file or stdout)")
+	s.Flags().StringVar(&auditFile, "audit-file", "./data/audit.jsonl", "file on which the changes made to the commands are recorded with --audit file")
 	s.Flags().StringVar(&key, "key", "", "authentication key to generate a jwt token")
 	s.Flags().StringVar(&password, "password", "", "password for the user of the PostgreSQL database")
 	s.Flags().StringVar(&port, "port", ":9091", "port for gRPC server")
 	s.Flags().StringVar(&pport, "postgresPort", "5432", "port of the PostgreSQL database host")
+	s.Flags().IntVar(&usageRetention, "usage-retention", 90, "days for which the usage of the commands is kept (0 keeps it forever)")
+	s.Flags().StringVar(&usageFile, "usage-file", "./data/usage.jsonl", "file on which the usage of the commands is kept")
+	s.Flags().StringVar(&schedulesFile, "schedules-file", "./data/schedules.jsonl", "file on which the scheduled messages are kept")
+	s.Flags().Uint64Var(&scriptSteps, "script-steps", 100000, "steps that the script of a command can execute")
+	s.Flags().DurationVar(&scriptTimeout, "script-timeout", time.Second, "time that the script of a command can run")
+	s.Flags().StringSliceVar(&rpcRateLimits, "rpc-rate-limit", nil, "rate limit for a specific RPC in the form RPC=rate:burst (e.g. AddCommand=0.5:2)")
 	s.Flags().StringVar(&sslca, "sslca", "", "ssl client certification file")
 	s.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
 	s.Flags().StringVar(&sslkey, "sslkey", "", "ssl certification key file")
+	s.Flags().DurationVar(&webhookBackoff, "webhook-backoff", time.Second, "time to wait before retrying a failed webhook delivery, doubled after every retry")
+	s.Flags().StringVar(&webhookDeliveriesFile, "webhook-deliveries-file", "./data/webhook-deliveries.jsonl", "file on which the webhook deliveries are kept")
+	s.Flags().IntVar(&webhookRetries, "webhook-retries", 3, "number of times a failed webhook delivery is retried")
+	s.Flags().StringVar(&webhooksFile, "webhooks-file", "./data/webhooks.jsonl", "file on which the registered webhooks are kept")
 	s.Flags().StringVar(&table, "table", "commands", "table of the PostgreSQL database")
 	s.Flags().StringVar(&user, "user", "", "user of the PostgreSQL database")
 
@@ -178,7 +280,12 @@ func serverWithPostgresDB() *cobra.Command {
 }
 
 func serverWithSQLiteDB() *cobra.Command {
+	var auditFile string
+	var auditSink string
 	var cacheCap int
+	var conversationTTL time.Duration
+	var resolveAlgorithm string
+	var resolveThreshold int
 	var database string
 	var httpPort string
 	var jsonOutput bool
@@ -186,9 +293,22 @@ func serverWithSQLiteDB() *cobra.Command {
 	var maxConnLifetime time.Duration
 	var maxConns int
 	var port string
+	var rateBurst int
+	var rateLimit float64
+	var rpcRateLimits []string
+	var schedulesFile string
+	var scriptSteps uint64
+	var scriptTimeout time.Duration
+	var usageFile string
+	var usageRetention int
 	var sslca string
 	var sslcrt string
 	var sslkey string
+	var webhookBackoff time.Duration
+	var webhookRetries int
+	var swaggerUI bool
+	var webhooksFile string
+	var webhookDeliveriesFile string
 	var table string
 
 	s := &cobra.Command{
@@ -198,11 +318,19 @@ func serverWithSQLiteDB() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			serverOptions := []server.Option{
 				server.WithHTTPPort(httpPort),
+				server.WithSwaggerUI(swaggerUI),
 				server.WithListener(port),
 				server.WithSQLiteDB(database, table, maxConns, maxConnLifetime),
 				server.WithRistrettoCache(cacheCap),
 				server.WithTextLogger(os.Stdout),
 				server.WithJWTAuthToken(key),
+				server.WithConversationTTL(conversationTTL),
+				server.WithResolver(resolveAlgorithm, resolveThreshold),
+				server.WithAudit(auditSink, auditFile),
+				server.WithWebhooks(webhooksFile, webhookDeliveriesFile, webhookRetries, webhookBackoff),
+				server.WithSchedules(schedulesFile),
+				server.WithUsage(usageFile, usageRetention),
+				server.WithScriptLimits(scriptSteps, scriptTimeout),
 			}
 
 			if sslcrt == "" || sslkey == "" || sslca == "" {
@@ -215,6 +343,12 @@ func serverWithSQLiteDB() *cobra.Command {
 				serverOptions = append(serverOptions, server.WithJSONLogger(os.Stdout))
 			}
 
+			limitOptions, err := rateLimitOptions(rateLimit, rateBurst, rpcRateLimits)
+			if err != nil {
+				return err
+			}
+			serverOptions = append(serverOptions, limitOptions...)
+
 			s, err := server.New(serverOptions...)
 			if err != nil {
 				return errors.Wrap(err, "while creating a new Botio server with SQLite")
@@ -234,17 +368,66 @@ func serverWithSQLiteDB() *cobra.Command {
 	}
 
 	s.Flags().BoolVar(&jsonOutput, "json", false, "enables JSON formatted logs")
+	s.Flags().DurationVar(&conversationTTL, "conversation-ttl", 10*time.Minute, "time after which a conversation without answers ends")
+	s.Flags().StringVar(&resolveAlgorithm, "resolve-algorithm", "levenshtein", "algorithm used to suggest commands similar to a mistyped one (levenshtein or damerau)")
+	s.Flags().IntVar(&resolveThreshold, "resolve-threshold", 2, "maximum number of edits between a mistyped command and the suggested ones (0 to disable suggestions)")
 	s.Flags().DurationVar(&maxConnLifetime, "maxConnLifetime", 2*time.Minute, "sets the lifetime of idle connections")
+	s.Flags().Float64Var(&rateLimit, "rate-limit", 0, "requests per second allowed per client and RPC (0 disables the limit)")
 	s.Flags().IntVar(&cacheCap, "cache", 262144000, "capacity of the in-memory cache in bytes")
+	s.Flags().IntVar(&rateBurst, "rate-burst", 10, "maximum burst of requests allowed per client and RPC")
 	s.Flags().IntVar(&maxConns, "maxConns", 5, "maximum number of open connections")
 	s.Flags().StringVar(&database, "database", "./data/botio.db", "database path")
 	s.Flags().StringVar(&httpPort, "http", ":8081", "port for HTTP server")
+	s.Flags().BoolVar(&swaggerUI, "swagger-ui", false, "serve a Swagger UI for the HTTP endpoints on /api/docs/")
+	s.Flags().StringVar(&auditSink, "audit", "db", "where the changes made to the commands are recorded (db, file or stdout)")
+	s.Flags().StringVar(&auditFile, "audit-file", "./data/audit.jsonl", "file on which the changes made to the commands are recorded with --audit file")
 	s.Flags().StringVar(&key, "key", "", "authentication key to generate a jwt token")
 	s.Flags().StringVar(&port, "port", ":9091", "port for gRPC server")
+	s.Flags().IntVar(&usageRetention, "usage-retention", 90, "days for which the usage of the commands is kept (0 keeps it forever)")
+	s.Flags().StringVar(&usageFile, "usage-file", "./data/usage.jsonl", "file on which the usage of the commands is kept")
+	s.Flags().StringVar(&schedulesFile, "schedules-file", "./data/schedules.jsonl", "file on which the scheduled messages are kept")
+	s.Flags().Uint64Var(&scriptSteps, "script-steps", 100000, "steps that the script of a command can execute")
+	s.Flags().DurationVar(&scriptTimeout, "script-timeout", time.Second, "time that the script of a command can run")
+	s.Flags().StringSliceVar(&rpcRateLimits, "rpc-rate-limit", nil, "rate limit for a specific RPC in the form RPC=rate:burst (e.g. AddCommand=0.5:2)")
 	s.Flags().StringVar(&sslca, "sslca", "", "ssl client certification file")
 	s.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
 	s.Flags().StringVar(&sslkey, "sslkey", "", "ssl certification key file")
+	s.Flags().DurationVar(&webhookBackoff, "webhook-backoff", time.Second, "time to wait before retrying a failed webhook delivery, doubled after every retry")
+	s.Flags().StringVar(&webhookDeliveriesFile, "webhook-deliveries-file", "./data/webhook-deliveries.jsonl", "file on which the webhook deliveries are kept")
+	s.Flags().IntVar(&webhookRetries, "webhook-retries", 3, "number of times a failed webhook delivery is retried")
+	s.Flags().StringVar(&webhooksFile, "webhooks-file", "./data/webhooks.jsonl", "file on which the registered webhooks are kept")
 	s.Flags().StringVar(&table, "table", "commands", "table of the SQLite database")
 
 	return s
 }
+
+// rateLimitOptions returns the server.Options for the default rate limit
+// and for the RPC specific rate limits in the form "RPC=rate:burst".
+func rateLimitOptions(r float64, burst int, rpcs []string) ([]server.Option, error) {
+	options := []server.Option{server.WithRateLimit(r, burst)}
+
+	for _, l := range rpcs {
+		parts := strings.SplitN(l, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("invalid RPC rate limit %q, expected RPC=rate:burst", l)
+		}
+
+		limit := strings.SplitN(parts[1], ":", 2)
+		rpcRate, err := strconv.ParseFloat(limit[0], 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "while parsing rate of RPC rate limit %q", l)
+		}
+
+		rpcBurst := burst
+		if len(limit) == 2 {
+			rpcBurst, err = strconv.Atoi(limit[1])
+			if err != nil {
+				return nil, errors.Wrapf(err, "while parsing burst of RPC rate limit %q", l)
+			}
+		}
+
+		options = append(options, server.WithRPCRateLimit(parts[0], rpcRate, rpcBurst))
+	}
+
+	return options, nil
+}
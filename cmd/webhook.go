@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// webhook returns a *cobra.Command with the
+// subcommands to manage the webhooks.
+func webhook() *cobra.Command {
+	webhook := &cobra.Command{
+		Use:   "webhook",
+		Short: "Manages the webhooks notified of the changes made to the commands.",
+	}
+
+	for _, cmd := range []*cobra.Command{addWebhook(), listWebhooks(), deleteWebhook(), listDeliveries(), redeliver()} {
+		webhook.AddCommand(cmd)
+	}
+
+	return webhook
+}
+
+func addWebhook() *cobra.Command {
+	var addr string
+	var events []string
+	var secret string
+	var serverName string
+	var sslca string
+	var sslcrt string
+	var sslkey string
+	var token string
+	var url string
+
+	add := &cobra.Command{
+		Use:     "add",
+		Short:   "Registers a new webhook.",
+		Example: "botio client webhook add --url https://example.com/botio --events add,delete --secret mysecret --token <jwt-token>",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := getClient(addr, token, serverName, sslcrt, sslkey, sslca)
+			if err != nil {
+				return err
+			}
+
+			hook, err := c.AddWebhook(context.TODO(), &proto.Webhook{
+				Url:    url,
+				Events: events,
+				Secret: secret,
+			})
+			if err != nil {
+				return errors.Wrapf(err, "while adding webhook for %q", url)
+			}
+
+			printWebhook(hook)
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	add.Flags().StringSliceVar(&events, "events", nil, "changes sent to the webhook (add, update, delete or rollback), all of them if empty")
+	add.Flags().StringVar(&addr, "addr", ":9091", "botio's gRPC server address")
+	add.Flags().StringVar(&secret, "secret", "", "secret used to sign the payloads sent to the webhook")
+	add.Flags().StringVar(&sslca, "sslca", "", "ssl client certification file")
+	add.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
+	add.Flags().StringVar(&sslkey, "sslkey", "", "ssl certification key file")
+	add.Flags().StringVar(&token, "token", "", "authentication token")
+	add.Flags().StringVar(&url, "url", "", "URL to which the changes are posted")
+
+	return add
+}
+
+func listWebhooks() *cobra.Command {
+	var addr string
+	var serverName string
+	var sslca string
+	var sslcrt string
+	var sslkey string
+	var token string
+
+	list := &cobra.Command{
+		Use:     "list",
+		Short:   "Lists the registered webhooks.",
+		Example: "botio client webhook list --token <jwt-token>",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := getClient(addr, token, serverName, sslcrt, sslkey, sslca)
+			if err != nil {
+				return err
+			}
+
+			hooks, err := c.ListWebhooks(context.TODO(), &empty.Empty{})
+			if err != nil {
+				return errors.Wrap(err, "while listing webhooks")
+			}
+
+			for _, hook := range hooks.GetWebhooks() {
+				printWebhook(hook)
+			}
+
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	list.Flags().StringVar(&addr, "addr", ":9091", "botio's gRPC server address")
+	list.Flags().StringVar(&sslca, "sslca", "", "ssl client certification file")
+	list.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
+	list.Flags().StringVar(&sslkey, "sslkey", "", "ssl certification key file")
+	list.Flags().StringVar(&token, "token", "", "authentication token")
+
+	return list
+}
+
+func deleteWebhook() *cobra.Command {
+	var addr string
+	var id string
+	var serverName string
+	var sslca string
+	var sslcrt string
+	var sslkey string
+	var token string
+
+	delete := &cobra.Command{
+		Use:     "delete",
+		Short:   "Deletes the requested webhook.",
+		Example: "botio client webhook delete --id 5f3a9c1d2b7e4a60 --token <jwt-token>",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := getClient(addr, token, serverName, sslcrt, sslkey, sslca)
+			if err != nil {
+				return err
+			}
+
+			if _, err := c.DeleteWebhook(context.TODO(), &proto.Webhook{Id: id}); err != nil {
+				return errors.Wrapf(err, "while deleting webhook %q", id)
+			}
+
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	delete.Flags().StringVar(&addr, "addr", ":9091", "botio's gRPC server address")
+	delete.Flags().StringVar(&id, "id", "", "webhook to delete")
+	delete.Flags().StringVar(&sslca, "sslca", "", "ssl client certification file")
+	delete.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
+	delete.Flags().StringVar(&sslkey, "sslkey", "", "ssl certification key file")
+	delete.Flags().StringVar(&token, "token", "", "authentication token")
+
+	return delete
+}
+
+func listDeliveries() *cobra.Command {
+	var addr string
+	var dead bool
+	var id string
+	var serverName string
+	var sslca string
+	var sslcrt string
+	var sslkey string
+	var token string
+
+	deliveries := &cobra.Command{
+		Use:     "deliveries",
+		Short:   "Lists the deliveries made to the webhooks.",
+		Example: "botio client webhook deliveries --dead --token <jwt-token>",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := getClient(addr, token, serverName, sslcrt, sslkey, sslca)
+			if err != nil {
+				return err
+			}
+
+			list, err := c.ListWebhookDeliveries(context.TODO(), &proto.DeliveryFilter{
+				Webhook: id,
+				Dead:    dead,
+			})
+			if err != nil {
+				return errors.Wrap(err, "while listing webhook deliveries")
+			}
+
+			for _, dl := range list.GetDeliveries() {
+				printDelivery(dl)
+			}
+
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	deliveries.Flags().BoolVar(&dead, "dead", false, "only list the deliveries that failed after every retry")
+	deliveries.Flags().StringVar(&addr, "addr", ":9091", "botio's gRPC server address")
+	deliveries.Flags().StringVar(&id, "id", "", "only list the deliveries made to this webhook")
+	deliveries.Flags().StringVar(&sslca, "sslca", "", "ssl client certification file")
+	deliveries.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
+	deliveries.Flags().StringVar(&sslkey, "sslkey", "", "ssl certification key file")
+	deliveries.Flags().StringVar(&token, "token", "", "authentication token")
+
+	return deliveries
+}
+
+func redeliver() *cobra.Command {
+	var addr string
+	var id int64
+	var serverName string
+	var sslca string
+	var sslcrt string
+	var sslkey string
+	var token string
+
+	redeliver := &cobra.Command{
+		Use:     "redeliver",
+		Short:   "Makes again a dead delivery.",
+		Example: "botio client webhook redeliver --id 42 --token <jwt-token>",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := getClient(addr, token, serverName, sslcrt, sslkey, sslca)
+			if err != nil {
+				return err
+			}
+
+			dl, err := c.RedeliverWebhook(context.TODO(), &proto.WebhookDelivery{Id: id})
+			if err != nil {
+				return errors.Wrapf(err, "while redelivering delivery %v", id)
+			}
+
+			printDelivery(dl)
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	redeliver.Flags().StringVar(&addr, "addr", ":9091", "botio's gRPC server address")
+	redeliver.Flags().Int64Var(&id, "id", 0, "dead delivery to make again")
+	redeliver.Flags().StringVar(&sslca, "sslca", "", "ssl client certification file")
+	redeliver.Flags().StringVar(&sslcrt, "sslcrt", "", "ssl certification file")
+	redeliver.Flags().StringVar(&sslkey, "sslkey", "", "ssl certification key file")
+	redeliver.Flags().StringVar(&token, "token", "", "authentication token")
+
+	return redeliver
+}
+
+func printWebhook(hook *proto.Webhook) {
+	events := "all"
+	if len(hook.GetEvents()) > 0 {
+		events = strings.Join(hook.GetEvents(), ", ")
+	}
+
+	fmt.Printf("%s: %s (%s)\n", hook.GetId(), hook.GetUrl(), events)
+}
+
+func printDelivery(dl *proto.WebhookDelivery) {
+	when := "unknown time"
+	if t, err := ptypes.Timestamp(dl.GetTime()); err == nil {
+		when = t.Local().Format(time.RFC1123)
+	}
+
+	state := "pending"
+	switch {
+	case dl.GetDelivered():
+		state = "delivered"
+	case dl.GetDead():
+		state = "dead"
+	}
+
+	fmt.Printf("delivery %v: %s %q to %s on %s, %s after %v attempts\n", dl.GetId(), dl.GetAction(), dl.GetCommand(), dl.GetWebhook(), when, state, dl.GetAttempts())
+	if e := dl.GetError(); e != "" {
+		fmt.Printf("\terror: %s\n", e)
+	}
+}
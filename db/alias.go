@@ -0,0 +1,116 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// ErrAliasInUse is returned when a command is stored with a name
+// or an alias that is already used by another command.
+var ErrAliasInUse = errors.New("alias already in use")
+
+// sqlAliases manages the table in which the SQL databases keep
+// the aliases of the commands stored in the commands table.
+type sqlAliases struct {
+	table    string
+	commands string
+	// param returns the placeholder for the nth parameter of a statement.
+	param func(n int) string
+}
+
+func (a *sqlAliases) create(client *sql.DB) error {
+	statement := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (alias TEXT NOT NULL PRIMARY KEY, command TEXT NOT NULL);", a.table)
+	if _, err := client.Exec(statement); err != nil {
+		return errors.Wrapf(err, "while creating table %q", a.table)
+	}
+
+	return nil
+}
+
+// check returns ErrAliasInUse if the command or any of its
+// aliases is already used by another command.
+func (a *sqlAliases) check(tx *sql.Tx, command string, aliases []string) error {
+	byAlias := fmt.Sprintf("SELECT command FROM %s WHERE alias = %s AND command <> %s", a.table, a.param(1), a.param(2))
+	byCommand := fmt.Sprintf("SELECT command FROM %s WHERE command = %s AND command <> %s", a.commands, a.param(1), a.param(2))
+
+	for _, name := range append([]string{command}, aliases...) {
+		queries := []string{byAlias}
+		if name != command {
+			queries = append(queries, byCommand)
+		}
+
+		for _, query := range queries {
+			var owner string
+			err := tx.QueryRow(query, name, command).Scan(&owner)
+			switch {
+			case err == sql.ErrNoRows:
+			case err != nil:
+				return errors.Wrapf(err, "while checking alias %q", name)
+			default:
+				return errors.Wrapf(ErrAliasInUse, "%q is used by command %q", name, owner)
+			}
+		}
+	}
+
+	return nil
+}
+
+// replace replaces the aliases of the command with the received ones.
+func (a *sqlAliases) replace(tx *sql.Tx, command string, aliases []string) error {
+	if err := a.remove(tx, command); err != nil {
+		return err
+	}
+
+	statement := fmt.Sprintf("INSERT INTO %s (alias, command) VALUES (%s, %s)", a.table, a.param(1), a.param(2))
+	for _, alias := range aliases {
+		if _, err := tx.Exec(statement, alias, command); err != nil {
+			return errors.Wrapf(err, "while adding alias %q of command %q", alias, command)
+		}
+	}
+
+	return nil
+}
+
+// remove removes all the aliases of the command.
+func (a *sqlAliases) remove(tx *sql.Tx, command string) error {
+	statement := fmt.Sprintf("DELETE FROM %s WHERE command = %s", a.table, a.param(1))
+	if _, err := tx.Exec(statement, command); err != nil {
+		return errors.Wrapf(err, "while removing aliases of command %q", command)
+	}
+
+	return nil
+}
+
+// resolve returns the command that has the received alias.
+func (a *sqlAliases) resolve(client *sql.DB, alias string) (string, error) {
+	statement := fmt.Sprintf("SELECT command FROM %s WHERE alias = %s", a.table, a.param(1))
+
+	var command string
+	if err := client.QueryRow(statement, alias).Scan(&command); err != nil {
+		return "", errors.Wrapf(err, "while resolving alias %q", alias)
+	}
+
+	return command, nil
+}
+
+// withTx runs fn inside a transaction that is committed
+// if fn succeeds and rolled back otherwise.
+func withTx(client *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := client.Begin()
+	if err != nil {
+		return errors.Wrap(err, "while starting a transaction")
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "while committing a transaction")
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/pkg/errors"
+)
+
+func TestAliases(t *testing.T) {
+	testDBs(t, func(t *testing.T, db DB) {
+		start := &proto.BotCommand{
+			Cmd:     &proto.Command{Command: "start"},
+			Resp:    &proto.Response{Response: "hi"},
+			Aliases: []string{"hello", "hi"},
+		}
+		if err := db.Add(start, nil); err != nil {
+			t.Fatalf("while adding command: %v", err)
+		}
+
+		cmd, err := db.Get(&proto.Command{Command: "hi"})
+		if err != nil {
+			t.Fatalf("while getting command by alias: %v", err)
+		}
+
+		if cmd.GetCmd().GetCommand() != "start" {
+			t.Fatalf("expected command %q. got=%q", "start", cmd.GetCmd().GetCommand())
+		}
+
+		for _, conflict := range []*proto.BotCommand{
+			{Cmd: &proto.Command{Command: "hello"}, Resp: &proto.Response{Response: "hey"}},
+			{Cmd: &proto.Command{Command: "greet"}, Resp: &proto.Response{Response: "hey"}, Aliases: []string{"hi"}},
+			{Cmd: &proto.Command{Command: "greet"}, Resp: &proto.Response{Response: "hey"}, Aliases: []string{"start"}},
+		} {
+			if err := db.Add(conflict, nil); errors.Cause(err) != ErrAliasInUse {
+				t.Fatalf("expected ErrAliasInUse adding %v. got=%v", conflict, err)
+			}
+		}
+
+		start.Aliases = []string{"hello"}
+		if err := db.Update(start, nil); err != nil {
+			t.Fatalf("while updating command: %v", err)
+		}
+
+		if _, err := db.Get(&proto.Command{Command: "hi"}); err == nil {
+			t.Fatalf("expected removed alias %q not to resolve", "hi")
+		}
+
+		greet := &proto.BotCommand{Cmd: &proto.Command{Command: "greet"}, Resp: &proto.Response{Response: "hey"}, Aliases: []string{"hi"}}
+		if err := db.Add(greet, nil); err != nil {
+			t.Fatalf("while adding command with a released alias: %v", err)
+		}
+
+		if err := db.Remove(&proto.Command{Command: "start"}, nil); err != nil {
+			t.Fatalf("while removing command: %v", err)
+		}
+
+		if _, err := db.Get(&proto.Command{Command: "hello"}); err == nil {
+			t.Fatalf("expected alias %q of removed command not to resolve", "hello")
+		}
+	})
+}
@@ -0,0 +1,243 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/danielkvist/botio/proto"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/pkg/errors"
+)
+
+// encodeAuditEvent returns the binary representation
+// of a *proto.AuditEvent that the databases store.
+func encodeAuditEvent(ev *proto.AuditEvent) ([]byte, error) {
+	b, err := pb.Marshal(ev)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while encoding audit event %v", ev.GetId())
+	}
+
+	return append([]byte{encodingVersion}, b...), nil
+}
+
+// decodeAuditEvent returns the *proto.AuditEvent
+// from its binary representation.
+func decodeAuditEvent(b []byte) (*proto.AuditEvent, error) {
+	if len(b) == 0 || b[0] != encodingVersion {
+		return nil, errors.New("while decoding audit event: unknown encoding")
+	}
+
+	ev := &proto.AuditEvent{}
+	if err := pb.Unmarshal(b[1:], ev); err != nil {
+		return nil, errors.Wrap(err, "while decoding audit event")
+	}
+
+	return ev, nil
+}
+
+// MatchAuditEvent reports whether the received
+// event meets the conditions of the filter.
+func MatchAuditEvent(ev *proto.AuditEvent, f *proto.AuditFilter) bool {
+	switch {
+	case f.GetCommand() != "" && f.GetCommand() != ev.GetCommand():
+		return false
+	case f.GetSubject() != "" && f.GetSubject() != ev.GetSubject():
+		return false
+	case f.GetAction() != "" && f.GetAction() != ev.GetAction():
+		return false
+	}
+
+	t, err := ptypes.Timestamp(ev.GetTime())
+	if err != nil {
+		return f.GetSince() == nil && f.GetUntil() == nil
+	}
+
+	if since, err := ptypes.Timestamp(f.GetSince()); err == nil && t.Before(since) {
+		return false
+	}
+
+	if until, err := ptypes.Timestamp(f.GetUntil()); err == nil && t.After(until) {
+		return false
+	}
+
+	return true
+}
+
+// FilterAuditEvents returns the events, from the oldest to the newest,
+// that meet the conditions of the filter keeping only the newest ones
+// if the filter has a limit.
+func FilterAuditEvents(events []*proto.AuditEvent, f *proto.AuditFilter) *proto.AuditEvents {
+	var matched []*proto.AuditEvent
+	for _, ev := range events {
+		if MatchAuditEvent(ev, f) {
+			matched = append(matched, ev)
+		}
+	}
+
+	if limit := int(f.GetLimit()); limit > 0 && len(matched) > limit {
+		matched = matched[len(matched)-limit:]
+	}
+
+	return &proto.AuditEvents{Events: matched}
+}
+
+// auditTime returns the time of the event as nanoseconds since
+// the Unix epoch, or zero if the event has no valid time.
+func auditTime(ts *timestamp.Timestamp) int64 {
+	t, err := ptypes.Timestamp(ts)
+	if err != nil {
+		return 0
+	}
+
+	return t.UnixNano()
+}
+
+// sqlAudit manages the table in which the SQL databases keep the audit
+// events. The filterable fields of the events are kept on their own
+// columns so the filters of the listings are applied by the database.
+type sqlAudit struct {
+	table string
+	// id is the definition of the column that numbers the events.
+	id string
+	// blob is the type of the column that holds the encoded events.
+	blob string
+	// param returns the placeholder for the nth parameter of a statement.
+	param func(n int) string
+	// returning reports whether the database returns the id of
+	// an inserted event with RETURNING instead of LastInsertId.
+	returning bool
+}
+
+func (a *sqlAudit) create(client *sql.DB) error {
+	statements := []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id %s, command TEXT NOT NULL, subject TEXT NOT NULL, action TEXT NOT NULL, time BIGINT NOT NULL, data %s NOT NULL);", a.table, a.id, a.blob),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_command ON %s (command);", a.table, a.table),
+	}
+
+	for _, statement := range statements {
+		if _, err := client.Exec(statement); err != nil {
+			return errors.Wrapf(err, "while creating table %q", a.table)
+		}
+	}
+
+	return nil
+}
+
+// add stores the event with the id the database assigns to it.
+func (a *sqlAudit) add(client *sql.DB, ev *proto.AuditEvent) error {
+	ev.Id = 0
+	data, err := encodeAuditEvent(ev)
+	if err != nil {
+		return err
+	}
+
+	statement := fmt.Sprintf(
+		"INSERT INTO %s (command, subject, action, time, data) VALUES (%s, %s, %s, %s, %s)",
+		a.table, a.param(1), a.param(2), a.param(3), a.param(4), a.param(5),
+	)
+	args := []interface{}{ev.GetCommand(), ev.GetSubject(), ev.GetAction(), auditTime(ev.GetTime()), data}
+
+	var id int64
+	if a.returning {
+		err = client.QueryRow(statement+" RETURNING id", args...).Scan(&id)
+	} else {
+		var res sql.Result
+		if res, err = client.Exec(statement, args...); err == nil {
+			id, err = res.LastInsertId()
+		}
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "while adding audit event")
+	}
+
+	ev.Id = id
+	return nil
+}
+
+// list returns the events that meet the conditions of the filter
+// from the oldest to the newest. If the filter has a limit
+// only the newest events are returned.
+func (a *sqlAudit) list(client *sql.DB, f *proto.AuditFilter) (*proto.AuditEvents, error) {
+	var conditions []string
+	var args []interface{}
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, a.param(len(args))))
+	}
+
+	if f.GetCommand() != "" {
+		where("command = %s", f.GetCommand())
+	}
+
+	if f.GetSubject() != "" {
+		where("subject = %s", f.GetSubject())
+	}
+
+	if f.GetAction() != "" {
+		where("action = %s", f.GetAction())
+	}
+
+	if since, err := ptypes.Timestamp(f.GetSince()); err == nil {
+		where("time >= %s", since.UnixNano())
+	}
+
+	if until, err := ptypes.Timestamp(f.GetUntil()); err == nil {
+		where("time <= %s", until.UnixNano())
+	}
+
+	if f.GetSince() != nil || f.GetUntil() != nil {
+		conditions = append(conditions, "time <> 0")
+	}
+
+	query := fmt.Sprintf("SELECT id, data FROM %s", a.table)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	limit := f.GetLimit()
+	if limit > 0 {
+		query += fmt.Sprintf(" ORDER BY id DESC LIMIT %d", limit)
+	} else {
+		query += " ORDER BY id"
+	}
+
+	rows, err := client.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "while getting the audit events")
+	}
+	defer rows.Close()
+
+	var events []*proto.AuditEvent
+	for rows.Next() {
+		var id int64
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, errors.Wrap(err, "while getting the audit events")
+		}
+
+		ev, err := decodeAuditEvent(data)
+		if err != nil {
+			return nil, err
+		}
+
+		ev.Id = id
+		events = append(events, ev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "while getting the audit events")
+	}
+
+	if limit > 0 {
+		for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+			events[i], events[j] = events[j], events[i]
+		}
+	}
+
+	return &proto.AuditEvents{Events: events}, nil
+}
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+func TestAuditEvents(t *testing.T) {
+	testDBs(t, func(t *testing.T, db DB) {
+		for i, action := range []string{"add", "update", "delete"} {
+			ev := &proto.AuditEvent{Action: action, Command: "start", Subject: "ops"}
+			if err := db.AddAuditEvent(ev); err != nil {
+				t.Fatalf("while adding audit event: %v", err)
+			}
+
+			if ev.GetId() != int64(i+1) {
+				t.Fatalf("expected audit event id %v. got=%v", i+1, ev.GetId())
+			}
+		}
+
+		events, err := db.AuditEvents(&proto.AuditFilter{})
+		if err != nil {
+			t.Fatalf("while getting audit events: %v", err)
+		}
+
+		if len(events.GetEvents()) != 3 {
+			t.Fatalf("expected %v audit events. got=%v", 3, len(events.GetEvents()))
+		}
+
+		for i, ev := range events.GetEvents() {
+			if ev.GetId() != int64(i+1) || ev.GetSubject() != "ops" {
+				t.Fatalf("expected audit event %v by %q. got=%v by %q", i+1, "ops", ev.GetId(), ev.GetSubject())
+			}
+		}
+	})
+}
+
+func TestAuditEventsFilter(t *testing.T) {
+	now := time.Now()
+	ago := func(d time.Duration) *timestamp.Timestamp {
+		ts, _ := ptypes.TimestampProto(now.Add(-d))
+		return ts
+	}
+
+	tt := []struct {
+		name        string
+		filter      *proto.AuditFilter
+		expectedIDs []int64
+	}{
+		{
+			name:        "without conditions",
+			filter:      &proto.AuditFilter{},
+			expectedIDs: []int64{1, 2, 3, 4},
+		},
+		{
+			name:        "by command",
+			filter:      &proto.AuditFilter{Command: "start"},
+			expectedIDs: []int64{1, 2},
+		},
+		{
+			name:        "by subject and action",
+			filter:      &proto.AuditFilter{Subject: "ops", Action: "add"},
+			expectedIDs: []int64{1, 3, 4},
+		},
+		{
+			name:        "by time",
+			filter:      &proto.AuditFilter{Since: ago(150 * time.Minute), Until: ago(90 * time.Minute)},
+			expectedIDs: []int64{2},
+		},
+		{
+			name:        "since without time",
+			filter:      &proto.AuditFilter{Since: ago(150 * time.Minute)},
+			expectedIDs: []int64{2, 3},
+		},
+		{
+			name:        "with limit",
+			filter:      &proto.AuditFilter{Limit: 2},
+			expectedIDs: []int64{3, 4},
+		},
+		{
+			name:        "by command with limit",
+			filter:      &proto.AuditFilter{Command: "start", Limit: 1},
+			expectedIDs: []int64{2},
+		},
+	}
+
+	testDBs(t, func(t *testing.T, db DB) {
+		events := []*proto.AuditEvent{
+			{Action: "add", Command: "start", Subject: "ops", Time: ago(3 * time.Hour)},
+			{Action: "update", Command: "start", Subject: "dev", Time: ago(2 * time.Hour)},
+			{Action: "add", Command: "stop", Subject: "ops", Time: ago(time.Hour)},
+			{Action: "add", Command: "help", Subject: "ops"},
+		}
+
+		for _, ev := range events {
+			if err := db.AddAuditEvent(ev); err != nil {
+				t.Fatalf("while adding audit event: %v", err)
+			}
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.name, func(t *testing.T) {
+				events, err := db.AuditEvents(tc.filter)
+				if err != nil {
+					t.Fatalf("while getting audit events: %v", err)
+				}
+
+				filtered := events.GetEvents()
+				if len(filtered) != len(tc.expectedIDs) {
+					t.Fatalf("expected %v events. got=%v", len(tc.expectedIDs), len(filtered))
+				}
+
+				for i, ev := range filtered {
+					if ev.GetId() != tc.expectedIDs[i] {
+						t.Fatalf("expected event %v. got=%v", tc.expectedIDs[i], ev.GetId())
+					}
+				}
+			})
+		}
+	})
+}
@@ -1,11 +1,13 @@
 package db
 
 import (
+	"encoding/binary"
 	"fmt"
 	"time"
 
 	"github.com/danielkvist/botio/proto"
 
+	"github.com/pkg/errors"
 	bolt "go.etcd.io/bbolt"
 )
 
@@ -26,9 +28,10 @@ func (bdb *Bolt) Connect() error {
 	}
 
 	err = db.Update(func(tx *bolt.Tx) error {
-		bucket := []byte(bdb.Col)
-		if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
-			return err
+		for _, bucket := range [][]byte{[]byte(bdb.Col), bdb.aliasBucket(), bdb.historyBucket(), bdb.auditBucket()} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
 		}
 
 		return nil
@@ -43,51 +46,125 @@ func (bdb *Bolt) Connect() error {
 }
 
 // Add receives a *proto.BotCommand and adds it to the bucket
-// designated. If something goes wrong it returns a non-nil error.
-func (bdb *Bolt) Add(cmd *proto.BotCommand) error {
+// designated alongside its aliases. If something goes wrong it returns
+// a non-nil error, which is ErrAlreadyExists if the command is already
+// stored and ErrAliasInUse if the command or any of its aliases is used
+// by another command.
+func (bdb *Bolt) Add(cmd *proto.BotCommand, rev *proto.Revision) error {
+	if err := bdb.put(cmd, rev, 0, true); err != nil {
+		return errors.Wrapf(err, "while adding command %q", cmd.GetCmd().GetCommand())
+	}
+
+	return nil
+}
+
+// put stores the command increasing its version if the stored
+// one matches the expected version, unless it is zero, and the
+// revision, if any, on the same transaction. If create is true
+// the command must not be stored yet.
+func (bdb *Bolt) put(cmd *proto.BotCommand, rev *proto.Revision, expected int64, create bool) error {
+	if cmd == nil {
+		cmd = &proto.BotCommand{}
+	}
+
 	el := cmd.GetCmd().GetCommand()
-	val := cmd.GetResp().GetResponse()
-	err := bdb.db.Update(func(tx *bolt.Tx) error {
+
+	return bdb.db.Update(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(bdb.Col))
-		return b.Put([]byte(el), []byte(val))
+		aliases := tx.Bucket(bdb.aliasBucket())
+
+		for _, name := range append([]string{el}, cmd.GetAliases()...) {
+			if owner := aliases.Get([]byte(name)); owner != nil && string(owner) != el {
+				return errors.Wrapf(ErrAliasInUse, "%q is used by command %q", name, owner)
+			}
+
+			if name != el && b.Get([]byte(name)) != nil {
+				return errors.Wrapf(ErrAliasInUse, "%q is used by command %q", name, name)
+			}
+		}
+
+		if create && b.Get([]byte(el)) != nil {
+			return errors.Wrapf(ErrAlreadyExists, "command %q", el)
+		}
+
+		version, err := bdb.version(b, el)
+		if err != nil {
+			return err
+		}
+
+		if err := checkVersion(el, expected, version); err != nil {
+			return err
+		}
+
+		if err := bdb.removeAliases(tx, el); err != nil {
+			return err
+		}
+
+		for _, alias := range cmd.GetAliases() {
+			if err := aliases.Put([]byte(alias), []byte(el)); err != nil {
+				return err
+			}
+		}
+
+		cmd.Version = version + 1
+		val, err := encode(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := b.Put([]byte(el), []byte(val)); err != nil {
+			return err
+		}
+
+		return bdb.addRevision(tx, el, rev)
 	})
+}
 
+// version returns the version of the command stored
+// on the bucket, which is zero if there is none.
+func (bdb *Bolt) version(b *bolt.Bucket, el string) (int64, error) {
+	val := b.Get([]byte(el))
+	if val == nil {
+		return 0, nil
+	}
+
+	stored, err := decode(el, val)
 	if err != nil {
-		return fmt.Errorf("while adding command %q: %v", el, err)
+		return 0, err
 	}
 
-	return nil
+	return stored.GetVersion(), nil
 }
 
 // Get receives a *proto.Command and returns the respective *proto.BotCommand
-// if exists in the designated bucket. If not it returns a non-nil error.
+// if exists in the designated bucket, looking for a command with that alias
+// if there is none with that name. If not it returns ErrNotFound.
 func (bdb *Bolt) Get(cmd *proto.Command) (*proto.BotCommand, error) {
 	el := cmd.GetCommand()
 
-	var val []byte
+	var command *proto.BotCommand
 	err := bdb.db.View(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(bdb.Col))
-		val = bucket.Get([]byte(el))
+		val := bucket.Get([]byte(el))
+		if owner := tx.Bucket(bdb.aliasBucket()).Get([]byte(el)); len(val) == 0 && owner != nil {
+			el = string(owner)
+			val = bucket.Get(owner)
+		}
 
 		if len(val) == 0 {
-			return fmt.Errorf("command %q not found", el)
+			return ErrNotFound
 		}
 
-		return nil
+		var err error
+		command, err = decode(el, val)
+		return err
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("while getting command %q: %v", el, err)
+		return nil, errors.Wrapf(err, "while getting command %q", el)
 	}
 
-	return &proto.BotCommand{
-		Cmd: &proto.Command{
-			Command: el,
-		},
-		Resp: &proto.Response{
-			Response: string(val),
-		},
-	}, nil
+	return command, nil
 }
 
 // GetAll ranges over all the entries of the designated bucket
@@ -101,14 +178,12 @@ func (bdb *Bolt) GetAll() (*proto.BotCommands, error) {
 		c := b.Cursor()
 
 		for k, v := c.First(); k != nil; k, v = c.Next() {
-			commands = append(commands, &proto.BotCommand{
-				Cmd: &proto.Command{
-					Command: string(k),
-				},
-				Resp: &proto.Response{
-					Response: string(v),
-				},
-			})
+			command, err := decode(string(k), v)
+			if err != nil {
+				return err
+			}
+
+			commands = append(commands, command)
 		}
 
 		return nil
@@ -119,36 +194,226 @@ func (bdb *Bolt) GetAll() (*proto.BotCommands, error) {
 	}, err
 }
 
-// Remove removes a *proto.BotCommand from the designated bucket.
-// It returns a non-nil error if something goes wrong.
-func (bdb *Bolt) Remove(cmd *proto.Command) error {
+// Remove removes a *proto.BotCommand and its aliases from the designated
+// bucket. It returns a non-nil error if something goes wrong, which is
+// ErrNotFound if the command is not stored.
+func (bdb *Bolt) Remove(cmd *proto.Command, rev *proto.Revision) error {
 	el := cmd.GetCommand()
 
 	return bdb.db.Update(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(bdb.Col))
-		err := b.Delete([]byte(el))
+		if b.Get([]byte(el)) == nil {
+			return errors.Wrapf(ErrNotFound, "while removing command %q", el)
+		}
+
+		version, err := bdb.version(b, el)
+		if err != nil {
+			return fmt.Errorf("while removing command %q: %v", el, err)
+		}
+
+		if err := checkVersion(el, cmd.GetVersion(), version); err != nil {
+			return errors.Wrapf(err, "while removing command %q", el)
+		}
+
+		if err := bdb.removeAliases(tx, el); err != nil {
+			return fmt.Errorf("while removing command %q: %v", el, err)
+		}
+
+		err = b.Delete([]byte(el))
 
 		if err != nil {
 			return fmt.Errorf("while removing command %q: %v", el, err)
 		}
 
+		if err := bdb.addRevision(tx, el, rev); err != nil {
+			return fmt.Errorf("while removing command %q: %v", el, err)
+		}
+
 		return nil
 	})
 }
 
-// Update updates the Response of an existing *proto.BotCommand
-// with he Response of the received *proto.BotCommand. If the
+// Update updates an existing *proto.BotCommand
+// with the received *proto.BotCommand. If the
 // *proto.BotCommand didn't exists it adds it to the bucket
 // due to how BoltDB databases work. If something goes wrong
-// it returns a non-nil error.
-func (bdb *Bolt) Update(cmd *proto.BotCommand) error {
-	if err := bdb.Add(cmd); err != nil {
-		return fmt.Errorf("while updating command %q: %v", cmd.GetCmd().GetCommand(), err)
+// it returns a non-nil error, which is ErrVersionMismatch if
+// the version of the received *proto.BotCommand is not zero
+// and differs from the stored one.
+func (bdb *Bolt) Update(cmd *proto.BotCommand, rev *proto.Revision) error {
+	if err := bdb.put(cmd, rev, cmd.GetVersion(), false); err != nil {
+		return errors.Wrapf(err, "while updating command %q", cmd.GetCmd().GetCommand())
 	}
 
 	return nil
 }
 
+// aliasBucket returns the name of the bucket that maps
+// the aliases to the commands of the designated bucket.
+func (bdb *Bolt) aliasBucket() []byte {
+	return []byte(bdb.Col + "_aliases")
+}
+
+// historyBucket returns the name of the bucket in which each command
+// of the designated bucket has a nested bucket with its revisions.
+func (bdb *Bolt) historyBucket() []byte {
+	return []byte(bdb.Col + "_history")
+}
+
+// auditBucket returns the name of the bucket that keeps
+// the audit events of the commands of the designated bucket.
+func (bdb *Bolt) auditBucket() []byte {
+	return []byte(bdb.Col + "_audit")
+}
+
+// removeAliases removes the aliases of the stored command.
+func (bdb *Bolt) removeAliases(tx *bolt.Tx, el string) error {
+	val := tx.Bucket([]byte(bdb.Col)).Get([]byte(el))
+	if len(val) == 0 {
+		return nil
+	}
+
+	stored, err := decode(el, val)
+	if err != nil {
+		return err
+	}
+
+	aliases := tx.Bucket(bdb.aliasBucket())
+	for _, alias := range stored.GetAliases() {
+		if err := aliases.Delete([]byte(alias)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addRevision stores the revision of the command, if any, on a
+// bucket for the command nested in the history bucket, numbering
+// it after the last one of the command.
+func (bdb *Bolt) addRevision(tx *bolt.Tx, el string, rev *proto.Revision) error {
+	if rev == nil {
+		return nil
+	}
+
+	b, err := tx.Bucket(bdb.historyBucket()).CreateBucketIfNotExists([]byte(el))
+	if err != nil {
+		return errors.Wrapf(err, "while adding revision of command %q", el)
+	}
+
+	n, err := b.NextSequence()
+	if err != nil {
+		return errors.Wrapf(err, "while adding revision of command %q", el)
+	}
+
+	rev.Number = int64(n)
+	val, err := encodeRevision(rev)
+	if err != nil {
+		return err
+	}
+
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, n)
+	return b.Put(key, val)
+}
+
+// Revisions returns the revisions of the command stored
+// on the history bucket from the oldest to the newest.
+func (bdb *Bolt) Revisions(cmd *proto.Command) (*proto.Revisions, error) {
+	el := cmd.GetCommand()
+
+	var revisions []*proto.Revision
+	err := bdb.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bdb.historyBucket()).Bucket([]byte(el))
+		if b == nil {
+			return nil
+		}
+
+		return b.ForEach(func(_, v []byte) error {
+			rev, err := decodeRevision(v)
+			if err != nil {
+				return err
+			}
+
+			revisions = append(revisions, rev)
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "while getting revisions of command %q", el)
+	}
+
+	return &proto.Revisions{Revisions: revisions}, nil
+}
+
+// AddAuditEvent stores the received *proto.AuditEvent on
+// the audit bucket numbering it after the last one.
+func (bdb *Bolt) AddAuditEvent(ev *proto.AuditEvent) error {
+	err := bdb.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bdb.auditBucket())
+
+		n, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		ev.Id = int64(n)
+		val, err := encodeAuditEvent(ev)
+		if err != nil {
+			return err
+		}
+
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, n)
+		return b.Put(key, val)
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "while adding audit event")
+	}
+
+	return nil
+}
+
+// AuditEvents returns the audit events stored on the audit bucket that
+// meet the conditions of the filter from the oldest to the newest. If
+// the filter has a limit the bucket is walked from its newest event.
+func (bdb *Bolt) AuditEvents(f *proto.AuditFilter) (*proto.AuditEvents, error) {
+	var events []*proto.AuditEvent
+	err := bdb.db.View(func(tx *bolt.Tx) error {
+		limit := int(f.GetLimit())
+		c := tx.Bucket(bdb.auditBucket()).Cursor()
+		for _, v := c.Last(); v != nil; _, v = c.Prev() {
+			ev, err := decodeAuditEvent(v)
+			if err != nil {
+				return err
+			}
+
+			if !MatchAuditEvent(ev, f) {
+				continue
+			}
+
+			events = append(events, ev)
+			if limit > 0 && len(events) == limit {
+				break
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, "while getting audit events")
+	}
+
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+
+	return &proto.AuditEvents{Events: events}, nil
+}
+
 // Close tries to close the connection to the BoltDB database.
 // If fails it returns a non-nil error.
 func (bdb *Bolt) Close() error {
@@ -4,21 +4,38 @@ package db
 
 import (
 	"github.com/danielkvist/botio/proto"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
 )
 
 // DB represents a database client with basic CRUD methods
 // as basic methods to connect and disconnect from the
-// database itself.
+// database itself. It also keeps the revisions of the commands,
+// which Add, Update and Remove number and store, if not nil, on
+// the same transaction as the change they record, and the audit
+// events, which are numbered by AddAuditEvent and filtered by
+// AuditEvents.
 type DB interface {
 	Connect() error
-	Add(cmd *proto.BotCommand) error
+	Add(cmd *proto.BotCommand, rev *proto.Revision) error
 	Get(cmd *proto.Command) (*proto.BotCommand, error)
 	GetAll() (*proto.BotCommands, error)
-	Remove(cmd *proto.Command) error
-	Update(cmd *proto.BotCommand) error
+	Remove(cmd *proto.Command, rev *proto.Revision) error
+	Update(cmd *proto.BotCommand, rev *proto.Revision) error
+	Revisions(cmd *proto.Command) (*proto.Revisions, error)
+	AddAuditEvent(ev *proto.AuditEvent) error
+	AuditEvents(f *proto.AuditFilter) (*proto.AuditEvents, error)
 	Close() error
 }
 
+// ErrNotFound is returned when the requested command is not stored.
+var ErrNotFound = errors.New("command not found")
+
+// ErrAlreadyExists is returned when a command is added
+// with the name of a command that is already stored.
+var ErrAlreadyExists = errors.New("command already exists")
+
 // Create follows the Factory pattern to return a DB
 // depending on the received parameter.
 func Create(env string) DB {
@@ -30,10 +47,56 @@ func Create(env string) DB {
 	case "sqlite":
 		return &SQLite{}
 	case "testing":
-		var m Mem
-		m = make(map[string]string)
-		return m
+		return newMem()
 	default:
 		return nil
 	}
 }
+
+// encodingVersion prefixes the encoded *proto.BotCommands to distinguish
+// them from the plain responses stored by older versions of botio.
+const encodingVersion byte = 0
+
+// encode returns the binary representation of a *proto.BotCommand
+// that the databases store.
+func encode(cmd *proto.BotCommand) ([]byte, error) {
+	b, err := pb.Marshal(cmd)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while encoding command %q", cmd.GetCmd().GetCommand())
+	}
+
+	return append([]byte{encodingVersion}, b...), nil
+}
+
+// decode returns the *proto.BotCommand for the received command
+// from its binary representation. Values stored by older versions
+// of botio are treated as plain responses.
+func decode(command string, b []byte) (*proto.BotCommand, error) {
+	if len(b) == 0 || b[0] != encodingVersion {
+		return &proto.BotCommand{
+			Cmd: &proto.Command{
+				Command: command,
+			},
+			Resp: &proto.Response{
+				Response: string(b),
+			},
+		}, nil
+	}
+
+	cmd := &proto.BotCommand{}
+	if err := pb.Unmarshal(b[1:], cmd); err != nil {
+		return nil, errors.Wrapf(err, "while decoding command %q", command)
+	}
+
+	return cmd, nil
+}
+
+// decodeRow returns the *proto.BotCommand stored on a row of a SQL
+// table. Rows stored by older versions of botio have no encoded data.
+func decodeRow(command, response string, data []byte) (*proto.BotCommand, error) {
+	if len(data) == 0 {
+		data = []byte(response)
+	}
+
+	return decode(command, data)
+}
@@ -0,0 +1,150 @@
+package db
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tt := []struct {
+		name     string
+		command  string
+		data     func() ([]byte, error)
+		expected *proto.BotCommand
+	}{
+		{
+			name:    "encoded command",
+			command: "start",
+			data: func() ([]byte, error) {
+				return encode(&proto.BotCommand{
+					Cmd:         &proto.Command{Command: "start"},
+					Resp:        &proto.Response{Response: "hi"},
+					Description: "Says hi",
+					Hidden:      true,
+				})
+			},
+			expected: &proto.BotCommand{
+				Cmd:         &proto.Command{Command: "start"},
+				Resp:        &proto.Response{Response: "hi"},
+				Description: "Says hi",
+				Hidden:      true,
+			},
+		},
+		{
+			name:    "legacy response",
+			command: "start",
+			data: func() ([]byte, error) {
+				return []byte("hi"), nil
+			},
+			expected: &proto.BotCommand{
+				Cmd:  &proto.Command{Command: "start"},
+				Resp: &proto.Response{Response: "hi"},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := tc.data()
+			if err != nil {
+				t.Fatalf("while encoding command: %v", err)
+			}
+
+			cmd, err := decode(tc.command, data)
+			if err != nil {
+				t.Fatalf("while decoding command: %v", err)
+			}
+
+			if !pb.Equal(cmd, tc.expected) {
+				t.Fatalf("expected command %v. got=%v", tc.expected, cmd)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	testDBs(t, func(t *testing.T, db DB) {
+		start := &proto.BotCommand{
+			Cmd:  &proto.Command{Command: "start"},
+			Resp: &proto.Response{Response: "hi"},
+		}
+
+		if _, err := db.Get(start.GetCmd()); errors.Cause(err) != ErrNotFound {
+			t.Fatalf("expected %v getting a missing command. got=%v", ErrNotFound, err)
+		}
+
+		if err := db.Remove(start.GetCmd(), nil); errors.Cause(err) != ErrNotFound {
+			t.Fatalf("expected %v removing a missing command. got=%v", ErrNotFound, err)
+		}
+
+		if err := db.Add(start, nil); err != nil {
+			t.Fatalf("while adding command: %v", err)
+		}
+
+		if err := db.Add(start, nil); errors.Cause(err) != ErrAlreadyExists {
+			t.Fatalf("expected %v adding a stored command. got=%v", ErrAlreadyExists, err)
+		}
+
+		// Updating a missing command adds it.
+		stop := &proto.BotCommand{
+			Cmd:  &proto.Command{Command: "stop"},
+			Resp: &proto.Response{Response: "bye"},
+		}
+
+		if err := db.Update(stop, nil); err != nil {
+			t.Fatalf("while updating a missing command: %v", err)
+		}
+
+		if _, err := db.Get(stop.GetCmd()); err != nil {
+			t.Fatalf("while getting command: %v", err)
+		}
+	})
+}
+
+// testDBs runs test as a subtest for every database that doesn't
+// need a server, connected to files on a temporary directory that
+// is removed once they are closed.
+func testDBs(t *testing.T, test func(t *testing.T, db DB)) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "botio")
+	if err != nil {
+		t.Fatalf("while creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tt := []struct {
+		name string
+		db   DB
+	}{
+		{
+			name: "mem",
+			db:   newMem(),
+		},
+		{
+			name: "bolt",
+			db:   &Bolt{Path: filepath.Join(dir, "bolt.db"), Col: "commands"},
+		},
+		{
+			name: "sqlite",
+			db:   &SQLite{Path: filepath.Join(dir, "sqlite.db"), Table: "commands", MaxConns: 1},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.db.Connect(); err != nil {
+				t.Fatalf("while connecting: %v", err)
+			}
+			defer tc.db.Close()
+
+			test(t, tc.db)
+		})
+	}
+}
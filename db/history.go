@@ -0,0 +1,119 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/danielkvist/botio/proto"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// encodeRevision returns the binary representation
+// of a *proto.Revision that the databases store.
+func encodeRevision(rev *proto.Revision) ([]byte, error) {
+	b, err := pb.Marshal(rev)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while encoding revision %v", rev.GetNumber())
+	}
+
+	return append([]byte{encodingVersion}, b...), nil
+}
+
+// decodeRevision returns the *proto.Revision
+// from its binary representation.
+func decodeRevision(b []byte) (*proto.Revision, error) {
+	if len(b) == 0 || b[0] != encodingVersion {
+		return nil, errors.New("while decoding revision: unknown encoding")
+	}
+
+	rev := &proto.Revision{}
+	if err := pb.Unmarshal(b[1:], rev); err != nil {
+		return nil, errors.Wrap(err, "while decoding revision")
+	}
+
+	return rev, nil
+}
+
+// sqlHistory manages the table in which the SQL databases
+// keep the revisions of the commands.
+type sqlHistory struct {
+	table string
+	// blob is the type of the column that holds the encoded revisions.
+	blob string
+	// param returns the placeholder for the nth parameter of a statement.
+	param func(n int) string
+}
+
+func (h *sqlHistory) create(client *sql.DB) error {
+	statement := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (command TEXT NOT NULL, number INTEGER NOT NULL, data %s NOT NULL, PRIMARY KEY (command, number));", h.table, h.blob)
+	if _, err := client.Exec(statement); err != nil {
+		return errors.Wrapf(err, "while creating table %q", h.table)
+	}
+
+	return nil
+}
+
+// add stores the revision of the command, if any, numbering it after
+// the last revision stored for the command. It runs on the transaction
+// that changes the command, which holds the lock on the row of the
+// command, so the revisions of a command are numbered one at a time.
+// If they aren't, the primary key makes the transaction fail instead
+// of numbering two revisions the same.
+func (h *sqlHistory) add(tx *sql.Tx, command string, rev *proto.Revision) error {
+	if rev == nil {
+		return nil
+	}
+
+	query := fmt.Sprintf("SELECT COALESCE(MAX(number), 0) FROM %s WHERE command = %s", h.table, h.param(1))
+
+	var last int64
+	if err := tx.QueryRow(query, command).Scan(&last); err != nil {
+		return errors.Wrapf(err, "while getting the last revision of command %q", command)
+	}
+
+	rev.Number = last + 1
+	data, err := encodeRevision(rev)
+	if err != nil {
+		return err
+	}
+
+	statement := fmt.Sprintf("INSERT INTO %s (command, number, data) VALUES (%s, %s, %s)", h.table, h.param(1), h.param(2), h.param(3))
+	if _, err := tx.Exec(statement, command, rev.Number, data); err != nil {
+		return errors.Wrapf(err, "while adding revision %v of command %q", rev.Number, command)
+	}
+
+	return nil
+}
+
+// list returns the revisions of the command from the oldest to the newest.
+func (h *sqlHistory) list(client *sql.DB, command string) (*proto.Revisions, error) {
+	query := fmt.Sprintf("SELECT data FROM %s WHERE command = %s ORDER BY number", h.table, h.param(1))
+	rows, err := client.Query(query, command)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while getting the revisions of command %q", command)
+	}
+	defer rows.Close()
+
+	var revisions []*proto.Revision
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, errors.Wrapf(err, "while getting the revisions of command %q", command)
+		}
+
+		rev, err := decodeRevision(data)
+		if err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, rev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrapf(err, "while getting the revisions of command %q", command)
+	}
+
+	return &proto.Revisions{Revisions: revisions}, nil
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/pkg/errors"
+)
+
+func TestRevisions(t *testing.T) {
+	testDBs(t, func(t *testing.T, db DB) {
+		start := &proto.BotCommand{Cmd: &proto.Command{Command: "start"}, Resp: &proto.Response{Response: "hi"}}
+
+		changes := []struct {
+			action string
+			change func(rev *proto.Revision) error
+		}{
+			{action: "add", change: func(rev *proto.Revision) error { return db.Add(start, rev) }},
+			{action: "update", change: func(rev *proto.Revision) error { return db.Update(start, rev) }},
+			{action: "delete", change: func(rev *proto.Revision) error { return db.Remove(start.GetCmd(), rev) }},
+		}
+
+		for i, c := range changes {
+			rev := &proto.Revision{Author: "ops", Action: c.action}
+			if err := c.change(rev); err != nil {
+				t.Fatalf("while making %s change: %v", c.action, err)
+			}
+
+			if rev.GetNumber() != int64(i+1) {
+				t.Fatalf("expected revision number %v. got=%v", i+1, rev.GetNumber())
+			}
+		}
+
+		if err := db.Remove(start.GetCmd(), &proto.Revision{Action: "delete"}); errors.Cause(err) != ErrNotFound {
+			t.Fatalf("expected error %v. got=%v", ErrNotFound, err)
+		}
+
+		stop := &proto.BotCommand{Cmd: &proto.Command{Command: "stop"}}
+		if err := db.Add(stop, &proto.Revision{Action: "add", Command: stop}); err != nil {
+			t.Fatalf("while adding command: %v", err)
+		}
+
+		if err := db.Add(stop, &proto.Revision{Action: "add", Command: stop}); errors.Cause(err) != ErrAlreadyExists {
+			t.Fatalf("expected error %v. got=%v", ErrAlreadyExists, err)
+		}
+
+		revisions, err := db.Revisions(start.GetCmd())
+		if err != nil {
+			t.Fatalf("while getting revisions: %v", err)
+		}
+
+		if len(revisions.GetRevisions()) != 3 {
+			t.Fatalf("expected %v revisions. got=%v", 3, len(revisions.GetRevisions()))
+		}
+
+		for i, rev := range revisions.GetRevisions() {
+			if rev.GetNumber() != int64(i+1) || rev.GetAuthor() != "ops" {
+				t.Fatalf("expected revision %v by %q. got=%v by %q", i+1, "ops", rev.GetNumber(), rev.GetAuthor())
+			}
+		}
+
+		revisions, err = db.Revisions(stop.GetCmd())
+		if err != nil {
+			t.Fatalf("while getting revisions: %v", err)
+		}
+
+		if len(revisions.GetRevisions()) != 1 {
+			t.Fatalf("expected %v revisions after a failed change. got=%v", 1, len(revisions.GetRevisions()))
+		}
+
+		if v := revisions.GetRevisions()[0].GetCommand().GetVersion(); v != 1 {
+			t.Fatalf("expected the revision to keep version %v. got=%v", 1, v)
+		}
+	})
+}
@@ -1,64 +1,120 @@
 package db
 
 import (
-	"fmt"
+	"sync"
 
 	"github.com/danielkvist/botio/proto"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
 )
 
 // Mem is a mocked-up database for testing.
-type Mem map[string]string
+type Mem struct {
+	mu       sync.RWMutex
+	commands map[string]*proto.BotCommand
+	aliases  map[string]string
+	history  map[string][]*proto.Revision
+	audit    []*proto.AuditEvent
+}
+
+func newMem() *Mem {
+	return &Mem{
+		commands: make(map[string]*proto.BotCommand),
+		aliases:  make(map[string]string),
+		history:  make(map[string][]*proto.Revision),
+	}
+}
 
 // Connect simulates a connection with a database.
-func (m Mem) Connect() error {
+func (m *Mem) Connect() error {
 	return nil
 }
 
 // Add receives a *proto.BotCommand and adds it
-// to the map using the Command as key and the
-// Response as a value.
-func (m Mem) Add(cmd *proto.BotCommand) error {
+// to the map using the Command as key. It returns
+// ErrAlreadyExists if the command is already stored
+// and ErrAliasInUse if the command or any of its aliases
+// is used by another command.
+func (m *Mem) Add(cmd *proto.BotCommand, rev *proto.Revision) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.put(cmd, rev, 0, true)
+}
+
+// put stores the command increasing its version if the stored
+// one matches the expected version, unless it is zero, and the
+// revision, if any. If create is true the command must not be
+// stored yet.
+func (m *Mem) put(cmd *proto.BotCommand, rev *proto.Revision, expected int64, create bool) error {
+	if cmd == nil {
+		cmd = &proto.BotCommand{}
+	}
+
 	el := cmd.GetCmd().GetCommand()
-	val := cmd.GetResp().GetResponse()
-	m[el] = val
+	for _, name := range append([]string{el}, cmd.GetAliases()...) {
+		if owner, ok := m.aliases[name]; ok && owner != el {
+			return errors.Wrapf(ErrAliasInUse, "%q is used by command %q", name, owner)
+		}
+
+		if _, ok := m.commands[name]; ok && name != el {
+			return errors.Wrapf(ErrAliasInUse, "%q is used by command %q", name, name)
+		}
+	}
+
+	stored, ok := m.commands[el]
+	if ok && create {
+		return errors.Wrapf(ErrAlreadyExists, "command %q", el)
+	}
+
+	version := stored.GetVersion()
+	if err := checkVersion(el, expected, version); err != nil {
+		return err
+	}
+
+	m.removeAliases(el)
+	for _, alias := range cmd.GetAliases() {
+		m.aliases[alias] = el
+	}
+
+	cmd.Version = version + 1
+	m.commands[el] = pb.Clone(cmd).(*proto.BotCommand)
+	m.addRevision(el, rev)
 	return nil
 }
 
 // Get receives a *proto.Command and returns if exists
-// the respective *proto.BotCommand.
-func (m Mem) Get(cmd *proto.Command) (*proto.BotCommand, error) {
+// the respective *proto.BotCommand or the one with that alias.
+// If neither exists it returns ErrNotFound.
+func (m *Mem) Get(cmd *proto.Command) (*proto.BotCommand, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	el := cmd.GetCommand()
-	val, ok := m[el]
+	if owner, ok := m.aliases[el]; ok {
+		if _, ok := m.commands[el]; !ok {
+			el = owner
+		}
+	}
+
+	val, ok := m.commands[el]
 	if !ok {
-		return nil, fmt.Errorf("command %q not found", el)
+		return nil, errors.Wrapf(ErrNotFound, "while getting command %q", el)
 	}
 
-	return &proto.BotCommand{
-		Cmd: &proto.Command{
-			Command: el,
-		},
-		Resp: &proto.Response{
-			Response: val,
-		},
-	}, nil
+	return pb.Clone(val).(*proto.BotCommand), nil
 }
 
 // GetAll ranges over the map and returns a *proto.BotCommands
 // with all the *proto.BotCommand found.
-func (m Mem) GetAll() (*proto.BotCommands, error) {
-	var commands []*proto.BotCommand
-
-	for k, v := range m {
-		c := &proto.BotCommand{
-			Cmd: &proto.Command{
-				Command: k,
-			},
-			Resp: &proto.Response{
-				Response: v,
-			},
-		}
+func (m *Mem) GetAll() (*proto.BotCommands, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
-		commands = append(commands, c)
+	var commands []*proto.BotCommand
+	for _, c := range m.commands {
+		commands = append(commands, pb.Clone(c).(*proto.BotCommand))
 	}
 
 	return &proto.BotCommands{
@@ -66,25 +122,116 @@ func (m Mem) GetAll() (*proto.BotCommands, error) {
 	}, nil
 }
 
-// Remove removes a *proto.BotCommand from the map.
-func (m Mem) Remove(cmd *proto.Command) error {
-	delete(m, cmd.GetCommand())
+// Remove removes a *proto.BotCommand from the map. It returns
+// ErrNotFound if the command is not stored and ErrVersionMismatch
+// if the version of the received *proto.Command is not zero and
+// differs from the stored one.
+func (m *Mem) Remove(cmd *proto.Command, rev *proto.Revision) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.commands[cmd.GetCommand()]
+	if !ok {
+		return errors.Wrapf(ErrNotFound, "while removing command %q", cmd.GetCommand())
+	}
+
+	if err := checkVersion(cmd.GetCommand(), cmd.GetVersion(), stored.GetVersion()); err != nil {
+		return err
+	}
+
+	m.removeAliases(cmd.GetCommand())
+	delete(m.commands, cmd.GetCommand())
+	m.addRevision(cmd.GetCommand(), rev)
 	return nil
 }
 
-// Update updates the Response of an existing *proto.BotCommand
-// with the Response of the received *proto.BotCommand.
+// Update updates an existing *proto.BotCommand
+// with the received *proto.BotCommand.
 // If the *proto.BotCommand didn't exists it adds it.
-func (m Mem) Update(cmd *proto.BotCommand) error {
-	m[cmd.GetCmd().GetCommand()] = cmd.GetResp().GetResponse()
+// It returns ErrVersionMismatch if the version of the
+// received *proto.BotCommand is not zero and differs
+// from the stored one.
+func (m *Mem) Update(cmd *proto.BotCommand, rev *proto.Revision) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.put(cmd, rev, cmd.GetVersion(), false)
+}
+
+// addRevision appends the revision, if any, to the revisions
+// of the command numbering it after the last one.
+func (m *Mem) addRevision(el string, rev *proto.Revision) {
+	if rev == nil {
+		return
+	}
+
+	rev.Number = int64(len(m.history[el]) + 1)
+	m.history[el] = append(m.history[el], pb.Clone(rev).(*proto.Revision))
+}
+
+// Revisions returns the revisions of the
+// command from the oldest to the newest.
+func (m *Mem) Revisions(cmd *proto.Command) (*proto.Revisions, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var revisions []*proto.Revision
+	for _, rev := range m.history[cmd.GetCommand()] {
+		revisions = append(revisions, pb.Clone(rev).(*proto.Revision))
+	}
+
+	return &proto.Revisions{Revisions: revisions}, nil
+}
+
+// AddAuditEvent appends the received *proto.AuditEvent
+// numbering it after the last one.
+func (m *Mem) AddAuditEvent(ev *proto.AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ev.Id = int64(len(m.audit) + 1)
+	m.audit = append(m.audit, pb.Clone(ev).(*proto.AuditEvent))
 	return nil
 }
 
+// AuditEvents returns the audit events that meet the
+// conditions of the filter from the oldest to the newest.
+func (m *Mem) AuditEvents(f *proto.AuditFilter) (*proto.AuditEvents, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var events []*proto.AuditEvent
+	for _, ev := range m.audit {
+		events = append(events, pb.Clone(ev).(*proto.AuditEvent))
+	}
+
+	return FilterAuditEvents(events, f), nil
+}
+
 // Close deletes all the keys from the map.
-func (m Mem) Close() error {
-	for k := range m {
-		delete(m, k)
+func (m *Mem) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for k := range m.commands {
+		delete(m.commands, k)
 	}
 
+	for k := range m.aliases {
+		delete(m.aliases, k)
+	}
+
+	for k := range m.history {
+		delete(m.history, k)
+	}
+
+	m.audit = nil
+
 	return nil
 }
+
+func (m *Mem) removeAliases(el string) {
+	for _, alias := range m.commands[el].GetAliases() {
+		delete(m.aliases, alias)
+	}
+}
@@ -7,8 +7,7 @@ import (
 )
 
 func TestConnect(t *testing.T) {
-	var m Mem
-	m = make(map[string]string)
+	m := newMem()
 	if err := m.Connect(); err != nil {
 		t.Fatalf("while connecting Mem should never fail: %v", err)
 	}
@@ -29,15 +28,14 @@ func TestAdd(t *testing.T) {
 		},
 	}
 
-	var m Mem
-	m = make(map[string]string, 1)
+	m := newMem()
 
 	for _, tc := range tt {
 		command := &proto.BotCommand{
 			Cmd:  tc.cmd,
 			Resp: tc.resp,
 		}
-		if err := m.Add(command); err != nil {
+		if err := m.Add(command, nil); err != nil {
 			t.Fatalf("while adding command %q: %v", tc.cmd.GetCommand(), err)
 		}
 	}
@@ -79,11 +77,9 @@ func TestGet(t *testing.T) {
 		},
 	}
 
-	var m Mem
-	m = map[string]string{
-		commandOne.Cmd.Command: commandOne.Resp.Response,
-		commandTwo.Cmd.Command: commandTwo.Resp.Response,
-	}
+	m := newMem()
+	m.commands[commandOne.Cmd.Command] = commandOne
+	m.commands[commandTwo.Cmd.Command] = commandTwo
 
 	for _, tc := range tt {
 		cmd, err := m.Get(tc.command.Cmd)
@@ -120,11 +116,9 @@ func TestGetAll(t *testing.T) {
 		},
 	}
 
-	var m Mem
-	m = map[string]string{
-		commandOne.Cmd.Command: commandOne.Resp.Response,
-		commandTwo.Cmd.Command: commandTwo.Resp.Response,
-	}
+	m := newMem()
+	m.commands[commandOne.Cmd.Command] = commandOne
+	m.commands[commandTwo.Cmd.Command] = commandTwo
 
 	commands, err := m.GetAll()
 	if err != nil {
@@ -146,21 +140,19 @@ func TestRemove(t *testing.T) {
 		},
 	}
 
-	var m Mem
-	m = map[string]string{
-		command.Cmd.Command: command.Resp.Response,
-	}
+	m := newMem()
+	m.commands[command.Cmd.Command] = command
 
-	if len(m) != 1 {
-		t.Fatalf("expected map to have 1 item. got=%v", len(m))
+	if len(m.commands) != 1 {
+		t.Fatalf("expected map to have 1 item. got=%v", len(m.commands))
 	}
 
-	if err := m.Remove(command.GetCmd()); err != nil {
+	if err := m.Remove(command.GetCmd(), nil); err != nil {
 		t.Fatalf("while removing command %q: %v", command.GetCmd().GetCommand(), err)
 	}
 
-	if len(m) != 0 {
-		t.Fatalf("expected map to have 0 item. got=%v", len(m))
+	if len(m.commands) != 0 {
+		t.Fatalf("expected map to have 0 item. got=%v", len(m.commands))
 	}
 }
 
@@ -183,28 +175,26 @@ func TestUpdate(t *testing.T) {
 		},
 	}
 
-	var m Mem
-	m = map[string]string{
-		oldCommand.Cmd.Command: oldCommand.Resp.Response,
-	}
+	m := newMem()
+	m.commands[oldCommand.Cmd.Command] = oldCommand
 
-	if err := m.Update(newCommand); err != nil {
+	if err := m.Update(newCommand, nil); err != nil {
 		t.Fatalf("while updating command responde: %v", err)
 	}
 
-	response, ok := m[oldCommand.Cmd.GetCommand()]
+	cmd, ok := m.commands[oldCommand.Cmd.GetCommand()]
 	if !ok {
 		t.Fatalf("while checking if command %q exists. command not found", oldCommand.Cmd.GetCommand())
 	}
 
+	response := cmd.GetResp().GetResponse()
 	if response != newCommand.GetResp().GetResponse() {
 		t.Fatalf("expected command to have update response %q. got=%q", newCommand.GetResp().GetResponse(), response)
 	}
 }
 
 func TestClose(t *testing.T) {
-	var m Mem
-	m = make(map[string]string)
+	m := newMem()
 	m.Add(&proto.BotCommand{
 		Cmd: &proto.Command{
 			Command: "Hi",
@@ -212,7 +202,7 @@ func TestClose(t *testing.T) {
 		Resp: &proto.Response{
 			Response: "Hello, World!",
 		},
-	})
+	}, nil)
 
 	if err := m.Close(); err != nil {
 		t.Fatalf("while closing Mem should never fail: %v", err)
@@ -7,8 +7,10 @@ import (
 
 	"github.com/danielkvist/botio/proto"
 
+	"github.com/pkg/errors"
+
 	// postgres driver
-	_ "github.com/jackc/pgx/v4"
+	_ "github.com/jackc/pgx/v4/stdlib"
 )
 
 // Postgres wraps a sql.DB client for PostgreSQL and
@@ -21,6 +23,9 @@ type Postgres struct {
 	DB              string
 	Table           string
 	client          *sql.DB
+	aliases         *sqlAliases
+	history         *sqlHistory
+	audit           *sqlAudit
 	MaxConns        int
 	MaxConnLifetime time.Duration
 }
@@ -29,7 +34,7 @@ type Postgres struct {
 // a non-nil error. It also tries to create a table for the commands if not exist.
 func (ps *Postgres) Connect() error {
 	psqlInfo := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", ps.Host, ps.Port, ps.User, ps.Password, ps.DB)
-	client, err := sql.Open("postgres", psqlInfo)
+	client, err := sql.Open("pgx", psqlInfo)
 	if err != nil {
 		return fmt.Errorf("while validating arguments to connect to DB: %v", err)
 	}
@@ -41,49 +46,171 @@ func (ps *Postgres) Connect() error {
 		return fmt.Errorf("while opening a connection to DB: %v", err)
 	}
 
-	statement := `
-		CREATE IF NOT EXISTS $1 (
+	statement := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
 			command TEXT NOT NULL PRIMARY KEY,
-			response TEXT NOT NULL
-		);`
+			response TEXT NOT NULL,
+			data BYTEA,
+			version BIGINT NOT NULL DEFAULT 0
+		);`, ps.Table)
 
-	if _, err := ps.client.Exec(statement, ps.Table); err != nil {
+	if _, err := ps.client.Exec(statement); err != nil {
 		return fmt.Errorf("while creating a table for commands: %v", err)
 	}
 
+	statement = fmt.Sprintf(`
+		ALTER TABLE %s
+		ADD COLUMN IF NOT EXISTS data BYTEA,
+		ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 0;`, ps.Table)
+	if _, err := ps.client.Exec(statement); err != nil {
+		return fmt.Errorf("while migrating the table for commands: %v", err)
+	}
+
+	ps.aliases = &sqlAliases{
+		table:    ps.Table + "_aliases",
+		commands: ps.Table,
+		param:    func(n int) string { return fmt.Sprintf("$%d", n) },
+	}
+
+	if err := ps.aliases.create(ps.client); err != nil {
+		return fmt.Errorf("while creating a table for aliases: %v", err)
+	}
+
+	ps.history = &sqlHistory{
+		table: ps.Table + "_history",
+		blob:  "BYTEA",
+		param: func(n int) string { return fmt.Sprintf("$%d", n) },
+	}
+
+	if err := ps.history.create(ps.client); err != nil {
+		return fmt.Errorf("while creating a table for revisions: %v", err)
+	}
+
+	ps.audit = &sqlAudit{
+		table:     ps.Table + "_audit",
+		id:        "BIGSERIAL PRIMARY KEY",
+		blob:      "BYTEA",
+		param:     func(n int) string { return fmt.Sprintf("$%d", n) },
+		returning: true,
+	}
+
+	if err := ps.audit.create(ps.client); err != nil {
+		return fmt.Errorf("while creating a table for audit events: %v", err)
+	}
+
 	return nil
 }
 
 // Add receives a *proto.BotCommand and adds it to the
-// table designated. If something goes wrong executing the
-// SQL statement it returns a non-nil error.
-func (ps *Postgres) Add(cmd *proto.BotCommand) error {
-	statement := `INSERT INTO $1 (command, response) VALUES ($2, $3);`
+// table designated alongside its aliases. If something goes wrong
+// executing the SQL statements it returns a non-nil error, which is
+// ErrAlreadyExists if the command is already stored and ErrAliasInUse
+// if the command or any of its aliases is used by another command.
+func (ps *Postgres) Add(cmd *proto.BotCommand, rev *proto.Revision) error {
+	return ps.put(cmd, rev, 0, true)
+}
+
+// put stores the command increasing its version if the stored
+// one matches the expected version, unless it is zero, and the
+// revision, if any, on the same transaction. If create is true
+// the command must not be stored yet.
+func (ps *Postgres) put(cmd *proto.BotCommand, rev *proto.Revision, expected int64, create bool) error {
+	if cmd == nil {
+		cmd = &proto.BotCommand{}
+	}
+
 	el := cmd.GetCmd().GetCommand()
 	val := cmd.GetResp().GetResponse()
 
-	if _, err := ps.client.Exec(statement, ps.Table, el, val); err != nil {
-		return fmt.Errorf("while adding command %q: %v", el, err)
-	}
+	insert := fmt.Sprintf(`INSERT INTO %s (command, response, data, version) VALUES ($1, $2, $3, $4);`, ps.Table)
+	update := fmt.Sprintf(`
+	UPDATE %s
+	SET response=$1, data=$2, version=$3
+	WHERE command=$4 AND version=$5;`, ps.Table)
 
-	return nil
+	return withTx(ps.client, func(tx *sql.Tx) error {
+		if err := ps.aliases.check(tx, el, cmd.GetAliases()); err != nil {
+			return err
+		}
+
+		version, stored, err := sqlVersion(tx, ps.Table, "$1", "FOR UPDATE", el)
+		if err != nil {
+			return err
+		}
+
+		if create && stored {
+			return errors.Wrapf(ErrAlreadyExists, "command %q", el)
+		}
+
+		if err := checkVersion(el, expected, version); err != nil {
+			return err
+		}
+
+		cmd.Version = version + 1
+		data, err := encode(cmd)
+		if err != nil {
+			return err
+		}
+
+		if !stored {
+			if _, err := tx.Exec(insert, el, val, data, cmd.GetVersion()); err != nil {
+				return errors.Wrapf(err, "while adding command %q", el)
+			}
+
+			if err := ps.aliases.replace(tx, el, cmd.GetAliases()); err != nil {
+				return err
+			}
+
+			return ps.history.add(tx, el, rev)
+		}
+
+		res, err := tx.Exec(update, val, data, cmd.GetVersion(), el, version)
+		if err != nil {
+			return errors.Wrapf(err, "while updating command %q", el)
+		}
+
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			// The command was changed since its version was gotten.
+			return errors.Wrapf(ErrVersionMismatch, "command %q changed while updating it", el)
+		}
+
+		if err := ps.aliases.replace(tx, el, cmd.GetAliases()); err != nil {
+			return err
+		}
+
+		return ps.history.add(tx, el, rev)
+	})
 }
 
 // Get receives a *proto.Command and returns the respective *proto.BotCommand
-// if exists in the designated table. If not or there is any problem
-// while executing the SQL statement it returns a non-nil error.
+// if exists in the designated table, looking for a command with that alias
+// if there is none with that name. If not it returns ErrNotFound and if
+// there is any problem while executing the SQL statement a non-nil error.
 func (ps *Postgres) Get(cmd *proto.Command) (*proto.BotCommand, error) {
 	el := cmd.GetCommand()
 
-	statement := `SELECT * FROM $1 WHERE command=$2;`
-	row := ps.client.QueryRow(statement, ps.Table, el)
+	statement := fmt.Sprintf(`SELECT response, data FROM %s WHERE command=$1;`, ps.Table)
+	row := ps.client.QueryRow(statement, el)
 
-	var command *proto.BotCommand
-	if err := row.Scan(command.Cmd.Command, command.Resp.Response); err != nil {
+	var response string
+	var data []byte
+	err := row.Scan(&response, &data)
+	if err == sql.ErrNoRows {
+		if command, aerr := ps.aliases.resolve(ps.client, el); aerr == nil {
+			el = command
+			err = ps.client.QueryRow(statement, el).Scan(&response, &data)
+		}
+	}
+
+	if err == sql.ErrNoRows {
+		return nil, errors.Wrapf(ErrNotFound, "while getting command %q", el)
+	}
+
+	if err != nil {
 		return nil, fmt.Errorf("while getting command %q: %v", el, err)
 	}
 
-	return command, nil
+	return decodeRow(el, response, data)
 }
 
 // GetAll ranges over all the entries of the designated table for the commands
@@ -91,8 +218,8 @@ func (ps *Postgres) Get(cmd *proto.Command) (*proto.BotCommand, error) {
 // If something goes wrong while executing the SQL statement or while
 // getting some command it returns a non-nil error.
 func (ps *Postgres) GetAll() (*proto.BotCommands, error) {
-	statement := `SELECT * FROM $1;`
-	rows, err := ps.client.Query(statement, ps.Table)
+	statement := fmt.Sprintf(`SELECT command, response, data FROM %s;`, ps.Table)
+	rows, err := ps.client.Query(statement)
 	if err != nil {
 		return nil, fmt.Errorf("while getting commands from DB: %v", err)
 	}
@@ -100,11 +227,17 @@ func (ps *Postgres) GetAll() (*proto.BotCommands, error) {
 
 	var commands []*proto.BotCommand
 	for rows.Next() {
-		var command *proto.BotCommand
-		if err := rows.Scan(command.Cmd.Command, command.Resp.Response); err != nil {
+		var el, response string
+		var data []byte
+		if err := rows.Scan(&el, &response, &data); err != nil {
 			return nil, fmt.Errorf("while getting command: %v", err)
 		}
 
+		command, err := decodeRow(el, response, data)
+		if err != nil {
+			return nil, err
+		}
+
 		commands = append(commands, command)
 	}
 
@@ -117,38 +250,69 @@ func (ps *Postgres) GetAll() (*proto.BotCommands, error) {
 	}, nil
 }
 
-// Remove removes a *proto.BotCommand from the designated table. It returns a
-// non-nil error if there is some problem while executing the
-// SQL statement or deleting the command.
-func (ps *Postgres) Remove(cmd *proto.Command) error {
+// Remove removes a *proto.BotCommand and its aliases from the designated
+// table. It returns a non-nil error if there is some problem while executing
+// the SQL statements or deleting the command, which is ErrNotFound if the command
+// is not stored and ErrVersionMismatch if the version of the received *proto.Command
+// is not zero and differs from the stored one.
+func (ps *Postgres) Remove(cmd *proto.Command, rev *proto.Revision) error {
 	el := cmd.GetCommand()
 
-	statement := `DELETE FROM $1 WHERE command=$2;`
-	if _, err := ps.client.Exec(statement, ps.Table, el); err != nil {
-		return fmt.Errorf("while removing command %q: %v", el, err)
-	}
+	statement := fmt.Sprintf(`DELETE FROM %s WHERE command=$1;`, ps.Table)
+	return withTx(ps.client, func(tx *sql.Tx) error {
+		version, stored, err := sqlVersion(tx, ps.Table, "$1", "FOR UPDATE", el)
+		if err != nil {
+			return err
+		}
 
-	return nil
+		if !stored {
+			return errors.Wrapf(ErrNotFound, "while removing command %q", el)
+		}
+
+		if err := checkVersion(el, cmd.GetVersion(), version); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(statement, el); err != nil {
+			return errors.Wrapf(err, "while removing command %q", el)
+		}
+
+		if err := ps.aliases.remove(tx, el); err != nil {
+			return err
+		}
+
+		return ps.history.add(tx, el, rev)
+	})
 }
 
-// Update updates the Response of an existing *proto.BotCommand
-// with the Response of the received *proto.BotCommand. If
-// there is any error while executing the SQL statement
-// it returns a non-nil error.
-func (ps *Postgres) Update(cmd *proto.BotCommand) error {
-	el := cmd.GetCmd().GetCommand()
-	val := cmd.GetResp().GetResponse()
+// Update updates an existing *proto.BotCommand and its
+// aliases with the received *proto.BotCommand increasing its
+// version. If the *proto.BotCommand didn't exists it adds it.
+// If there is any error while executing the SQL statements
+// it returns a non-nil error, which is ErrAliasInUse if the command
+// or any of its aliases is used by another command and ErrVersionMismatch
+// if the version of the received *proto.BotCommand is not zero and
+// differs from the stored one.
+func (ps *Postgres) Update(cmd *proto.BotCommand, rev *proto.Revision) error {
+	return ps.put(cmd, rev, cmd.GetVersion(), false)
+}
 
-	statement := `
-	UPDATE $1
-	SET response=$2
-	WHERE command=$3;`
+// Revisions returns the revisions of the command
+// stored on the history table from the oldest to the newest.
+func (ps *Postgres) Revisions(cmd *proto.Command) (*proto.Revisions, error) {
+	return ps.history.list(ps.client, cmd.GetCommand())
+}
 
-	if _, err := ps.client.Exec(statement, val, el); err != nil {
-		return fmt.Errorf("while updating command %q: %v", el, err)
-	}
+// AddAuditEvent stores the received *proto.AuditEvent on
+// the audit table, which assigns its id.
+func (ps *Postgres) AddAuditEvent(ev *proto.AuditEvent) error {
+	return ps.audit.add(ps.client, ev)
+}
 
-	return nil
+// AuditEvents returns the audit events stored on the audit table
+// that meet the conditions of the filter from the oldest to the newest.
+func (ps *Postgres) AuditEvents(f *proto.AuditFilter) (*proto.AuditEvents, error) {
+	return ps.audit.list(ps.client, f)
 }
 
 // Close tries to close the connection to the PostgreSQL database.
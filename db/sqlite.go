@@ -20,6 +20,9 @@ type SQLite struct {
 	Path            string
 	Table           string
 	client          *sql.DB
+	aliases         *sqlAliases
+	history         *sqlHistory
+	audit           *sqlAudit
 	MaxConns        int
 	MaxConnLifetime time.Duration
 }
@@ -39,7 +42,7 @@ func (sq *SQLite) Connect() error {
 		return errors.Wrapf(err, "while opening a connection the SQLite DB")
 	}
 
-	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (command TEXT NOT NULL PRIMARY KEY, response TEXT NOT NULL);", sq.Table)
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (command TEXT NOT NULL PRIMARY KEY, response TEXT NOT NULL, data BLOB, version INTEGER NOT NULL DEFAULT 0);", sq.Table)
 	stmt, err := sq.client.Prepare(query)
 	if err != nil {
 		return errors.Wrapf(err, "while preparing SQL query")
@@ -51,34 +54,161 @@ func (sq *SQLite) Connect() error {
 		return errors.Wrapf(err, "while creating table %q", sq.Table)
 	}
 
-	return nil
+	if err := sq.addColumn(sq.Table, "data", "BLOB"); err != nil {
+		return err
+	}
+
+	if err := sq.addColumn(sq.Table, "version", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+
+	sq.aliases = &sqlAliases{
+		table:    sq.Table + "_aliases",
+		commands: sq.Table,
+		param:    func(int) string { return "?" },
+	}
+
+	if err := sq.aliases.create(sq.client); err != nil {
+		return err
+	}
+
+	sq.history = &sqlHistory{
+		table: sq.Table + "_history",
+		blob:  "BLOB",
+		param: func(int) string { return "?" },
+	}
+
+	if err := sq.history.create(sq.client); err != nil {
+		return err
+	}
+
+	sq.audit = &sqlAudit{
+		table: sq.Table + "_audit",
+		id:    "INTEGER PRIMARY KEY AUTOINCREMENT",
+		blob:  "BLOB",
+		param: func(int) string { return "?" },
+	}
+
+	return sq.audit.create(sq.client)
 }
 
-// Add receives a *proto.BotCommand and adds it to the table designated. If
-// something goes wrong while executing the SQL statement it returns a non-nil
-// error.
-func (sq *SQLite) Add(cmd *proto.BotCommand) error {
-	query := fmt.Sprintf("INSERT INTO %s (command, response) VALUES (?, ?)", sq.Table)
-	stmt, err := sq.client.Prepare(query)
+// addColumn adds a column to a table created by an older version
+// of botio if the table doesn't have it yet.
+func (sq *SQLite) addColumn(table, column, definition string) error {
+	rows, err := sq.client.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
 	if err != nil {
-		return errors.Wrapf(err, "while preparing SQL query")
+		return errors.Wrapf(err, "while getting the columns of table %q", table)
 	}
-	defer stmt.Close()
+	defer rows.Close()
 
-	el := cmd.GetCmd().GetCommand()
-	val := cmd.GetResp().GetResponse()
-	if _, err := stmt.Exec(el, val); err != nil {
-		return errors.Wrapf(err, "while adding command %q to table %q", el, sq.Table)
+	for rows.Next() {
+		var cid int
+		var name, ctype string
+		var notNull, pk int
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &ctype, &notNull, &dflt, &pk); err != nil {
+			return errors.Wrapf(err, "while getting the columns of table %q", table)
+		}
+
+		if name == column {
+			return nil
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.Wrapf(err, "while getting the columns of table %q", table)
+	}
+
+	if _, err := sq.client.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return errors.Wrapf(err, "while adding column %q to table %q", column, table)
 	}
 
 	return nil
 }
 
+// Add receives a *proto.BotCommand and adds it to the table designated
+// alongside its aliases. If something goes wrong while executing the SQL
+// statements it returns a non-nil error, which is ErrAlreadyExists if the
+// command is already stored and ErrAliasInUse if the command or any of its
+// aliases is used by another command.
+func (sq *SQLite) Add(cmd *proto.BotCommand, rev *proto.Revision) error {
+	return sq.put(cmd, rev, 0, true)
+}
+
+// put stores the command increasing its version if the stored
+// one matches the expected version, unless it is zero, and the
+// revision, if any, on the same transaction. If create is true
+// the command must not be stored yet.
+func (sq *SQLite) put(cmd *proto.BotCommand, rev *proto.Revision, expected int64, create bool) error {
+	if cmd == nil {
+		cmd = &proto.BotCommand{}
+	}
+
+	el := cmd.GetCmd().GetCommand()
+	val := cmd.GetResp().GetResponse()
+
+	return withTx(sq.client, func(tx *sql.Tx) error {
+		if err := sq.aliases.check(tx, el, cmd.GetAliases()); err != nil {
+			return err
+		}
+
+		version, stored, err := sqlVersion(tx, sq.Table, "?", "", el)
+		if err != nil {
+			return err
+		}
+
+		if create && stored {
+			return errors.Wrapf(ErrAlreadyExists, "command %q", el)
+		}
+
+		if err := checkVersion(el, expected, version); err != nil {
+			return err
+		}
+
+		cmd.Version = version + 1
+		data, err := encode(cmd)
+		if err != nil {
+			return err
+		}
+
+		if !stored {
+			query := fmt.Sprintf("INSERT INTO %s (command, response, data, version) VALUES (?, ?, ?, ?)", sq.Table)
+			if _, err := tx.Exec(query, el, val, data, cmd.GetVersion()); err != nil {
+				return errors.Wrapf(err, "while adding command %q to table %q", el, sq.Table)
+			}
+
+			if err := sq.aliases.replace(tx, el, cmd.GetAliases()); err != nil {
+				return err
+			}
+
+			return sq.history.add(tx, el, rev)
+		}
+
+		query := fmt.Sprintf("UPDATE %s SET response=?, data=?, version=? WHERE command=? AND version=?", sq.Table)
+		res, err := tx.Exec(query, val, data, cmd.GetVersion(), el, version)
+		if err != nil {
+			return errors.Wrapf(err, "while updating command %q on table %q", el, sq.Table)
+		}
+
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			// The command was changed since its version was gotten.
+			return errors.Wrapf(ErrVersionMismatch, "command %q changed while updating it", el)
+		}
+
+		if err := sq.aliases.replace(tx, el, cmd.GetAliases()); err != nil {
+			return err
+		}
+
+		return sq.history.add(tx, el, rev)
+	})
+}
+
 // Get reveives a *proto.Command and returns the respective *proto.BotCommand
-// if exists in the designated table. If not exists or there is any problem
-// while executing the SQL statement it returns a non-nil error.
+// if exists in the designated table, looking for a command with that alias
+// if there is none with that name. If not exists it returns ErrNotFound and if
+// there is any problem while executing the SQL statement a non-nil error.
 func (sq *SQLite) Get(cmd *proto.Command) (*proto.BotCommand, error) {
-	query := fmt.Sprintf("SELECT response FROM %s WHERE command = ?", sq.Table)
+	query := fmt.Sprintf("SELECT response, data FROM %s WHERE command = ?", sq.Table)
 	stmt, err := sq.client.Prepare(query)
 	if err != nil {
 		return nil, errors.Wrapf(err, "while preparing SQL query")
@@ -89,18 +219,24 @@ func (sq *SQLite) Get(cmd *proto.Command) (*proto.BotCommand, error) {
 	row := stmt.QueryRow(el)
 
 	var response string
-	if err := row.Scan(&response); err != nil {
+	var data []byte
+	err = row.Scan(&response, &data)
+	if err == sql.ErrNoRows {
+		if command, aerr := sq.aliases.resolve(sq.client, el); aerr == nil {
+			el = command
+			err = stmt.QueryRow(el).Scan(&response, &data)
+		}
+	}
+
+	if err == sql.ErrNoRows {
+		return nil, errors.Wrapf(ErrNotFound, "while scanning DB for command %q", el)
+	}
+
+	if err != nil {
 		return nil, errors.Wrapf(err, "while scanning DB for command %q", el)
 	}
 
-	return &proto.BotCommand{
-		Cmd: &proto.Command{
-			Command: el,
-		},
-		Resp: &proto.Response{
-			Response: response,
-		},
-	}, nil
+	return decodeRow(el, response, data)
 }
 
 // GetAll ranges over all the entries of the designated table for the commands
@@ -108,7 +244,7 @@ func (sq *SQLite) Get(cmd *proto.Command) (*proto.BotCommand, error) {
 // something goes wrong while executing the SQL statement or while
 // getting some *proto.BotCommand it returns a non-nil error.
 func (sq *SQLite) GetAll() (*proto.BotCommands, error) {
-	query := fmt.Sprintf("SELECT command, response FROM %s", sq.Table)
+	query := fmt.Sprintf("SELECT command, response, data FROM %s", sq.Table)
 	stmt, err := sq.client.Prepare(query)
 	if err != nil {
 		return nil, errors.Wrapf(err, "while preparing SQL query")
@@ -125,18 +261,17 @@ func (sq *SQLite) GetAll() (*proto.BotCommands, error) {
 	for rows.Next() {
 		var command string
 		var response string
-		if err := rows.Scan(&command, &response); err != nil {
+		var data []byte
+		if err := rows.Scan(&command, &response, &data); err != nil {
 			return nil, errors.Wrapf(err, "while getting command from table %q", sq.Table)
 		}
 
-		commands = append(commands, &proto.BotCommand{
-			Cmd: &proto.Command{
-				Command: command,
-			},
-			Resp: &proto.Response{
-				Response: response,
-			},
-		})
+		c, err := decodeRow(command, response, data)
+		if err != nil {
+			return nil, err
+		}
+
+		commands = append(commands, c)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -148,43 +283,66 @@ func (sq *SQLite) GetAll() (*proto.BotCommands, error) {
 	}, nil
 }
 
-// Remove removes the received *proto.BotCommand from the designated table. It returns
-// a non-nil error if something goes wrong while executing the SQL statement.
-func (sq *SQLite) Remove(cmd *proto.Command) error {
-	query := fmt.Sprintf("DELETE FROM %s WHERE command = ?", sq.Table)
-	stmt, err := sq.client.Prepare(query)
-	if err != nil {
-		return errors.Wrapf(err, "while preparing SQL query")
-	}
-	defer stmt.Close()
-
+// Remove removes the received *proto.BotCommand and its aliases from the designated
+// table. It returns a non-nil error if something goes wrong while executing the SQL statements,
+// which is ErrNotFound if the command is not stored and ErrVersionMismatch if the version of the
+// received *proto.Command is not zero and differs from the stored one.
+func (sq *SQLite) Remove(cmd *proto.Command, rev *proto.Revision) error {
 	el := cmd.GetCommand()
-	if _, err := stmt.Exec(el); err != nil {
-		return errors.Wrapf(err, "while removing command %q from table %q", el, sq.Table)
-	}
 
-	return nil
+	return withTx(sq.client, func(tx *sql.Tx) error {
+		version, stored, err := sqlVersion(tx, sq.Table, "?", "", el)
+		if err != nil {
+			return err
+		}
+
+		if !stored {
+			return errors.Wrapf(ErrNotFound, "while removing command %q from table %q", el, sq.Table)
+		}
+
+		if err := checkVersion(el, cmd.GetVersion(), version); err != nil {
+			return err
+		}
+
+		query := fmt.Sprintf("DELETE FROM %s WHERE command = ?", sq.Table)
+		if _, err := tx.Exec(query, el); err != nil {
+			return errors.Wrapf(err, "while removing command %q from table %q", el, sq.Table)
+		}
+
+		if err := sq.aliases.remove(tx, el); err != nil {
+			return err
+		}
+
+		return sq.history.add(tx, el, rev)
+	})
 }
 
-// Update updates the *proto.Response of an existing *proto.BotCommand with the
-// *proto.Response of the received *proto.BotCommand. If something goes wrong while
-// executing the SQL statement it returns a non-nil error.
-func (sq *SQLite) Update(cmd *proto.BotCommand) error {
-	query := fmt.Sprintf("UPDATE %s SET response=? WHERE command=?", sq.Table)
-	stmt, err := sq.client.Prepare(query)
-	if err != nil {
-		return errors.Wrapf(err, "while preparing SQL query")
-	}
-	defer stmt.Close()
+// Update updates an existing *proto.BotCommand and its aliases with the received
+// *proto.BotCommand increasing its version. If the *proto.BotCommand didn't exists
+// it adds it. If something goes wrong while executing the SQL statements it returns
+// a non-nil error, which is ErrAliasInUse if the command or any of its aliases is
+// used by another command and ErrVersionMismatch if the version of the received
+// *proto.BotCommand is not zero and differs from the stored one.
+func (sq *SQLite) Update(cmd *proto.BotCommand, rev *proto.Revision) error {
+	return sq.put(cmd, rev, cmd.GetVersion(), false)
+}
 
-	el := cmd.GetCmd().GetCommand()
-	val := cmd.GetResp().GetResponse()
+// Revisions returns the revisions of the command
+// stored on the history table from the oldest to the newest.
+func (sq *SQLite) Revisions(cmd *proto.Command) (*proto.Revisions, error) {
+	return sq.history.list(sq.client, cmd.GetCommand())
+}
 
-	if _, err := stmt.Exec(val, el); err != nil {
-		return errors.Wrapf(err, "while updating command %q on table %q", el, sq.Table)
-	}
+// AddAuditEvent stores the received *proto.AuditEvent on
+// the audit table, which assigns its id.
+func (sq *SQLite) AddAuditEvent(ev *proto.AuditEvent) error {
+	return sq.audit.add(sq.client, ev)
+}
 
-	return nil
+// AuditEvents returns the audit events stored on the audit table
+// that meet the conditions of the filter from the oldest to the newest.
+func (sq *SQLite) AuditEvents(f *proto.AuditFilter) (*proto.AuditEvents, error) {
+	return sq.audit.list(sq.client, f)
 }
 
 // Close tries to close the connection to the SQLite database. If it fails
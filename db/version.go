@@ -0,0 +1,42 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// ErrVersionMismatch is returned when a command is updated or removed
+// expecting a version different from the one that is stored.
+var ErrVersionMismatch = errors.New("version mismatch")
+
+// checkVersion returns ErrVersionMismatch if the expected
+// version is not zero and differs from the stored one.
+func checkVersion(command string, expected, stored int64) error {
+	if expected != 0 && expected != stored {
+		return errors.Wrapf(ErrVersionMismatch, "command %q has version %v instead of %v", command, stored, expected)
+	}
+
+	return nil
+}
+
+// sqlVersion returns the version of the command stored on the table
+// of a SQL database and whether the command is stored at all. The
+// placeholder is the one used by the database for the first parameter
+// and lock, if not empty, the clause that locks the row of the command
+// until the end of the transaction.
+func sqlVersion(tx *sql.Tx, table, placeholder, lock, command string) (int64, bool, error) {
+	query := fmt.Sprintf("SELECT version FROM %s WHERE command = %s %s", table, placeholder, lock)
+
+	var version int64
+	err := tx.QueryRow(query, command).Scan(&version)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0, false, nil
+	case err != nil:
+		return 0, false, errors.Wrapf(err, "while getting the version of command %q", command)
+	}
+
+	return version, true, nil
+}
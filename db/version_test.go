@@ -0,0 +1,58 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/pkg/errors"
+)
+
+func TestVersions(t *testing.T) {
+	testDBs(t, func(t *testing.T, db DB) {
+		start := &proto.BotCommand{
+			Cmd:  &proto.Command{Command: "start"},
+			Resp: &proto.Response{Response: "Hi"},
+		}
+
+		if err := db.Add(start, nil); err != nil {
+			t.Fatalf("while adding command: %v", err)
+		}
+
+		if start.GetVersion() != 1 {
+			t.Fatalf("expected version %v after adding. got=%v", 1, start.GetVersion())
+		}
+
+		start.Resp.Response = "Hello"
+		if err := db.Update(start, nil); err != nil {
+			t.Fatalf("while updating command: %v", err)
+		}
+
+		stale := &proto.BotCommand{
+			Cmd:     &proto.Command{Command: "start"},
+			Resp:    &proto.Response{Response: "Hey"},
+			Version: 1,
+		}
+
+		if err := db.Update(stale, nil); errors.Cause(err) != ErrVersionMismatch {
+			t.Fatalf("expected %v updating a stale command. got=%v", ErrVersionMismatch, err)
+		}
+
+		if err := db.Remove(&proto.Command{Command: "start", Version: 1}, nil); errors.Cause(err) != ErrVersionMismatch {
+			t.Fatalf("expected %v removing a stale command. got=%v", ErrVersionMismatch, err)
+		}
+
+		stored, err := db.Get(&proto.Command{Command: "start"})
+		if err != nil {
+			t.Fatalf("while getting command: %v", err)
+		}
+
+		if stored.GetVersion() != 2 || stored.GetResp().GetResponse() != "Hello" {
+			t.Fatalf("expected version %v with response %q. got=%v with %q", 2, "Hello", stored.GetVersion(), stored.GetResp().GetResponse())
+		}
+
+		if err := db.Remove(&proto.Command{Command: "start", Version: 2}, nil); err != nil {
+			t.Fatalf("while removing command: %v", err)
+		}
+	})
+}
@@ -1,13 +1,12 @@
 module github.com/danielkvist/botio
 
-go 1.12
+go 1.16
 
 require (
-	github.com/bwmarrin/discordgo v0.20.2
+	github.com/bwmarrin/discordgo v0.25.0
 	github.com/dgraph-io/ristretto v0.0.0-20191114170855-99d1bbbf28e6
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
-	github.com/golang/protobuf v1.3.3
-	github.com/gorilla/websocket v1.4.1 // indirect
+	github.com/golang/protobuf v1.4.1
 	github.com/grpc-ecosystem/go-grpc-middleware v1.2.0
 	github.com/grpc-ecosystem/grpc-gateway v1.12.1
 	github.com/jackc/pgtype v1.0.3 // indirect
@@ -17,13 +16,13 @@ require (
 	github.com/sirupsen/logrus v1.4.2
 	github.com/spf13/cobra v0.0.5
 	github.com/spf13/pflag v1.0.5 // indirect
+	github.com/swaggo/files v0.0.0-20190704085106-630677cd5c14
 	github.com/yanzay/tbot/v2 v2.1.0
-	go.etcd.io/bbolt v1.3.3
-	golang.org/x/crypto v0.0.0-20191206172530-e9b2fee46413 // indirect
-	golang.org/x/net v0.0.0-20191204025024-5ee1b9f4859a // indirect
-	golang.org/x/sys v0.0.0-20191204072324-ce4227a45e2e // indirect
-	golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543 // indirect
-	google.golang.org/genproto v0.0.0-20191205163323-51378566eb59
+	go.etcd.io/bbolt v1.3.5
+	go.starlark.net v0.0.0-20230302034142-4b1e35fe2254
+	golang.org/x/text v0.3.3
+	golang.org/x/time v0.0.0-20191024005414-555d28b269f0
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013
 	google.golang.org/grpc v1.27.0
 	gopkg.in/yaml.v2 v2.2.7 // indirect
 )
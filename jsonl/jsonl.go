@@ -0,0 +1,107 @@
+// Package jsonl reads and writes the files on which protocol
+// buffer messages are kept, one JSON object per line.
+package jsonl
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+
+	"github.com/golang/protobuf/jsonpb"
+	pb "github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// Load decodes every message kept on the file on path into a new
+// message returned by newMsg and passes it to add. A missing file
+// keeps no messages, and a last line cut short by a write that
+// didn't finish is ignored.
+func Load(path string, newMsg func() pb.Message, add func(pb.Message)) error {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "while reading file %q", path)
+	}
+
+	lines := bytes.Split(data, []byte("\n"))
+	for i, line := range lines {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		msg := newMsg()
+		if err := jsonpb.Unmarshal(bytes.NewReader(line), msg); err != nil {
+			if i == len(lines)-1 {
+				break
+			}
+
+			return errors.Wrapf(err, "while decoding line %v of %q", i+1, path)
+		}
+
+		add(msg)
+	}
+
+	return nil
+}
+
+// Save replaces the file on path with the received messages. They are
+// written to a temporary file first so the file is never left half written.
+func Save(path string, msgs []pb.Message) error {
+	data, err := encode(msgs)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return errors.Wrapf(err, "while writing file %q", tmp)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return errors.Wrapf(err, "while replacing file %q", path)
+	}
+
+	return nil
+}
+
+// Append adds the received messages to the end of the file on path.
+func Append(path string, msgs ...pb.Message) error {
+	data, err := encode(msgs)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "while opening file %q", path)
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return errors.Wrapf(err, "while appending to file %q", path)
+	}
+
+	if err := file.Close(); err != nil {
+		return errors.Wrapf(err, "while closing file %q", path)
+	}
+
+	return nil
+}
+
+func encode(msgs []pb.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	m := &jsonpb.Marshaler{OrigName: true}
+	for _, msg := range msgs {
+		if err := m.Marshal(&buf, msg); err != nil {
+			return nil, errors.Wrapf(err, "while encoding %T", msg)
+		}
+
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
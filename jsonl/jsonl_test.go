@@ -0,0 +1,65 @@
+package jsonl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+
+	pb "github.com/golang/protobuf/proto"
+)
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "botio")
+	if err != nil {
+		t.Fatalf("while creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "commands.jsonl")
+	load := func() []string {
+		var commands []string
+		err := Load(path, func() pb.Message { return &proto.Command{} }, func(msg pb.Message) {
+			commands = append(commands, msg.(*proto.Command).GetCommand())
+		})
+		if err != nil {
+			t.Fatalf("while loading file: %v", err)
+		}
+
+		return commands
+	}
+
+	if commands := load(); len(commands) != 0 {
+		t.Fatalf("expected no commands on a missing file. got=%v", commands)
+	}
+
+	if err := Save(path, []pb.Message{&proto.Command{Command: "start"}, &proto.Command{Command: "help"}}); err != nil {
+		t.Fatalf("while saving file: %v", err)
+	}
+
+	if err := Append(path, &proto.Command{Command: "stop"}); err != nil {
+		t.Fatalf("while appending to file: %v", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatalf("while opening file: %v", err)
+	}
+	file.WriteString(`{"comm`)
+	file.Close()
+
+	commands := load()
+	if len(commands) != 3 || commands[0] != "start" || commands[2] != "stop" {
+		t.Fatalf("expected commands %q, %q and %q ignoring the cut line. got=%v", "start", "help", "stop", commands)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("not json\n{}\n"), 0600); err != nil {
+		t.Fatalf("while writing file: %v", err)
+	}
+
+	if err := Load(path, func() pb.Message { return &proto.Command{} }, func(pb.Message) {}); err == nil {
+		t.Fatalf("expected an error loading a malformed line")
+	}
+}
@@ -7,7 +7,10 @@ import (
 	context "context"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
+	duration "github.com/golang/protobuf/ptypes/duration"
 	empty "github.com/golang/protobuf/ptypes/empty"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	_ "github.com/grpc-ecosystem/grpc-gateway/protoc-gen-swagger/options"
 	_ "google.golang.org/genproto/googleapis/api/annotations"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
@@ -28,7 +31,21 @@ const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 // Command represents a command's name.
 type Command struct {
-	Command              string   `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	Command string `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	// Languages of the user as BCP 47 tags ordered by preference, like
+	// "es-AR, en". The response is translated to the best match.
+	Lang string `protobuf:"bytes,2,opt,name=lang,proto3" json:"lang,omitempty"`
+	// Version that the command is expected to have when it is deleted.
+	// Zero skips the check.
+	Version int64 `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	// Arguments that follow the command on the message, sent to its callout.
+	Args []string `protobuf:"bytes,4,rep,name=args,proto3" json:"args,omitempty"`
+	// Who requested the command, sent to its callout. Commands
+	// with access rules can't be gotten without it unless raw.
+	Caller *Caller `protobuf:"bytes,5,opt,name=caller,proto3" json:"caller,omitempty"`
+	// Raw returns the command as it is stored, without checking
+	// its access rules nor resolving its response.
+	Raw                  bool     `protobuf:"varint,6,opt,name=raw,proto3" json:"raw,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -66,9 +83,57 @@ func (m *Command) GetCommand() string {
 	return ""
 }
 
+func (m *Command) GetLang() string {
+	if m != nil {
+		return m.Lang
+	}
+	return ""
+}
+
+func (m *Command) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *Command) GetArgs() []string {
+	if m != nil {
+		return m.Args
+	}
+	return nil
+}
+
+func (m *Command) GetCaller() *Caller {
+	if m != nil {
+		return m.Caller
+	}
+	return nil
+}
+
+func (m *Command) GetRaw() bool {
+	if m != nil {
+		return m.Raw
+	}
+	return false
+}
+
 // Response represents a commnad's response.
 type Response struct {
-	Response             string   `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
+	Response string `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
+	// Buttons shown below the response on the platforms that support them.
+	Buttons []*Button `protobuf:"bytes,2,rep,name=buttons,proto3" json:"buttons,omitempty"`
+	// Translations of the response keyed by BCP 47 tag, like "es" or
+	// "en-GB". The response is used when no translation matches.
+	Translations map[string]string `protobuf:"bytes,3,rep,name=translations,proto3" json:"translations,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Alternatives to the response from which one is picked on every
+	// lookup following the selection mode, no matter the language, and
+	// then translated. Variants without translations of their own are
+	// translated with the translations of the response.
+	Variants []*Variant `protobuf:"bytes,4,rep,name=variants,proto3" json:"variants,omitempty"`
+	// How the variant is picked: "random", the default, "round-robin",
+	// "weighted" or "sticky", which picks the same variant for a user.
+	Selection            string   `protobuf:"bytes,5,opt,name=selection,proto3" json:"selection,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -106,21 +171,178 @@ func (m *Response) GetResponse() string {
 	return ""
 }
 
+func (m *Response) GetButtons() []*Button {
+	if m != nil {
+		return m.Buttons
+	}
+	return nil
+}
+
+func (m *Response) GetTranslations() map[string]string {
+	if m != nil {
+		return m.Translations
+	}
+	return nil
+}
+
+func (m *Response) GetVariants() []*Variant {
+	if m != nil {
+		return m.Variants
+	}
+	return nil
+}
+
+func (m *Response) GetSelection() string {
+	if m != nil {
+		return m.Selection
+	}
+	return ""
+}
+
+// Variant represents an alternative response. Its weight is
+// used by the weighted and sticky selections, 1 if zero.
+type Variant struct {
+	Response string `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
+	Weight   int64  `protobuf:"varint,2,opt,name=weight,proto3" json:"weight,omitempty"`
+	// Translations of the variant keyed by BCP 47 tag, see Response.
+	Translations         map[string]string `protobuf:"bytes,3,rep,name=translations,proto3" json:"translations,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *Variant) Reset()         { *m = Variant{} }
+func (m *Variant) String() string { return proto.CompactTextString(m) }
+func (*Variant) ProtoMessage()    {}
+func (*Variant) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{2}
+}
+
+func (m *Variant) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Variant.Unmarshal(m, b)
+}
+func (m *Variant) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Variant.Marshal(b, m, deterministic)
+}
+func (m *Variant) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Variant.Merge(m, src)
+}
+func (m *Variant) XXX_Size() int {
+	return xxx_messageInfo_Variant.Size(m)
+}
+func (m *Variant) XXX_DiscardUnknown() {
+	xxx_messageInfo_Variant.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Variant proto.InternalMessageInfo
+
+func (m *Variant) GetResponse() string {
+	if m != nil {
+		return m.Response
+	}
+	return ""
+}
+
+func (m *Variant) GetWeight() int64 {
+	if m != nil {
+		return m.Weight
+	}
+	return 0
+}
+
+func (m *Variant) GetTranslations() map[string]string {
+	if m != nil {
+		return m.Translations
+	}
+	return nil
+}
+
+// Button represents a button that triggers another command when pressed.
+type Button struct {
+	Text                 string   `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Command              string   `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Button) Reset()         { *m = Button{} }
+func (m *Button) String() string { return proto.CompactTextString(m) }
+func (*Button) ProtoMessage()    {}
+func (*Button) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{3}
+}
+
+func (m *Button) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Button.Unmarshal(m, b)
+}
+func (m *Button) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Button.Marshal(b, m, deterministic)
+}
+func (m *Button) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Button.Merge(m, src)
+}
+func (m *Button) XXX_Size() int {
+	return xxx_messageInfo_Button.Size(m)
+}
+func (m *Button) XXX_DiscardUnknown() {
+	xxx_messageInfo_Button.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Button proto.InternalMessageInfo
+
+func (m *Button) GetText() string {
+	if m != nil {
+		return m.Text
+	}
+	return ""
+}
+
+func (m *Button) GetCommand() string {
+	if m != nil {
+		return m.Command
+	}
+	return ""
+}
+
 // BotCommand is a encapsulates a command's name and his
 // response.
 type BotCommand struct {
-	Cmd                  *Command  `protobuf:"bytes,1,opt,name=cmd,proto3" json:"cmd,omitempty"`
-	Resp                 *Response `protobuf:"bytes,2,opt,name=resp,proto3" json:"resp,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
+	Cmd  *Command  `protobuf:"bytes,1,opt,name=cmd,proto3" json:"cmd,omitempty"`
+	Resp *Response `protobuf:"bytes,2,opt,name=resp,proto3" json:"resp,omitempty"`
+	// Short explanation of what the command does.
+	Description string `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	// Hidden commands are not listed by the bots.
+	Hidden bool `protobuf:"varint,4,opt,name=hidden,proto3" json:"hidden,omitempty"`
+	// Dialog started by the command instead of answering with its response.
+	Flow *Flow `protobuf:"bytes,5,opt,name=flow,proto3" json:"flow,omitempty"`
+	// Other names that resolve to the same command.
+	Aliases []string `protobuf:"bytes,6,rep,name=aliases,proto3" json:"aliases,omitempty"`
+	// Version of the stored command, increased on every change. When
+	// updating a command it must match the stored one unless it is zero.
+	Version int64 `protobuf:"varint,7,opt,name=version,proto3" json:"version,omitempty"`
+	// HTTP endpoint that provides the response of the command.
+	Callout *Callout `protobuf:"bytes,8,opt,name=callout,proto3" json:"callout,omitempty"`
+	// Starlark script that computes the response of the command. It
+	// must define a respond function that receives the context of the
+	// request, with its args, user, chat, platform, lang and command,
+	// and returns the response.
+	Script string `protobuf:"bytes,9,opt,name=script,proto3" json:"script,omitempty"`
+	// Patterns of the messages, besides the command itself,
+	// that are answered with the response of the command.
+	Triggers []*Trigger `protobuf:"bytes,10,rep,name=triggers,proto3" json:"triggers,omitempty"`
+	// Who can use the command. Commands without rules can be used by anyone.
+	Access               *Access  `protobuf:"bytes,11,opt,name=access,proto3" json:"access,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *BotCommand) Reset()         { *m = BotCommand{} }
 func (m *BotCommand) String() string { return proto.CompactTextString(m) }
 func (*BotCommand) ProtoMessage()    {}
 func (*BotCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0dff099eb2e3dfdb, []int{2}
+	return fileDescriptor_0dff099eb2e3dfdb, []int{4}
 }
 
 func (m *BotCommand) XXX_Unmarshal(b []byte) error {
@@ -155,237 +377,2998 @@ func (m *BotCommand) GetResp() *Response {
 	return nil
 }
 
-// BotCommands represents a list of BotCommands.
-type BotCommands struct {
-	Commands             []*BotCommand `protobuf:"bytes,1,rep,name=commands,proto3" json:"commands,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
-	XXX_unrecognized     []byte        `json:"-"`
-	XXX_sizecache        int32         `json:"-"`
+func (m *BotCommand) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
 }
 
-func (m *BotCommands) Reset()         { *m = BotCommands{} }
-func (m *BotCommands) String() string { return proto.CompactTextString(m) }
-func (*BotCommands) ProtoMessage()    {}
-func (*BotCommands) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0dff099eb2e3dfdb, []int{3}
+func (m *BotCommand) GetHidden() bool {
+	if m != nil {
+		return m.Hidden
+	}
+	return false
 }
 
-func (m *BotCommands) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_BotCommands.Unmarshal(m, b)
-}
-func (m *BotCommands) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_BotCommands.Marshal(b, m, deterministic)
-}
-func (m *BotCommands) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_BotCommands.Merge(m, src)
-}
-func (m *BotCommands) XXX_Size() int {
-	return xxx_messageInfo_BotCommands.Size(m)
+func (m *BotCommand) GetFlow() *Flow {
+	if m != nil {
+		return m.Flow
+	}
+	return nil
 }
-func (m *BotCommands) XXX_DiscardUnknown() {
-	xxx_messageInfo_BotCommands.DiscardUnknown(m)
+
+func (m *BotCommand) GetAliases() []string {
+	if m != nil {
+		return m.Aliases
+	}
+	return nil
 }
 
-var xxx_messageInfo_BotCommands proto.InternalMessageInfo
+func (m *BotCommand) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
 
-func (m *BotCommands) GetCommands() []*BotCommand {
+func (m *BotCommand) GetCallout() *Callout {
 	if m != nil {
-		return m.Commands
+		return m.Callout
 	}
 	return nil
 }
 
-func init() {
-	proto.RegisterType((*Command)(nil), "proto.Command")
-	proto.RegisterType((*Response)(nil), "proto.Response")
-	proto.RegisterType((*BotCommand)(nil), "proto.BotCommand")
-	proto.RegisterType((*BotCommands)(nil), "proto.BotCommands")
+func (m *BotCommand) GetScript() string {
+	if m != nil {
+		return m.Script
+	}
+	return ""
 }
 
-func init() { proto.RegisterFile("commands.proto", fileDescriptor_0dff099eb2e3dfdb) }
+func (m *BotCommand) GetTriggers() []*Trigger {
+	if m != nil {
+		return m.Triggers
+	}
+	return nil
+}
 
-var fileDescriptor_0dff099eb2e3dfdb = []byte{
-	// 358 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x90, 0xc1, 0x4a, 0xc3, 0x40,
-	0x10, 0x86, 0x49, 0x6b, 0x6d, 0x9d, 0xda, 0xaa, 0x03, 0x4a, 0xd8, 0x16, 0x29, 0x5b, 0x90, 0x5a,
-	0x30, 0xc1, 0x78, 0x13, 0x2f, 0x56, 0xc5, 0x8b, 0xa7, 0xb4, 0x9e, 0x3c, 0xa5, 0xcd, 0x5a, 0x02,
-	0x4d, 0x36, 0x34, 0xab, 0x20, 0xe2, 0xc5, 0x57, 0xf0, 0xb5, 0xbc, 0xf9, 0x0a, 0x3e, 0x88, 0x64,
-	0xb3, 0xdb, 0x40, 0x62, 0x3d, 0x78, 0xda, 0x1d, 0xe6, 0x9f, 0x6f, 0xfe, 0xf9, 0xa1, 0x3d, 0xe3,
-	0x61, 0xe8, 0x45, 0x7e, 0x62, 0xc5, 0x4b, 0x2e, 0x38, 0xd6, 0xe4, 0x43, 0xba, 0x73, 0xce, 0xe7,
-	0x0b, 0x66, 0x7b, 0x71, 0x60, 0x7b, 0x51, 0xc4, 0x85, 0x27, 0x02, 0x1e, 0x29, 0x11, 0xe9, 0xa8,
-	0xae, 0xac, 0xa6, 0x4f, 0x8f, 0x36, 0x0b, 0x63, 0xf1, 0x92, 0x35, 0x69, 0x1f, 0xea, 0x57, 0x19,
-	0x13, 0x4d, 0xa8, 0x2b, 0xbc, 0x69, 0xf4, 0x8c, 0xc1, 0x96, 0xab, 0x4b, 0x7a, 0x04, 0x0d, 0x97,
-	0x25, 0x31, 0x8f, 0x12, 0x86, 0x04, 0x1a, 0x4b, 0xf5, 0x57, 0xb2, 0x55, 0x4d, 0xc7, 0x00, 0x23,
-	0x2e, 0x34, 0xaf, 0x07, 0xd5, 0x59, 0x98, 0xb1, 0x9a, 0x4e, 0x3b, 0xdb, 0x67, 0xa9, 0xa6, 0x9b,
-	0xb6, 0xb0, 0x0f, 0x1b, 0xe9, 0xac, 0x59, 0x91, 0x92, 0x1d, 0x25, 0xd1, 0xab, 0x5c, 0xd9, 0xa4,
-	0x17, 0xd0, 0xcc, 0xa1, 0x09, 0x9e, 0x40, 0x43, 0x87, 0x60, 0x1a, 0xbd, 0xea, 0xa0, 0xe9, 0xec,
-	0xa9, 0xb9, 0x5c, 0xe5, 0xae, 0x24, 0xce, 0x67, 0x15, 0x6a, 0x23, 0x2e, 0x02, 0x8e, 0x13, 0x80,
-	0x4b, 0xdf, 0xd7, 0xe6, 0xca, 0x43, 0xe4, 0xc0, 0xca, 0x82, 0xb2, 0x74, 0x50, 0xd6, 0x4d, 0x1a,
-	0x14, 0xed, 0xbc, 0x7f, 0x7d, 0x7f, 0x54, 0xf6, 0xe9, 0xae, 0xcc, 0xf7, 0xf9, 0xd4, 0xd6, 0xf0,
-	0x73, 0x63, 0x88, 0x63, 0x80, 0x5b, 0xb6, 0x3a, 0xb9, 0x70, 0x25, 0x29, 0x6f, 0xa1, 0x54, 0xd2,
-	0xba, 0x48, 0x8a, 0x34, 0xfb, 0x55, 0xfd, 0xde, 0x70, 0x02, 0xdb, 0x77, 0x41, 0x92, 0xdf, 0xbc,
-	0xc6, 0x19, 0xc1, 0x12, 0x3e, 0xa1, 0xa6, 0xe4, 0x23, 0x96, 0xdc, 0x22, 0x83, 0xd6, 0x7d, 0xec,
-	0x7b, 0x82, 0xfd, 0x23, 0x83, 0x63, 0x49, 0xed, 0x3b, 0x87, 0xbf, 0xb8, 0x0e, 0x7d, 0x4b, 0x3b,
-	0x4f, 0x13, 0x79, 0x80, 0xd6, 0x35, 0x5b, 0xb0, 0x7c, 0x4d, 0x31, 0x94, 0x75, 0x3b, 0x54, 0x32,
-	0xc3, 0x3f, 0x92, 0x99, 0x6e, 0xca, 0x99, 0xb3, 0x9f, 0x00, 0x00, 0x00, 0xff, 0xff, 0xae, 0xc9,
-	0x28, 0x70, 0x09, 0x03, 0x00, 0x00,
+func (m *BotCommand) GetAccess() *Access {
+	if m != nil {
+		return m.Access
+	}
+	return nil
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+// Access represents the rules that decide who can use a command. Denied
+// chats and users can never use it. If there are allowed chats, users
+// or roles the caller must be in them. A chat type of "private" only
+// allows direct messages and "group" only allows the other chats.
+type Access struct {
+	AllowedChats []string `protobuf:"bytes,1,rep,name=allowed_chats,json=allowedChats,proto3" json:"allowed_chats,omitempty"`
+	DeniedChats  []string `protobuf:"bytes,2,rep,name=denied_chats,json=deniedChats,proto3" json:"denied_chats,omitempty"`
+	AllowedUsers []string `protobuf:"bytes,3,rep,name=allowed_users,json=allowedUsers,proto3" json:"allowed_users,omitempty"`
+	DeniedUsers  []string `protobuf:"bytes,4,rep,name=denied_users,json=deniedUsers,proto3" json:"denied_users,omitempty"`
+	// Discord roles of which the user must have at least one.
+	Roles []string `protobuf:"bytes,5,rep,name=roles,proto3" json:"roles,omitempty"`
+	// Only the administrators of the chat can use the command.
+	AdminsOnly           bool     `protobuf:"varint,6,opt,name=admins_only,json=adminsOnly,proto3" json:"admins_only,omitempty"`
+	ChatType             string   `protobuf:"bytes,7,opt,name=chat_type,json=chatType,proto3" json:"chat_type,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (m *Access) Reset()         { *m = Access{} }
+func (m *Access) String() string { return proto.CompactTextString(m) }
+func (*Access) ProtoMessage()    {}
+func (*Access) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{5}
+}
 
-// BotioClient is the client API for Botio service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type BotioClient interface {
-	AddCommand(ctx context.Context, in *BotCommand, opts ...grpc.CallOption) (*empty.Empty, error)
-	GetCommand(ctx context.Context, in *Command, opts ...grpc.CallOption) (*BotCommand, error)
-	ListCommands(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*BotCommands, error)
-	UpdateCommand(ctx context.Context, in *BotCommand, opts ...grpc.CallOption) (*empty.Empty, error)
-	DeleteCommand(ctx context.Context, in *Command, opts ...grpc.CallOption) (*empty.Empty, error)
+func (m *Access) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Access.Unmarshal(m, b)
+}
+func (m *Access) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Access.Marshal(b, m, deterministic)
+}
+func (m *Access) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Access.Merge(m, src)
+}
+func (m *Access) XXX_Size() int {
+	return xxx_messageInfo_Access.Size(m)
+}
+func (m *Access) XXX_DiscardUnknown() {
+	xxx_messageInfo_Access.DiscardUnknown(m)
 }
 
-type botioClient struct {
-	cc *grpc.ClientConn
+var xxx_messageInfo_Access proto.InternalMessageInfo
+
+func (m *Access) GetAllowedChats() []string {
+	if m != nil {
+		return m.AllowedChats
+	}
+	return nil
 }
 
-func NewBotioClient(cc *grpc.ClientConn) BotioClient {
-	return &botioClient{cc}
+func (m *Access) GetDeniedChats() []string {
+	if m != nil {
+		return m.DeniedChats
+	}
+	return nil
 }
 
-func (c *botioClient) AddCommand(ctx context.Context, in *BotCommand, opts ...grpc.CallOption) (*empty.Empty, error) {
-	out := new(empty.Empty)
-	err := c.cc.Invoke(ctx, "/proto.Botio/AddCommand", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *Access) GetAllowedUsers() []string {
+	if m != nil {
+		return m.AllowedUsers
 	}
-	return out, nil
+	return nil
 }
 
-func (c *botioClient) GetCommand(ctx context.Context, in *Command, opts ...grpc.CallOption) (*BotCommand, error) {
-	out := new(BotCommand)
-	err := c.cc.Invoke(ctx, "/proto.Botio/GetCommand", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *Access) GetDeniedUsers() []string {
+	if m != nil {
+		return m.DeniedUsers
 	}
-	return out, nil
+	return nil
 }
 
-func (c *botioClient) ListCommands(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*BotCommands, error) {
-	out := new(BotCommands)
-	err := c.cc.Invoke(ctx, "/proto.Botio/ListCommands", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *Access) GetRoles() []string {
+	if m != nil {
+		return m.Roles
 	}
-	return out, nil
+	return nil
 }
 
-func (c *botioClient) UpdateCommand(ctx context.Context, in *BotCommand, opts ...grpc.CallOption) (*empty.Empty, error) {
-	out := new(empty.Empty)
-	err := c.cc.Invoke(ctx, "/proto.Botio/UpdateCommand", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *Access) GetAdminsOnly() bool {
+	if m != nil {
+		return m.AdminsOnly
 	}
-	return out, nil
+	return false
 }
 
-func (c *botioClient) DeleteCommand(ctx context.Context, in *Command, opts ...grpc.CallOption) (*empty.Empty, error) {
-	out := new(empty.Empty)
-	err := c.cc.Invoke(ctx, "/proto.Botio/DeleteCommand", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *Access) GetChatType() string {
+	if m != nil {
+		return m.ChatType
 	}
-	return out, nil
+	return ""
 }
 
-// BotioServer is the server API for Botio service.
-type BotioServer interface {
-	AddCommand(context.Context, *BotCommand) (*empty.Empty, error)
-	GetCommand(context.Context, *Command) (*BotCommand, error)
-	ListCommands(context.Context, *empty.Empty) (*BotCommands, error)
-	UpdateCommand(context.Context, *BotCommand) (*empty.Empty, error)
-	DeleteCommand(context.Context, *Command) (*empty.Empty, error)
+// Trigger represents a pattern of the messages that are answered
+// with the response of a command even if they don't mention the
+// bot. The type is "exact", when the message must be the pattern,
+// "prefix", when it must start with it, "keyword", when it must
+// contain it as whole words, all of them ignoring the case, or
+// "regex", when it must match the pattern as a Go regular
+// expression whose named groups are available to the response
+// as a Go text/template, like "Deploying {{.env}}".
+type Trigger struct {
+	Type                 string   `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Pattern              string   `protobuf:"bytes,2,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-// UnimplementedBotioServer can be embedded to have forward compatible implementations.
-type UnimplementedBotioServer struct {
+func (m *Trigger) Reset()         { *m = Trigger{} }
+func (m *Trigger) String() string { return proto.CompactTextString(m) }
+func (*Trigger) ProtoMessage()    {}
+func (*Trigger) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{6}
 }
 
-func (*UnimplementedBotioServer) AddCommand(ctx context.Context, req *BotCommand) (*empty.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method AddCommand not implemented")
+func (m *Trigger) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Trigger.Unmarshal(m, b)
 }
-func (*UnimplementedBotioServer) GetCommand(ctx context.Context, req *Command) (*BotCommand, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetCommand not implemented")
+func (m *Trigger) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Trigger.Marshal(b, m, deterministic)
 }
-func (*UnimplementedBotioServer) ListCommands(ctx context.Context, req *empty.Empty) (*BotCommands, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ListCommands not implemented")
+func (m *Trigger) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Trigger.Merge(m, src)
 }
-func (*UnimplementedBotioServer) UpdateCommand(ctx context.Context, req *BotCommand) (*empty.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method UpdateCommand not implemented")
+func (m *Trigger) XXX_Size() int {
+	return xxx_messageInfo_Trigger.Size(m)
 }
-func (*UnimplementedBotioServer) DeleteCommand(ctx context.Context, req *Command) (*empty.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method DeleteCommand not implemented")
+func (m *Trigger) XXX_DiscardUnknown() {
+	xxx_messageInfo_Trigger.DiscardUnknown(m)
 }
 
-func RegisterBotioServer(s *grpc.Server, srv BotioServer) {
-	s.RegisterService(&_Botio_serviceDesc, srv)
-}
+var xxx_messageInfo_Trigger proto.InternalMessageInfo
 
-func _Botio_AddCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+func (m *Trigger) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Trigger) GetPattern() string {
+	if m != nil {
+		return m.Pattern
+	}
+	return ""
+}
+
+// MatchRequest represents a message sent by a Caller
+// that may match the trigger of a command.
+type MatchRequest struct {
+	Text string `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	// Languages of the user, see Command.
+	Lang                 string   `protobuf:"bytes,2,opt,name=lang,proto3" json:"lang,omitempty"`
+	Caller               *Caller  `protobuf:"bytes,3,opt,name=caller,proto3" json:"caller,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MatchRequest) Reset()         { *m = MatchRequest{} }
+func (m *MatchRequest) String() string { return proto.CompactTextString(m) }
+func (*MatchRequest) ProtoMessage()    {}
+func (*MatchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{7}
+}
+
+func (m *MatchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MatchRequest.Unmarshal(m, b)
+}
+func (m *MatchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MatchRequest.Marshal(b, m, deterministic)
+}
+func (m *MatchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MatchRequest.Merge(m, src)
+}
+func (m *MatchRequest) XXX_Size() int {
+	return xxx_messageInfo_MatchRequest.Size(m)
+}
+func (m *MatchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MatchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MatchRequest proto.InternalMessageInfo
+
+func (m *MatchRequest) GetText() string {
+	if m != nil {
+		return m.Text
+	}
+	return ""
+}
+
+func (m *MatchRequest) GetLang() string {
+	if m != nil {
+		return m.Lang
+	}
+	return ""
+}
+
+func (m *MatchRequest) GetCaller() *Caller {
+	if m != nil {
+		return m.Caller
+	}
+	return nil
+}
+
+// Callout represents an HTTP endpoint that provides the response of a
+// command. The endpoint receives a POST with the command, its arguments
+// and its caller as JSON, and its JSON answer is rendered through the
+// template, a Go text/template, to get the response. Without a template
+// the answer is used as it is.
+type Callout struct {
+	Url      string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Template string `protobuf:"bytes,2,opt,name=template,proto3" json:"template,omitempty"`
+	// Milliseconds to wait for the endpoint, 5000 if zero.
+	TimeoutMs int64 `protobuf:"varint,3,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
+	// Seconds during which a response is reused for the
+	// same request. Zero calls the endpoint every time.
+	CacheTtl int64 `protobuf:"varint,4,opt,name=cache_ttl,json=cacheTtl,proto3" json:"cache_ttl,omitempty"`
+	// Response used when the endpoint fails. If empty the
+	// command fails too.
+	Fallback string `protobuf:"bytes,5,opt,name=fallback,proto3" json:"fallback,omitempty"`
+	// Shared cache reuses the responses for the same arguments
+	// no matter who sent them. Otherwise the responses are
+	// only reused for the same platform, chat and user.
+	SharedCache          bool     `protobuf:"varint,6,opt,name=shared_cache,json=sharedCache,proto3" json:"shared_cache,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Callout) Reset()         { *m = Callout{} }
+func (m *Callout) String() string { return proto.CompactTextString(m) }
+func (*Callout) ProtoMessage()    {}
+func (*Callout) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{8}
+}
+
+func (m *Callout) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Callout.Unmarshal(m, b)
+}
+func (m *Callout) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Callout.Marshal(b, m, deterministic)
+}
+func (m *Callout) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Callout.Merge(m, src)
+}
+func (m *Callout) XXX_Size() int {
+	return xxx_messageInfo_Callout.Size(m)
+}
+func (m *Callout) XXX_DiscardUnknown() {
+	xxx_messageInfo_Callout.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Callout proto.InternalMessageInfo
+
+func (m *Callout) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *Callout) GetTemplate() string {
+	if m != nil {
+		return m.Template
+	}
+	return ""
+}
+
+func (m *Callout) GetTimeoutMs() int64 {
+	if m != nil {
+		return m.TimeoutMs
+	}
+	return 0
+}
+
+func (m *Callout) GetCacheTtl() int64 {
+	if m != nil {
+		return m.CacheTtl
+	}
+	return 0
+}
+
+func (m *Callout) GetFallback() string {
+	if m != nil {
+		return m.Fallback
+	}
+	return ""
+}
+
+func (m *Callout) GetSharedCache() bool {
+	if m != nil {
+		return m.SharedCache
+	}
+	return false
+}
+
+// Flow represents a dialog made of steps. Each step sends its prompt
+// and waits for an answer that decides which step comes next. Steps
+// without branches nor next step end the dialog with their prompt.
+type Flow struct {
+	// Name of the first step.
+	Start string           `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	Steps map[string]*Step `protobuf:"bytes,2,rep,name=steps,proto3" json:"steps,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Answer that ends the dialog at any step.
+	Cancel               string   `protobuf:"bytes,3,opt,name=cancel,proto3" json:"cancel,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Flow) Reset()         { *m = Flow{} }
+func (m *Flow) String() string { return proto.CompactTextString(m) }
+func (*Flow) ProtoMessage()    {}
+func (*Flow) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{9}
+}
+
+func (m *Flow) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Flow.Unmarshal(m, b)
+}
+func (m *Flow) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Flow.Marshal(b, m, deterministic)
+}
+func (m *Flow) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Flow.Merge(m, src)
+}
+func (m *Flow) XXX_Size() int {
+	return xxx_messageInfo_Flow.Size(m)
+}
+func (m *Flow) XXX_DiscardUnknown() {
+	xxx_messageInfo_Flow.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Flow proto.InternalMessageInfo
+
+func (m *Flow) GetStart() string {
+	if m != nil {
+		return m.Start
+	}
+	return ""
+}
+
+func (m *Flow) GetSteps() map[string]*Step {
+	if m != nil {
+		return m.Steps
+	}
+	return nil
+}
+
+func (m *Flow) GetCancel() string {
+	if m != nil {
+		return m.Cancel
+	}
+	return ""
+}
+
+// Step represents a prompt of a Flow.
+type Step struct {
+	Prompt string `protobuf:"bytes,1,opt,name=prompt,proto3" json:"prompt,omitempty"`
+	// Step that follows each expected answer. Answers are matched ignoring case.
+	Branches map[string]string `protobuf:"bytes,2,rep,name=branches,proto3" json:"branches,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Step that follows any other answer. If empty, unexpected
+	// answers are answered with the retry prompt.
+	Next                 string   `protobuf:"bytes,3,opt,name=next,proto3" json:"next,omitempty"`
+	Retry                string   `protobuf:"bytes,4,opt,name=retry,proto3" json:"retry,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Step) Reset()         { *m = Step{} }
+func (m *Step) String() string { return proto.CompactTextString(m) }
+func (*Step) ProtoMessage()    {}
+func (*Step) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{10}
+}
+
+func (m *Step) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Step.Unmarshal(m, b)
+}
+func (m *Step) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Step.Marshal(b, m, deterministic)
+}
+func (m *Step) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Step.Merge(m, src)
+}
+func (m *Step) XXX_Size() int {
+	return xxx_messageInfo_Step.Size(m)
+}
+func (m *Step) XXX_DiscardUnknown() {
+	xxx_messageInfo_Step.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Step proto.InternalMessageInfo
+
+func (m *Step) GetPrompt() string {
+	if m != nil {
+		return m.Prompt
+	}
+	return ""
+}
+
+func (m *Step) GetBranches() map[string]string {
+	if m != nil {
+		return m.Branches
+	}
+	return nil
+}
+
+func (m *Step) GetNext() string {
+	if m != nil {
+		return m.Next
+	}
+	return ""
+}
+
+func (m *Step) GetRetry() string {
+	if m != nil {
+		return m.Retry
+	}
+	return ""
+}
+
+// Caller represents who sends a message to a bot.
+type Caller struct {
+	Platform string `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`
+	ChatId   string `protobuf:"bytes,2,opt,name=chat_id,json=chatId,proto3" json:"chat_id,omitempty"`
+	UserId   string `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Discord roles of the user on the guild of the chat.
+	Roles []string `protobuf:"bytes,4,rep,name=roles,proto3" json:"roles,omitempty"`
+	// Admin is true if the user administers the chat.
+	Admin bool `protobuf:"varint,5,opt,name=admin,proto3" json:"admin,omitempty"`
+	// Private is true if the chat is a direct message with the bot.
+	Private              bool     `protobuf:"varint,6,opt,name=private,proto3" json:"private,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Caller) Reset()         { *m = Caller{} }
+func (m *Caller) String() string { return proto.CompactTextString(m) }
+func (*Caller) ProtoMessage()    {}
+func (*Caller) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{11}
+}
+
+func (m *Caller) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Caller.Unmarshal(m, b)
+}
+func (m *Caller) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Caller.Marshal(b, m, deterministic)
+}
+func (m *Caller) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Caller.Merge(m, src)
+}
+func (m *Caller) XXX_Size() int {
+	return xxx_messageInfo_Caller.Size(m)
+}
+func (m *Caller) XXX_DiscardUnknown() {
+	xxx_messageInfo_Caller.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Caller proto.InternalMessageInfo
+
+func (m *Caller) GetPlatform() string {
+	if m != nil {
+		return m.Platform
+	}
+	return ""
+}
+
+func (m *Caller) GetChatId() string {
+	if m != nil {
+		return m.ChatId
+	}
+	return ""
+}
+
+func (m *Caller) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *Caller) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *Caller) GetAdmin() bool {
+	if m != nil {
+		return m.Admin
+	}
+	return false
+}
+
+func (m *Caller) GetPrivate() bool {
+	if m != nil {
+		return m.Private
+	}
+	return false
+}
+
+// ConverseRequest represents a message sent by a Caller
+// that may start or advance a dialog.
+type ConverseRequest struct {
+	Caller               *Caller  `protobuf:"bytes,1,opt,name=caller,proto3" json:"caller,omitempty"`
+	Input                string   `protobuf:"bytes,2,opt,name=input,proto3" json:"input,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ConverseRequest) Reset()         { *m = ConverseRequest{} }
+func (m *ConverseRequest) String() string { return proto.CompactTextString(m) }
+func (*ConverseRequest) ProtoMessage()    {}
+func (*ConverseRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{12}
+}
+
+func (m *ConverseRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConverseRequest.Unmarshal(m, b)
+}
+func (m *ConverseRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConverseRequest.Marshal(b, m, deterministic)
+}
+func (m *ConverseRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConverseRequest.Merge(m, src)
+}
+func (m *ConverseRequest) XXX_Size() int {
+	return xxx_messageInfo_ConverseRequest.Size(m)
+}
+func (m *ConverseRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConverseRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConverseRequest proto.InternalMessageInfo
+
+func (m *ConverseRequest) GetCaller() *Caller {
+	if m != nil {
+		return m.Caller
+	}
+	return nil
+}
+
+func (m *ConverseRequest) GetInput() string {
+	if m != nil {
+		return m.Input
+	}
+	return ""
+}
+
+// ConverseResponse represents the answer to a ConverseRequest.
+type ConverseResponse struct {
+	Response *Response `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
+	// Done is true when the dialog has ended.
+	Done bool `protobuf:"varint,2,opt,name=done,proto3" json:"done,omitempty"`
+	// Time after which the dialog ends if the
+	// user doesn't answer, while it's not done.
+	Ttl                  *duration.Duration `protobuf:"bytes,3,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *ConverseResponse) Reset()         { *m = ConverseResponse{} }
+func (m *ConverseResponse) String() string { return proto.CompactTextString(m) }
+func (*ConverseResponse) ProtoMessage()    {}
+func (*ConverseResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{13}
+}
+
+func (m *ConverseResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConverseResponse.Unmarshal(m, b)
+}
+func (m *ConverseResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConverseResponse.Marshal(b, m, deterministic)
+}
+func (m *ConverseResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConverseResponse.Merge(m, src)
+}
+func (m *ConverseResponse) XXX_Size() int {
+	return xxx_messageInfo_ConverseResponse.Size(m)
+}
+func (m *ConverseResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConverseResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConverseResponse proto.InternalMessageInfo
+
+func (m *ConverseResponse) GetResponse() *Response {
+	if m != nil {
+		return m.Response
+	}
+	return nil
+}
+
+func (m *ConverseResponse) GetDone() bool {
+	if m != nil {
+		return m.Done
+	}
+	return false
+}
+
+func (m *ConverseResponse) GetTtl() *duration.Duration {
+	if m != nil {
+		return m.Ttl
+	}
+	return nil
+}
+
+// BotCommands represents a list of BotCommands.
+type BotCommands struct {
+	Commands             []*BotCommand `protobuf:"bytes,1,rep,name=commands,proto3" json:"commands,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *BotCommands) Reset()         { *m = BotCommands{} }
+func (m *BotCommands) String() string { return proto.CompactTextString(m) }
+func (*BotCommands) ProtoMessage()    {}
+func (*BotCommands) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{14}
+}
+
+func (m *BotCommands) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BotCommands.Unmarshal(m, b)
+}
+func (m *BotCommands) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BotCommands.Marshal(b, m, deterministic)
+}
+func (m *BotCommands) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BotCommands.Merge(m, src)
+}
+func (m *BotCommands) XXX_Size() int {
+	return xxx_messageInfo_BotCommands.Size(m)
+}
+func (m *BotCommands) XXX_DiscardUnknown() {
+	xxx_messageInfo_BotCommands.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BotCommands proto.InternalMessageInfo
+
+func (m *BotCommands) GetCommands() []*BotCommand {
+	if m != nil {
+		return m.Commands
+	}
+	return nil
+}
+
+// SearchRequest represents a search of the visible commands
+// whose name or description contain the query.
+type SearchRequest struct {
+	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Maximum number of commands returned. Zero means no limit.
+	Limit int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Who searches the commands. The commands that it can't use,
+	// or the ones with access rules if it's empty, are left out.
+	// With a caller the responses are the ones that would be sent
+	// to it and the commands with callouts or scripts are left out.
+	Caller *Caller `protobuf:"bytes,3,opt,name=caller,proto3" json:"caller,omitempty"`
+	// Languages of the caller, see Command.
+	Lang                 string   `protobuf:"bytes,4,opt,name=lang,proto3" json:"lang,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SearchRequest) Reset()         { *m = SearchRequest{} }
+func (m *SearchRequest) String() string { return proto.CompactTextString(m) }
+func (*SearchRequest) ProtoMessage()    {}
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{15}
+}
+
+func (m *SearchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SearchRequest.Unmarshal(m, b)
+}
+func (m *SearchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SearchRequest.Marshal(b, m, deterministic)
+}
+func (m *SearchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchRequest.Merge(m, src)
+}
+func (m *SearchRequest) XXX_Size() int {
+	return xxx_messageInfo_SearchRequest.Size(m)
+}
+func (m *SearchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchRequest proto.InternalMessageInfo
+
+func (m *SearchRequest) GetQuery() string {
+	if m != nil {
+		return m.Query
+	}
+	return ""
+}
+
+func (m *SearchRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *SearchRequest) GetCaller() *Caller {
+	if m != nil {
+		return m.Caller
+	}
+	return nil
+}
+
+func (m *SearchRequest) GetLang() string {
+	if m != nil {
+		return m.Lang
+	}
+	return ""
+}
+
+// ResolveRequest represents a command, maybe mistyped, to resolve.
+type ResolveRequest struct {
+	Command string `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	// Maximum number of suggestions returned. Zero means no limit.
+	Limit int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Languages of the user, see Command.
+	Lang string `protobuf:"bytes,3,opt,name=lang,proto3" json:"lang,omitempty"`
+	// Who sends the command. Commands that it can't use are not resolved.
+	Caller *Caller `protobuf:"bytes,4,opt,name=caller,proto3" json:"caller,omitempty"`
+	// Arguments of the command, passed to the callout or the script of the match.
+	Args                 []string `protobuf:"bytes,5,rep,name=args,proto3" json:"args,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResolveRequest) Reset()         { *m = ResolveRequest{} }
+func (m *ResolveRequest) String() string { return proto.CompactTextString(m) }
+func (*ResolveRequest) ProtoMessage()    {}
+func (*ResolveRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{16}
+}
+
+func (m *ResolveRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResolveRequest.Unmarshal(m, b)
+}
+func (m *ResolveRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResolveRequest.Marshal(b, m, deterministic)
+}
+func (m *ResolveRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResolveRequest.Merge(m, src)
+}
+func (m *ResolveRequest) XXX_Size() int {
+	return xxx_messageInfo_ResolveRequest.Size(m)
+}
+func (m *ResolveRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResolveRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResolveRequest proto.InternalMessageInfo
+
+func (m *ResolveRequest) GetCommand() string {
+	if m != nil {
+		return m.Command
+	}
+	return ""
+}
+
+func (m *ResolveRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *ResolveRequest) GetLang() string {
+	if m != nil {
+		return m.Lang
+	}
+	return ""
+}
+
+func (m *ResolveRequest) GetCaller() *Caller {
+	if m != nil {
+		return m.Caller
+	}
+	return nil
+}
+
+func (m *ResolveRequest) GetArgs() []string {
+	if m != nil {
+		return m.Args
+	}
+	return nil
+}
+
+// ResolveResponse represents the command that matches a ResolveRequest,
+// if any, or the commands that are similar to it ranked by similarity.
+type ResolveResponse struct {
+	Match                *BotCommand `protobuf:"bytes,1,opt,name=match,proto3" json:"match,omitempty"`
+	Suggestions          []string    `protobuf:"bytes,2,rep,name=suggestions,proto3" json:"suggestions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *ResolveResponse) Reset()         { *m = ResolveResponse{} }
+func (m *ResolveResponse) String() string { return proto.CompactTextString(m) }
+func (*ResolveResponse) ProtoMessage()    {}
+func (*ResolveResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{17}
+}
+
+func (m *ResolveResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResolveResponse.Unmarshal(m, b)
+}
+func (m *ResolveResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResolveResponse.Marshal(b, m, deterministic)
+}
+func (m *ResolveResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResolveResponse.Merge(m, src)
+}
+func (m *ResolveResponse) XXX_Size() int {
+	return xxx_messageInfo_ResolveResponse.Size(m)
+}
+func (m *ResolveResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResolveResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResolveResponse proto.InternalMessageInfo
+
+func (m *ResolveResponse) GetMatch() *BotCommand {
+	if m != nil {
+		return m.Match
+	}
+	return nil
+}
+
+func (m *ResolveResponse) GetSuggestions() []string {
+	if m != nil {
+		return m.Suggestions
+	}
+	return nil
+}
+
+// Revision represents a change made to a command. Revisions are
+// numbered from one for each command in the order they were made.
+type Revision struct {
+	Number int64 `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	// Subject of the JWT used to make the change, if any.
+	Author string               `protobuf:"bytes,2,opt,name=author,proto3" json:"author,omitempty"`
+	Time   *timestamp.Timestamp `protobuf:"bytes,3,opt,name=time,proto3" json:"time,omitempty"`
+	// Kind of change: "add", "update", "delete" or "rollback".
+	Action string `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`
+	// The command after the change. Empty for deletions.
+	Command *BotCommand `protobuf:"bytes,5,opt,name=command,proto3" json:"command,omitempty"`
+	// Lines removed from and added to the command by the change.
+	Diff                 string   `protobuf:"bytes,6,opt,name=diff,proto3" json:"diff,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Revision) Reset()         { *m = Revision{} }
+func (m *Revision) String() string { return proto.CompactTextString(m) }
+func (*Revision) ProtoMessage()    {}
+func (*Revision) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{18}
+}
+
+func (m *Revision) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Revision.Unmarshal(m, b)
+}
+func (m *Revision) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Revision.Marshal(b, m, deterministic)
+}
+func (m *Revision) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Revision.Merge(m, src)
+}
+func (m *Revision) XXX_Size() int {
+	return xxx_messageInfo_Revision.Size(m)
+}
+func (m *Revision) XXX_DiscardUnknown() {
+	xxx_messageInfo_Revision.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Revision proto.InternalMessageInfo
+
+func (m *Revision) GetNumber() int64 {
+	if m != nil {
+		return m.Number
+	}
+	return 0
+}
+
+func (m *Revision) GetAuthor() string {
+	if m != nil {
+		return m.Author
+	}
+	return ""
+}
+
+func (m *Revision) GetTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
+func (m *Revision) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+func (m *Revision) GetCommand() *BotCommand {
+	if m != nil {
+		return m.Command
+	}
+	return nil
+}
+
+func (m *Revision) GetDiff() string {
+	if m != nil {
+		return m.Diff
+	}
+	return ""
+}
+
+// Revisions represents the history of a command.
+type Revisions struct {
+	Revisions            []*Revision `protobuf:"bytes,1,rep,name=revisions,proto3" json:"revisions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *Revisions) Reset()         { *m = Revisions{} }
+func (m *Revisions) String() string { return proto.CompactTextString(m) }
+func (*Revisions) ProtoMessage()    {}
+func (*Revisions) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{19}
+}
+
+func (m *Revisions) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Revisions.Unmarshal(m, b)
+}
+func (m *Revisions) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Revisions.Marshal(b, m, deterministic)
+}
+func (m *Revisions) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Revisions.Merge(m, src)
+}
+func (m *Revisions) XXX_Size() int {
+	return xxx_messageInfo_Revisions.Size(m)
+}
+func (m *Revisions) XXX_DiscardUnknown() {
+	xxx_messageInfo_Revisions.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Revisions proto.InternalMessageInfo
+
+func (m *Revisions) GetRevisions() []*Revision {
+	if m != nil {
+		return m.Revisions
+	}
+	return nil
+}
+
+// RollbackRequest represents a request to restore
+// a command as it was after one of its revisions.
+type RollbackRequest struct {
+	Command              string   `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	Revision             int64    `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RollbackRequest) Reset()         { *m = RollbackRequest{} }
+func (m *RollbackRequest) String() string { return proto.CompactTextString(m) }
+func (*RollbackRequest) ProtoMessage()    {}
+func (*RollbackRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{20}
+}
+
+func (m *RollbackRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RollbackRequest.Unmarshal(m, b)
+}
+func (m *RollbackRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RollbackRequest.Marshal(b, m, deterministic)
+}
+func (m *RollbackRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RollbackRequest.Merge(m, src)
+}
+func (m *RollbackRequest) XXX_Size() int {
+	return xxx_messageInfo_RollbackRequest.Size(m)
+}
+func (m *RollbackRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RollbackRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RollbackRequest proto.InternalMessageInfo
+
+func (m *RollbackRequest) GetCommand() string {
+	if m != nil {
+		return m.Command
+	}
+	return ""
+}
+
+func (m *RollbackRequest) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+// AuditEvent represents a change made to the commands, recorded
+// with who made it, from where and the command before and after it.
+type AuditEvent struct {
+	Id int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Kind of change: "add", "update", "delete" or "rollback".
+	Action  string `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	Command string `protobuf:"bytes,3,opt,name=command,proto3" json:"command,omitempty"`
+	// Subject of the JWT used to make the change, if any.
+	Subject string `protobuf:"bytes,4,opt,name=subject,proto3" json:"subject,omitempty"`
+	// Address of the client that made the change.
+	Peer string               `protobuf:"bytes,5,opt,name=peer,proto3" json:"peer,omitempty"`
+	Time *timestamp.Timestamp `protobuf:"bytes,6,opt,name=time,proto3" json:"time,omitempty"`
+	// The command before the change. Empty for additions.
+	Before *BotCommand `protobuf:"bytes,7,opt,name=before,proto3" json:"before,omitempty"`
+	// The command after the change. Empty for deletions.
+	After                *BotCommand `protobuf:"bytes,8,opt,name=after,proto3" json:"after,omitempty"`
+	RequestId            string      `protobuf:"bytes,9,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *AuditEvent) Reset()         { *m = AuditEvent{} }
+func (m *AuditEvent) String() string { return proto.CompactTextString(m) }
+func (*AuditEvent) ProtoMessage()    {}
+func (*AuditEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{21}
+}
+
+func (m *AuditEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditEvent.Unmarshal(m, b)
+}
+func (m *AuditEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditEvent.Marshal(b, m, deterministic)
+}
+func (m *AuditEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditEvent.Merge(m, src)
+}
+func (m *AuditEvent) XXX_Size() int {
+	return xxx_messageInfo_AuditEvent.Size(m)
+}
+func (m *AuditEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditEvent proto.InternalMessageInfo
+
+func (m *AuditEvent) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *AuditEvent) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetCommand() string {
+	if m != nil {
+		return m.Command
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetPeer() string {
+	if m != nil {
+		return m.Peer
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
+func (m *AuditEvent) GetBefore() *BotCommand {
+	if m != nil {
+		return m.Before
+	}
+	return nil
+}
+
+func (m *AuditEvent) GetAfter() *BotCommand {
+	if m != nil {
+		return m.After
+	}
+	return nil
+}
+
+func (m *AuditEvent) GetRequestId() string {
+	if m != nil {
+		return m.RequestId
+	}
+	return ""
+}
+
+// AuditEvents represents a list of audit events
+// from the oldest to the newest.
+type AuditEvents struct {
+	Events               []*AuditEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *AuditEvents) Reset()         { *m = AuditEvents{} }
+func (m *AuditEvents) String() string { return proto.CompactTextString(m) }
+func (*AuditEvents) ProtoMessage()    {}
+func (*AuditEvents) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{22}
+}
+
+func (m *AuditEvents) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditEvents.Unmarshal(m, b)
+}
+func (m *AuditEvents) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditEvents.Marshal(b, m, deterministic)
+}
+func (m *AuditEvents) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditEvents.Merge(m, src)
+}
+func (m *AuditEvents) XXX_Size() int {
+	return xxx_messageInfo_AuditEvents.Size(m)
+}
+func (m *AuditEvents) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditEvents.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditEvents proto.InternalMessageInfo
+
+func (m *AuditEvents) GetEvents() []*AuditEvent {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+// AuditFilter represents the conditions that the listed audit
+// events must meet. Empty fields match every event and a limit
+// greater than zero keeps only the newest events.
+type AuditFilter struct {
+	Command              string               `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	Subject              string               `protobuf:"bytes,2,opt,name=subject,proto3" json:"subject,omitempty"`
+	Action               string               `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	Since                *timestamp.Timestamp `protobuf:"bytes,4,opt,name=since,proto3" json:"since,omitempty"`
+	Until                *timestamp.Timestamp `protobuf:"bytes,5,opt,name=until,proto3" json:"until,omitempty"`
+	Limit                int32                `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *AuditFilter) Reset()         { *m = AuditFilter{} }
+func (m *AuditFilter) String() string { return proto.CompactTextString(m) }
+func (*AuditFilter) ProtoMessage()    {}
+func (*AuditFilter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{23}
+}
+
+func (m *AuditFilter) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditFilter.Unmarshal(m, b)
+}
+func (m *AuditFilter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditFilter.Marshal(b, m, deterministic)
+}
+func (m *AuditFilter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditFilter.Merge(m, src)
+}
+func (m *AuditFilter) XXX_Size() int {
+	return xxx_messageInfo_AuditFilter.Size(m)
+}
+func (m *AuditFilter) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditFilter.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditFilter proto.InternalMessageInfo
+
+func (m *AuditFilter) GetCommand() string {
+	if m != nil {
+		return m.Command
+	}
+	return ""
+}
+
+func (m *AuditFilter) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
+func (m *AuditFilter) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+func (m *AuditFilter) GetSince() *timestamp.Timestamp {
+	if m != nil {
+		return m.Since
+	}
+	return nil
+}
+
+func (m *AuditFilter) GetUntil() *timestamp.Timestamp {
+	if m != nil {
+		return m.Until
+	}
+	return nil
+}
+
+func (m *AuditFilter) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+// Webhook represents an URL that receives a signed JSON payload
+// every time a command changes. Only the changes whose action is
+// on events are sent, or every change if events is empty.
+type Webhook struct {
+	Id     string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Url    string   `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Events []string `protobuf:"bytes,3,rep,name=events,proto3" json:"events,omitempty"`
+	// Key of the HMAC-SHA256 signature of the payloads.
+	// It is never returned once the webhook is registered.
+	Secret               string               `protobuf:"bytes,4,opt,name=secret,proto3" json:"secret,omitempty"`
+	Created              *timestamp.Timestamp `protobuf:"bytes,5,opt,name=created,proto3" json:"created,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Webhook) Reset()         { *m = Webhook{} }
+func (m *Webhook) String() string { return proto.CompactTextString(m) }
+func (*Webhook) ProtoMessage()    {}
+func (*Webhook) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{24}
+}
+
+func (m *Webhook) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Webhook.Unmarshal(m, b)
+}
+func (m *Webhook) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Webhook.Marshal(b, m, deterministic)
+}
+func (m *Webhook) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Webhook.Merge(m, src)
+}
+func (m *Webhook) XXX_Size() int {
+	return xxx_messageInfo_Webhook.Size(m)
+}
+func (m *Webhook) XXX_DiscardUnknown() {
+	xxx_messageInfo_Webhook.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Webhook proto.InternalMessageInfo
+
+func (m *Webhook) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Webhook) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *Webhook) GetEvents() []string {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+func (m *Webhook) GetSecret() string {
+	if m != nil {
+		return m.Secret
+	}
+	return ""
+}
+
+func (m *Webhook) GetCreated() *timestamp.Timestamp {
+	if m != nil {
+		return m.Created
+	}
+	return nil
+}
+
+// Webhooks represents a list of webhooks.
+type Webhooks struct {
+	Webhooks             []*Webhook `protobuf:"bytes,1,rep,name=webhooks,proto3" json:"webhooks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *Webhooks) Reset()         { *m = Webhooks{} }
+func (m *Webhooks) String() string { return proto.CompactTextString(m) }
+func (*Webhooks) ProtoMessage()    {}
+func (*Webhooks) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{25}
+}
+
+func (m *Webhooks) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Webhooks.Unmarshal(m, b)
+}
+func (m *Webhooks) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Webhooks.Marshal(b, m, deterministic)
+}
+func (m *Webhooks) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Webhooks.Merge(m, src)
+}
+func (m *Webhooks) XXX_Size() int {
+	return xxx_messageInfo_Webhooks.Size(m)
+}
+func (m *Webhooks) XXX_DiscardUnknown() {
+	xxx_messageInfo_Webhooks.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Webhooks proto.InternalMessageInfo
+
+func (m *Webhooks) GetWebhooks() []*Webhook {
+	if m != nil {
+		return m.Webhooks
+	}
+	return nil
+}
+
+// WebhookDelivery represents the delivery of a
+// change made to a command to one of the webhooks.
+type WebhookDelivery struct {
+	Id       int64                `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Webhook  string               `protobuf:"bytes,2,opt,name=webhook,proto3" json:"webhook,omitempty"`
+	Action   string               `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	Command  string               `protobuf:"bytes,4,opt,name=command,proto3" json:"command,omitempty"`
+	Time     *timestamp.Timestamp `protobuf:"bytes,5,opt,name=time,proto3" json:"time,omitempty"`
+	Attempts int32                `protobuf:"varint,6,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	// HTTP status code of the last attempt, if any.
+	Status int32 `protobuf:"varint,7,opt,name=status,proto3" json:"status,omitempty"`
+	// Error of the last attempt, if any.
+	Error     string `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	Delivered bool   `protobuf:"varint,9,opt,name=delivered,proto3" json:"delivered,omitempty"`
+	// Dead is true when the delivery failed after every retry.
+	Dead bool `protobuf:"varint,10,opt,name=dead,proto3" json:"dead,omitempty"`
+	// JSON payload posted to the webhook, kept to redeliver it.
+	Payload              string   `protobuf:"bytes,11,opt,name=payload,proto3" json:"payload,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WebhookDelivery) Reset()         { *m = WebhookDelivery{} }
+func (m *WebhookDelivery) String() string { return proto.CompactTextString(m) }
+func (*WebhookDelivery) ProtoMessage()    {}
+func (*WebhookDelivery) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{26}
+}
+
+func (m *WebhookDelivery) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WebhookDelivery.Unmarshal(m, b)
+}
+func (m *WebhookDelivery) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WebhookDelivery.Marshal(b, m, deterministic)
+}
+func (m *WebhookDelivery) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WebhookDelivery.Merge(m, src)
+}
+func (m *WebhookDelivery) XXX_Size() int {
+	return xxx_messageInfo_WebhookDelivery.Size(m)
+}
+func (m *WebhookDelivery) XXX_DiscardUnknown() {
+	xxx_messageInfo_WebhookDelivery.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WebhookDelivery proto.InternalMessageInfo
+
+func (m *WebhookDelivery) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *WebhookDelivery) GetWebhook() string {
+	if m != nil {
+		return m.Webhook
+	}
+	return ""
+}
+
+func (m *WebhookDelivery) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+func (m *WebhookDelivery) GetCommand() string {
+	if m != nil {
+		return m.Command
+	}
+	return ""
+}
+
+func (m *WebhookDelivery) GetTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
+func (m *WebhookDelivery) GetAttempts() int32 {
+	if m != nil {
+		return m.Attempts
+	}
+	return 0
+}
+
+func (m *WebhookDelivery) GetStatus() int32 {
+	if m != nil {
+		return m.Status
+	}
+	return 0
+}
+
+func (m *WebhookDelivery) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *WebhookDelivery) GetDelivered() bool {
+	if m != nil {
+		return m.Delivered
+	}
+	return false
+}
+
+func (m *WebhookDelivery) GetDead() bool {
+	if m != nil {
+		return m.Dead
+	}
+	return false
+}
+
+func (m *WebhookDelivery) GetPayload() string {
+	if m != nil {
+		return m.Payload
+	}
+	return ""
+}
+
+// WebhookDeliveries represents a list of webhook
+// deliveries from the oldest to the newest.
+type WebhookDeliveries struct {
+	Deliveries           []*WebhookDelivery `protobuf:"bytes,1,rep,name=deliveries,proto3" json:"deliveries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *WebhookDeliveries) Reset()         { *m = WebhookDeliveries{} }
+func (m *WebhookDeliveries) String() string { return proto.CompactTextString(m) }
+func (*WebhookDeliveries) ProtoMessage()    {}
+func (*WebhookDeliveries) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{27}
+}
+
+func (m *WebhookDeliveries) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WebhookDeliveries.Unmarshal(m, b)
+}
+func (m *WebhookDeliveries) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WebhookDeliveries.Marshal(b, m, deterministic)
+}
+func (m *WebhookDeliveries) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WebhookDeliveries.Merge(m, src)
+}
+func (m *WebhookDeliveries) XXX_Size() int {
+	return xxx_messageInfo_WebhookDeliveries.Size(m)
+}
+func (m *WebhookDeliveries) XXX_DiscardUnknown() {
+	xxx_messageInfo_WebhookDeliveries.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WebhookDeliveries proto.InternalMessageInfo
+
+func (m *WebhookDeliveries) GetDeliveries() []*WebhookDelivery {
+	if m != nil {
+		return m.Deliveries
+	}
+	return nil
+}
+
+// DeliveryFilter represents the conditions that the listed deliveries
+// must meet. An empty webhook matches every webhook and dead keeps
+// only the deliveries that failed after every retry.
+type DeliveryFilter struct {
+	Webhook              string   `protobuf:"bytes,1,opt,name=webhook,proto3" json:"webhook,omitempty"`
+	Dead                 bool     `protobuf:"varint,2,opt,name=dead,proto3" json:"dead,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeliveryFilter) Reset()         { *m = DeliveryFilter{} }
+func (m *DeliveryFilter) String() string { return proto.CompactTextString(m) }
+func (*DeliveryFilter) ProtoMessage()    {}
+func (*DeliveryFilter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{28}
+}
+
+func (m *DeliveryFilter) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeliveryFilter.Unmarshal(m, b)
+}
+func (m *DeliveryFilter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeliveryFilter.Marshal(b, m, deterministic)
+}
+func (m *DeliveryFilter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeliveryFilter.Merge(m, src)
+}
+func (m *DeliveryFilter) XXX_Size() int {
+	return xxx_messageInfo_DeliveryFilter.Size(m)
+}
+func (m *DeliveryFilter) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeliveryFilter.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeliveryFilter proto.InternalMessageInfo
+
+func (m *DeliveryFilter) GetWebhook() string {
+	if m != nil {
+		return m.Webhook
+	}
+	return ""
+}
+
+func (m *DeliveryFilter) GetDead() bool {
+	if m != nil {
+		return m.Dead
+	}
+	return false
+}
+
+// Schedule represents a message sent by the bots of a platform to
+// some of its chats every time its cron expression matches. Without
+// a cron expression the message is sent only once, as soon as possible.
+// The message is the response of the command, if any, or the response.
+type Schedule struct {
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Cron expression with minute, hour, day of month, month and day of week.
+	Cron string `protobuf:"bytes,2,opt,name=cron,proto3" json:"cron,omitempty"`
+	// Platform of the chats, "telegram" or "discord".
+	Platform string               `protobuf:"bytes,3,opt,name=platform,proto3" json:"platform,omitempty"`
+	Chats    []string             `protobuf:"bytes,4,rep,name=chats,proto3" json:"chats,omitempty"`
+	Response string               `protobuf:"bytes,5,opt,name=response,proto3" json:"response,omitempty"`
+	Command  string               `protobuf:"bytes,6,opt,name=command,proto3" json:"command,omitempty"`
+	Created  *timestamp.Timestamp `protobuf:"bytes,7,opt,name=created,proto3" json:"created,omitempty"`
+	// Name of the bot that sends it, so only the bots, and replicas
+	// of a bot, started with that name send it. Empty for the bots
+	// started without a name.
+	Bot                  string   `protobuf:"bytes,8,opt,name=bot,proto3" json:"bot,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Schedule) Reset()         { *m = Schedule{} }
+func (m *Schedule) String() string { return proto.CompactTextString(m) }
+func (*Schedule) ProtoMessage()    {}
+func (*Schedule) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{29}
+}
+
+func (m *Schedule) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Schedule.Unmarshal(m, b)
+}
+func (m *Schedule) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Schedule.Marshal(b, m, deterministic)
+}
+func (m *Schedule) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Schedule.Merge(m, src)
+}
+func (m *Schedule) XXX_Size() int {
+	return xxx_messageInfo_Schedule.Size(m)
+}
+func (m *Schedule) XXX_DiscardUnknown() {
+	xxx_messageInfo_Schedule.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Schedule proto.InternalMessageInfo
+
+func (m *Schedule) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Schedule) GetCron() string {
+	if m != nil {
+		return m.Cron
+	}
+	return ""
+}
+
+func (m *Schedule) GetPlatform() string {
+	if m != nil {
+		return m.Platform
+	}
+	return ""
+}
+
+func (m *Schedule) GetChats() []string {
+	if m != nil {
+		return m.Chats
+	}
+	return nil
+}
+
+func (m *Schedule) GetResponse() string {
+	if m != nil {
+		return m.Response
+	}
+	return ""
+}
+
+func (m *Schedule) GetCommand() string {
+	if m != nil {
+		return m.Command
+	}
+	return ""
+}
+
+func (m *Schedule) GetCreated() *timestamp.Timestamp {
+	if m != nil {
+		return m.Created
+	}
+	return nil
+}
+
+func (m *Schedule) GetBot() string {
+	if m != nil {
+		return m.Bot
+	}
+	return ""
+}
+
+// Schedules represents a list of scheduled messages.
+type Schedules struct {
+	Schedules            []*Schedule `protobuf:"bytes,1,rep,name=schedules,proto3" json:"schedules,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *Schedules) Reset()         { *m = Schedules{} }
+func (m *Schedules) String() string { return proto.CompactTextString(m) }
+func (*Schedules) ProtoMessage()    {}
+func (*Schedules) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{30}
+}
+
+func (m *Schedules) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Schedules.Unmarshal(m, b)
+}
+func (m *Schedules) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Schedules.Marshal(b, m, deterministic)
+}
+func (m *Schedules) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Schedules.Merge(m, src)
+}
+func (m *Schedules) XXX_Size() int {
+	return xxx_messageInfo_Schedules.Size(m)
+}
+func (m *Schedules) XXX_DiscardUnknown() {
+	xxx_messageInfo_Schedules.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Schedules proto.InternalMessageInfo
+
+func (m *Schedules) GetSchedules() []*Schedule {
+	if m != nil {
+		return m.Schedules
+	}
+	return nil
+}
+
+// ScheduleClaim represents the claim of a replica of a bot to send
+// the scheduled message with the id at the minute, so no other
+// replica sends it too.
+type ScheduleClaim struct {
+	Id                   string               `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Minute               *timestamp.Timestamp `protobuf:"bytes,2,opt,name=minute,proto3" json:"minute,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *ScheduleClaim) Reset()         { *m = ScheduleClaim{} }
+func (m *ScheduleClaim) String() string { return proto.CompactTextString(m) }
+func (*ScheduleClaim) ProtoMessage()    {}
+func (*ScheduleClaim) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{31}
+}
+
+func (m *ScheduleClaim) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ScheduleClaim.Unmarshal(m, b)
+}
+func (m *ScheduleClaim) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ScheduleClaim.Marshal(b, m, deterministic)
+}
+func (m *ScheduleClaim) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ScheduleClaim.Merge(m, src)
+}
+func (m *ScheduleClaim) XXX_Size() int {
+	return xxx_messageInfo_ScheduleClaim.Size(m)
+}
+func (m *ScheduleClaim) XXX_DiscardUnknown() {
+	xxx_messageInfo_ScheduleClaim.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ScheduleClaim proto.InternalMessageInfo
+
+func (m *ScheduleClaim) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *ScheduleClaim) GetMinute() *timestamp.Timestamp {
+	if m != nil {
+		return m.Minute
+	}
+	return nil
+}
+
+// UsageEvent represents the outcome of the lookup of a command
+// requested to a bot: "hit" if it was answered, "miss" if it
+// doesn't exist or "error" if the lookup failed.
+type UsageEvent struct {
+	Command   string `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	Outcome   string `protobuf:"bytes,2,opt,name=outcome,proto3" json:"outcome,omitempty"`
+	LatencyMs int64  `protobuf:"varint,3,opt,name=latency_ms,json=latencyMs,proto3" json:"latency_ms,omitempty"`
+	Platform  string `protobuf:"bytes,4,opt,name=platform,proto3" json:"platform,omitempty"`
+	// Anonymized ID of the chat in which the command was requested.
+	Chat                 string               `protobuf:"bytes,5,opt,name=chat,proto3" json:"chat,omitempty"`
+	Time                 *timestamp.Timestamp `protobuf:"bytes,6,opt,name=time,proto3" json:"time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *UsageEvent) Reset()         { *m = UsageEvent{} }
+func (m *UsageEvent) String() string { return proto.CompactTextString(m) }
+func (*UsageEvent) ProtoMessage()    {}
+func (*UsageEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{32}
+}
+
+func (m *UsageEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UsageEvent.Unmarshal(m, b)
+}
+func (m *UsageEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UsageEvent.Marshal(b, m, deterministic)
+}
+func (m *UsageEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UsageEvent.Merge(m, src)
+}
+func (m *UsageEvent) XXX_Size() int {
+	return xxx_messageInfo_UsageEvent.Size(m)
+}
+func (m *UsageEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_UsageEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UsageEvent proto.InternalMessageInfo
+
+func (m *UsageEvent) GetCommand() string {
+	if m != nil {
+		return m.Command
+	}
+	return ""
+}
+
+func (m *UsageEvent) GetOutcome() string {
+	if m != nil {
+		return m.Outcome
+	}
+	return ""
+}
+
+func (m *UsageEvent) GetLatencyMs() int64 {
+	if m != nil {
+		return m.LatencyMs
+	}
+	return 0
+}
+
+func (m *UsageEvent) GetPlatform() string {
+	if m != nil {
+		return m.Platform
+	}
+	return ""
+}
+
+func (m *UsageEvent) GetChat() string {
+	if m != nil {
+		return m.Chat
+	}
+	return ""
+}
+
+func (m *UsageEvent) GetTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
+// UsageReport represents a batch of usage events reported by a bot.
+type UsageReport struct {
+	Events               []*UsageEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *UsageReport) Reset()         { *m = UsageReport{} }
+func (m *UsageReport) String() string { return proto.CompactTextString(m) }
+func (*UsageReport) ProtoMessage()    {}
+func (*UsageReport) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{33}
+}
+
+func (m *UsageReport) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UsageReport.Unmarshal(m, b)
+}
+func (m *UsageReport) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UsageReport.Marshal(b, m, deterministic)
+}
+func (m *UsageReport) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UsageReport.Merge(m, src)
+}
+func (m *UsageReport) XXX_Size() int {
+	return xxx_messageInfo_UsageReport.Size(m)
+}
+func (m *UsageReport) XXX_DiscardUnknown() {
+	xxx_messageInfo_UsageReport.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UsageReport proto.InternalMessageInfo
+
+func (m *UsageReport) GetEvents() []*UsageEvent {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+// UsageStatsRequest represents a request for the usage stats of the
+// last days, seven if zero, keeping the limit most used and most missed
+// commands, ten if zero.
+type UsageStatsRequest struct {
+	Days                 int32    `protobuf:"varint,1,opt,name=days,proto3" json:"days,omitempty"`
+	Limit                int32    `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UsageStatsRequest) Reset()         { *m = UsageStatsRequest{} }
+func (m *UsageStatsRequest) String() string { return proto.CompactTextString(m) }
+func (*UsageStatsRequest) ProtoMessage()    {}
+func (*UsageStatsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{34}
+}
+
+func (m *UsageStatsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UsageStatsRequest.Unmarshal(m, b)
+}
+func (m *UsageStatsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UsageStatsRequest.Marshal(b, m, deterministic)
+}
+func (m *UsageStatsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UsageStatsRequest.Merge(m, src)
+}
+func (m *UsageStatsRequest) XXX_Size() int {
+	return xxx_messageInfo_UsageStatsRequest.Size(m)
+}
+func (m *UsageStatsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UsageStatsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UsageStatsRequest proto.InternalMessageInfo
+
+func (m *UsageStatsRequest) GetDays() int32 {
+	if m != nil {
+		return m.Days
+	}
+	return 0
+}
+
+func (m *UsageStatsRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+// CommandUsage represents how many times a command was requested
+// on the requested period of time and from how many chats.
+type CommandUsage struct {
+	Command              string   `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	Hits                 int64    `protobuf:"varint,2,opt,name=hits,proto3" json:"hits,omitempty"`
+	Misses               int64    `protobuf:"varint,3,opt,name=misses,proto3" json:"misses,omitempty"`
+	Errors               int64    `protobuf:"varint,4,opt,name=errors,proto3" json:"errors,omitempty"`
+	Chats                int64    `protobuf:"varint,5,opt,name=chats,proto3" json:"chats,omitempty"`
+	AvgLatencyMs         int64    `protobuf:"varint,6,opt,name=avg_latency_ms,json=avgLatencyMs,proto3" json:"avg_latency_ms,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CommandUsage) Reset()         { *m = CommandUsage{} }
+func (m *CommandUsage) String() string { return proto.CompactTextString(m) }
+func (*CommandUsage) ProtoMessage()    {}
+func (*CommandUsage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{35}
+}
+
+func (m *CommandUsage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CommandUsage.Unmarshal(m, b)
+}
+func (m *CommandUsage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CommandUsage.Marshal(b, m, deterministic)
+}
+func (m *CommandUsage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CommandUsage.Merge(m, src)
+}
+func (m *CommandUsage) XXX_Size() int {
+	return xxx_messageInfo_CommandUsage.Size(m)
+}
+func (m *CommandUsage) XXX_DiscardUnknown() {
+	xxx_messageInfo_CommandUsage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CommandUsage proto.InternalMessageInfo
+
+func (m *CommandUsage) GetCommand() string {
+	if m != nil {
+		return m.Command
+	}
+	return ""
+}
+
+func (m *CommandUsage) GetHits() int64 {
+	if m != nil {
+		return m.Hits
+	}
+	return 0
+}
+
+func (m *CommandUsage) GetMisses() int64 {
+	if m != nil {
+		return m.Misses
+	}
+	return 0
+}
+
+func (m *CommandUsage) GetErrors() int64 {
+	if m != nil {
+		return m.Errors
+	}
+	return 0
+}
+
+func (m *CommandUsage) GetChats() int64 {
+	if m != nil {
+		return m.Chats
+	}
+	return 0
+}
+
+func (m *CommandUsage) GetAvgLatencyMs() int64 {
+	if m != nil {
+		return m.AvgLatencyMs
+	}
+	return 0
+}
+
+// DayUsage represents the lookups of every command on a day.
+type DayUsage struct {
+	// Day in the form YYYY-MM-DD, in UTC.
+	Day                  string   `protobuf:"bytes,1,opt,name=day,proto3" json:"day,omitempty"`
+	Hits                 int64    `protobuf:"varint,2,opt,name=hits,proto3" json:"hits,omitempty"`
+	Misses               int64    `protobuf:"varint,3,opt,name=misses,proto3" json:"misses,omitempty"`
+	Errors               int64    `protobuf:"varint,4,opt,name=errors,proto3" json:"errors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DayUsage) Reset()         { *m = DayUsage{} }
+func (m *DayUsage) String() string { return proto.CompactTextString(m) }
+func (*DayUsage) ProtoMessage()    {}
+func (*DayUsage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{36}
+}
+
+func (m *DayUsage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DayUsage.Unmarshal(m, b)
+}
+func (m *DayUsage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DayUsage.Marshal(b, m, deterministic)
+}
+func (m *DayUsage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DayUsage.Merge(m, src)
+}
+func (m *DayUsage) XXX_Size() int {
+	return xxx_messageInfo_DayUsage.Size(m)
+}
+func (m *DayUsage) XXX_DiscardUnknown() {
+	xxx_messageInfo_DayUsage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DayUsage proto.InternalMessageInfo
+
+func (m *DayUsage) GetDay() string {
+	if m != nil {
+		return m.Day
+	}
+	return ""
+}
+
+func (m *DayUsage) GetHits() int64 {
+	if m != nil {
+		return m.Hits
+	}
+	return 0
+}
+
+func (m *DayUsage) GetMisses() int64 {
+	if m != nil {
+		return m.Misses
+	}
+	return 0
+}
+
+func (m *DayUsage) GetErrors() int64 {
+	if m != nil {
+		return m.Errors
+	}
+	return 0
+}
+
+// UsageStats represents the most used commands, the most requested
+// commands that don't exist and the lookups of each day from the
+// oldest to the newest.
+type UsageStats struct {
+	TopCommands          []*CommandUsage `protobuf:"bytes,1,rep,name=top_commands,json=topCommands,proto3" json:"top_commands,omitempty"`
+	TopMissing           []*CommandUsage `protobuf:"bytes,2,rep,name=top_missing,json=topMissing,proto3" json:"top_missing,omitempty"`
+	Days                 []*DayUsage     `protobuf:"bytes,3,rep,name=days,proto3" json:"days,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *UsageStats) Reset()         { *m = UsageStats{} }
+func (m *UsageStats) String() string { return proto.CompactTextString(m) }
+func (*UsageStats) ProtoMessage()    {}
+func (*UsageStats) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{37}
+}
+
+func (m *UsageStats) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UsageStats.Unmarshal(m, b)
+}
+func (m *UsageStats) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UsageStats.Marshal(b, m, deterministic)
+}
+func (m *UsageStats) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UsageStats.Merge(m, src)
+}
+func (m *UsageStats) XXX_Size() int {
+	return xxx_messageInfo_UsageStats.Size(m)
+}
+func (m *UsageStats) XXX_DiscardUnknown() {
+	xxx_messageInfo_UsageStats.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UsageStats proto.InternalMessageInfo
+
+func (m *UsageStats) GetTopCommands() []*CommandUsage {
+	if m != nil {
+		return m.TopCommands
+	}
+	return nil
+}
+
+func (m *UsageStats) GetTopMissing() []*CommandUsage {
+	if m != nil {
+		return m.TopMissing
+	}
+	return nil
+}
+
+func (m *UsageStats) GetDays() []*DayUsage {
+	if m != nil {
+		return m.Days
+	}
+	return nil
+}
+
+// ChangeToken identifies the state of the commands stored on a server.
+// It changes every time a command is added, updated, deleted or rolled
+// back, so clients can poll it to know when to get the commands again.
+type ChangeToken struct {
+	Token                string   `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ChangeToken) Reset()         { *m = ChangeToken{} }
+func (m *ChangeToken) String() string { return proto.CompactTextString(m) }
+func (*ChangeToken) ProtoMessage()    {}
+func (*ChangeToken) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0dff099eb2e3dfdb, []int{38}
+}
+
+func (m *ChangeToken) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChangeToken.Unmarshal(m, b)
+}
+func (m *ChangeToken) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChangeToken.Marshal(b, m, deterministic)
+}
+func (m *ChangeToken) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChangeToken.Merge(m, src)
+}
+func (m *ChangeToken) XXX_Size() int {
+	return xxx_messageInfo_ChangeToken.Size(m)
+}
+func (m *ChangeToken) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChangeToken.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChangeToken proto.InternalMessageInfo
+
+func (m *ChangeToken) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*Command)(nil), "proto.Command")
+	proto.RegisterType((*Response)(nil), "proto.Response")
+	proto.RegisterMapType((map[string]string)(nil), "proto.Response.TranslationsEntry")
+	proto.RegisterType((*Variant)(nil), "proto.Variant")
+	proto.RegisterMapType((map[string]string)(nil), "proto.Variant.TranslationsEntry")
+	proto.RegisterType((*Button)(nil), "proto.Button")
+	proto.RegisterType((*BotCommand)(nil), "proto.BotCommand")
+	proto.RegisterType((*Access)(nil), "proto.Access")
+	proto.RegisterType((*Trigger)(nil), "proto.Trigger")
+	proto.RegisterType((*MatchRequest)(nil), "proto.MatchRequest")
+	proto.RegisterType((*Callout)(nil), "proto.Callout")
+	proto.RegisterType((*Flow)(nil), "proto.Flow")
+	proto.RegisterMapType((map[string]*Step)(nil), "proto.Flow.StepsEntry")
+	proto.RegisterType((*Step)(nil), "proto.Step")
+	proto.RegisterMapType((map[string]string)(nil), "proto.Step.BranchesEntry")
+	proto.RegisterType((*Caller)(nil), "proto.Caller")
+	proto.RegisterType((*ConverseRequest)(nil), "proto.ConverseRequest")
+	proto.RegisterType((*ConverseResponse)(nil), "proto.ConverseResponse")
+	proto.RegisterType((*BotCommands)(nil), "proto.BotCommands")
+	proto.RegisterType((*SearchRequest)(nil), "proto.SearchRequest")
+	proto.RegisterType((*ResolveRequest)(nil), "proto.ResolveRequest")
+	proto.RegisterType((*ResolveResponse)(nil), "proto.ResolveResponse")
+	proto.RegisterType((*Revision)(nil), "proto.Revision")
+	proto.RegisterType((*Revisions)(nil), "proto.Revisions")
+	proto.RegisterType((*RollbackRequest)(nil), "proto.RollbackRequest")
+	proto.RegisterType((*AuditEvent)(nil), "proto.AuditEvent")
+	proto.RegisterType((*AuditEvents)(nil), "proto.AuditEvents")
+	proto.RegisterType((*AuditFilter)(nil), "proto.AuditFilter")
+	proto.RegisterType((*Webhook)(nil), "proto.Webhook")
+	proto.RegisterType((*Webhooks)(nil), "proto.Webhooks")
+	proto.RegisterType((*WebhookDelivery)(nil), "proto.WebhookDelivery")
+	proto.RegisterType((*WebhookDeliveries)(nil), "proto.WebhookDeliveries")
+	proto.RegisterType((*DeliveryFilter)(nil), "proto.DeliveryFilter")
+	proto.RegisterType((*Schedule)(nil), "proto.Schedule")
+	proto.RegisterType((*Schedules)(nil), "proto.Schedules")
+	proto.RegisterType((*ScheduleClaim)(nil), "proto.ScheduleClaim")
+	proto.RegisterType((*UsageEvent)(nil), "proto.UsageEvent")
+	proto.RegisterType((*UsageReport)(nil), "proto.UsageReport")
+	proto.RegisterType((*UsageStatsRequest)(nil), "proto.UsageStatsRequest")
+	proto.RegisterType((*CommandUsage)(nil), "proto.CommandUsage")
+	proto.RegisterType((*DayUsage)(nil), "proto.DayUsage")
+	proto.RegisterType((*UsageStats)(nil), "proto.UsageStats")
+	proto.RegisterType((*ChangeToken)(nil), "proto.ChangeToken")
+}
+
+func init() { proto.RegisterFile("commands.proto", fileDescriptor_0dff099eb2e3dfdb) }
+
+var fileDescriptor_0dff099eb2e3dfdb = []byte{
+	// 2878 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x59, 0xc9, 0x93, 0x1c, 0x47,
+	0xd5, 0xff, 0xaa, 0xb7, 0xe9, 0x7e, 0x3d, 0x6b, 0x7a, 0x34, 0x2a, 0xb5, 0x64, 0xbb, 0x54, 0xf2,
+	0x87, 0xe5, 0xb1, 0xd4, 0x83, 0x07, 0x23, 0x1c, 0x62, 0x0b, 0x69, 0x24, 0x2b, 0x64, 0x3c, 0xb6,
+	0xa9, 0x19, 0xd9, 0x66, 0x8b, 0x21, 0xbb, 0x2b, 0xa7, 0xbb, 0xac, 0xea, 0xaa, 0x72, 0x65, 0x76,
+	0x0f, 0x1d, 0x0e, 0x47, 0x10, 0x1c, 0x08, 0x0e, 0x44, 0x10, 0xc0, 0x95, 0xc0, 0xc1, 0x0d, 0xae,
+	0x70, 0x25, 0x82, 0x03, 0xfc, 0x03, 0x84, 0xef, 0x3e, 0x71, 0xe4, 0xce, 0x95, 0xc8, 0x97, 0x99,
+	0xb5, 0xf4, 0x32, 0x92, 0x81, 0xd3, 0xe4, 0x5b, 0xea, 0xe5, 0xcb, 0xb7, 0xe5, 0x2f, 0x7b, 0x60,
+	0xbd, 0x1f, 0x8f, 0x46, 0x34, 0xf2, 0x79, 0x37, 0x49, 0x63, 0x11, 0x93, 0x3a, 0xfe, 0xe9, 0x5c,
+	0x19, 0xc4, 0xf1, 0x20, 0x64, 0x7b, 0x34, 0x09, 0xf6, 0x68, 0x14, 0xc5, 0x82, 0x8a, 0x20, 0x8e,
+	0xb4, 0x52, 0xe7, 0x39, 0x2d, 0x45, 0xaa, 0x37, 0x3e, 0xdd, 0xf3, 0xc7, 0x29, 0x2a, 0x68, 0xf9,
+	0xe5, 0x59, 0x39, 0x1b, 0x25, 0x62, 0xaa, 0x85, 0xcf, 0xcf, 0x0a, 0x45, 0x30, 0x62, 0x5c, 0xd0,
+	0x51, 0xa2, 0x15, 0x6e, 0xe0, 0x9f, 0xfe, 0xcd, 0x01, 0x8b, 0x6e, 0xf2, 0x33, 0x3a, 0x18, 0xb0,
+	0x74, 0x2f, 0x4e, 0x70, 0xff, 0x79, 0x5f, 0xdc, 0xdf, 0x5a, 0xb0, 0x72, 0xa0, 0xce, 0x40, 0x6c,
+	0x58, 0xd1, 0xc7, 0xb1, 0x2d, 0xc7, 0xba, 0xde, 0xf2, 0x0c, 0x49, 0x08, 0xd4, 0x42, 0x1a, 0x0d,
+	0xec, 0x0a, 0xb2, 0x71, 0x2d, 0xb5, 0x27, 0x2c, 0xe5, 0x41, 0x1c, 0xd9, 0x55, 0xc7, 0xba, 0x5e,
+	0xf5, 0x0c, 0x29, 0xb5, 0x69, 0x3a, 0xe0, 0x76, 0xcd, 0xa9, 0x4a, 0x6d, 0xb9, 0x26, 0xff, 0x0f,
+	0x8d, 0x3e, 0x0d, 0x43, 0x96, 0xda, 0x75, 0xc7, 0xba, 0xde, 0xde, 0x5f, 0x53, 0xfb, 0x77, 0x0f,
+	0x90, 0xe9, 0x69, 0x21, 0xd9, 0x84, 0x6a, 0x4a, 0xcf, 0xec, 0x86, 0x63, 0x5d, 0x6f, 0x7a, 0x72,
+	0xe9, 0xfe, 0xae, 0x02, 0x4d, 0x8f, 0xf1, 0x24, 0x8e, 0x38, 0x23, 0x1d, 0x68, 0xa6, 0x7a, 0xad,
+	0x5d, 0xcc, 0x68, 0xf2, 0x22, 0xac, 0xf4, 0xc6, 0x42, 0xc4, 0x11, 0xb7, 0x2b, 0x4e, 0xb5, 0xb0,
+	0xc5, 0x5d, 0xe4, 0x7a, 0x46, 0x4a, 0xee, 0xc3, 0xaa, 0x48, 0x69, 0xc4, 0x43, 0x15, 0x08, 0xbb,
+	0x8a, 0xda, 0x57, 0xb5, 0xb6, 0xd9, 0xab, 0x7b, 0x5c, 0xd0, 0xb9, 0x1f, 0x89, 0x74, 0xea, 0x95,
+	0x3e, 0x23, 0xbb, 0xd0, 0x9c, 0xd0, 0x34, 0xa0, 0x91, 0x50, 0x27, 0x6d, 0xef, 0xaf, 0x6b, 0x13,
+	0xef, 0x2a, 0xb6, 0x97, 0xc9, 0xc9, 0x15, 0x68, 0x71, 0x16, 0xb2, 0xbe, 0xfc, 0x12, 0x03, 0xd0,
+	0xf2, 0x72, 0x46, 0xe7, 0x9b, 0xb0, 0x35, 0xb7, 0x99, 0x8c, 0xc4, 0x63, 0x36, 0xd5, 0xa7, 0x94,
+	0x4b, 0xb2, 0x0d, 0xf5, 0x09, 0x0d, 0xc7, 0x4c, 0x67, 0x41, 0x11, 0xb7, 0x2b, 0xaf, 0x59, 0xee,
+	0xdf, 0x2c, 0x58, 0xd1, 0x9b, 0x9e, 0x1b, 0xa2, 0x1d, 0x68, 0x9c, 0xb1, 0x60, 0x30, 0x14, 0x68,
+	0xa2, 0xea, 0x69, 0x8a, 0xdc, 0x5b, 0x18, 0x11, 0xa7, 0x7c, 0x9c, 0x27, 0x05, 0xe4, 0xbf, 0x3f,
+	0xc6, 0x2d, 0x68, 0xa8, 0x5c, 0xc9, 0x0a, 0x12, 0xec, 0x47, 0x42, 0x7f, 0x86, 0xeb, 0x62, 0x75,
+	0x56, 0x4a, 0xd5, 0xe9, 0xfe, 0xab, 0x02, 0x70, 0x37, 0x16, 0xa6, 0x8c, 0x1d, 0xa8, 0xf6, 0x47,
+	0xaa, 0x84, 0xf3, 0x9c, 0x68, 0xa1, 0x27, 0x45, 0xe4, 0x1a, 0xd4, 0x64, 0x4c, 0xd0, 0x4e, 0x7b,
+	0x7f, 0x63, 0x26, 0xf3, 0x1e, 0x0a, 0x89, 0x03, 0x6d, 0x9f, 0xf1, 0x7e, 0x1a, 0x24, 0xc2, 0xd4,
+	0x78, 0xcb, 0x2b, 0xb2, 0x64, 0x38, 0x87, 0x81, 0xef, 0xb3, 0xc8, 0xae, 0x61, 0xbd, 0x6a, 0x8a,
+	0x3c, 0x0f, 0xb5, 0xd3, 0x30, 0x3e, 0xd3, 0x95, 0xde, 0xd6, 0xe6, 0x5f, 0x0f, 0xe3, 0x33, 0x0f,
+	0x05, 0xf2, 0x28, 0x34, 0x0c, 0x28, 0x67, 0xdc, 0x6e, 0x60, 0x8f, 0x18, 0xb2, 0xd8, 0x54, 0x2b,
+	0xe5, 0xa6, 0xba, 0x0e, 0x2b, 0xb2, 0x47, 0xe2, 0xb1, 0xb0, 0x9b, 0xe5, 0x93, 0x29, 0xae, 0x67,
+	0xc4, 0xd2, 0x2d, 0xe5, 0xa3, 0xdd, 0x42, 0x9f, 0x35, 0x25, 0x0b, 0x56, 0xa4, 0x81, 0x9c, 0x07,
+	0xdc, 0x86, 0x52, 0xc1, 0x1e, 0x2b, 0xb6, 0x97, 0xc9, 0x65, 0xbb, 0xd2, 0x7e, 0x9f, 0x71, 0x6e,
+	0xb7, 0x4b, 0xed, 0x7a, 0x07, 0x99, 0x9e, 0x16, 0xba, 0xff, 0xb4, 0xa0, 0xa1, 0x58, 0xe4, 0x1a,
+	0xac, 0xc9, 0xfd, 0xcf, 0x98, 0x7f, 0xd2, 0x1f, 0x52, 0xc1, 0x6d, 0x0b, 0x4f, 0xb6, 0xaa, 0x99,
+	0x07, 0x92, 0x47, 0xae, 0xc2, 0xaa, 0xcf, 0xa2, 0x20, 0xd3, 0xa9, 0xa0, 0x4e, 0x5b, 0xf1, 0x94,
+	0x4a, 0xc1, 0xce, 0x98, 0xb3, 0x54, 0x15, 0x63, 0x6e, 0xe7, 0x91, 0xe4, 0x15, 0xec, 0x28, 0x9d,
+	0x5a, 0xd1, 0x8e, 0x52, 0xd9, 0x86, 0x7a, 0x1a, 0x87, 0x8c, 0xdb, 0x75, 0x94, 0x29, 0x82, 0x3c,
+	0x0f, 0x6d, 0xea, 0x8f, 0x82, 0x88, 0x9f, 0xc4, 0x51, 0x38, 0xd5, 0x73, 0x06, 0x14, 0xeb, 0xed,
+	0x28, 0x9c, 0x92, 0xcb, 0xd0, 0x92, 0xae, 0x9d, 0x88, 0x69, 0xc2, 0x30, 0x05, 0x2d, 0xaf, 0x29,
+	0x19, 0xc7, 0xd3, 0x84, 0xb9, 0x5f, 0x81, 0x15, 0x1d, 0x2a, 0xac, 0x50, 0xa9, 0x62, 0x2a, 0x74,
+	0x9a, 0x30, 0x99, 0xbc, 0x84, 0x0a, 0xc1, 0xd2, 0xc8, 0x54, 0xa8, 0x26, 0xdd, 0x1f, 0xc0, 0xea,
+	0x21, 0x15, 0xfd, 0xa1, 0xc7, 0x3e, 0x1c, 0x33, 0x2e, 0x16, 0xd6, 0xf7, 0xa2, 0x19, 0x9b, 0x4f,
+	0xcd, 0xea, 0x39, 0x53, 0xd3, 0xfd, 0xa3, 0x1c, 0xe2, 0x3a, 0xfb, 0x9b, 0x50, 0x1d, 0xa7, 0xa1,
+	0x69, 0xb8, 0x71, 0x1a, 0xca, 0x89, 0x20, 0xd8, 0x28, 0x09, 0xa9, 0x30, 0x3d, 0x97, 0xd1, 0xe4,
+	0x59, 0x00, 0x79, 0x7f, 0xc4, 0x63, 0x71, 0x32, 0xe2, 0x7a, 0x8e, 0xb7, 0x34, 0xe7, 0x90, 0x63,
+	0x34, 0x68, 0x7f, 0xc8, 0x4e, 0x84, 0x08, 0xb1, 0xc8, 0xab, 0x5e, 0x13, 0x19, 0xc7, 0x02, 0xed,
+	0x9e, 0xd2, 0x30, 0xec, 0xd1, 0xfe, 0x63, 0x3d, 0xd3, 0x32, 0x5a, 0x26, 0x88, 0x0f, 0x69, 0x2a,
+	0x13, 0x2d, 0xd5, 0x75, 0xa0, 0xdb, 0x8a, 0x77, 0x20, 0x59, 0xee, 0x1f, 0x2c, 0xa8, 0xc9, 0x9e,
+	0x90, 0x99, 0xe2, 0x82, 0xa6, 0x26, 0x1a, 0x8a, 0x20, 0x37, 0x24, 0x97, 0x25, 0x66, 0x98, 0xef,
+	0x14, 0xba, 0xa8, 0x7b, 0x24, 0x05, 0x6a, 0x04, 0x29, 0x25, 0x59, 0xf3, 0x7d, 0x1a, 0xf5, 0x59,
+	0xa8, 0xfb, 0x54, 0x53, 0x9d, 0xfb, 0x00, 0xb9, 0xf2, 0x82, 0x61, 0x74, 0xb5, 0x38, 0x8c, 0xf2,
+	0x5e, 0x95, 0xdf, 0x14, 0x27, 0xd3, 0x9f, 0x2d, 0xa8, 0x49, 0x9e, 0xdc, 0x27, 0x49, 0xe3, 0x51,
+	0x62, 0x9c, 0xd5, 0x14, 0xf9, 0x32, 0x34, 0x7b, 0x29, 0x8d, 0xfa, 0x43, 0x66, 0x1c, 0xbe, 0x54,
+	0x30, 0xd5, 0xbd, 0xab, 0x65, 0xca, 0xe7, 0x4c, 0x55, 0xe6, 0x3c, 0x92, 0x75, 0xa0, 0x9c, 0xc6,
+	0x35, 0x16, 0x2e, 0x13, 0xe9, 0x14, 0xe3, 0xdd, 0xf2, 0x14, 0xd1, 0xf9, 0x2a, 0xac, 0x95, 0x8c,
+	0x7c, 0xae, 0xc1, 0xfa, 0x1b, 0x0b, 0x1a, 0xaa, 0x64, 0x64, 0xd2, 0x64, 0xe6, 0x4f, 0xe3, 0x74,
+	0x64, 0xae, 0x07, 0x43, 0x93, 0x8b, 0xb0, 0x82, 0xb5, 0x1f, 0x98, 0x09, 0xdb, 0x90, 0xe4, 0x43,
+	0x5f, 0x0a, 0x64, 0x9f, 0x49, 0x81, 0x0e, 0xaf, 0x24, 0x1f, 0xfa, 0x79, 0x93, 0xd5, 0x8a, 0x4d,
+	0xb6, 0x0d, 0x75, 0xec, 0x28, 0xac, 0x8a, 0xa6, 0xa7, 0x08, 0xec, 0x8e, 0x34, 0x98, 0x50, 0x61,
+	0xaa, 0xc1, 0x90, 0xee, 0x5b, 0xb0, 0x71, 0x10, 0x47, 0x72, 0xd0, 0x31, 0xd3, 0x20, 0x79, 0xe1,
+	0x5b, 0xe7, 0xc1, 0x85, 0x6d, 0xa8, 0x07, 0x51, 0x32, 0x16, 0xe6, 0xc8, 0x48, 0xb8, 0x3f, 0xb6,
+	0x60, 0x33, 0x37, 0xa8, 0xef, 0xbe, 0x97, 0x67, 0xee, 0xc5, 0x05, 0x73, 0x3f, 0x53, 0x90, 0x79,
+	0xf1, 0xe3, 0x48, 0x45, 0xb2, 0xe9, 0xe1, 0x9a, 0xbc, 0x0c, 0x55, 0xd9, 0x05, 0xaa, 0x11, 0x2f,
+	0x75, 0x15, 0x0c, 0xeb, 0x1a, 0x18, 0xd6, 0xbd, 0xa7, 0x31, 0x9c, 0x27, 0xb5, 0xdc, 0xaf, 0x41,
+	0x3b, 0xbf, 0x91, 0x38, 0xb9, 0x09, 0x4d, 0x03, 0x14, 0x71, 0x2e, 0xb6, 0xf7, 0xb7, 0x0c, 0x38,
+	0xc9, 0xb4, 0xbc, 0x4c, 0xc5, 0x9d, 0xc0, 0xda, 0x11, 0xa3, 0x69, 0x3e, 0x2f, 0xb6, 0xa1, 0xfe,
+	0xe1, 0x98, 0xa5, 0x26, 0xdd, 0x8a, 0x90, 0xdc, 0x30, 0x18, 0x05, 0xea, 0xf4, 0x75, 0x4f, 0x11,
+	0x4f, 0x39, 0x33, 0xb2, 0x71, 0x53, 0xcb, 0xc7, 0x8d, 0xfb, 0x0b, 0x0b, 0xd6, 0x3d, 0xc6, 0xe3,
+	0x70, 0x92, 0x25, 0x62, 0x39, 0x26, 0x5c, 0xbc, 0xbb, 0x31, 0x5b, 0x5d, 0x38, 0xc5, 0x6a, 0x4f,
+	0xf0, 0x08, 0x61, 0x63, 0x3d, 0x87, 0x8d, 0xee, 0xf7, 0x61, 0x23, 0x73, 0x28, 0xc3, 0x79, 0xf5,
+	0x91, 0x9c, 0xa5, 0x3a, 0x8b, 0x0b, 0x02, 0xa9, 0xe4, 0xf2, 0x02, 0xe7, 0xe3, 0xc1, 0x80, 0x71,
+	0x05, 0x6a, 0xf4, 0x5d, 0x53, 0x60, 0xb9, 0x7f, 0xb5, 0x24, 0xb6, 0x9c, 0x04, 0x5c, 0xdf, 0xe6,
+	0xd1, 0x78, 0xd4, 0xd3, 0x25, 0x57, 0xf5, 0x34, 0x25, 0xf9, 0x74, 0x2c, 0x86, 0x71, 0x6a, 0x9a,
+	0x42, 0x51, 0xa4, 0x0b, 0x35, 0x39, 0x28, 0x75, 0x94, 0x3b, 0x73, 0x05, 0x71, 0x6c, 0x70, 0xb9,
+	0x87, 0x7a, 0x68, 0x47, 0x01, 0xc0, 0x9a, 0xb6, 0x83, 0x14, 0x79, 0x39, 0x8f, 0x70, 0x7d, 0xd9,
+	0x89, 0x8a, 0x40, 0xdc, 0x0f, 0x4e, 0x4f, 0xb1, 0x83, 0x5a, 0x1e, 0xae, 0xdd, 0xdb, 0xd0, 0x32,
+	0x87, 0x90, 0x95, 0xd6, 0x4a, 0x0d, 0xa1, 0x4b, 0x2d, 0xaf, 0x73, 0xc5, 0xf7, 0x72, 0x0d, 0xf7,
+	0x01, 0x6c, 0x78, 0xb1, 0x9a, 0xd9, 0x4f, 0xce, 0x38, 0x42, 0x4b, 0xf5, 0xa5, 0x06, 0x90, 0x19,
+	0xed, 0x7e, 0x52, 0x01, 0xb8, 0x33, 0xf6, 0x03, 0x71, 0x7f, 0xc2, 0x22, 0x41, 0xd6, 0xa1, 0x12,
+	0xf8, 0x3a, 0x90, 0x95, 0xc0, 0x2f, 0x1c, 0xbe, 0x52, 0x3a, 0x7c, 0x61, 0xb3, 0x6a, 0x79, 0x33,
+	0x1b, 0x56, 0xf8, 0xb8, 0xf7, 0x01, 0xeb, 0x0b, 0x1d, 0x2f, 0x43, 0xca, 0x18, 0x24, 0x4c, 0x3f,
+	0x24, 0x5a, 0x1e, 0xae, 0xb3, 0x64, 0x34, 0x9e, 0x32, 0x19, 0x2f, 0x41, 0xa3, 0xc7, 0x4e, 0xe3,
+	0x54, 0xdd, 0xf1, 0x0b, 0x63, 0xae, 0x15, 0x64, 0xbd, 0xd1, 0x53, 0xc1, 0x52, 0xbb, 0xb9, 0x4c,
+	0x53, 0xc9, 0xe5, 0x5d, 0x9a, 0xaa, 0x18, 0xca, 0x41, 0xa9, 0xb0, 0x57, 0x4b, 0x73, 0x1e, 0xfa,
+	0xee, 0x6b, 0xd0, 0xce, 0x03, 0xc4, 0xa5, 0x07, 0x0c, 0x57, 0x33, 0x03, 0x21, 0xd7, 0xf1, 0xb4,
+	0x82, 0xfb, 0xa9, 0xa5, 0x3f, 0x7d, 0x3d, 0x08, 0xe5, 0x46, 0xcb, 0x33, 0x54, 0x08, 0x5a, 0xa5,
+	0x1c, 0xb4, 0x3c, 0x01, 0xd5, 0x52, 0x02, 0xbe, 0x08, 0x75, 0x1e, 0x44, 0x7d, 0x66, 0xd7, 0x9e,
+	0x18, 0x39, 0xa5, 0x28, 0xbf, 0x18, 0x47, 0x22, 0x08, 0xed, 0xfa, 0x93, 0xbf, 0x40, 0xc5, 0x7c,
+	0x52, 0x34, 0x0a, 0x93, 0xc2, 0xfd, 0xa5, 0x05, 0x2b, 0xef, 0xb1, 0xde, 0x30, 0x8e, 0x1f, 0x17,
+	0xca, 0xa5, 0x85, 0xe5, 0xa2, 0x41, 0x4c, 0x25, 0x07, 0x31, 0x3b, 0x59, 0xb8, 0x14, 0x1e, 0xd4,
+	0x94, 0xe4, 0x73, 0xd6, 0x4f, 0x99, 0xa9, 0x12, 0x4d, 0x91, 0x57, 0x61, 0xa5, 0x9f, 0x32, 0x2a,
+	0x98, 0xff, 0x14, 0x7e, 0x1a, 0x55, 0xf7, 0x16, 0x34, 0xb5, 0x4b, 0xf8, 0xbe, 0x3b, 0xd3, 0x6b,
+	0xdb, 0x2a, 0xc1, 0x65, 0xad, 0xe2, 0x65, 0x72, 0xf7, 0x4f, 0x15, 0xd8, 0xd0, 0xdc, 0x7b, 0x2c,
+	0x0c, 0x26, 0x72, 0x3a, 0xcf, 0xb6, 0x80, 0x0d, 0x2b, 0x5a, 0xdf, 0xe4, 0x46, 0x93, 0x4b, 0x73,
+	0x53, 0xc8, 0x73, 0xad, 0x9c, 0x67, 0x53, 0xee, 0xf5, 0xa7, 0x2c, 0xf7, 0x0e, 0x34, 0xa9, 0x90,
+	0xa0, 0x4f, 0x70, 0x9d, 0x84, 0x8c, 0xc6, 0x08, 0x0a, 0x2a, 0xc6, 0x1c, 0x5b, 0xa1, 0xee, 0x69,
+	0x4a, 0x66, 0x8d, 0xa5, 0x69, 0xac, 0xea, 0xbe, 0xe5, 0x29, 0x42, 0xbe, 0x64, 0x7d, 0x75, 0x42,
+	0xa6, 0x6a, 0xbc, 0xe9, 0xe5, 0x0c, 0x1c, 0x4f, 0x8c, 0xfa, 0x36, 0xe8, 0x7b, 0x93, 0x51, 0x5f,
+	0xa1, 0xe2, 0x69, 0x18, 0x53, 0xdf, 0x6e, 0x1b, 0x54, 0x8c, 0xa4, 0xfb, 0x2d, 0xd8, 0x2a, 0x07,
+	0x2d, 0x60, 0x9c, 0xdc, 0x02, 0xf0, 0x33, 0xca, 0xb6, 0x4a, 0xe0, 0x6f, 0x26, 0xc4, 0x5e, 0x41,
+	0xd3, 0xfd, 0x06, 0xac, 0x1b, 0x7e, 0xde, 0x26, 0x26, 0xe0, 0x56, 0x39, 0xe0, 0xc6, 0xcd, 0x4a,
+	0xee, 0xa6, 0xfb, 0x99, 0x05, 0xcd, 0xa3, 0xfe, 0x90, 0xf9, 0xe3, 0x90, 0xcd, 0xd5, 0x23, 0x81,
+	0x5a, 0x3f, 0xcd, 0x86, 0x17, 0xae, 0x4b, 0x48, 0xaa, 0x3a, 0x83, 0xa4, 0xb6, 0xa1, 0xae, 0x1e,
+	0x38, 0x1a, 0x17, 0x21, 0x51, 0x7a, 0x9a, 0xd7, 0x67, 0x9e, 0xe6, 0x85, 0x5c, 0x37, 0xca, 0xb9,
+	0x2e, 0x54, 0xf2, 0xca, 0x53, 0x57, 0xb2, 0xec, 0xa0, 0x5e, 0x2c, 0x74, 0xee, 0xe4, 0x52, 0x5e,
+	0x13, 0xe6, 0x7c, 0x78, 0x4d, 0x70, 0x43, 0xcc, 0x5c, 0x13, 0x46, 0xc9, 0xcb, 0x35, 0xdc, 0x23,
+	0x58, 0x33, 0xec, 0x83, 0x90, 0x06, 0xa3, 0xb9, 0x00, 0xed, 0x43, 0x63, 0x14, 0x44, 0x63, 0x61,
+	0x80, 0xf4, 0x79, 0x3e, 0x6a, 0x4d, 0xf7, 0x2f, 0x16, 0xc0, 0x23, 0x4e, 0x07, 0x4c, 0x5d, 0x19,
+	0xe7, 0x4e, 0xb5, 0x78, 0x2c, 0xfa, 0xf1, 0xc8, 0x40, 0x5b, 0x43, 0xca, 0x91, 0x2b, 0x9f, 0x31,
+	0x51, 0x7f, 0x5a, 0x78, 0xbe, 0x68, 0xce, 0x21, 0x2f, 0xa5, 0xa8, 0x36, 0x93, 0x22, 0x99, 0xd2,
+	0x21, 0x15, 0xe6, 0x16, 0x91, 0xeb, 0xcf, 0x7b, 0x8b, 0xc8, 0x91, 0x8e, 0x07, 0xf0, 0x58, 0x12,
+	0xa7, 0x62, 0xe9, 0x48, 0xcf, 0x0f, 0x99, 0x8d, 0xf4, 0xaf, 0xc3, 0x16, 0x72, 0x8f, 0x04, 0x15,
+	0xbc, 0xf0, 0x2a, 0xf4, 0xe9, 0x94, 0xe3, 0xf1, 0xeb, 0x1e, 0xae, 0x17, 0xa3, 0x2c, 0xf7, 0xf7,
+	0x16, 0xac, 0xea, 0xdb, 0x07, 0xcd, 0x9c, 0xff, 0xd3, 0xdd, 0x30, 0xc0, 0xa7, 0xb6, 0x0c, 0x0e,
+	0xae, 0x65, 0xcb, 0x8f, 0x02, 0xce, 0x99, 0x09, 0x99, 0xa6, 0x24, 0x1f, 0xbb, 0x9c, 0xeb, 0xb7,
+	0x9e, 0xa6, 0xf2, 0x72, 0xae, 0x23, 0x5b, 0x11, 0xe4, 0x05, 0x58, 0xa7, 0x93, 0xc1, 0x49, 0x21,
+	0x01, 0x0d, 0x14, 0xaf, 0xd2, 0xc9, 0xe0, 0x4d, 0x93, 0x03, 0xf7, 0x87, 0xd0, 0xbc, 0x47, 0xa7,
+	0xca, 0xcb, 0x4d, 0xa8, 0xfa, 0x34, 0x7b, 0xb3, 0xf8, 0x74, 0xfa, 0xbf, 0xf0, 0xce, 0xfd, 0xc4,
+	0xd4, 0x11, 0x06, 0x93, 0xdc, 0x82, 0x55, 0x11, 0x27, 0x27, 0x33, 0x78, 0xfb, 0x99, 0xf2, 0xef,
+	0x40, 0x2a, 0x6d, 0x6d, 0x11, 0x27, 0x19, 0x46, 0x7f, 0x15, 0x24, 0x79, 0x22, 0x37, 0x0b, 0xf0,
+	0x19, 0xbe, 0xf4, 0x33, 0x10, 0x71, 0x72, 0xa8, 0xd4, 0xe4, 0x4f, 0x49, 0x98, 0xb3, 0x6a, 0xa9,
+	0x87, 0xcc, 0x89, 0x55, 0x12, 0xdd, 0x6b, 0xd0, 0x3e, 0x18, 0xd2, 0x68, 0xc0, 0x8e, 0xe3, 0xc7,
+	0x2c, 0x92, 0xe1, 0x14, 0x72, 0x61, 0xd0, 0x3c, 0x12, 0xfb, 0x3f, 0xdd, 0x82, 0xfa, 0xdd, 0x58,
+	0x04, 0x31, 0x39, 0x06, 0xb8, 0xe3, 0xfb, 0x7a, 0x4b, 0x32, 0x0f, 0x38, 0x3a, 0x3b, 0x73, 0x95,
+	0x79, 0x5f, 0xfe, 0x42, 0xec, 0x5e, 0xfe, 0xc9, 0xa7, 0xff, 0xf8, 0x75, 0xe5, 0x82, 0xbb, 0x89,
+	0x3f, 0x3c, 0x4f, 0x5e, 0xd9, 0x33, 0x41, 0xb8, 0x6d, 0xed, 0x92, 0x23, 0x80, 0x07, 0xcc, 0x98,
+	0x20, 0x33, 0xbf, 0x8b, 0x75, 0xe6, 0x77, 0x71, 0x5d, 0xb4, 0x76, 0x85, 0x74, 0x66, 0xad, 0xed,
+	0x7d, 0xa4, 0x57, 0x1f, 0x93, 0x63, 0x58, 0x7d, 0x33, 0xe0, 0xf9, 0x43, 0x67, 0x89, 0x67, 0x1d,
+	0x32, 0x67, 0x9e, 0xbb, 0x36, 0xda, 0x27, 0x64, 0xce, 0x5b, 0xf2, 0x08, 0xd6, 0xa5, 0xab, 0x85,
+	0x90, 0x3d, 0xc9, 0x6e, 0x41, 0xd7, 0xbd, 0x88, 0x76, 0xb7, 0xc8, 0x46, 0x66, 0x17, 0x85, 0x9c,
+	0x78, 0xb0, 0xae, 0x9e, 0x55, 0x99, 0xbb, 0xdb, 0x66, 0xe6, 0x15, 0x5f, 0x5b, 0x0b, 0x9d, 0xdd,
+	0x41, 0xa3, 0x9b, 0x64, 0xdd, 0x18, 0xe5, 0xf8, 0x09, 0xe9, 0x65, 0x2f, 0x26, 0x13, 0xd9, 0x0b,
+	0xf9, 0xb3, 0xb2, 0xf0, 0x90, 0xea, 0xec, 0xcc, 0xb2, 0xd5, 0xe0, 0x77, 0xaf, 0xa2, 0xe1, 0xcb,
+	0xe4, 0x92, 0x31, 0x9c, 0x2a, 0x85, 0x42, 0x90, 0xdf, 0x87, 0xa6, 0x79, 0xce, 0x92, 0x9d, 0x2c,
+	0x6f, 0xa5, 0x07, 0x73, 0xe7, 0xe2, 0x1c, 0x5f, 0xdb, 0x5f, 0x50, 0x13, 0x4a, 0x43, 0xd6, 0x04,
+	0x83, 0xb5, 0x47, 0x89, 0x4f, 0x05, 0xfb, 0x0f, 0x8a, 0xed, 0x25, 0x34, 0x7c, 0x6d, 0xff, 0xb9,
+	0x05, 0xe5, 0x31, 0xf2, 0xbb, 0xc6, 0x7b, 0xb9, 0xcd, 0xf7, 0x60, 0xed, 0x1e, 0x0b, 0x99, 0x60,
+	0xcb, 0xaa, 0x6f, 0xd9, 0x1e, 0xba, 0x04, 0x77, 0xcf, 0x2b, 0xc1, 0x53, 0xd8, 0x2e, 0x94, 0x60,
+	0xfe, 0x12, 0x9a, 0xdd, 0x63, 0x73, 0xe6, 0x19, 0xc4, 0xdd, 0x1b, 0x68, 0xfd, 0x0b, 0xe4, 0x85,
+	0xe5, 0xd6, 0xf7, 0xb2, 0xa7, 0x12, 0x09, 0xf3, 0xa7, 0x92, 0x39, 0x46, 0x96, 0xd3, 0xf2, 0x13,
+	0x6a, 0x51, 0x33, 0x75, 0x71, 0xaf, 0xeb, 0xee, 0xb5, 0xf3, 0xf6, 0xd2, 0x66, 0x64, 0xc8, 0xde,
+	0x81, 0x0d, 0x79, 0xaa, 0xe2, 0x8b, 0x81, 0x14, 0x5f, 0x08, 0x0a, 0xe3, 0x74, 0xc8, 0xdc, 0xab,
+	0x81, 0xbb, 0x17, 0x70, 0xab, 0x0d, 0xb2, 0x66, 0xb6, 0xa2, 0x52, 0x48, 0x1e, 0xe2, 0x54, 0xc9,
+	0x10, 0x77, 0x19, 0x52, 0x75, 0x66, 0xe8, 0xf9, 0xb2, 0x31, 0x58, 0x57, 0x3a, 0xf7, 0x6d, 0xd5,
+	0xf5, 0x19, 0x54, 0x5e, 0xd6, 0x9d, 0x1b, 0x65, 0xa3, 0x0b, 0x5a, 0xde, 0x58, 0x25, 0xef, 0x9a,
+	0x12, 0x59, 0xe6, 0xe0, 0xb2, 0x12, 0x79, 0x16, 0x4d, 0x5e, 0xdc, 0xbd, 0x30, 0x6b, 0x72, 0xef,
+	0xa3, 0xc0, 0xff, 0x98, 0xf8, 0x70, 0xa1, 0xe0, 0x6a, 0x01, 0x67, 0x9a, 0x36, 0x2d, 0x83, 0xc6,
+	0x8e, 0xbd, 0x10, 0x6a, 0x4a, 0x80, 0xd9, 0xc1, 0x8d, 0xb6, 0x09, 0x31, 0x1b, 0xe5, 0xe0, 0x93,
+	0x9c, 0xc2, 0xa6, 0xc7, 0x34, 0x6d, 0x0e, 0xb0, 0x04, 0xb4, 0x76, 0x96, 0xf0, 0x4d, 0xad, 0xbb,
+	0x17, 0xe7, 0xed, 0xe3, 0x51, 0x64, 0xe0, 0x0f, 0xa1, 0x7d, 0xc7, 0xf7, 0x33, 0x98, 0x3a, 0x0b,
+	0xd9, 0x3a, 0xb3, 0x0c, 0xf7, 0x0a, 0x1a, 0xdd, 0x71, 0xb7, 0xb2, 0xb1, 0xa5, 0x25, 0x98, 0xc7,
+	0x63, 0x58, 0x93, 0xc1, 0xc9, 0x61, 0xe1, 0xb2, 0x44, 0x6e, 0xce, 0xd8, 0xe5, 0xee, 0x25, 0x34,
+	0xfc, 0x0c, 0x99, 0x37, 0x4c, 0xbe, 0x83, 0x48, 0x9c, 0x09, 0xb6, 0xdc, 0xcf, 0x65, 0xc9, 0x7c,
+	0x0e, 0xad, 0xda, 0xbb, 0x3b, 0x73, 0x56, 0x55, 0x36, 0x07, 0xb0, 0x86, 0xf8, 0x33, 0xb3, 0xbc,
+	0x3d, 0x63, 0x19, 0xa5, 0x4b, 0xcd, 0x5f, 0x47, 0xf3, 0xae, 0xfb, 0xec, 0x62, 0xf3, 0x7b, 0x7d,
+	0xf9, 0xb5, 0xbe, 0x2c, 0xdb, 0x0a, 0xd4, 0x29, 0xe0, 0x42, 0x8a, 0x48, 0x4e, 0x09, 0x96, 0x6e,
+	0xa2, 0x6b, 0xdc, 0xcd, 0xda, 0x6f, 0x2c, 0x3f, 0x52, 0x46, 0xd7, 0x1e, 0x30, 0x51, 0x80, 0x2a,
+	0x76, 0xd1, 0x6c, 0x11, 0x0a, 0x76, 0xb6, 0xe6, 0x24, 0xf3, 0x6d, 0x8d, 0x76, 0xc9, 0x3b, 0xfa,
+	0x5f, 0x0b, 0x87, 0x8c, 0x23, 0x6d, 0x10, 0x4b, 0xf1, 0xff, 0x0d, 0x8b, 0x06, 0xd2, 0x9c, 0x9b,
+	0xf8, 0xa3, 0xd9, 0x6d, 0x6b, 0xf7, 0xee, 0xcf, 0xab, 0xbf, 0xba, 0xf3, 0xb3, 0x2a, 0xf9, 0xcc,
+	0x32, 0x78, 0xe4, 0xef, 0xd6, 0x1b, 0x47, 0x6f, 0xbf, 0xe5, 0x0c, 0xa8, 0x60, 0x67, 0x74, 0xea,
+	0xc4, 0xa7, 0x8e, 0x18, 0x32, 0xa7, 0x27, 0x65, 0x2f, 0x72, 0x87, 0xb3, 0x74, 0xc2, 0xd2, 0xae,
+	0x73, 0x5f, 0x56, 0xb1, 0xa3, 0x7f, 0xef, 0x70, 0x46, 0x63, 0x2e, 0x9c, 0x1e, 0x73, 0xe4, 0x8f,
+	0x65, 0x2c, 0x12, 0x41, 0x5f, 0xbe, 0x4a, 0x9c, 0xb3, 0x40, 0x0c, 0x1d, 0xea, 0xbc, 0xf1, 0xde,
+	0xb1, 0x33, 0x60, 0x11, 0x4b, 0x91, 0x79, 0x9a, 0xc6, 0x23, 0xb4, 0xa8, 0x2c, 0xbd, 0xc8, 0x9d,
+	0xc7, 0x6c, 0x7a, 0xc3, 0xe1, 0x2c, 0x12, 0x4e, 0x1c, 0xa1, 0x04, 0x2f, 0x76, 0x67, 0xc8, 0xa8,
+	0xcf, 0x52, 0x27, 0x4e, 0x6f, 0x38, 0x61, 0xf0, 0x98, 0x39, 0x34, 0x9a, 0x3a, 0xb1, 0x18, 0xb2,
+	0xd4, 0x19, 0x78, 0xef, 0x1c, 0x38, 0x23, 0x26, 0xa8, 0x4f, 0x05, 0xbd, 0x61, 0xbe, 0x7a, 0x90,
+	0x26, 0xfd, 0x9b, 0x87, 0x9a, 0x7b, 0xb3, 0x68, 0xa3, 0xbb, 0x6f, 0xbd, 0xb2, 0x5b, 0xb1, 0x2a,
+	0xfb, 0x9b, 0x34, 0x49, 0x42, 0xe9, 0x5c, 0x10, 0x47, 0x7b, 0x1f, 0xf0, 0x38, 0xba, 0x3d, 0xc7,
+	0xf9, 0x6e, 0x02, 0x91, 0x06, 0x6c, 0x84, 0x35, 0x2b, 0xe4, 0xfd, 0xa7, 0xf2, 0xfe, 0x34, 0x4e,
+	0xcf, 0x68, 0xea, 0x33, 0xdf, 0x11, 0x31, 0x8a, 0xd1, 0x45, 0xa5, 0xe3, 0x50, 0x8e, 0x2c, 0xb4,
+	0x99, 0xb9, 0xdd, 0xed, 0xd4, 0x95, 0x8b, 0x95, 0x5e, 0x1b, 0x5a, 0x66, 0xc7, 0xff, 0xeb, 0x35,
+	0x30, 0x75, 0x5f, 0xfa, 0xf7, 0x00, 0xf9, 0x92, 0xc6, 0xe1, 0x6b, 0x20, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// BotioClient is the client API for Botio service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type BotioClient interface {
+	AddCommand(ctx context.Context, in *BotCommand, opts ...grpc.CallOption) (*empty.Empty, error)
+	GetCommand(ctx context.Context, in *Command, opts ...grpc.CallOption) (*BotCommand, error)
+	ListCommands(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*BotCommands, error)
+	GetChangeToken(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ChangeToken, error)
+	SearchCommands(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*BotCommands, error)
+	ResolveCommand(ctx context.Context, in *ResolveRequest, opts ...grpc.CallOption) (*ResolveResponse, error)
+	Converse(ctx context.Context, in *ConverseRequest, opts ...grpc.CallOption) (*ConverseResponse, error)
+	UpdateCommand(ctx context.Context, in *BotCommand, opts ...grpc.CallOption) (*empty.Empty, error)
+	DeleteCommand(ctx context.Context, in *Command, opts ...grpc.CallOption) (*empty.Empty, error)
+	ListCommandRevisions(ctx context.Context, in *Command, opts ...grpc.CallOption) (*Revisions, error)
+	RollbackCommand(ctx context.Context, in *RollbackRequest, opts ...grpc.CallOption) (*BotCommand, error)
+	ListAuditEvents(ctx context.Context, in *AuditFilter, opts ...grpc.CallOption) (*AuditEvents, error)
+	AddWebhook(ctx context.Context, in *Webhook, opts ...grpc.CallOption) (*Webhook, error)
+	ListWebhooks(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*Webhooks, error)
+	DeleteWebhook(ctx context.Context, in *Webhook, opts ...grpc.CallOption) (*empty.Empty, error)
+	ListWebhookDeliveries(ctx context.Context, in *DeliveryFilter, opts ...grpc.CallOption) (*WebhookDeliveries, error)
+	RedeliverWebhook(ctx context.Context, in *WebhookDelivery, opts ...grpc.CallOption) (*WebhookDelivery, error)
+	AddSchedule(ctx context.Context, in *Schedule, opts ...grpc.CallOption) (*Schedule, error)
+	ListSchedules(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*Schedules, error)
+	DeleteSchedule(ctx context.Context, in *Schedule, opts ...grpc.CallOption) (*empty.Empty, error)
+	ClaimSchedule(ctx context.Context, in *ScheduleClaim, opts ...grpc.CallOption) (*empty.Empty, error)
+	ReportUsage(ctx context.Context, in *UsageReport, opts ...grpc.CallOption) (*empty.Empty, error)
+	GetUsageStats(ctx context.Context, in *UsageStatsRequest, opts ...grpc.CallOption) (*UsageStats, error)
+	MatchMessage(ctx context.Context, in *MatchRequest, opts ...grpc.CallOption) (*BotCommand, error)
+}
+
+type botioClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBotioClient(cc grpc.ClientConnInterface) BotioClient {
+	return &botioClient{cc}
+}
+
+func (c *botioClient) AddCommand(ctx context.Context, in *BotCommand, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Botio/AddCommand", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) GetCommand(ctx context.Context, in *Command, opts ...grpc.CallOption) (*BotCommand, error) {
+	out := new(BotCommand)
+	err := c.cc.Invoke(ctx, "/proto.Botio/GetCommand", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) ListCommands(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*BotCommands, error) {
+	out := new(BotCommands)
+	err := c.cc.Invoke(ctx, "/proto.Botio/ListCommands", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) GetChangeToken(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ChangeToken, error) {
+	out := new(ChangeToken)
+	err := c.cc.Invoke(ctx, "/proto.Botio/GetChangeToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) SearchCommands(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*BotCommands, error) {
+	out := new(BotCommands)
+	err := c.cc.Invoke(ctx, "/proto.Botio/SearchCommands", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) ResolveCommand(ctx context.Context, in *ResolveRequest, opts ...grpc.CallOption) (*ResolveResponse, error) {
+	out := new(ResolveResponse)
+	err := c.cc.Invoke(ctx, "/proto.Botio/ResolveCommand", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) Converse(ctx context.Context, in *ConverseRequest, opts ...grpc.CallOption) (*ConverseResponse, error) {
+	out := new(ConverseResponse)
+	err := c.cc.Invoke(ctx, "/proto.Botio/Converse", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) UpdateCommand(ctx context.Context, in *BotCommand, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Botio/UpdateCommand", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) DeleteCommand(ctx context.Context, in *Command, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Botio/DeleteCommand", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) ListCommandRevisions(ctx context.Context, in *Command, opts ...grpc.CallOption) (*Revisions, error) {
+	out := new(Revisions)
+	err := c.cc.Invoke(ctx, "/proto.Botio/ListCommandRevisions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) RollbackCommand(ctx context.Context, in *RollbackRequest, opts ...grpc.CallOption) (*BotCommand, error) {
+	out := new(BotCommand)
+	err := c.cc.Invoke(ctx, "/proto.Botio/RollbackCommand", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) ListAuditEvents(ctx context.Context, in *AuditFilter, opts ...grpc.CallOption) (*AuditEvents, error) {
+	out := new(AuditEvents)
+	err := c.cc.Invoke(ctx, "/proto.Botio/ListAuditEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) AddWebhook(ctx context.Context, in *Webhook, opts ...grpc.CallOption) (*Webhook, error) {
+	out := new(Webhook)
+	err := c.cc.Invoke(ctx, "/proto.Botio/AddWebhook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) ListWebhooks(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*Webhooks, error) {
+	out := new(Webhooks)
+	err := c.cc.Invoke(ctx, "/proto.Botio/ListWebhooks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) DeleteWebhook(ctx context.Context, in *Webhook, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Botio/DeleteWebhook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) ListWebhookDeliveries(ctx context.Context, in *DeliveryFilter, opts ...grpc.CallOption) (*WebhookDeliveries, error) {
+	out := new(WebhookDeliveries)
+	err := c.cc.Invoke(ctx, "/proto.Botio/ListWebhookDeliveries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) RedeliverWebhook(ctx context.Context, in *WebhookDelivery, opts ...grpc.CallOption) (*WebhookDelivery, error) {
+	out := new(WebhookDelivery)
+	err := c.cc.Invoke(ctx, "/proto.Botio/RedeliverWebhook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) AddSchedule(ctx context.Context, in *Schedule, opts ...grpc.CallOption) (*Schedule, error) {
+	out := new(Schedule)
+	err := c.cc.Invoke(ctx, "/proto.Botio/AddSchedule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) ListSchedules(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*Schedules, error) {
+	out := new(Schedules)
+	err := c.cc.Invoke(ctx, "/proto.Botio/ListSchedules", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) DeleteSchedule(ctx context.Context, in *Schedule, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Botio/DeleteSchedule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) ClaimSchedule(ctx context.Context, in *ScheduleClaim, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Botio/ClaimSchedule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) ReportUsage(ctx context.Context, in *UsageReport, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Botio/ReportUsage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) GetUsageStats(ctx context.Context, in *UsageStatsRequest, opts ...grpc.CallOption) (*UsageStats, error) {
+	out := new(UsageStats)
+	err := c.cc.Invoke(ctx, "/proto.Botio/GetUsageStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botioClient) MatchMessage(ctx context.Context, in *MatchRequest, opts ...grpc.CallOption) (*BotCommand, error) {
+	out := new(BotCommand)
+	err := c.cc.Invoke(ctx, "/proto.Botio/MatchMessage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BotioServer is the server API for Botio service.
+type BotioServer interface {
+	AddCommand(context.Context, *BotCommand) (*empty.Empty, error)
+	GetCommand(context.Context, *Command) (*BotCommand, error)
+	ListCommands(context.Context, *empty.Empty) (*BotCommands, error)
+	GetChangeToken(context.Context, *empty.Empty) (*ChangeToken, error)
+	SearchCommands(context.Context, *SearchRequest) (*BotCommands, error)
+	ResolveCommand(context.Context, *ResolveRequest) (*ResolveResponse, error)
+	Converse(context.Context, *ConverseRequest) (*ConverseResponse, error)
+	UpdateCommand(context.Context, *BotCommand) (*empty.Empty, error)
+	DeleteCommand(context.Context, *Command) (*empty.Empty, error)
+	ListCommandRevisions(context.Context, *Command) (*Revisions, error)
+	RollbackCommand(context.Context, *RollbackRequest) (*BotCommand, error)
+	ListAuditEvents(context.Context, *AuditFilter) (*AuditEvents, error)
+	AddWebhook(context.Context, *Webhook) (*Webhook, error)
+	ListWebhooks(context.Context, *empty.Empty) (*Webhooks, error)
+	DeleteWebhook(context.Context, *Webhook) (*empty.Empty, error)
+	ListWebhookDeliveries(context.Context, *DeliveryFilter) (*WebhookDeliveries, error)
+	RedeliverWebhook(context.Context, *WebhookDelivery) (*WebhookDelivery, error)
+	AddSchedule(context.Context, *Schedule) (*Schedule, error)
+	ListSchedules(context.Context, *empty.Empty) (*Schedules, error)
+	DeleteSchedule(context.Context, *Schedule) (*empty.Empty, error)
+	ClaimSchedule(context.Context, *ScheduleClaim) (*empty.Empty, error)
+	ReportUsage(context.Context, *UsageReport) (*empty.Empty, error)
+	GetUsageStats(context.Context, *UsageStatsRequest) (*UsageStats, error)
+	MatchMessage(context.Context, *MatchRequest) (*BotCommand, error)
+}
+
+// UnimplementedBotioServer can be embedded to have forward compatible implementations.
+type UnimplementedBotioServer struct {
+}
+
+func (*UnimplementedBotioServer) AddCommand(ctx context.Context, req *BotCommand) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddCommand not implemented")
+}
+func (*UnimplementedBotioServer) GetCommand(ctx context.Context, req *Command) (*BotCommand, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCommand not implemented")
+}
+func (*UnimplementedBotioServer) ListCommands(ctx context.Context, req *empty.Empty) (*BotCommands, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCommands not implemented")
+}
+func (*UnimplementedBotioServer) GetChangeToken(ctx context.Context, req *empty.Empty) (*ChangeToken, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChangeToken not implemented")
+}
+func (*UnimplementedBotioServer) SearchCommands(ctx context.Context, req *SearchRequest) (*BotCommands, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchCommands not implemented")
+}
+func (*UnimplementedBotioServer) ResolveCommand(ctx context.Context, req *ResolveRequest) (*ResolveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResolveCommand not implemented")
+}
+func (*UnimplementedBotioServer) Converse(ctx context.Context, req *ConverseRequest) (*ConverseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Converse not implemented")
+}
+func (*UnimplementedBotioServer) UpdateCommand(ctx context.Context, req *BotCommand) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCommand not implemented")
+}
+func (*UnimplementedBotioServer) DeleteCommand(ctx context.Context, req *Command) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCommand not implemented")
+}
+func (*UnimplementedBotioServer) ListCommandRevisions(ctx context.Context, req *Command) (*Revisions, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCommandRevisions not implemented")
+}
+func (*UnimplementedBotioServer) RollbackCommand(ctx context.Context, req *RollbackRequest) (*BotCommand, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RollbackCommand not implemented")
+}
+func (*UnimplementedBotioServer) ListAuditEvents(ctx context.Context, req *AuditFilter) (*AuditEvents, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuditEvents not implemented")
+}
+func (*UnimplementedBotioServer) AddWebhook(ctx context.Context, req *Webhook) (*Webhook, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddWebhook not implemented")
+}
+func (*UnimplementedBotioServer) ListWebhooks(ctx context.Context, req *empty.Empty) (*Webhooks, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhooks not implemented")
+}
+func (*UnimplementedBotioServer) DeleteWebhook(ctx context.Context, req *Webhook) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteWebhook not implemented")
+}
+func (*UnimplementedBotioServer) ListWebhookDeliveries(ctx context.Context, req *DeliveryFilter) (*WebhookDeliveries, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhookDeliveries not implemented")
+}
+func (*UnimplementedBotioServer) RedeliverWebhook(ctx context.Context, req *WebhookDelivery) (*WebhookDelivery, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RedeliverWebhook not implemented")
+}
+func (*UnimplementedBotioServer) AddSchedule(ctx context.Context, req *Schedule) (*Schedule, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddSchedule not implemented")
+}
+func (*UnimplementedBotioServer) ListSchedules(ctx context.Context, req *empty.Empty) (*Schedules, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSchedules not implemented")
+}
+func (*UnimplementedBotioServer) DeleteSchedule(ctx context.Context, req *Schedule) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSchedule not implemented")
+}
+func (*UnimplementedBotioServer) ClaimSchedule(ctx context.Context, req *ScheduleClaim) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClaimSchedule not implemented")
+}
+func (*UnimplementedBotioServer) ReportUsage(ctx context.Context, req *UsageReport) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportUsage not implemented")
+}
+func (*UnimplementedBotioServer) GetUsageStats(ctx context.Context, req *UsageStatsRequest) (*UsageStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUsageStats not implemented")
+}
+func (*UnimplementedBotioServer) MatchMessage(ctx context.Context, req *MatchRequest) (*BotCommand, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MatchMessage not implemented")
+}
+
+func RegisterBotioServer(s *grpc.Server, srv BotioServer) {
+	s.RegisterService(&_Botio_serviceDesc, srv)
+}
+
+func _Botio_AddCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(BotCommand)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(BotioServer).AddCommand(ctx, in)
+		return srv.(BotioServer).AddCommand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Botio/AddCommand",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotioServer).AddCommand(ctx, req.(*BotCommand))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Botio_GetCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Command)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotioServer).GetCommand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Botio/GetCommand",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotioServer).GetCommand(ctx, req.(*Command))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Botio_ListCommands_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotioServer).ListCommands(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Botio/ListCommands",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotioServer).ListCommands(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Botio_GetChangeToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotioServer).GetChangeToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Botio/GetChangeToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotioServer).GetChangeToken(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Botio_SearchCommands_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotioServer).SearchCommands(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.Botio/AddCommand",
+		FullMethod: "/proto.Botio/SearchCommands",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(BotioServer).AddCommand(ctx, req.(*BotCommand))
+		return srv.(BotioServer).SearchCommands(ctx, req.(*SearchRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Botio_GetCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Command)
+func _Botio_ResolveCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(BotioServer).GetCommand(ctx, in)
+		return srv.(BotioServer).ResolveCommand(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.Botio/GetCommand",
+		FullMethod: "/proto.Botio/ResolveCommand",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(BotioServer).GetCommand(ctx, req.(*Command))
+		return srv.(BotioServer).ResolveCommand(ctx, req.(*ResolveRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Botio_ListCommands_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(empty.Empty)
+func _Botio_Converse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConverseRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(BotioServer).ListCommands(ctx, in)
+		return srv.(BotioServer).Converse(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.Botio/ListCommands",
+		FullMethod: "/proto.Botio/Converse",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(BotioServer).ListCommands(ctx, req.(*empty.Empty))
+		return srv.(BotioServer).Converse(ctx, req.(*ConverseRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -426,6 +3409,276 @@ func _Botio_DeleteCommand_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Botio_ListCommandRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Command)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotioServer).ListCommandRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Botio/ListCommandRevisions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotioServer).ListCommandRevisions(ctx, req.(*Command))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Botio_RollbackCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RollbackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotioServer).RollbackCommand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Botio/RollbackCommand",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotioServer).RollbackCommand(ctx, req.(*RollbackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Botio_ListAuditEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuditFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotioServer).ListAuditEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Botio/ListAuditEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotioServer).ListAuditEvents(ctx, req.(*AuditFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Botio_AddWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Webhook)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotioServer).AddWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Botio/AddWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotioServer).AddWebhook(ctx, req.(*Webhook))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Botio_ListWebhooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotioServer).ListWebhooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Botio/ListWebhooks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotioServer).ListWebhooks(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Botio_DeleteWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Webhook)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotioServer).DeleteWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Botio/DeleteWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotioServer).DeleteWebhook(ctx, req.(*Webhook))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Botio_ListWebhookDeliveries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeliveryFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotioServer).ListWebhookDeliveries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Botio/ListWebhookDeliveries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotioServer).ListWebhookDeliveries(ctx, req.(*DeliveryFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Botio_RedeliverWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookDelivery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotioServer).RedeliverWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Botio/RedeliverWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotioServer).RedeliverWebhook(ctx, req.(*WebhookDelivery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Botio_AddSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Schedule)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotioServer).AddSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Botio/AddSchedule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotioServer).AddSchedule(ctx, req.(*Schedule))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Botio_ListSchedules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotioServer).ListSchedules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Botio/ListSchedules",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotioServer).ListSchedules(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Botio_DeleteSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Schedule)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotioServer).DeleteSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Botio/DeleteSchedule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotioServer).DeleteSchedule(ctx, req.(*Schedule))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Botio_ClaimSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScheduleClaim)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotioServer).ClaimSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Botio/ClaimSchedule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotioServer).ClaimSchedule(ctx, req.(*ScheduleClaim))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Botio_ReportUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UsageReport)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotioServer).ReportUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Botio/ReportUsage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotioServer).ReportUsage(ctx, req.(*UsageReport))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Botio_GetUsageStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UsageStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotioServer).GetUsageStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Botio/GetUsageStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotioServer).GetUsageStats(ctx, req.(*UsageStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Botio_MatchMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotioServer).MatchMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Botio/MatchMessage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotioServer).MatchMessage(ctx, req.(*MatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Botio_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.Botio",
 	HandlerType: (*BotioServer)(nil),
@@ -442,6 +3695,22 @@ var _Botio_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ListCommands",
 			Handler:    _Botio_ListCommands_Handler,
 		},
+		{
+			MethodName: "GetChangeToken",
+			Handler:    _Botio_GetChangeToken_Handler,
+		},
+		{
+			MethodName: "SearchCommands",
+			Handler:    _Botio_SearchCommands_Handler,
+		},
+		{
+			MethodName: "ResolveCommand",
+			Handler:    _Botio_ResolveCommand_Handler,
+		},
+		{
+			MethodName: "Converse",
+			Handler:    _Botio_Converse_Handler,
+		},
 		{
 			MethodName: "UpdateCommand",
 			Handler:    _Botio_UpdateCommand_Handler,
@@ -450,6 +3719,66 @@ var _Botio_serviceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteCommand",
 			Handler:    _Botio_DeleteCommand_Handler,
 		},
+		{
+			MethodName: "ListCommandRevisions",
+			Handler:    _Botio_ListCommandRevisions_Handler,
+		},
+		{
+			MethodName: "RollbackCommand",
+			Handler:    _Botio_RollbackCommand_Handler,
+		},
+		{
+			MethodName: "ListAuditEvents",
+			Handler:    _Botio_ListAuditEvents_Handler,
+		},
+		{
+			MethodName: "AddWebhook",
+			Handler:    _Botio_AddWebhook_Handler,
+		},
+		{
+			MethodName: "ListWebhooks",
+			Handler:    _Botio_ListWebhooks_Handler,
+		},
+		{
+			MethodName: "DeleteWebhook",
+			Handler:    _Botio_DeleteWebhook_Handler,
+		},
+		{
+			MethodName: "ListWebhookDeliveries",
+			Handler:    _Botio_ListWebhookDeliveries_Handler,
+		},
+		{
+			MethodName: "RedeliverWebhook",
+			Handler:    _Botio_RedeliverWebhook_Handler,
+		},
+		{
+			MethodName: "AddSchedule",
+			Handler:    _Botio_AddSchedule_Handler,
+		},
+		{
+			MethodName: "ListSchedules",
+			Handler:    _Botio_ListSchedules_Handler,
+		},
+		{
+			MethodName: "DeleteSchedule",
+			Handler:    _Botio_DeleteSchedule_Handler,
+		},
+		{
+			MethodName: "ClaimSchedule",
+			Handler:    _Botio_ClaimSchedule_Handler,
+		},
+		{
+			MethodName: "ReportUsage",
+			Handler:    _Botio_ReportUsage_Handler,
+		},
+		{
+			MethodName: "GetUsageStats",
+			Handler:    _Botio_GetUsageStats_Handler,
+		},
+		{
+			MethodName: "MatchMessage",
+			Handler:    _Botio_MatchMessage_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "commands.proto",
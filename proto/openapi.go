@@ -0,0 +1,12 @@
+package proto
+
+import (
+	// Needed by go:embed.
+	_ "embed"
+)
+
+// OpenAPI is the OpenAPI v2 specification of the JSON gateway,
+// generated alongside the gateway and kept on the binary.
+//
+//go:embed commands.swagger.json
+var OpenAPI string
@@ -0,0 +1,72 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tt := []struct {
+		name           string
+		expr           string
+		expectedToFail bool
+	}{
+		{name: "every minute", expr: "* * * * *"},
+		{name: "lists ranges and steps", expr: "0,30 9-17/2 1-15 */3 mon-fri"},
+		{name: "names", expr: "0 12 * JAN,jul sun"},
+		{name: "sunday as seven", expr: "0 12 * * 7"},
+		{name: "descriptor", expr: "@daily"},
+		{name: "too few fields", expr: "* * * *", expectedToFail: true},
+		{name: "out of range", expr: "60 * * * *", expectedToFail: true},
+		{name: "reversed range", expr: "* 17-9 * * *", expectedToFail: true},
+		{name: "invalid step", expr: "*/0 * * * *", expectedToFail: true},
+		{name: "unknown name", expr: "* * * * monday", expectedToFail: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(tc.expr)
+			if tc.expectedToFail != (err != nil) {
+				t.Fatalf("expected error %v parsing %q. got=%v", tc.expectedToFail, tc.expr, err)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	// Monday, 7 December 2020.
+	monday := time.Date(2020, time.December, 7, 9, 30, 0, 0, time.UTC)
+
+	tt := []struct {
+		name     string
+		expr     string
+		time     time.Time
+		expected bool
+	}{
+		{name: "every minute", expr: "* * * * *", time: monday, expected: true},
+		{name: "exact minute", expr: "30 9 * * *", time: monday, expected: true},
+		{name: "other minute", expr: "31 9 * * *", time: monday},
+		{name: "step", expr: "*/15 * * * *", time: monday, expected: true},
+		{name: "step from value", expr: "5/10 * * * *", time: monday.Add(5 * time.Minute), expected: true},
+		{name: "weekdays", expr: "30 9 * * mon-fri", time: monday, expected: true},
+		{name: "weekend", expr: "30 9 * * sat,sun", time: monday},
+		{name: "sunday as seven", expr: "30 9 * * 7", time: monday.AddDate(0, 0, 6), expected: true},
+		{name: "day of month or week", expr: "30 9 1 * mon", time: monday, expected: true},
+		{name: "day of month and any week day", expr: "30 9 1 * *", time: monday},
+		{name: "month", expr: "30 9 * dec *", time: monday, expected: true},
+		{name: "hourly", expr: "@hourly", time: monday.Add(30 * time.Minute), expected: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := Parse(tc.expr)
+			if err != nil {
+				t.Fatalf("while parsing %q: %v", tc.expr, err)
+			}
+
+			if got := c.Match(tc.time); got != tc.expected {
+				t.Fatalf("expected %q to match %v %v. got=%v", tc.expr, tc.time, tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// Package schedule exports a Store for the messages that the bots
+// send on their own, either periodically following a cron expression
+// or once, and a parser of cron expressions.
+package schedule
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/danielkvist/botio/jsonl"
+	"github.com/danielkvist/botio/proto"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/pkg/errors"
+)
+
+// Platforms are the platforms whose chats can receive scheduled messages.
+var Platforms = []string{"telegram", "discord"}
+
+// ErrNotFound is returned when the requested schedule is not stored.
+var ErrNotFound = errors.New("schedule not found")
+
+// ErrClaimed is returned when the sending of a schedule at a
+// minute was already claimed by another replica of its bot.
+var ErrClaimed = errors.New("schedule already claimed")
+
+// ErrInvalid is returned when a schedule can't be stored because it
+// has an invalid cron expression, an unknown platform, no chats or no
+// message to send.
+var ErrInvalid = errors.New("invalid schedule")
+
+// Store keeps the scheduled messages on the file on Path, one JSON object
+// per line, or only in memory if Path is empty. The last minute at which
+// each one was claimed is only kept in memory.
+type Store struct {
+	Path      string
+	mu        sync.Mutex
+	schedules []*proto.Schedule
+	claims    map[string]time.Time
+}
+
+// New returns a Store with the schedules
+// kept on the file on path, if any.
+func New(path string) (*Store, error) {
+	s := &Store{Path: path, claims: make(map[string]time.Time)}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Add stores the received schedule assigning it an ID.
+func (s *Store) Add(sch *proto.Schedule) (*proto.Schedule, error) {
+	if err := Validate(sch); err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, errors.Wrap(err, "while generating schedule ID")
+	}
+
+	added := pb.Clone(sch).(*proto.Schedule)
+	added.Id = hex.EncodeToString(b)
+	added.Created = ptypes.TimestampNow()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedules := append(s.schedules[:len(s.schedules):len(s.schedules)], added)
+	if err := s.save(schedules); err != nil {
+		return nil, err
+	}
+
+	s.schedules = schedules
+	return pb.Clone(added).(*proto.Schedule), nil
+}
+
+// List returns the stored schedules from the oldest to the newest.
+func (s *Store) List() *proto.Schedules {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedules := make([]*proto.Schedule, 0, len(s.schedules))
+	for _, sch := range s.schedules {
+		schedules = append(schedules, pb.Clone(sch).(*proto.Schedule))
+	}
+
+	return &proto.Schedules{Schedules: schedules}
+}
+
+// Remove removes the schedule with the received ID.
+func (s *Store) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedules := make([]*proto.Schedule, 0, len(s.schedules))
+	for _, sch := range s.schedules {
+		if sch.GetId() != id {
+			schedules = append(schedules, sch)
+		}
+	}
+
+	if len(schedules) == len(s.schedules) {
+		return errors.Wrapf(ErrNotFound, "while removing schedule %q", id)
+	}
+
+	if err := s.save(schedules); err != nil {
+		return err
+	}
+
+	s.schedules = schedules
+	delete(s.claims, id)
+	return nil
+}
+
+// Claim claims the sending of the schedule with the received ID at
+// the minute of t for the replica of its bot that asks for it. It
+// returns ErrClaimed if that minute, or a later one, was already claimed.
+func (s *Store) Claim(id string, t time.Time) error {
+	minute := t.Truncate(time.Minute)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := false
+	for _, sch := range s.schedules {
+		found = found || sch.GetId() == id
+	}
+
+	if !found {
+		return errors.Wrapf(ErrNotFound, "while claiming schedule %q", id)
+	}
+
+	if last, ok := s.claims[id]; ok && !minute.After(last) {
+		return errors.Wrapf(ErrClaimed, "while claiming schedule %q at %v", id, minute)
+	}
+
+	s.claims[id] = minute
+	return nil
+}
+
+// Validate returns a non-nil error wrapping ErrInvalid
+// if the received schedule can't be stored.
+func Validate(sch *proto.Schedule) error {
+	if sch.GetCron() != "" {
+		if _, err := Parse(sch.GetCron()); err != nil {
+			return errors.Wrap(ErrInvalid, err.Error())
+		}
+	}
+
+	known := false
+	for _, p := range Platforms {
+		known = known || p == sch.GetPlatform()
+	}
+
+	switch {
+	case !known:
+		return errors.Wrapf(ErrInvalid, "unknown platform %q", sch.GetPlatform())
+	case len(sch.GetChats()) == 0:
+		return errors.Wrap(ErrInvalid, "no chats provided")
+	case sch.GetResponse() == "" && sch.GetCommand() == "":
+		return errors.Wrap(ErrInvalid, "no response or command provided")
+	}
+
+	return nil
+}
+
+func (s *Store) load() error {
+	if s.Path == "" {
+		return nil
+	}
+
+	err := jsonl.Load(s.Path, func() pb.Message { return &proto.Schedule{} }, func(msg pb.Message) {
+		s.schedules = append(s.schedules, msg.(*proto.Schedule))
+	})
+	return errors.Wrap(err, "while loading schedules")
+}
+
+func (s *Store) save(schedules []*proto.Schedule) error {
+	if s.Path == "" {
+		return nil
+	}
+
+	msgs := make([]pb.Message, 0, len(schedules))
+	for _, sch := range schedules {
+		msgs = append(msgs, sch)
+	}
+
+	return errors.Wrap(jsonl.Save(s.Path, msgs), "while saving schedules")
+}
@@ -0,0 +1,144 @@
+package schedule
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/pkg/errors"
+)
+
+func TestValidate(t *testing.T) {
+	tt := []struct {
+		name           string
+		schedule       *proto.Schedule
+		expectedToFail bool
+	}{
+		{
+			name:     "periodic response",
+			schedule: &proto.Schedule{Cron: "0 9 * * mon", Platform: "telegram", Chats: []string{"1"}, Response: "Good morning"},
+		},
+		{
+			name:     "once command",
+			schedule: &proto.Schedule{Platform: "discord", Chats: []string{"1", "2"}, Command: "announcement"},
+		},
+		{
+			name:           "invalid cron",
+			schedule:       &proto.Schedule{Cron: "every day", Platform: "telegram", Chats: []string{"1"}, Response: "hi"},
+			expectedToFail: true,
+		},
+		{
+			name:           "unknown platform",
+			schedule:       &proto.Schedule{Platform: "slack", Chats: []string{"1"}, Response: "hi"},
+			expectedToFail: true,
+		},
+		{
+			name:           "without chats",
+			schedule:       &proto.Schedule{Platform: "telegram", Response: "hi"},
+			expectedToFail: true,
+		},
+		{
+			name:           "without message",
+			schedule:       &proto.Schedule{Platform: "telegram", Chats: []string{"1"}},
+			expectedToFail: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(tc.schedule)
+			if tc.expectedToFail && errors.Cause(err) != ErrInvalid {
+				t.Fatalf("expected ErrInvalid. got=%v", err)
+			}
+
+			if !tc.expectedToFail && err != nil {
+				t.Fatalf("expected no error. got=%v", err)
+			}
+		})
+	}
+}
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "botio")
+	if err != nil {
+		t.Fatalf("while creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "schedules.jsonl")
+	s, err := New(path)
+	if err != nil {
+		t.Fatalf("while creating store: %v", err)
+	}
+
+	first, err := s.Add(&proto.Schedule{Cron: "@daily", Platform: "telegram", Chats: []string{"1"}, Response: "hi"})
+	if err != nil {
+		t.Fatalf("while adding schedule: %v", err)
+	}
+
+	second, err := s.Add(&proto.Schedule{Platform: "discord", Chats: []string{"2"}, Command: "announcement"})
+	if err != nil {
+		t.Fatalf("while adding schedule: %v", err)
+	}
+
+	if first.GetId() == "" || first.GetId() == second.GetId() || first.GetCreated() == nil {
+		t.Fatalf("expected schedules with different IDs and creation times. got=%v and %v", first, second)
+	}
+
+	if err := s.Remove(first.GetId()); err != nil {
+		t.Fatalf("while removing schedule %q: %v", first.GetId(), err)
+	}
+
+	if err := s.Remove(first.GetId()); errors.Cause(err) != ErrNotFound {
+		t.Fatalf("expected ErrNotFound removing schedule %q twice. got=%v", first.GetId(), err)
+	}
+
+	reloaded, err := New(path)
+	if err != nil {
+		t.Fatalf("while reloading store: %v", err)
+	}
+
+	schedules := reloaded.List().GetSchedules()
+	if len(schedules) != 1 || schedules[0].GetId() != second.GetId() || schedules[0].GetCommand() != "announcement" {
+		t.Fatalf("expected only schedule %q after reloading. got=%v", second.GetId(), schedules)
+	}
+}
+
+func TestClaim(t *testing.T) {
+	s, err := New("")
+	if err != nil {
+		t.Fatalf("while creating store: %v", err)
+	}
+
+	sch, err := s.Add(&proto.Schedule{Cron: "* * * * *", Platform: "telegram", Chats: []string{"1"}, Response: "hi", Bot: "support"})
+	if err != nil {
+		t.Fatalf("while adding schedule: %v", err)
+	}
+
+	nine := time.Date(2020, time.December, 7, 9, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		name          string
+		id            string
+		time          time.Time
+		expectedError error
+	}{
+		{name: "first claim", id: sch.GetId(), time: nine},
+		{name: "same minute", id: sch.GetId(), time: nine.Add(30 * time.Second), expectedError: ErrClaimed},
+		{name: "earlier minute", id: sch.GetId(), time: nine.Add(-time.Minute), expectedError: ErrClaimed},
+		{name: "next minute", id: sch.GetId(), time: nine.Add(time.Minute)},
+		{name: "missing schedule", id: "missing", time: nine, expectedError: ErrNotFound},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := s.Claim(tc.id, tc.time); errors.Cause(err) != tc.expectedError {
+				t.Fatalf("expected error %v. got=%v", tc.expectedError, err)
+			}
+		})
+	}
+}
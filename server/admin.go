@@ -0,0 +1,49 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// adminAsset is a file of the admin UI.
+type adminAsset struct {
+	contentType string
+	content     string
+}
+
+// adminAssets are the files of the admin UI keyed by their path.
+// They are kept on the binary so the UI needs nothing else to be
+// served and doesn't depend on any external CDN.
+var adminAssets = map[string]adminAsset{
+	"/":           {contentType: "text/html; charset=utf-8", content: adminIndex},
+	"/index.html": {contentType: "text/html; charset=utf-8", content: adminIndex},
+	"/app.js":     {contentType: "application/javascript; charset=utf-8", content: adminScript},
+	"/app.css":    {contentType: "text/css; charset=utf-8", content: adminStyle},
+}
+
+// adminUI returns an http.Handler that serves the admin UI, a single
+// page that manages the commands using the JSON gateway with the JWT
+// provided on its login form. The paths it receives must not contain
+// the prefix under which it is mounted.
+func adminUI() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		asset, ok := adminAssets[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", asset.contentType)
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", "no-cache")
+		http.ServeContent(w, r, r.URL.Path, time.Time{}, strings.NewReader(asset.content))
+	})
+}
@@ -0,0 +1,434 @@
+package server
+
+// adminIndex is the page of the admin UI.
+const adminIndex = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Botio</title>
+<link rel="stylesheet" href="app.css">
+</head>
+<body>
+<header>
+	<h1>Botio</h1>
+	<nav id="nav" hidden>
+		<button type="button" data-view="commands">Commands</button>
+		<button type="button" data-view="usage">Usage</button>
+		<button type="button" id="logout">Log out</button>
+	</nav>
+</header>
+
+<main>
+	<p id="error" class="error" hidden></p>
+
+	<section id="login" hidden>
+		<form id="login-form">
+			<label for="token">JWT</label>
+			<textarea id="token" rows="4" required placeholder="Paste the JWT generated by the server"></textarea>
+			<button type="submit">Log in</button>
+		</form>
+	</section>
+
+	<section id="commands" hidden>
+		<div class="toolbar">
+			<input id="search" type="search" placeholder="Search commands">
+			<button type="button" id="new">New command</button>
+		</div>
+		<table>
+			<thead>
+				<tr><th>Command</th><th>Response</th><th>Description</th><th>Version</th><th></th></tr>
+			</thead>
+			<tbody id="rows"></tbody>
+		</table>
+		<div class="pager">
+			<button type="button" id="prev">Previous</button>
+			<span id="page"></span>
+			<button type="button" id="next">Next</button>
+		</div>
+	</section>
+
+	<section id="editor" hidden>
+		<form id="editor-form">
+			<h2 id="editor-title"></h2>
+			<label for="f-command">Command</label>
+			<input id="f-command" required>
+			<label for="f-response">Response</label>
+			<textarea id="f-response" rows="6"></textarea>
+			<label for="f-buttons">Buttons, one TEXT=COMMAND per line</label>
+			<textarea id="f-buttons" rows="3"></textarea>
+			<label for="f-description">Description</label>
+			<input id="f-description">
+			<label for="f-aliases">Aliases, separated by commas</label>
+			<input id="f-aliases">
+			<label class="check"><input id="f-hidden" type="checkbox"> Hidden</label>
+			<h3>Preview</h3>
+			<div id="preview" class="preview"></div>
+			<div class="actions">
+				<button type="submit">Save</button>
+				<button type="button" id="cancel">Cancel</button>
+			</div>
+		</form>
+	</section>
+
+	<section id="history" hidden>
+		<h2 id="history-title"></h2>
+		<ol id="revisions" reversed></ol>
+		<button type="button" id="history-back">Back</button>
+	</section>
+
+	<section id="usage" hidden>
+		<h2>Usage of the last 7 days</h2>
+		<p id="usage-missing" hidden>Usage stats are not available on this server.</p>
+		<div id="usage-tables">
+			<h3>Most used commands</h3>
+			<table>
+				<thead><tr><th>Command</th><th>Hits</th><th>Errors</th><th>Chats</th><th>Avg. latency (ms)</th></tr></thead>
+				<tbody id="top-commands"></tbody>
+			</table>
+			<h3>Most requested missing commands</h3>
+			<table>
+				<thead><tr><th>Command</th><th>Misses</th><th>Chats</th></tr></thead>
+				<tbody id="top-missing"></tbody>
+			</table>
+		</div>
+	</section>
+</main>
+
+<script src="app.js"></script>
+</body>
+</html>
+`
+
+// adminStyle is the stylesheet of the admin UI.
+const adminStyle = `* { box-sizing: border-box; }
+body { margin: 0; font-family: system-ui, sans-serif; color: #222; background: #f6f7f9; }
+header { display: flex; align-items: center; justify-content: space-between; padding: 0 1.5rem; background: #24292e; color: #fff; }
+header h1 { font-size: 1.25rem; }
+nav button { margin-left: .5rem; background: transparent; color: #fff; border-color: #888; }
+main { max-width: 960px; margin: 1.5rem auto; padding: 0 1rem; }
+section { background: #fff; padding: 1rem 1.5rem; border-radius: 6px; box-shadow: 0 1px 3px rgba(0, 0, 0, .1); }
+label { display: block; margin: .75rem 0 .25rem; font-weight: 600; }
+label.check { font-weight: normal; }
+input, textarea { width: 100%; padding: .4rem; font: inherit; border: 1px solid #ccc; border-radius: 4px; }
+input[type=checkbox] { width: auto; }
+button { padding: .4rem .8rem; font: inherit; border: 1px solid #ccc; border-radius: 4px; background: #fff; cursor: pointer; }
+button[type=submit] { background: #2f6fde; border-color: #2f6fde; color: #fff; }
+button.danger { color: #b00020; }
+table { width: 100%; border-collapse: collapse; margin: 1rem 0; }
+th, td { padding: .4rem; text-align: left; border-bottom: 1px solid #eee; vertical-align: top; }
+td.actions, .actions { white-space: nowrap; }
+td.actions button { margin-right: .25rem; }
+.toolbar, .pager { display: flex; gap: .5rem; align-items: center; }
+.pager { justify-content: center; }
+.error { padding: .75rem; color: #b00020; background: #fde7ea; border-radius: 4px; }
+.preview { padding: .75rem; white-space: pre-wrap; background: #eef3fb; border-radius: 8px; min-height: 2.5rem; }
+.preview .button { display: inline-block; margin: .5rem .25rem 0 0; padding: .2rem .6rem; border: 1px solid #2f6fde; border-radius: 4px; color: #2f6fde; }
+.actions { margin-top: 1rem; }
+pre { padding: .5rem; overflow-x: auto; background: #f6f8fa; }
+.muted { color: #777; }
+`
+
+// adminScript is the script of the admin UI. It keeps the JWT on the
+// session storage of the browser and sends it on every request.
+const adminScript = `(function () {
+	"use strict";
+
+	var pageSize = 20;
+	var state = { commands: [], page: 0, editing: null };
+
+	function $(id) { return document.getElementById(id); }
+
+	function token() { return sessionStorage.getItem("botio-token") || ""; }
+
+	function api(method, path, body) {
+		var opts = { method: method, headers: { "Token": token() } };
+		if (body !== undefined) {
+			opts.headers["Content-Type"] = "application/json";
+			opts.body = JSON.stringify(body);
+		}
+
+		return fetch(path, opts).then(function (resp) {
+			return resp.text().then(function (text) {
+				var data = {};
+				if (text) {
+					try { data = JSON.parse(text); } catch (e) { data = { message: text }; }
+				}
+
+				if (!resp.ok) {
+					var err = new Error(data.message || data.error || resp.statusText);
+					err.status = resp.status;
+					throw err;
+				}
+
+				return data;
+			});
+		});
+	}
+
+	function showError(err) {
+		$("error").textContent = err ? (err.message || String(err)) : "";
+		$("error").hidden = !err;
+	}
+
+	function show(view) {
+		["login", "commands", "editor", "history", "usage"].forEach(function (id) {
+			$(id).hidden = id !== view;
+		});
+		$("nav").hidden = view === "login";
+		showError(null);
+	}
+
+	function el(tag, text, cls) {
+		var e = document.createElement(tag);
+		if (text !== undefined) { e.textContent = text; }
+		if (cls) { e.className = cls; }
+		return e;
+	}
+
+	function button(text, onclick, cls) {
+		var b = el("button", text, cls);
+		b.type = "button";
+		b.addEventListener("click", onclick);
+		return b;
+	}
+
+	// Commands
+
+	function loadCommands() {
+		return api("GET", "/api/v1/commands").then(function (data) {
+			state.commands = (data.commands || []).sort(function (a, b) {
+				return name(a) < name(b) ? -1 : 1;
+			});
+			renderCommands();
+		});
+	}
+
+	function name(cmd) { return (cmd.cmd && cmd.cmd.command) || ""; }
+
+	function responseOf(cmd) { return (cmd.resp && cmd.resp.response) || ""; }
+
+	function filtered() {
+		var q = $("search").value.trim().toLowerCase();
+		if (!q) { return state.commands; }
+
+		return state.commands.filter(function (cmd) {
+			return [name(cmd), cmd.description || ""].concat(cmd.aliases || []).some(function (s) {
+				return s.toLowerCase().indexOf(q) !== -1;
+			});
+		});
+	}
+
+	function renderCommands() {
+		var cmds = filtered();
+		var pages = Math.max(1, Math.ceil(cmds.length / pageSize));
+		state.page = Math.min(state.page, pages - 1);
+
+		var rows = $("rows");
+		rows.textContent = "";
+		cmds.slice(state.page * pageSize, (state.page + 1) * pageSize).forEach(function (cmd) {
+			var tr = el("tr");
+			tr.appendChild(el("td", "/" + name(cmd) + (cmd.hidden ? " (hidden)" : "")));
+			tr.appendChild(el("td", responseOf(cmd)));
+			tr.appendChild(el("td", cmd.description || ""));
+			tr.appendChild(el("td", String(cmd.version || "")));
+
+			var actions = el("td", undefined, "actions");
+			actions.appendChild(button("Edit", function () { openEditor(cmd); }));
+			actions.appendChild(button("History", function () { openHistory(name(cmd)); }));
+			actions.appendChild(button("Delete", function () { deleteCommand(cmd); }, "danger"));
+			tr.appendChild(actions);
+			rows.appendChild(tr);
+		});
+
+		if (cmds.length === 0) {
+			var empty = el("tr");
+			var td = el("td", "There are no commands.", "muted");
+			td.colSpan = 5;
+			empty.appendChild(td);
+			rows.appendChild(empty);
+		}
+
+		$("page").textContent = "Page " + (state.page + 1) + " of " + pages;
+		$("prev").disabled = state.page === 0;
+		$("next").disabled = state.page >= pages - 1;
+	}
+
+	function deleteCommand(cmd) {
+		if (!confirm("Delete /" + name(cmd) + "?")) { return; }
+
+		api("DELETE", "/api/v1/commands/" + encodeURIComponent(name(cmd)) + "?version=" + (cmd.version || 0))
+			.then(loadCommands)
+			.catch(showError);
+	}
+
+	// Editor
+
+	function openEditor(cmd) {
+		state.editing = cmd;
+		$("editor-title").textContent = cmd ? "Edit /" + name(cmd) : "New command";
+		$("f-command").value = cmd ? name(cmd) : "";
+		$("f-command").readOnly = !!cmd;
+		$("f-response").value = cmd ? responseOf(cmd) : "";
+		$("f-buttons").value = cmd ? ((cmd.resp && cmd.resp.buttons) || []).map(function (b) {
+			return b.text + "=" + b.command;
+		}).join("\n") : "";
+		$("f-description").value = cmd ? cmd.description || "" : "";
+		$("f-aliases").value = cmd ? (cmd.aliases || []).join(", ") : "";
+		$("f-hidden").checked = cmd ? !!cmd.hidden : false;
+		renderPreview();
+		show("editor");
+	}
+
+	function parseButtons() {
+		return $("f-buttons").value.split("\n").map(function (line) {
+			var i = line.indexOf("=");
+			return i < 0 ? null : { text: line.slice(0, i).trim(), command: line.slice(i + 1).trim() };
+		}).filter(function (b) { return b && b.text && b.command; });
+	}
+
+	function renderPreview() {
+		var preview = $("preview");
+		preview.textContent = $("f-response").value;
+		if (!preview.textContent) {
+			preview.appendChild(el("span", "The response is empty.", "muted"));
+		}
+
+		parseButtons().forEach(function (b) {
+			preview.appendChild(document.createTextNode(" "));
+			preview.appendChild(el("span", b.text, "button"));
+		});
+	}
+
+	function saveCommand(ev) {
+		ev.preventDefault();
+
+		// Start from the stored command so the fields
+		// that the form doesn't show are kept.
+		var cmd = state.editing ? JSON.parse(JSON.stringify(state.editing)) : {};
+		cmd.cmd = { command: $("f-command").value.trim() };
+		cmd.resp = cmd.resp || {};
+		cmd.resp.response = $("f-response").value;
+		cmd.resp.buttons = parseButtons();
+		cmd.description = $("f-description").value.trim();
+		cmd.aliases = $("f-aliases").value.split(",").map(function (a) { return a.trim(); }).filter(Boolean);
+		cmd.hidden = $("f-hidden").checked;
+
+		var req = state.editing
+			? api("PATCH", "/api/v1/commands/" + encodeURIComponent(cmd.cmd.command), cmd)
+			: api("POST", "/api/v1/commands", cmd);
+
+		req.then(function () {
+			show("commands");
+			return loadCommands();
+		}).catch(function (err) {
+			if (err.status === 412) {
+				err = new Error("The command was changed by someone else. Reload it and try again.");
+			}
+			showError(err);
+		});
+	}
+
+	// History
+
+	function openHistory(command) {
+		api("GET", "/api/v1/commands/" + encodeURIComponent(command) + "/revisions").then(function (data) {
+			$("history-title").textContent = "History of /" + command;
+			var list = $("revisions");
+			list.textContent = "";
+			(data.revisions || []).forEach(function (rev) {
+				var li = el("li");
+				var when = rev.time ? new Date(rev.time).toLocaleString() : "unknown time";
+				li.appendChild(el("strong", rev.action + " by " + (rev.author || "unknown") + " on " + when));
+				if (rev.diff) { li.appendChild(el("pre", rev.diff)); }
+				list.appendChild(li);
+			});
+
+			if (!list.firstChild) {
+				list.appendChild(el("li", "There are no revisions.", "muted"));
+			}
+
+			show("history");
+		}).catch(showError);
+	}
+
+	// Usage
+
+	function openUsage() {
+		show("usage");
+		api("GET", "/api/v1/usage?days=7&limit=10").then(function (data) {
+			$("usage-missing").hidden = true;
+			$("usage-tables").hidden = false;
+			fill("top-commands", data.top_commands, ["command", "hits", "errors", "chats", "avg_latency_ms"]);
+			fill("top-missing", data.top_missing, ["command", "misses", "chats"]);
+		}).catch(function () {
+			$("usage-missing").hidden = false;
+			$("usage-tables").hidden = true;
+		});
+	}
+
+	function fill(id, rows, fields) {
+		var body = $(id);
+		body.textContent = "";
+		(rows || []).forEach(function (row) {
+			var tr = el("tr");
+			fields.forEach(function (f) { tr.appendChild(el("td", String(row[f] || 0))); });
+			body.appendChild(tr);
+		});
+	}
+
+	// Session
+
+	function login(ev) {
+		ev.preventDefault();
+		sessionStorage.setItem("botio-token", $("token").value.trim());
+		start();
+	}
+
+	function logout() {
+		sessionStorage.removeItem("botio-token");
+		state.commands = [];
+		show("login");
+	}
+
+	function start() {
+		if (!token()) {
+			show("login");
+			return;
+		}
+
+		loadCommands().then(function () {
+			show("commands");
+		}).catch(function (err) {
+			sessionStorage.removeItem("botio-token");
+			show("login");
+			showError(new Error("Login failed: " + err.message));
+		});
+	}
+
+	document.addEventListener("DOMContentLoaded", function () {
+		$("login-form").addEventListener("submit", login);
+		$("logout").addEventListener("click", logout);
+		$("search").addEventListener("input", function () { state.page = 0; renderCommands(); });
+		$("prev").addEventListener("click", function () { state.page--; renderCommands(); });
+		$("next").addEventListener("click", function () { state.page++; renderCommands(); });
+		$("new").addEventListener("click", function () { openEditor(null); });
+		$("cancel").addEventListener("click", function () { show("commands"); });
+		$("history-back").addEventListener("click", function () { show("commands"); });
+		$("editor-form").addEventListener("submit", saveCommand);
+		$("f-response").addEventListener("input", renderPreview);
+		$("f-buttons").addEventListener("input", renderPreview);
+		document.querySelectorAll("nav [data-view]").forEach(function (b) {
+			b.addEventListener("click", function () {
+				if (b.dataset.view === "usage") {
+					openUsage();
+				} else {
+					show("commands");
+					loadCommands().catch(showError);
+				}
+			});
+		});
+		start();
+	});
+})();
+`
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAdminUI(t *testing.T) {
+	tt := []struct {
+		name                string
+		method              string
+		path                string
+		expectedStatus      int
+		expectedContentType string
+	}{
+		{
+			name:                "index",
+			method:              http.MethodGet,
+			path:                "/admin/",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/html",
+		},
+		{
+			name:                "script",
+			method:              http.MethodGet,
+			path:                "/admin/app.js",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/javascript",
+		},
+		{
+			name:                "stylesheet",
+			method:              http.MethodGet,
+			path:                "/admin/app.css",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/css",
+		},
+		{
+			name:           "without trailing slash",
+			method:         http.MethodGet,
+			path:           "/admin",
+			expectedStatus: http.StatusMovedPermanently,
+		},
+		{
+			name:           "unknown asset",
+			method:         http.MethodGet,
+			path:           "/admin/secret.txt",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "post",
+			method:         http.MethodPost,
+			path:           "/admin/",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/admin/", http.StripPrefix("/admin", adminUI()))
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+			if rec.Code != tc.expectedStatus {
+				t.Fatalf("expected status code %v. got=%v", tc.expectedStatus, rec.Code)
+			}
+
+			if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, tc.expectedContentType) {
+				t.Fatalf("expected content type %q. got=%q", tc.expectedContentType, ct)
+			}
+		})
+	}
+}
+
+func TestAdminAssetsAreSelfContained(t *testing.T) {
+	for path, asset := range adminAssets {
+		for _, external := range []string{"http://", "https://", "//cdn"} {
+			if strings.Contains(asset.content, external) {
+				t.Fatalf("expected asset %q to not reference %q", path, external)
+			}
+		}
+	}
+}
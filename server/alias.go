@@ -0,0 +1,52 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/pkg/errors"
+)
+
+// validateAliases checks that the aliases of the received
+// command are not empty, repeated or equal to its name.
+func validateAliases(cmd *proto.BotCommand) error {
+	seen := map[string]bool{cmd.GetCmd().GetCommand(): true}
+	for _, alias := range cmd.GetAliases() {
+		if alias == "" {
+			return errors.New("empty alias")
+		}
+
+		if seen[alias] {
+			return errors.Errorf("alias %q is repeated or equal to the command", alias)
+		}
+
+		seen[alias] = true
+	}
+
+	return nil
+}
+
+// uncache removes from the cache the stored command with the received
+// name under its name and all its aliases, so the cached aliases don't
+// outlive the command or the aliases that it no longer has. The keys
+// are removed even if they don't seem cached, since the cache may be
+// still adding them.
+func (s *server) uncache(cmd *proto.Command) {
+	keys := []string{cmd.GetCommand()}
+	if stored, err := s.db.Get(cmd); err == nil {
+		keys = append(keys, stored.GetCmd().GetCommand())
+		keys = append(keys, stored.GetAliases()...)
+	}
+
+	for _, key := range keys {
+		if err := s.cache.Remove(&proto.Command{Command: key}); err != nil {
+			s.logError(
+				"cache",
+				"Remove",
+				err.Error(),
+				fmt.Sprintf("remove BotCommand %q failed", key),
+			)
+		}
+	}
+}
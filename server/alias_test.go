@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danielkvist/botio/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAliases(t *testing.T) {
+	s := testServer(t)
+	start := &proto.BotCommand{
+		Cmd:     &proto.Command{Command: "start"},
+		Resp:    &proto.Response{Response: "hi"},
+		Aliases: []string{"hello", "hi"},
+	}
+
+	if _, err := s.AddCommand(context.TODO(), start); err != nil {
+		t.Fatalf("while adding command: %v", err)
+	}
+
+	tt := []struct {
+		name         string
+		command      *proto.BotCommand
+		expectedCode codes.Code
+	}{
+		{
+			name:         "alias used by another command",
+			command:      &proto.BotCommand{Cmd: &proto.Command{Command: "greet"}, Resp: &proto.Response{Response: "hey"}, Aliases: []string{"hi"}},
+			expectedCode: codes.AlreadyExists,
+		},
+		{
+			name:         "name used as alias",
+			command:      &proto.BotCommand{Cmd: &proto.Command{Command: "hello"}, Resp: &proto.Response{Response: "hey"}},
+			expectedCode: codes.AlreadyExists,
+		},
+		{
+			name:         "repeated alias",
+			command:      &proto.BotCommand{Cmd: &proto.Command{Command: "greet"}, Resp: &proto.Response{Response: "hey"}, Aliases: []string{"hey", "hey"}},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "empty alias",
+			command:      &proto.BotCommand{Cmd: &proto.Command{Command: "greet"}, Resp: &proto.Response{Response: "hey"}, Aliases: []string{""}},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := s.AddCommand(context.TODO(), tc.command)
+			if code := status.Code(err); code != tc.expectedCode {
+				t.Fatalf("expected code %v. got=%v (%v)", tc.expectedCode, code, err)
+			}
+		})
+	}
+
+	// The last alias is gotten from the cache.
+	for _, alias := range []string{"hello", "hi", "hi"} {
+		cmd, err := s.GetCommand(context.TODO(), &proto.Command{Command: alias})
+		if err != nil {
+			t.Fatalf("while getting command by alias %q: %v", alias, err)
+		}
+
+		if cmd.GetCmd().GetCommand() != "start" {
+			t.Fatalf("expected command %q for alias %q. got=%q", "start", alias, cmd.GetCmd().GetCommand())
+		}
+
+		// Let the cache admit the command.
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	start.Aliases = []string{"hello"}
+	if _, err := s.UpdateCommand(context.TODO(), start); err != nil {
+		t.Fatalf("while updating command: %v", err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if _, err := s.GetCommand(context.TODO(), &proto.Command{Command: "hi"}); err == nil {
+		t.Fatalf("expected removed alias %q not to resolve", "hi")
+	}
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/danielkvist/botio/audit"
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDKey is the metadata key of the ID that the clients
+// can send to correlate their requests with the audit events.
+const requestIDKey = "x-request-id"
+
+// ListAuditEvents returns the audit events that meet the conditions of the received filter from the
+// oldest to the newest. It returns a non-nil error if something went wrong, if the audit sink can't
+// list its events or if the context was cancelled.
+func (s *server) ListAuditEvents(ctx context.Context, f *proto.AuditFilter) (*proto.AuditEvents, error) {
+	var events *proto.AuditEvents
+	var err error
+
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return &proto.AuditEvents{}, status.Error(codes.Canceled, ctx.Err().Error())
+	default:
+		events, err = s.auditor.List(f)
+		if err == audit.ErrNotListable {
+			return &proto.AuditEvents{}, status.Error(codes.Unimplemented, err.Error())
+		}
+
+		if err != nil {
+			s.logError(
+				"audit",
+				"List",
+				err.Error(),
+				"list audit events failed",
+			)
+			return &proto.AuditEvents{}, status.Error(codes.Internal, "error while listing audit events")
+		}
+	}
+
+	s.logInfo(
+		"server",
+		"ListAuditEvents",
+		fmt.Sprintf("%v audit events gotten successfully", len(events.GetEvents())),
+		time.Since(start),
+	)
+	return events, nil
+}
+
+// audit writes an audit event for the change made to the command
+// with who made it and from where, and returns it. Errors are only
+// logged since the change has already been made.
+func (s *server) audit(ctx context.Context, cmd *proto.Command, action string, before, after *proto.BotCommand) *proto.AuditEvent {
+	ev := &proto.AuditEvent{
+		Action:    action,
+		Command:   cmd.GetCommand(),
+		Subject:   subjectFromContext(ctx),
+		Peer:      clientHost(ctx),
+		Time:      ptypes.TimestampNow(),
+		Before:    before,
+		After:     after,
+		RequestId: requestID(ctx),
+	}
+
+	if err := s.auditor.Write(ev); err != nil {
+		s.logError(
+			"audit",
+			"Write",
+			err.Error(),
+			fmt.Sprintf("write %s audit event of BotCommand %q failed", action, cmd.GetCommand()),
+		)
+	}
+
+	return ev
+}
+
+// requestID returns the ID sent by the client with the
+// request or, if there is none, a new random one.
+func requestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if id := md.Get(requestIDKey); len(id) > 0 && id[0] != "" {
+			return id[0]
+		}
+	}
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
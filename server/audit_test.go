@@ -0,0 +1,122 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestListAuditEvents(t *testing.T) {
+	s := testServer(t)
+	gateway := peer.NewContext(context.TODO(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1234}})
+	ctx := metadata.NewIncomingContext(
+		context.WithValue(gateway, subjectKey, "ops"),
+		metadata.Pairs(requestIDKey, "req-1", "x-forwarded-for", "10.0.0.1"),
+	)
+
+	start := &proto.BotCommand{Cmd: &proto.Command{Command: "start"}, Resp: &proto.Response{Response: "hi"}}
+	if _, err := s.AddCommand(ctx, start); err != nil {
+		t.Fatalf("while adding command: %v", err)
+	}
+
+	if _, err := s.UpdateCommand(context.TODO(), &proto.BotCommand{Cmd: start.GetCmd(), Resp: &proto.Response{Response: "hello"}}); err != nil {
+		t.Fatalf("while updating command: %v", err)
+	}
+
+	if _, err := s.DeleteCommand(ctx, start.GetCmd()); err != nil {
+		t.Fatalf("while deleting command: %v", err)
+	}
+
+	tt := []struct {
+		name            string
+		filter          *proto.AuditFilter
+		expectedActions []string
+	}{
+		{
+			name:            "all events",
+			filter:          &proto.AuditFilter{},
+			expectedActions: []string{actionAdd, actionUpdate, actionDelete},
+		},
+		{
+			name:            "by subject",
+			filter:          &proto.AuditFilter{Subject: "ops"},
+			expectedActions: []string{actionAdd, actionDelete},
+		},
+		{
+			name:            "by action",
+			filter:          &proto.AuditFilter{Action: actionUpdate},
+			expectedActions: []string{actionUpdate},
+		},
+		{
+			name:            "with limit",
+			filter:          &proto.AuditFilter{Limit: 1},
+			expectedActions: []string{actionDelete},
+		},
+		{
+			name:   "by other command",
+			filter: &proto.AuditFilter{Command: "stop"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			events, err := s.ListAuditEvents(context.TODO(), tc.filter)
+			if err != nil {
+				t.Fatalf("while listing audit events: %v", err)
+			}
+
+			if len(events.GetEvents()) != len(tc.expectedActions) {
+				t.Fatalf("expected %v audit events. got=%v", len(tc.expectedActions), len(events.GetEvents()))
+			}
+
+			for i, ev := range events.GetEvents() {
+				if ev.GetAction() != tc.expectedActions[i] {
+					t.Fatalf("expected audit event %v to be %q. got=%q", i, tc.expectedActions[i], ev.GetAction())
+				}
+			}
+		})
+	}
+
+	events, err := s.ListAuditEvents(context.TODO(), &proto.AuditFilter{Action: actionDelete})
+	if err != nil {
+		t.Fatalf("while listing audit events: %v", err)
+	}
+
+	deleted := events.GetEvents()[0]
+	switch {
+	case deleted.GetRequestId() != "req-1":
+		t.Fatalf("expected request ID %q. got=%q", "req-1", deleted.GetRequestId())
+	case deleted.GetPeer() != "10.0.0.1":
+		t.Fatalf("expected peer %q. got=%q", "10.0.0.1", deleted.GetPeer())
+	case deleted.GetBefore().GetResp().GetResponse() != "hello" || deleted.GetAfter() != nil:
+		t.Fatalf("expected the command before and not after the deletion. got=%v and %v", deleted.GetBefore(), deleted.GetAfter())
+	}
+}
+
+func TestListAuditEventsNotListable(t *testing.T) {
+	s, err := New(
+		WithTestDB(),
+		WithRistrettoCache(262144000),
+		WithListener(":0"),
+		WithInsecureGRPCServer(),
+		WithJWTAuthToken("testing"),
+		WithTextLogger(&bytes.Buffer{}),
+		WithAudit("stdout", ""),
+	)
+	if err != nil {
+		t.Fatalf("while creating a new Server for testing: %v", err)
+	}
+
+	_, err = s.ListAuditEvents(context.TODO(), &proto.AuditFilter{})
+	if code := status.Code(err); code != codes.Unimplemented {
+		t.Fatalf("expected code %v. got=%v", codes.Unimplemented, code)
+	}
+}
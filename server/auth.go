@@ -9,6 +9,17 @@ import (
 	"google.golang.org/grpc/metadata"
 )
 
+type contextKey string
+
+const subjectKey contextKey = "subject"
+
+// subjectFromContext returns the subject of the JWT used to
+// authenticate the request, if any.
+func subjectFromContext(ctx context.Context) string {
+	sub, _ := ctx.Value(subjectKey).(string)
+	return sub
+}
+
 func (s *server) jwtAuth(ctx context.Context) (context.Context, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
@@ -64,5 +75,11 @@ func (s *server) jwtAuth(ctx context.Context) (context.Context, error) {
 		return nil, err
 	}
 
+	if claims, ok := token.Claims.(jwt.MapClaims); ok {
+		if sub, ok := claims["sub"].(string); ok && sub != "" {
+			ctx = context.WithValue(ctx, subjectKey, sub)
+		}
+	}
+
 	return ctx, nil
 }
@@ -0,0 +1,239 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/danielkvist/botio/proto"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultCalloutTimeout is the time to wait for the
+	// endpoint of a callout that has no timeout.
+	defaultCalloutTimeout = 5 * time.Second
+	// maxCalloutBody is the maximum size in bytes of
+	// the answer of the endpoint of a callout.
+	maxCalloutBody = 1 << 20
+	// maxCalloutCache is the maximum number of cached responses,
+	// and of parsed templates, after which the least recently
+	// used ones are evicted.
+	maxCalloutCache = 1000
+	// maxCalloutCacheSize is the maximum size in bytes of the cached
+	// responses, and of the parsed templates, after which the least
+	// recently used ones are evicted.
+	maxCalloutCacheSize = 16 << 20
+)
+
+// calloutRequest is the body posted to the endpoint of a callout.
+type calloutRequest struct {
+	Command  string   `json:"command"`
+	Args     []string `json:"args"`
+	Lang     string   `json:"lang,omitempty"`
+	Platform string   `json:"platform,omitempty"`
+	ChatID   string   `json:"chat_id,omitempty"`
+	UserID   string   `json:"user_id,omitempty"`
+}
+
+// callouts calls the endpoints that provide the responses of the
+// commands and keeps the responses during their cache TTL, as long as
+// they fit on the cache. The templates of the callouts are parsed once
+// and kept while they are used.
+type callouts struct {
+	client    *http.Client
+	cache     *lru
+	templates *lru
+}
+
+func newCallouts() *callouts {
+	return &callouts{
+		client:    &http.Client{},
+		cache:     newLRU(maxCalloutCache, maxCalloutCacheSize),
+		templates: newLRU(maxCalloutCache, maxCalloutCacheSize),
+	}
+}
+
+// resolve returns a copy of the received command whose response is
+// the one provided by its callout for the request, or the fallback of
+// the callout if it fails. Commands without callout are returned as
+// they are.
+func (co *callouts) resolve(ctx context.Context, cmd *proto.BotCommand, req *proto.Command) (*proto.BotCommand, error) {
+	callout := cmd.GetCallout()
+	if callout == nil {
+		return cmd, nil
+	}
+
+	text, err := co.respond(ctx, callout, req)
+	if err != nil {
+		if callout.GetFallback() == "" {
+			return nil, err
+		}
+
+		text = callout.GetFallback()
+	}
+
+	resolved := pb.Clone(cmd).(*proto.BotCommand)
+	if resolved.Resp == nil {
+		resolved.Resp = &proto.Response{}
+	}
+	resolved.Resp.Response = text
+
+	return resolved, err
+}
+
+func (co *callouts) respond(ctx context.Context, callout *proto.Callout, req *proto.Command) (string, error) {
+	key := calloutKey(callout, req)
+	if cached, ok := co.cache.get(key); ok {
+		return cached.(string), nil
+	}
+
+	body, err := co.call(ctx, callout, req)
+	if err != nil {
+		return "", err
+	}
+
+	text, err := co.render(callout.GetTemplate(), body)
+	if err != nil {
+		return "", errors.Wrapf(err, "while rendering the callout of command %q", req.GetCommand())
+	}
+
+	if ttl := time.Duration(callout.GetCacheTtl()) * time.Second; ttl > 0 {
+		co.cache.add(key, text, len(text), time.Now().Add(ttl))
+	}
+
+	return text, nil
+}
+
+// calloutKey returns the key under which the response of the callout
+// to the request is cached. Unless the callout has a shared cache the
+// key includes who sent the request, since the endpoint receives it too.
+func calloutKey(callout *proto.Callout, req *proto.Command) string {
+	parts := []string{callout.GetUrl(), callout.GetTemplate(), req.GetCommand(), req.GetLang()}
+	if !callout.GetSharedCache() {
+		caller := req.GetCaller()
+		parts = append(parts, caller.GetPlatform(), caller.GetChatId(), caller.GetUserId())
+	}
+
+	return strings.Join(append(parts, req.GetArgs()...), "\x00")
+}
+
+// call posts the request to the endpoint of the callout and returns its answer.
+func (co *callouts) call(ctx context.Context, callout *proto.Callout, req *proto.Command) ([]byte, error) {
+	timeout := time.Duration(callout.GetTimeoutMs()) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultCalloutTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	payload, err := json.Marshal(calloutRequest{
+		Command:  req.GetCommand(),
+		Args:     req.GetArgs(),
+		Lang:     req.GetLang(),
+		Platform: req.GetCaller().GetPlatform(),
+		ChatID:   req.GetCaller().GetChatId(),
+		UserID:   req.GetCaller().GetUserId(),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "while encoding the callout request of command %q", req.GetCommand())
+	}
+
+	r, err := http.NewRequest(http.MethodPost, callout.GetUrl(), bytes.NewReader(payload))
+	if err != nil {
+		return nil, errors.Wrapf(err, "while creating the callout request of command %q", req.GetCommand())
+	}
+	r.Header.Set("Content-Type", "application/json")
+
+	resp, err := co.client.Do(r.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrapf(err, "while calling the callout of command %q", req.GetCommand())
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxCalloutBody))
+	if err != nil {
+		return nil, errors.Wrapf(err, "while reading the callout answer of command %q", req.GetCommand())
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, errors.Errorf("unexpected status %q from the callout of command %q", resp.Status, req.GetCommand())
+	}
+
+	return body, nil
+}
+
+// render executes the template with the received JSON as data,
+// or returns the JSON as it is if there is no template.
+func (co *callouts) render(tmpl string, body []byte) (string, error) {
+	if tmpl == "" {
+		return string(body), nil
+	}
+
+	t, err := co.template(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return "", errors.Wrap(err, "while decoding answer")
+	}
+
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return "", errors.Wrap(err, "while executing template")
+	}
+
+	return out.String(), nil
+}
+
+// template returns the parsed template, parsing it only
+// if it's not kept from a previous response.
+func (co *callouts) template(tmpl string) (*template.Template, error) {
+	if t, ok := co.templates.get(tmpl); ok {
+		return t.(*template.Template), nil
+	}
+
+	t, err := template.New("callout").Option("missingkey=zero").Parse(tmpl)
+	if err != nil {
+		return nil, errors.Wrap(err, "while parsing template")
+	}
+
+	co.templates.add(tmpl, t, len(tmpl), time.Time{})
+	return t, nil
+}
+
+// validateCallout checks that the callout of the received
+// command, if any, has an HTTP URL and a valid template.
+func validateCallout(cmd *proto.BotCommand) error {
+	callout := cmd.GetCallout()
+	if callout == nil {
+		return nil
+	}
+
+	u, err := url.Parse(callout.GetUrl())
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("URL %q is not an absolute HTTP URL", callout.GetUrl())
+	}
+
+	if _, err := template.New("callout").Parse(callout.GetTemplate()); err != nil {
+		return errors.Wrap(err, "invalid template")
+	}
+
+	if callout.GetTimeoutMs() < 0 || callout.GetCacheTtl() < 0 {
+		return errors.Errorf("invalid timeout of %vms or cache TTL of %vs", callout.GetTimeoutMs(), callout.GetCacheTtl())
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danielkvist/botio/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCallouts(t *testing.T) {
+	var calls int32
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		var req calloutRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch strings.Join(req.Args, " ") {
+		case "slow":
+			time.Sleep(200 * time.Millisecond)
+		case "broken":
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		fmt.Fprintf(w, `{"status": "passing", "branch": %q, "user": %q}`, strings.Join(req.Args, " "), req.UserID)
+	}))
+	defer endpoint.Close()
+
+	s := testServer(t)
+	ctx := context.TODO()
+
+	if _, err := s.AddCommand(ctx, &proto.BotCommand{
+		Cmd:     &proto.Command{Command: "invalid"},
+		Callout: &proto.Callout{Url: "/build", Template: "{{.status"},
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected %v adding a command with an invalid callout. got=%v", codes.InvalidArgument, err)
+	}
+
+	commands := []*proto.BotCommand{
+		{
+			Cmd:     &proto.Command{Command: "build"},
+			Callout: &proto.Callout{Url: endpoint.URL, Template: "Build of {{.branch}} is {{.status}}", TimeoutMs: 50, CacheTtl: 60},
+		},
+		{
+			Cmd:     &proto.Command{Command: "raw"},
+			Callout: &proto.Callout{Url: endpoint.URL, Fallback: "unknown"},
+		},
+		{
+			Cmd:     &proto.Command{Command: "whoami"},
+			Callout: &proto.Callout{Url: endpoint.URL, Template: "You are {{.user}}", TimeoutMs: 50, Fallback: "nobody"},
+		},
+	}
+
+	for _, cmd := range commands {
+		if _, err := s.AddCommand(ctx, cmd); err != nil {
+			t.Fatalf("while adding command %q: %v", cmd.GetCmd().GetCommand(), err)
+		}
+	}
+
+	tt := []struct {
+		name             string
+		cmd              *proto.Command
+		expectedResponse string
+		expectedCode     codes.Code
+	}{
+		{
+			name:             "rendered",
+			cmd:              &proto.Command{Command: "build", Args: []string{"main"}},
+			expectedResponse: "Build of main is passing",
+		},
+		{
+			name:             "without template",
+			cmd:              &proto.Command{Command: "raw", Args: []string{"dev"}},
+			expectedResponse: `{"status": "passing", "branch": "dev", "user": ""}`,
+		},
+		{
+			name:             "with caller",
+			cmd:              &proto.Command{Command: "whoami", Caller: &proto.Caller{UserId: "42"}},
+			expectedResponse: "You are 42",
+		},
+		{
+			name:             "fallback on failure",
+			cmd:              &proto.Command{Command: "raw", Args: []string{"broken"}},
+			expectedResponse: "unknown",
+		},
+		{
+			name:             "fallback on timeout",
+			cmd:              &proto.Command{Command: "whoami", Args: []string{"slow"}},
+			expectedResponse: "nobody",
+		},
+		{
+			name:         "timeout without fallback",
+			cmd:          &proto.Command{Command: "build", Args: []string{"slow"}},
+			expectedCode: codes.Unavailable,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := s.GetCommand(ctx, tc.cmd)
+			if status.Code(err) != tc.expectedCode {
+				t.Fatalf("expected code %v. got=%v", tc.expectedCode, err)
+			}
+
+			if err == nil && c.GetResp().GetResponse() != tc.expectedResponse {
+				t.Fatalf("expected response %q. got=%q", tc.expectedResponse, c.GetResp().GetResponse())
+			}
+		})
+	}
+
+	atomic.StoreInt32(&calls, 0)
+	for i := 0; i < 3; i++ {
+		if _, err := s.GetCommand(ctx, &proto.Command{Command: "build", Args: []string{"main"}}); err != nil {
+			t.Fatalf("while getting command: %v", err)
+		}
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 0 {
+		t.Fatalf("expected cached responses to be reused. got %v calls", n)
+	}
+
+	if _, err := s.GetCommand(ctx, &proto.Command{Command: "build", Args: []string{"dev"}}); err != nil {
+		t.Fatalf("while getting command: %v", err)
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("expected a call for other arguments. got %v calls", n)
+	}
+
+	if _, err := s.GetCommand(ctx, &proto.Command{Command: "build", Args: []string{"main"}, Caller: &proto.Caller{UserId: "42"}}); err != nil {
+		t.Fatalf("while getting command: %v", err)
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("expected a call for another caller. got %v calls", n)
+	}
+
+	// The templates are parsed once no matter how many times they're rendered.
+	if n := s.(*server).callouts.templates.len(); n != 2 {
+		t.Fatalf("expected %v parsed templates. got=%v", 2, n)
+	}
+}
+
+func TestCalloutKey(t *testing.T) {
+	req := &proto.Command{Command: "build", Args: []string{"main"}, Caller: &proto.Caller{Platform: "telegram", ChatId: "1", UserId: "42"}}
+	other := &proto.Command{Command: "build", Args: []string{"main"}, Caller: &proto.Caller{Platform: "telegram", ChatId: "1", UserId: "7"}}
+
+	private := &proto.Callout{Url: "http://ci", CacheTtl: 60}
+	if calloutKey(private, req) == calloutKey(private, other) {
+		t.Fatalf("expected different cache keys for different callers")
+	}
+
+	shared := &proto.Callout{Url: "http://ci", CacheTtl: 60, SharedCache: true}
+	if calloutKey(shared, req) != calloutKey(shared, other) {
+		t.Fatalf("expected the same cache key for different callers on a shared cache")
+	}
+}
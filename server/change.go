@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// changes counts the changes made to the commands through
+// a server. The counter starts from zero on every start, so
+// the time at which it started is part of its tokens.
+type changes struct {
+	started int64
+	count   uint64
+}
+
+func newChanges() *changes {
+	return &changes{started: time.Now().UnixNano()}
+}
+
+// bump counts a new change.
+func (c *changes) bump() {
+	atomic.AddUint64(&c.count, 1)
+}
+
+// token returns a token that differs from the previous
+// ones every time a change is counted or the server restarts.
+func (c *changes) token() string {
+	return fmt.Sprintf("%x-%d", c.started, atomic.LoadUint64(&c.count))
+}
+
+// GetChangeToken returns a token that changes every time a command is
+// changed through the server, so bots can get the commands only when
+// they change. Changes made through other servers sharing the same
+// database are not counted. It returns a non-nil error if the context
+// was cancelled.
+func (s *server) GetChangeToken(ctx context.Context, _ *empty.Empty) (*proto.ChangeToken, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, status.Error(codes.Canceled, err.Error())
+	}
+
+	return &proto.ChangeToken{Token: s.changes.token()}, nil
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/ptypes/empty"
+)
+
+func TestChangeToken(t *testing.T) {
+	s := testServer(t)
+	token := func() string {
+		t.Helper()
+		tok, err := s.GetChangeToken(context.TODO(), &empty.Empty{})
+		if err != nil {
+			t.Fatalf("while getting change token: %v", err)
+		}
+
+		return tok.GetToken()
+	}
+
+	start := token()
+	if token() != start {
+		t.Fatalf("expected change token to stay the same without changes")
+	}
+
+	if _, err := s.AddCommand(context.TODO(), &proto.BotCommand{
+		Cmd:  &proto.Command{Command: "start"},
+		Resp: &proto.Response{Response: "hi"},
+	}); err != nil {
+		t.Fatalf("while adding command: %v", err)
+	}
+
+	added := token()
+	if added == start {
+		t.Fatalf("expected change token to change after adding a command")
+	}
+
+	if _, err := s.GetCommand(context.TODO(), &proto.Command{Command: "start"}); err != nil {
+		t.Fatalf("while getting command: %v", err)
+	}
+
+	if token() != added {
+		t.Fatalf("expected change token to stay the same after getting a command")
+	}
+}
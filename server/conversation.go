@@ -0,0 +1,239 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const defaultConversationTTL = 10 * time.Minute
+
+// conversations keeps the step in which the conversation
+// of each caller is. Conversations expire after ttl
+// without receiving any answer.
+type conversations struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	states    map[string]*conversation
+	lastSweep time.Time
+}
+
+type conversation struct {
+	command string
+	step    string
+	expires time.Time
+}
+
+func newConversations(ttl time.Duration) *conversations {
+	return &conversations{
+		ttl:       ttl,
+		states:    make(map[string]*conversation),
+		lastSweep: time.Now(),
+	}
+}
+
+func (cs *conversations) get(key string) (*conversation, bool) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(cs.lastSweep) > cs.ttl {
+		for k, c := range cs.states {
+			if now.After(c.expires) {
+				delete(cs.states, k)
+			}
+		}
+
+		cs.lastSweep = now
+	}
+
+	c, ok := cs.states[key]
+	if !ok || now.After(c.expires) {
+		delete(cs.states, key)
+		return nil, false
+	}
+
+	return c, true
+}
+
+func (cs *conversations) set(key, command, step string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.states[key] = &conversation{
+		command: command,
+		step:    step,
+		expires: time.Now().Add(cs.ttl),
+	}
+}
+
+func (cs *conversations) end(key string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	delete(cs.states, key)
+}
+
+// Converse starts or advances the conversation of the received caller. If the
+// caller has no conversation in progress and the input doesn't start with a
+// command with a Flow it returns a codes.NotFound error, so the bots can
+// handle the input as a regular command. Conversations in progress are
+// returned with the time after which they end without an answer.
+func (s *server) Converse(ctx context.Context, req *proto.ConverseRequest) (*proto.ConverseResponse, error) {
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return &proto.ConverseResponse{}, status.Error(codes.Canceled, ctx.Err().Error())
+	default:
+	}
+
+	caller := req.GetCaller()
+	if caller.GetPlatform() == "" || caller.GetChatId() == "" || caller.GetUserId() == "" {
+		return &proto.ConverseResponse{}, status.Error(codes.InvalidArgument, "caller must have a platform, a chat ID and a user ID")
+	}
+
+	key := strings.Join([]string{caller.GetPlatform(), caller.GetChatId(), caller.GetUserId()}, ":")
+	input := strings.TrimSpace(req.GetInput())
+
+	var resp *proto.ConverseResponse
+	if c, ok := s.conversations.get(key); ok {
+		cmd, err := s.GetCommand(ctx, &proto.Command{Command: c.command, Caller: caller})
+		if err != nil || cmd.GetFlow() == nil {
+			s.conversations.end(key)
+			return &proto.ConverseResponse{}, status.Errorf(codes.NotFound, "command %q of the conversation not found", c.command)
+		}
+
+		resp = s.advance(key, c.command, cmd.GetFlow(), c.step, input)
+	} else {
+		fields := strings.Fields(input)
+		if len(fields) == 0 {
+			return &proto.ConverseResponse{}, status.Error(codes.NotFound, "no conversation in progress")
+		}
+
+		command := strings.TrimPrefix(fields[0], "/")
+		cmd, err := s.GetCommand(ctx, &proto.Command{Command: command, Caller: req.GetCaller()})
+		if err != nil || cmd.GetFlow() == nil {
+			return &proto.ConverseResponse{}, status.Error(codes.NotFound, "no conversation in progress")
+		}
+
+		resp = s.enter(key, command, cmd.GetFlow(), cmd.GetFlow().GetStart())
+	}
+
+	if !resp.GetDone() {
+		resp.Ttl = ptypes.DurationProto(s.conversations.ttl)
+	}
+
+	s.logInfo(
+		"server",
+		"Converse",
+		fmt.Sprintf("conversation of %q advanced successfully", key),
+		time.Since(start),
+	)
+	return resp, nil
+}
+
+// advance moves the conversation to the step that follows
+// the received answer to the current step.
+func (s *server) advance(key, command string, flow *proto.Flow, current, input string) *proto.ConverseResponse {
+	if flow.GetCancel() != "" && strings.EqualFold(input, flow.GetCancel()) {
+		s.conversations.end(key)
+		return &proto.ConverseResponse{Done: true}
+	}
+
+	step := flow.GetSteps()[current]
+	for answer, next := range step.GetBranches() {
+		if strings.EqualFold(strings.TrimPrefix(input, "/"), answer) {
+			return s.enter(key, command, flow, next)
+		}
+	}
+
+	if step.GetNext() != "" {
+		return s.enter(key, command, flow, step.GetNext())
+	}
+
+	s.conversations.set(key, command, current)
+
+	retry := step.GetRetry()
+	if retry == "" {
+		retry = step.GetPrompt()
+	}
+
+	return &proto.ConverseResponse{Response: stepResponse(retry, step)}
+}
+
+// enter moves the conversation to the received step and
+// ends it if the step is the last one of the flow.
+func (s *server) enter(key, command string, flow *proto.Flow, name string) *proto.ConverseResponse {
+	step := flow.GetSteps()[name]
+	if len(step.GetBranches()) == 0 && step.GetNext() == "" {
+		s.conversations.end(key)
+		return &proto.ConverseResponse{
+			Response: stepResponse(step.GetPrompt(), step),
+			Done:     true,
+		}
+	}
+
+	s.conversations.set(key, command, name)
+	return &proto.ConverseResponse{Response: stepResponse(step.GetPrompt(), step)}
+}
+
+// stepResponse returns a *proto.Response with the received text
+// and a button for each expected answer of the step.
+func stepResponse(text string, step *proto.Step) *proto.Response {
+	answers := make([]string, 0, len(step.GetBranches()))
+	for answer := range step.GetBranches() {
+		answers = append(answers, answer)
+	}
+	sort.Strings(answers)
+
+	resp := &proto.Response{Response: text}
+	for _, answer := range answers {
+		resp.Buttons = append(resp.Buttons, &proto.Button{Text: answer, Command: answer})
+	}
+
+	return resp
+}
+
+// validateFlow checks that every step referenced by
+// the received Flow exists and has a prompt.
+func validateFlow(flow *proto.Flow) error {
+	if flow == nil {
+		return nil
+	}
+
+	steps := flow.GetSteps()
+	if _, ok := steps[flow.GetStart()]; !ok {
+		return errors.Errorf("start step %q not found", flow.GetStart())
+	}
+
+	for name, step := range steps {
+		if step.GetPrompt() == "" {
+			return errors.Errorf("step %q has no prompt", name)
+		}
+
+		if next := step.GetNext(); next != "" {
+			if _, ok := steps[next]; !ok {
+				return errors.Errorf("next step %q of step %q not found", next, name)
+			}
+		}
+
+		for answer, next := range step.GetBranches() {
+			if _, ok := steps[next]; !ok {
+				return errors.Errorf("step %q for answer %q of step %q not found", next, answer, name)
+			}
+		}
+	}
+
+	return nil
+}
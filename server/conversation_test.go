@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestConverse(t *testing.T) {
+	order := &proto.BotCommand{
+		Cmd:  &proto.Command{Command: "order"},
+		Resp: &proto.Response{Response: "Let's order something"},
+		Flow: &proto.Flow{
+			Start:  "size",
+			Cancel: "cancel",
+			Steps: map[string]*proto.Step{
+				"size": {
+					Prompt:   "Small or big?",
+					Branches: map[string]string{"small": "name", "big": "big"},
+					Retry:    "Please, answer small or big.",
+				},
+				"big":  {Prompt: "Are you sure?", Branches: map[string]string{"yes": "name", "no": "size"}},
+				"name": {Prompt: "What's your name?", Next: "done"},
+				"done": {Prompt: "Thanks! Your order is on its way."},
+			},
+		},
+	}
+
+	tt := []struct {
+		name     string
+		caller   *proto.Caller
+		inputs   []string
+		expected []string
+		done     []bool
+	}{
+		{
+			name:     "straight to the end",
+			caller:   &proto.Caller{Platform: "telegram", ChatId: "1", UserId: "a"},
+			inputs:   []string{"/order", "SMALL", "Alice"},
+			expected: []string{"Small or big?", "What's your name?", "Thanks! Your order is on its way."},
+			done:     []bool{false, false, true},
+		},
+		{
+			name:     "with retry and loop",
+			caller:   &proto.Caller{Platform: "telegram", ChatId: "1", UserId: "b"},
+			inputs:   []string{"order", "medium", "big", "no", "big", "yes", "Bob"},
+			expected: []string{"Small or big?", "Please, answer small or big.", "Are you sure?", "Small or big?", "Are you sure?", "What's your name?", "Thanks! Your order is on its way."},
+			done:     []bool{false, false, false, false, false, false, true},
+		},
+		{
+			name:     "cancelled",
+			caller:   &proto.Caller{Platform: "discord", ChatId: "1", UserId: "a"},
+			inputs:   []string{"/order", "cancel"},
+			expected: []string{"Small or big?", ""},
+			done:     []bool{false, true},
+		},
+	}
+
+	s := testServer(t)
+	if _, err := s.AddCommand(context.TODO(), order); err != nil {
+		t.Fatalf("while adding command: %v", err)
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			for i, input := range tc.inputs {
+				resp, err := s.Converse(context.TODO(), &proto.ConverseRequest{Caller: tc.caller, Input: input})
+				if err != nil {
+					t.Fatalf("(%v) while conversing: %v", i, err)
+				}
+
+				if resp.GetResponse().GetResponse() != tc.expected[i] {
+					t.Fatalf("(%v) expected response %q. got=%q", i, tc.expected[i], resp.GetResponse().GetResponse())
+				}
+
+				if resp.GetDone() != tc.done[i] {
+					t.Fatalf("(%v) expected done to be %v. got=%v", i, tc.done[i], resp.GetDone())
+				}
+
+				ttl, _ := ptypes.Duration(resp.GetTtl())
+				if expected := defaultConversationTTL; !resp.GetDone() && ttl != expected {
+					t.Fatalf("(%v) expected a TTL of %v for a conversation in progress. got=%v", i, expected, ttl)
+				}
+			}
+
+			if _, err := s.Converse(context.TODO(), &proto.ConverseRequest{Caller: tc.caller, Input: "hello"}); status.Code(err) != codes.NotFound {
+				t.Fatalf("expected conversation to be over. got=%v", err)
+			}
+		})
+	}
+}
+
+func TestConverseWithoutFlow(t *testing.T) {
+	s := testServer(t)
+	if _, err := s.AddCommand(context.TODO(), &proto.BotCommand{
+		Cmd:  &proto.Command{Command: "start"},
+		Resp: &proto.Response{Response: "hi"},
+	}); err != nil {
+		t.Fatalf("while adding command: %v", err)
+	}
+
+	caller := &proto.Caller{Platform: "telegram", ChatId: "1", UserId: "a"}
+	for _, input := range []string{"/start", "/unknown", ""} {
+		if _, err := s.Converse(context.TODO(), &proto.ConverseRequest{Caller: caller, Input: input}); status.Code(err) != codes.NotFound {
+			t.Fatalf("expected %v for input %q. got=%v", codes.NotFound, input, err)
+		}
+	}
+
+	if _, err := s.Converse(context.TODO(), &proto.ConverseRequest{Input: "/start"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected %v without caller. got=%v", codes.InvalidArgument, err)
+	}
+}
+
+func TestConversationTTL(t *testing.T) {
+	cs := newConversations(10 * time.Millisecond)
+	cs.set("key", "order", "size")
+	if _, ok := cs.get("key"); !ok {
+		t.Fatalf("expected conversation to be in progress")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := cs.get("key"); ok {
+		t.Fatalf("expected conversation to have expired")
+	}
+}
+
+func TestValidateFlow(t *testing.T) {
+	tt := []struct {
+		name           string
+		flow           *proto.Flow
+		expectedToFail bool
+	}{
+		{
+			name: "without flow",
+		},
+		{
+			name: "valid flow",
+			flow: &proto.Flow{
+				Start: "a",
+				Steps: map[string]*proto.Step{
+					"a": {Prompt: "a", Next: "b"},
+					"b": {Prompt: "b"},
+				},
+			},
+		},
+		{
+			name:           "without start step",
+			flow:           &proto.Flow{Start: "a"},
+			expectedToFail: true,
+		},
+		{
+			name: "with unknown branch",
+			flow: &proto.Flow{
+				Start: "a",
+				Steps: map[string]*proto.Step{
+					"a": {Prompt: "a", Branches: map[string]string{"yes": "b"}},
+				},
+			},
+			expectedToFail: true,
+		},
+		{
+			name: "without prompt",
+			flow: &proto.Flow{
+				Start: "a",
+				Steps: map[string]*proto.Step{"a": {}},
+			},
+			expectedToFail: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := validateFlow(tc.flow); (err != nil) != tc.expectedToFail {
+				t.Fatalf("expected error to be %v. got=%v", tc.expectedToFail, err)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"net/http"
+	"strings"
 
 	"github.com/danielkvist/botio/proto"
 
@@ -16,7 +17,11 @@ func (s *server) jsonGateway() error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	mux := runtime.NewServeMux()
+	mux := runtime.NewServeMux(
+		runtime.WithForwardResponseOption(setETag),
+		runtime.WithIncomingHeaderMatcher(incomingHeader),
+		runtime.WithProtoErrorHandler(httpError),
+	)
 	options := []grpc.DialOption{
 		grpc.WithInsecure(),
 	}
@@ -26,5 +31,28 @@ func (s *server) jsonGateway() error {
 		return errors.Wrapf(err, "while registering Botio HTTP handler")
 	}
 
-	return http.ListenAndServe(s.httpPort, mux)
+	root := http.NewServeMux()
+	root.Handle("/admin/", http.StripPrefix("/admin", adminUI()))
+	root.Handle("/api/v1/openapi.json", openAPI())
+	if s.docs {
+		root.Handle("/api/docs/", http.StripPrefix("/api/docs", swaggerUI()))
+	}
+	root.Handle("/", mux)
+
+	return http.ListenAndServe(s.httpPort, root)
+}
+
+// incomingHeader forwards to the gRPC server the X-Request-Id header
+// and the Token header, as the token metadata used to authenticate the
+// requests, alongside the ones forwarded by default.
+func incomingHeader(key string) (string, bool) {
+	if strings.EqualFold(key, requestIDKey) {
+		return requestIDKey, true
+	}
+
+	if strings.EqualFold(key, "token") {
+		return "token", true
+	}
+
+	return runtime.DefaultHeaderMatcher(key)
 }
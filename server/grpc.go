@@ -3,17 +3,22 @@ package server
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/danielkvist/botio/access"
+	"github.com/danielkvist/botio/db"
 	"github.com/danielkvist/botio/proto"
 
 	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
-// AddCommand tries to add a received command to the Server's database. It returns a non-nil error
-// if something went wrong or if the context was cancelled.
+// AddCommand tries to add a received command to the Server's database, recording it as a new
+// revision. It returns a non-nil error if something went wrong or if the context was cancelled.
 func (s *server) AddCommand(ctx context.Context, cmd *proto.BotCommand) (*empty.Empty, error) {
 	start := time.Now()
 
@@ -21,17 +26,55 @@ func (s *server) AddCommand(ctx context.Context, cmd *proto.BotCommand) (*empty.
 	case <-ctx.Done():
 		return &empty.Empty{}, status.Error(codes.Canceled, ctx.Err().Error())
 	default:
-		if err := s.db.Add(cmd); err != nil {
+		if cmd.GetCmd().GetCommand() == "" {
+			return &empty.Empty{}, status.Error(codes.InvalidArgument, "no command provided")
+		}
+
+		if err := validateFlow(cmd.GetFlow()); err != nil {
+			return &empty.Empty{}, status.Errorf(codes.InvalidArgument, "invalid flow: %v", err)
+		}
+
+		if err := validateAliases(cmd); err != nil {
+			return &empty.Empty{}, status.Errorf(codes.InvalidArgument, "invalid aliases: %v", err)
+		}
+
+		if err := validateTranslations(cmd); err != nil {
+			return &empty.Empty{}, status.Errorf(codes.InvalidArgument, "invalid translations: %v", err)
+		}
+
+		if err := validateVariants(cmd); err != nil {
+			return &empty.Empty{}, status.Errorf(codes.InvalidArgument, "invalid variants: %v", err)
+		}
+
+		if err := access.Validate(cmd); err != nil {
+			return &empty.Empty{}, status.Errorf(codes.InvalidArgument, "invalid access rules: %v", err)
+		}
+
+		if err := validateTriggers(cmd); err != nil {
+			return &empty.Empty{}, status.Errorf(codes.InvalidArgument, "invalid triggers: %v", err)
+		}
+
+		if err := validateCallout(cmd); err != nil {
+			return &empty.Empty{}, status.Errorf(codes.InvalidArgument, "invalid callout: %v", err)
+		}
+
+		if err := s.scripts.validate(cmd); err != nil {
+			return &empty.Empty{}, status.Errorf(codes.InvalidArgument, "invalid script: %v", err)
+		}
+
+		if err := s.db.Add(cmd, s.revision(ctx, actionAdd, nil, cmd)); err != nil {
 			s.logError(
 				"db",
 				"Add",
 				err.Error(),
 				fmt.Sprintf("add BotCommand %q: %q failed", cmd.GetCmd().GetCommand(), cmd.GetResp().GetResponse()),
 			)
-			return &empty.Empty{}, status.Error(codes.Internal, "error while adding command")
+			return &empty.Empty{}, statusFromDB(err, "error while adding command")
 		}
 	}
 
+	s.record(ctx, cmd.GetCmd(), actionAdd, nil, cmd)
+
 	s.logInfo(
 		"server",
 		"AddCommand",
@@ -41,9 +84,19 @@ func (s *server) AddCommand(ctx context.Context, cmd *proto.BotCommand) (*empty.
 	return &empty.Empty{}, nil
 }
 
-// GetCommand tries to get the specified command from the Server's database. It returns a non-nil error
-// if something went wrong or if the context was cancelled.
+// GetCommand tries to get the specified command from the Server's database with one of its response
+// variants, if any, its response translated to the language of the command, if any, or its response
+// provided by its callout or its script, or as it is stored if the command is raw. It returns a
+// non-nil error if something went wrong, if the caller of the command can't use it, if the
+// callout failed without a fallback, if the script failed or if the context was cancelled.
 func (s *server) GetCommand(ctx context.Context, cmd *proto.Command) (*proto.BotCommand, error) {
+	return s.getCommand(ctx, cmd, nil)
+}
+
+// getCommand works like GetCommand rendering the stored response, once its variant is
+// picked and translated, as a template with the received captures, if any, before its
+// callout or its script run, so their responses are never rendered.
+func (s *server) getCommand(ctx context.Context, cmd *proto.Command, captures map[string]string) (*proto.BotCommand, error) {
 	var c *proto.BotCommand
 	var err error
 
@@ -53,6 +106,10 @@ func (s *server) GetCommand(ctx context.Context, cmd *proto.Command) (*proto.Bot
 	case <-ctx.Done():
 		return &proto.BotCommand{}, status.Error(codes.Canceled, ctx.Err().Error())
 	default:
+		if cmd.GetCommand() == "" {
+			return &proto.BotCommand{}, status.Error(codes.InvalidArgument, "no command provided")
+		}
+
 		if ok := s.inCache(cmd); !ok {
 			c, err = s.db.Get(cmd)
 			if err != nil {
@@ -62,7 +119,7 @@ func (s *server) GetCommand(ctx context.Context, cmd *proto.Command) (*proto.Bot
 					err.Error(),
 					fmt.Sprintf("get BotCommand %q failed", cmd.GetCommand()),
 				)
-				return &proto.BotCommand{}, status.Error(codes.Internal, "error while getting command")
+				return &proto.BotCommand{}, statusFromDB(err, "error while getting command")
 			}
 
 			if err := s.cache.Add(c); err != nil {
@@ -87,6 +144,53 @@ func (s *server) GetCommand(ctx context.Context, cmd *proto.Command) (*proto.Bot
 		}
 	}
 
+	if cmd.GetRaw() {
+		s.logInfo(
+			"server",
+			"GetCommand",
+			fmt.Sprintf("BotCommand %q gotten raw successfully", c.GetCmd().GetCommand()),
+			time.Since(start),
+		)
+		return c, nil
+	}
+
+	if err := access.Check(c, cmd.GetCaller()); err != nil {
+		s.logInfo(
+			"server",
+			"GetCommand",
+			fmt.Sprintf("BotCommand %q denied: %v", cmd.GetCommand(), err),
+			time.Since(start),
+		)
+		return &proto.BotCommand{}, status.Error(codes.PermissionDenied, "command not allowed")
+	}
+
+	c = s.renderCaptures(localize(s.variants.pick(c, cmd), cmd.GetLang()), captures)
+
+	c, err = s.callouts.resolve(ctx, c, cmd)
+	if err != nil {
+		s.logError(
+			"callout",
+			"resolve",
+			err.Error(),
+			fmt.Sprintf("get response of BotCommand %q from its callout failed", cmd.GetCommand()),
+		)
+
+		if c == nil {
+			return &proto.BotCommand{}, status.Error(codes.Unavailable, "error while calling the callout of the command")
+		}
+	}
+
+	c, err = s.scripts.resolve(ctx, c, cmd)
+	if err != nil {
+		s.logError(
+			"script",
+			"resolve",
+			err.Error(),
+			fmt.Sprintf("get response of BotCommand %q from its script failed", cmd.GetCommand()),
+		)
+		return &proto.BotCommand{}, status.Error(codes.Internal, "error while running the script of the command")
+	}
+
 	s.logInfo(
 		"server",
 		"GetCommand",
@@ -130,37 +234,144 @@ func (s *server) ListCommands(ctx context.Context, _ *empty.Empty) (*proto.BotCo
 	return commands, nil
 }
 
-// UpdateCommand tries to update the specified command to the Server's database. It returns a non-nil error
+// SearchCommands returns the visible commands that the caller of the request can use
+// whose name or description contain the received query, ignoring case, sorted by name.
+// If the request has a caller the commands are returned with the response that would
+// be sent to it, leaving out the ones whose response comes from a callout or a script. It returns a non-nil error
 // if something went wrong or if the context was cancelled.
+func (s *server) SearchCommands(ctx context.Context, req *proto.SearchRequest) (*proto.BotCommands, error) {
+	var commands *proto.BotCommands
+	var err error
+
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return &proto.BotCommands{}, status.Error(codes.Canceled, ctx.Err().Error())
+	default:
+		commands, err = s.db.GetAll()
+		if err != nil {
+			s.logError(
+				"db",
+				"GetAll",
+				err.Error(),
+				"get BotCommands failed",
+			)
+
+			return &proto.BotCommands{}, status.Error(codes.Internal, "error while searching commands")
+		}
+	}
+
+	query := strings.ToLower(req.GetQuery())
+	var found []*proto.BotCommand
+	for _, c := range commands.GetCommands() {
+		if c.GetHidden() || access.Check(c, req.GetCaller()) != nil {
+			continue
+		}
+
+		// Searches of bots get the responses as they are sent, which
+		// can't be known for the ones computed on every request.
+		if req.GetCaller() != nil && (c.GetCallout() != nil || c.GetScript() != "") {
+			continue
+		}
+
+		if strings.Contains(strings.ToLower(c.GetCmd().GetCommand()), query) || strings.Contains(strings.ToLower(c.GetDescription()), query) {
+			found = append(found, c)
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].GetCmd().GetCommand() < found[j].GetCmd().GetCommand()
+	})
+
+	if limit := int(req.GetLimit()); limit > 0 && len(found) > limit {
+		found = found[:limit]
+	}
+
+	if caller := req.GetCaller(); caller != nil {
+		for i, c := range found {
+			found[i] = localize(s.variants.pick(c, &proto.Command{Command: c.GetCmd().GetCommand(), Lang: req.GetLang(), Caller: caller}), req.GetLang())
+		}
+	}
+
+	s.logInfo(
+		"server",
+		"SearchCommands",
+		fmt.Sprintf("%v BotCommands found for %q", len(found), req.GetQuery()),
+		time.Since(start),
+	)
+	return &proto.BotCommands{Commands: found}, nil
+}
+
+// UpdateCommand tries to update the specified command to the Server's database, recording the change
+// as a new revision. It returns a non-nil error if something went wrong or if the context was cancelled.
 func (s *server) UpdateCommand(ctx context.Context, cmd *proto.BotCommand) (*empty.Empty, error) {
+	var before *proto.BotCommand
+
 	start := time.Now()
 
 	select {
 	case <-ctx.Done():
 		return &empty.Empty{}, status.Error(codes.Canceled, ctx.Err().Error())
 	default:
-		if ok := s.inCache(cmd.GetCmd()); ok {
-			if err := s.cache.Remove(cmd.GetCmd()); err != nil {
-				s.logError(
-					"cache",
-					"Remove",
-					err.Error(),
-					fmt.Sprintf("remove BotCommand %q failed", cmd.GetCmd().GetCommand()),
-				)
-			}
+		if cmd.GetCmd().GetCommand() == "" {
+			return &empty.Empty{}, status.Error(codes.InvalidArgument, "no command provided")
+		}
+
+		if err := validateFlow(cmd.GetFlow()); err != nil {
+			return &empty.Empty{}, status.Errorf(codes.InvalidArgument, "invalid flow: %v", err)
+		}
+
+		if err := validateAliases(cmd); err != nil {
+			return &empty.Empty{}, status.Errorf(codes.InvalidArgument, "invalid aliases: %v", err)
+		}
+
+		if err := validateTranslations(cmd); err != nil {
+			return &empty.Empty{}, status.Errorf(codes.InvalidArgument, "invalid translations: %v", err)
+		}
+
+		if err := validateVariants(cmd); err != nil {
+			return &empty.Empty{}, status.Errorf(codes.InvalidArgument, "invalid variants: %v", err)
 		}
 
-		if err := s.db.Update(cmd); err != nil {
+		if err := access.Validate(cmd); err != nil {
+			return &empty.Empty{}, status.Errorf(codes.InvalidArgument, "invalid access rules: %v", err)
+		}
+
+		if err := validateTriggers(cmd); err != nil {
+			return &empty.Empty{}, status.Errorf(codes.InvalidArgument, "invalid triggers: %v", err)
+		}
+
+		if err := validateCallout(cmd); err != nil {
+			return &empty.Empty{}, status.Errorf(codes.InvalidArgument, "invalid callout: %v", err)
+		}
+
+		if err := s.scripts.validate(cmd); err != nil {
+			return &empty.Empty{}, status.Errorf(codes.InvalidArgument, "invalid script: %v", err)
+		}
+
+		version, err := expectedVersion(ctx, cmd.GetVersion())
+		if err != nil {
+			return &empty.Empty{}, status.Error(codes.InvalidArgument, err.Error())
+		}
+		cmd.Version = version
+
+		before = s.stored(cmd.GetCmd())
+		s.uncache(cmd.GetCmd())
+
+		if err := s.db.Update(cmd, s.revision(ctx, actionUpdate, before, cmd)); err != nil {
 			s.logError(
 				"db",
 				"Update",
 				err.Error(),
 				fmt.Sprintf("update BotCommand %q: %q failed", cmd.GetCmd().GetCommand(), cmd.GetResp().GetResponse()),
 			)
-			return &empty.Empty{}, status.Error(codes.Internal, "error while updating command")
+			return &empty.Empty{}, statusFromDB(err, "error while updating command")
 		}
 	}
 
+	s.record(ctx, cmd.GetCmd(), actionUpdate, before, cmd)
+
 	s.logInfo(
 		"server",
 		"UpdateCommand",
@@ -170,37 +381,45 @@ func (s *server) UpdateCommand(ctx context.Context, cmd *proto.BotCommand) (*emp
 	return &empty.Empty{}, nil
 }
 
-// DeleteCommand tries to remove the specified command from the Server's database. It returns a non-nil error
-// if something went wrong or if the context was cancelled.
+// DeleteCommand tries to remove the specified command from the Server's database, recording the deletion
+// as a new revision. It returns a non-nil error if something went wrong or if the context was cancelled.
 func (s *server) DeleteCommand(ctx context.Context, cmd *proto.Command) (*empty.Empty, error) {
+	var before *proto.BotCommand
+
 	start := time.Now()
 
 	select {
 	case <-ctx.Done():
 		return &empty.Empty{}, status.Error(codes.Canceled, ctx.Err().Error())
 	default:
-		if ok := s.inCache(cmd); ok {
-			if err := s.cache.Remove(cmd); err != nil {
-				s.logError(
-					"cache",
-					"Remove",
-					err.Error(),
-					fmt.Sprintf("remove BotCommand %q failed", cmd.GetCommand()),
-				)
-			}
+		if cmd.GetCommand() == "" {
+			return &empty.Empty{}, status.Error(codes.InvalidArgument, "no command provided")
+		}
+
+		version, err := expectedVersion(ctx, cmd.GetVersion())
+		if err != nil {
+			return &empty.Empty{}, status.Error(codes.InvalidArgument, err.Error())
 		}
+		cmd.Version = version
 
-		if err := s.db.Remove(cmd); err != nil {
+		before = s.stored(cmd)
+		s.uncache(cmd)
+
+		if err := s.db.Remove(cmd, s.revision(ctx, actionDelete, before, nil)); err != nil {
 			s.logError(
 				"db",
 				"Remove",
 				err.Error(),
 				fmt.Sprintf("remove BotCommand %q failed", cmd.GetCommand()),
 			)
-			return &empty.Empty{}, status.Error(codes.Internal, "error while removing command")
+			return &empty.Empty{}, statusFromDB(err, "error while removing command")
 		}
 	}
 
+	if before != nil {
+		s.record(ctx, cmd, actionDelete, before, nil)
+	}
+
 	s.logInfo(
 		"server",
 		"DeleteCommand",
@@ -217,3 +436,19 @@ func (s *server) inCache(cmd *proto.Command) bool {
 
 	return true
 }
+
+// statusFromDB returns the status of a request that failed because of
+// the received database error. Errors that are not caused by the request
+// itself are reported as internal errors with the received message.
+func statusFromDB(err error, msg string) error {
+	switch errors.Cause(err) {
+	case db.ErrNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case db.ErrAlreadyExists, db.ErrAliasInUse:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case db.ErrVersionMismatch:
+		return status.Error(codes.Aborted, err.Error())
+	}
+
+	return status.Error(codes.Internal, msg)
+}
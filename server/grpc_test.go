@@ -3,11 +3,16 @@ package server
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/danielkvist/botio/proto"
 
 	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestAddCommand(t *testing.T) {
@@ -17,7 +22,8 @@ func TestAddCommand(t *testing.T) {
 		expectedToFail bool
 	}{
 		{
-			name: "without command",
+			name:           "without command",
+			expectedToFail: true,
 		},
 		{
 			name: "with command",
@@ -80,6 +86,50 @@ func TestGetCommand(t *testing.T) {
 	}
 }
 
+func TestGetCommandAccess(t *testing.T) {
+	s := testServer(t)
+	ctx := context.TODO()
+
+	if _, err := s.AddCommand(ctx, &proto.BotCommand{
+		Cmd:    &proto.Command{Command: "ban"},
+		Resp:   &proto.Response{Response: "banned"},
+		Access: &proto.Access{ChatType: "channel"},
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected %v adding a command with an unknown chat type. got=%v", codes.InvalidArgument, err)
+	}
+
+	if _, err := s.AddCommand(ctx, &proto.BotCommand{
+		Cmd:    &proto.Command{Command: "ban"},
+		Resp:   &proto.Response{Response: "banned"},
+		Access: &proto.Access{AdminsOnly: true, ChatType: "group"},
+	}); err != nil {
+		t.Fatalf("while adding command: %v", err)
+	}
+
+	tt := []struct {
+		name         string
+		caller       *proto.Caller
+		raw          bool
+		expectedCode codes.Code
+	}{
+		{name: "without caller", expectedCode: codes.PermissionDenied},
+		{name: "raw without caller", raw: true, expectedCode: codes.OK},
+		{name: "administrator", caller: &proto.Caller{ChatId: "1", UserId: "1", Admin: true}, expectedCode: codes.OK},
+		{name: "not administrator", caller: &proto.Caller{ChatId: "1", UserId: "2"}, expectedCode: codes.PermissionDenied},
+		{name: "direct message", caller: &proto.Caller{ChatId: "1", UserId: "1", Admin: true, Private: true}, expectedCode: codes.PermissionDenied},
+		{name: "unknown chat", caller: &proto.Caller{UserId: "1", Admin: true}, expectedCode: codes.PermissionDenied},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := s.GetCommand(ctx, &proto.Command{Command: "ban", Caller: tc.caller, Raw: tc.raw})
+			if status.Code(err) != tc.expectedCode {
+				t.Fatalf("expected %v. got=%v", tc.expectedCode, err)
+			}
+		})
+	}
+}
+
 func TestListCommands(t *testing.T) {
 	commandOne := &proto.BotCommand{
 		Cmd: &proto.Command{
@@ -118,6 +168,88 @@ func TestListCommands(t *testing.T) {
 	}
 }
 
+func TestSearchCommands(t *testing.T) {
+	s := testServer(t)
+	for _, c := range []*proto.BotCommand{
+		{Cmd: &proto.Command{Command: "start"}, Resp: &proto.Response{Response: "hi", Translations: map[string]string{"es": "hola"}}, Description: "Says hi"},
+		{Cmd: &proto.Command{Command: "stop"}, Resp: &proto.Response{Response: "bye"}},
+		{Cmd: &proto.Command{Command: "weather"}, Resp: &proto.Response{Variants: []*proto.Variant{{Response: "sunny"}}}, Description: "Starts a forecast"},
+		{Cmd: &proto.Command{Command: "stats"}, Resp: &proto.Response{Response: "secret"}, Hidden: true},
+		{Cmd: &proto.Command{Command: "staff"}, Resp: &proto.Response{Response: "team"}, Access: &proto.Access{AllowedUsers: []string{"1"}}},
+		{Cmd: &proto.Command{Command: "status"}, Callout: &proto.Callout{Url: "http://localhost", Fallback: "unknown"}},
+	} {
+		if _, err := s.AddCommand(context.TODO(), c); err != nil {
+			t.Fatalf("while adding command %q: %v", c.GetCmd().GetCommand(), err)
+		}
+	}
+
+	tt := []struct {
+		name              string
+		request           *proto.SearchRequest
+		expectedCommands  []string
+		expectedResponses []string
+	}{
+		{
+			name:             "by name",
+			request:          &proto.SearchRequest{Query: "st"},
+			expectedCommands: []string{"start", "status", "stop", "weather"},
+		},
+		{
+			name:             "by description ignoring case",
+			request:          &proto.SearchRequest{Query: "START"},
+			expectedCommands: []string{"start", "weather"},
+		},
+		{
+			name:             "with limit",
+			request:          &proto.SearchRequest{Query: "st", Limit: 1},
+			expectedCommands: []string{"start"},
+		},
+		{
+			name:              "with caller",
+			request:           &proto.SearchRequest{Query: "st", Lang: "es", Caller: &proto.Caller{UserId: "2"}},
+			expectedCommands:  []string{"start", "stop", "weather"},
+			expectedResponses: []string{"hola", "bye", "sunny"},
+		},
+		{
+			name:             "allowed caller",
+			request:          &proto.SearchRequest{Query: "sta", Caller: &proto.Caller{UserId: "1"}},
+			expectedCommands: []string{"staff", "start", "weather"},
+		},
+		{
+			name:             "not allowed caller",
+			request:          &proto.SearchRequest{Query: "sta", Caller: &proto.Caller{UserId: "2"}},
+			expectedCommands: []string{"start", "weather"},
+		},
+		{
+			name:    "without results",
+			request: &proto.SearchRequest{Query: "nothing"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			commands, err := s.SearchCommands(context.TODO(), tc.request)
+			if err != nil {
+				t.Fatalf("while searching commands: %v", err)
+			}
+
+			var names, responses []string
+			for _, c := range commands.GetCommands() {
+				names = append(names, c.GetCmd().GetCommand())
+				responses = append(responses, c.GetResp().GetResponse())
+			}
+
+			if strings.Join(names, ",") != strings.Join(tc.expectedCommands, ",") {
+				t.Fatalf("expected commands %v. got=%v", tc.expectedCommands, names)
+			}
+
+			if tc.expectedResponses != nil && strings.Join(responses, ",") != strings.Join(tc.expectedResponses, ",") {
+				t.Fatalf("expected responses %v. got=%v", tc.expectedResponses, responses)
+			}
+		})
+	}
+}
+
 func TestUpdateCommand(t *testing.T) {
 	command := &proto.BotCommand{
 		Cmd: &proto.Command{
@@ -156,6 +288,40 @@ func TestUpdateCommand(t *testing.T) {
 	}
 }
 
+func TestUpdateCommandRightAfterGet(t *testing.T) {
+	s := testServer(t)
+	if _, err := s.AddCommand(context.TODO(), &proto.BotCommand{
+		Cmd:  &proto.Command{Command: "start"},
+		Resp: &proto.Response{Response: "hi"},
+	}); err != nil {
+		t.Fatalf("while adding command: %v", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		if _, err := s.GetCommand(context.TODO(), &proto.Command{Command: "start"}); err != nil {
+			t.Fatalf("(%v) while getting command: %v", i, err)
+		}
+
+		resp := fmt.Sprintf("hello %v", i)
+		if _, err := s.UpdateCommand(context.TODO(), &proto.BotCommand{
+			Cmd:  &proto.Command{Command: "start"},
+			Resp: &proto.Response{Response: resp},
+		}); err != nil {
+			t.Fatalf("(%v) while updating command: %v", i, err)
+		}
+
+		time.Sleep(time.Millisecond)
+		cmd, err := s.GetCommand(context.TODO(), &proto.Command{Command: "start"})
+		if err != nil {
+			t.Fatalf("(%v) while getting command: %v", i, err)
+		}
+
+		if r := cmd.GetResp().GetResponse(); r != resp {
+			t.Fatalf("(%v) expected command to have updated response %q. got=%q", i, resp, r)
+		}
+	}
+}
+
 func TestDeleteCommand(t *testing.T) {
 	s := testServer(t)
 	if _, err := s.AddCommand(context.TODO(), &proto.BotCommand{
@@ -203,3 +369,81 @@ func testServer(t *testing.T) Server {
 
 	return s
 }
+
+func TestStatusCodes(t *testing.T) {
+	s := testServer(t)
+	start := &proto.BotCommand{
+		Cmd:  &proto.Command{Command: "start"},
+		Resp: &proto.Response{Response: "hi"},
+	}
+
+	if _, err := s.AddCommand(context.TODO(), start); err != nil {
+		t.Fatalf("while adding command: %v", err)
+	}
+
+	tt := []struct {
+		name         string
+		call         func() error
+		expectedCode codes.Code
+	}{
+		{
+			name: "add existing command",
+			call: func() error {
+				_, err := s.AddCommand(context.TODO(), start)
+				return err
+			},
+			expectedCode: codes.AlreadyExists,
+		},
+		{
+			name: "add without command",
+			call: func() error {
+				_, err := s.AddCommand(context.TODO(), &proto.BotCommand{Resp: &proto.Response{Response: "hi"}})
+				return err
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "get missing command",
+			call: func() error {
+				_, err := s.GetCommand(context.TODO(), &proto.Command{Command: "stop"})
+				return err
+			},
+			expectedCode: codes.NotFound,
+		},
+		{
+			name: "get without command",
+			call: func() error {
+				_, err := s.GetCommand(context.TODO(), &proto.Command{})
+				return err
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "delete missing command",
+			call: func() error {
+				_, err := s.DeleteCommand(context.TODO(), &proto.Command{Command: "stop"})
+				return err
+			},
+			expectedCode: codes.NotFound,
+		},
+		{
+			name: "update missing command",
+			call: func() error {
+				_, err := s.UpdateCommand(context.TODO(), &proto.BotCommand{
+					Cmd:  &proto.Command{Command: "stop"},
+					Resp: &proto.Response{Response: "bye"},
+				})
+				return err
+			},
+			expectedCode: codes.OK,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := status.Code(tc.call()); code != tc.expectedCode {
+				t.Fatalf("expected code %v. got=%v", tc.expectedCode, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/jsonpb"
+	pb "github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Actions recorded on the revisions of the commands.
+const (
+	actionAdd      = "add"
+	actionUpdate   = "update"
+	actionDelete   = "delete"
+	actionRollback = "rollback"
+)
+
+// ListCommandRevisions returns the revisions of the received command from the oldest to the newest.
+// It returns a non-nil error if something went wrong or if the context was cancelled.
+func (s *server) ListCommandRevisions(ctx context.Context, cmd *proto.Command) (*proto.Revisions, error) {
+	var revisions *proto.Revisions
+	var err error
+
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return &proto.Revisions{}, status.Error(codes.Canceled, ctx.Err().Error())
+	default:
+		revisions, err = s.db.Revisions(cmd)
+		if err != nil {
+			s.logError(
+				"db",
+				"Revisions",
+				err.Error(),
+				fmt.Sprintf("get revisions of BotCommand %q failed", cmd.GetCommand()),
+			)
+			return &proto.Revisions{}, status.Error(codes.Internal, "error while getting revisions")
+		}
+	}
+
+	s.logInfo(
+		"server",
+		"ListCommandRevisions",
+		fmt.Sprintf("%v revisions of BotCommand %q gotten successfully", len(revisions.GetRevisions()), cmd.GetCommand()),
+		time.Since(start),
+	)
+	return revisions, nil
+}
+
+// RollbackCommand restores the received command as it was after the requested revision, adding it
+// again if it was deleted, and records the rollback as a new revision. It returns a non-nil error
+// if something went wrong or if the context was cancelled.
+func (s *server) RollbackCommand(ctx context.Context, req *proto.RollbackRequest) (*proto.BotCommand, error) {
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return &proto.BotCommand{}, status.Error(codes.Canceled, ctx.Err().Error())
+	default:
+	}
+
+	name := &proto.Command{Command: req.GetCommand()}
+	if name.GetCommand() == "" || req.GetRevision() < 1 {
+		return &proto.BotCommand{}, status.Error(codes.InvalidArgument, "a command and a revision greater than zero must be provided")
+	}
+
+	revisions, err := s.db.Revisions(name)
+	if err != nil {
+		s.logError(
+			"db",
+			"Revisions",
+			err.Error(),
+			fmt.Sprintf("get revisions of BotCommand %q failed", name.GetCommand()),
+		)
+		return &proto.BotCommand{}, status.Error(codes.Internal, "error while getting revisions")
+	}
+
+	var target *proto.Revision
+	for _, rev := range revisions.GetRevisions() {
+		if rev.GetNumber() == req.GetRevision() {
+			target = rev
+		}
+	}
+
+	switch {
+	case target == nil:
+		return &proto.BotCommand{}, status.Errorf(codes.NotFound, "revision %v of command %q not found", req.GetRevision(), name.GetCommand())
+	case target.GetCommand() == nil:
+		return &proto.BotCommand{}, status.Errorf(codes.FailedPrecondition, "revision %v of command %q deleted it", req.GetRevision(), name.GetCommand())
+	}
+
+	// The restored command is written over whatever version is stored.
+	restored := pb.Clone(target.GetCommand()).(*proto.BotCommand)
+	restored.Version = 0
+	current := s.stored(name)
+
+	rev := s.revision(ctx, actionRollback, current, restored)
+	s.uncache(name)
+	if current != nil {
+		err = s.db.Update(restored, rev)
+	} else {
+		err = s.db.Add(restored, rev)
+	}
+
+	if err != nil {
+		s.logError(
+			"db",
+			"Rollback",
+			err.Error(),
+			fmt.Sprintf("rollback BotCommand %q to revision %v failed", name.GetCommand(), req.GetRevision()),
+		)
+		return &proto.BotCommand{}, statusFromDB(err, "error while rolling back command")
+	}
+
+	s.record(ctx, name, actionRollback, current, restored)
+
+	s.logInfo(
+		"server",
+		"RollbackCommand",
+		fmt.Sprintf("BotCommand %q rolled back to revision %v successfully", name.GetCommand(), req.GetRevision()),
+		time.Since(start),
+	)
+	return restored, nil
+}
+
+// stored returns the stored command with the received
+// name, or nil if there is none or it can't be gotten.
+func (s *server) stored(cmd *proto.Command) *proto.BotCommand {
+	c, err := s.db.Get(cmd)
+	if err != nil || c.GetCmd().GetCommand() != cmd.GetCommand() {
+		return nil
+	}
+
+	return c
+}
+
+// revision returns the revision of a change of a command made by the
+// author of the request, which the database stores alongside the change.
+// The command of the revision is the one that the database stores, so it
+// has the version assigned by the database once the change is made.
+func (s *server) revision(ctx context.Context, action string, before, after *proto.BotCommand) *proto.Revision {
+	return &proto.Revision{
+		Author:  subjectFromContext(ctx),
+		Time:    ptypes.TimestampNow(),
+		Action:  action,
+		Command: after,
+		Diff:    diff(before, after),
+	}
+}
+
+// record writes the audit event of a change, whose revision is already
+// stored, and sends it to the webhooks. Errors are only logged since the
+// change has already been made. The change is counted on the change
+// token, so the bots and the index of the triggers pick it up.
+func (s *server) record(ctx context.Context, cmd *proto.Command, action string, before, after *proto.BotCommand) {
+	s.changes.bump()
+
+	ev := s.audit(ctx, cmd, action, before, after)
+	if err := s.webhooks.Send(ev); err != nil {
+		s.logError(
+			"webhook",
+			"Send",
+			err.Error(),
+			fmt.Sprintf("send %s event of BotCommand %q to webhooks failed", action, cmd.GetCommand()),
+		)
+	}
+}
+
+// diff returns the lines of the JSON representation of the
+// command that were removed, prefixed by "-", and added,
+// prefixed by "+", when it changed from before to after.
+// The version is left out since it changes on every revision.
+func diff(before, after *proto.BotCommand) string {
+	a, b := lines(before), lines(after)
+
+	// lcs[i][j] is the length of the longest common
+	// subsequence of the lines a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			out = append(out, "-"+a[i])
+			i++
+		default:
+			out = append(out, "+"+b[j])
+			j++
+		}
+	}
+
+	return strings.Join(out, "\n")
+}
+
+func lines(cmd *proto.BotCommand) []string {
+	if cmd == nil {
+		return nil
+	}
+
+	cmd = pb.Clone(cmd).(*proto.BotCommand)
+	cmd.Version = 0
+
+	m := jsonpb.Marshaler{Indent: "  ", OrigName: true}
+	s, err := m.MarshalToString(cmd)
+	if err != nil {
+		return nil
+	}
+
+	return strings.Split(s, "\n")
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRollbackCommand(t *testing.T) {
+	s := testServer(t)
+	ctx := context.WithValue(context.TODO(), subjectKey, "ops")
+
+	start := &proto.BotCommand{Cmd: &proto.Command{Command: "start"}, Resp: &proto.Response{Response: "hi"}}
+	if _, err := s.AddCommand(ctx, start); err != nil {
+		t.Fatalf("while adding command: %v", err)
+	}
+
+	if _, err := s.UpdateCommand(ctx, &proto.BotCommand{Cmd: start.GetCmd(), Resp: &proto.Response{Response: "hello"}}); err != nil {
+		t.Fatalf("while updating command: %v", err)
+	}
+
+	if _, err := s.DeleteCommand(ctx, start.GetCmd()); err != nil {
+		t.Fatalf("while deleting command: %v", err)
+	}
+
+	revisions, err := s.ListCommandRevisions(ctx, start.GetCmd())
+	if err != nil {
+		t.Fatalf("while listing revisions: %v", err)
+	}
+
+	var actions []string
+	for _, rev := range revisions.GetRevisions() {
+		if rev.GetAuthor() != "ops" {
+			t.Fatalf("expected author %q. got=%q", "ops", rev.GetAuthor())
+		}
+
+		actions = append(actions, rev.GetAction())
+	}
+
+	if len(actions) != 3 || actions[0] != actionAdd || actions[1] != actionUpdate || actions[2] != actionDelete {
+		t.Fatalf("expected actions add, update and delete. got=%v", actions)
+	}
+
+	tt := []struct {
+		name             string
+		revision         int64
+		expectedResponse string
+		expectedCode     codes.Code
+	}{
+		{name: "deleted command", revision: 1, expectedResponse: "hi"},
+		{name: "existing command", revision: 2, expectedResponse: "hello"},
+		{name: "deletion", revision: 3, expectedCode: codes.FailedPrecondition},
+		{name: "missing revision", revision: 10, expectedCode: codes.NotFound},
+		{name: "invalid revision", expectedCode: codes.InvalidArgument},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, err := s.RollbackCommand(ctx, &proto.RollbackRequest{Command: "start", Revision: tc.revision})
+			if code := status.Code(err); code != tc.expectedCode {
+				t.Fatalf("expected code %v. got=%v (%v)", tc.expectedCode, code, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if cmd.GetResp().GetResponse() != tc.expectedResponse {
+				t.Fatalf("expected response %q. got=%q", tc.expectedResponse, cmd.GetResp().GetResponse())
+			}
+
+			stored, err := s.GetCommand(ctx, start.GetCmd())
+			if err != nil {
+				t.Fatalf("while getting rolled back command: %v", err)
+			}
+
+			if stored.GetResp().GetResponse() != tc.expectedResponse {
+				t.Fatalf("expected stored response %q. got=%q", tc.expectedResponse, stored.GetResp().GetResponse())
+			}
+		})
+	}
+
+	revisions, err = s.ListCommandRevisions(ctx, start.GetCmd())
+	if err != nil {
+		t.Fatalf("while listing revisions: %v", err)
+	}
+
+	if n := len(revisions.GetRevisions()); n != 5 {
+		t.Fatalf("expected %v revisions after the rollbacks. got=%v", 5, n)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before := &proto.BotCommand{Cmd: &proto.Command{Command: "start"}, Resp: &proto.Response{Response: "hi"}}
+	after := &proto.BotCommand{Cmd: &proto.Command{Command: "start"}, Resp: &proto.Response{Response: "hello"}}
+
+	tt := []struct {
+		name     string
+		before   *proto.BotCommand
+		after    *proto.BotCommand
+		expected string
+	}{
+		{
+			name:     "change",
+			before:   before,
+			after:    after,
+			expected: "-    \"response\": \"hi\"\n+    \"response\": \"hello\"",
+		},
+		{
+			name:   "without changes",
+			before: before,
+			after:  before,
+		},
+		{
+			name:     "deletion",
+			before:   before,
+			expected: "-{\n-  \"cmd\": {\n-    \"command\": \"start\"\n-  },\n-  \"resp\": {\n-    \"response\": \"hi\"\n-  }\n-}",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if d := diff(tc.before, tc.after); d != tc.expected {
+				t.Fatalf("expected diff %q. got=%q", tc.expected, d)
+			}
+		})
+	}
+}
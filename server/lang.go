@@ -0,0 +1,68 @@
+package server
+
+import (
+	"github.com/danielkvist/botio/proto"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+)
+
+// localize returns a copy of the received command whose response is
+// the translation that best matches the received languages, falling back
+// from regional variants to their base language, like "es-AR" to "es",
+// and finally to the untranslated response.
+func localize(cmd *proto.BotCommand, lang string) *proto.BotCommand {
+	translations := cmd.GetResp().GetTranslations()
+	if lang == "" || len(translations) == 0 {
+		return cmd
+	}
+
+	preferred, _, err := language.ParseAcceptLanguage(lang)
+	if err != nil || len(preferred) == 0 {
+		return cmd
+	}
+
+	// The first tag is the one returned when nothing
+	// matches, which stands for the untranslated response.
+	tags := []language.Tag{language.Und}
+	keys := []string{""}
+	for key := range translations {
+		tag, err := language.Parse(key)
+		if err != nil {
+			continue
+		}
+
+		tags = append(tags, tag)
+		keys = append(keys, key)
+	}
+
+	_, i, confidence := language.NewMatcher(tags).Match(preferred...)
+	if i == 0 || confidence == language.No {
+		return cmd
+	}
+
+	localized := pb.Clone(cmd).(*proto.BotCommand)
+	localized.Resp.Response = translations[keys[i]]
+	return localized
+}
+
+// validateTranslations checks that the translations of
+// the received command are keyed by valid language tags.
+func validateTranslations(cmd *proto.BotCommand) error {
+	return checkTranslations(cmd.GetResp().GetTranslations())
+}
+
+func checkTranslations(translations map[string]string) error {
+	for key, text := range translations {
+		if _, err := language.Parse(key); err != nil {
+			return errors.Wrapf(err, "invalid language tag %q", key)
+		}
+
+		if text == "" {
+			return errors.Errorf("empty translation for language %q", key)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLocalize(t *testing.T) {
+	cmd := &proto.BotCommand{
+		Cmd: &proto.Command{Command: "start"},
+		Resp: &proto.Response{
+			Response: "Hi",
+			Translations: map[string]string{
+				"es":    "Hola",
+				"en-GB": "Hello mate",
+				"pt-BR": "Olá",
+			},
+		},
+	}
+
+	tt := []struct {
+		name             string
+		lang             string
+		expectedResponse string
+	}{
+		{name: "without language", expectedResponse: "Hi"},
+		{name: "exact", lang: "es", expectedResponse: "Hola"},
+		{name: "regional variant", lang: "es-AR", expectedResponse: "Hola"},
+		{name: "regional translation", lang: "en-GB", expectedResponse: "Hello mate"},
+		{name: "base language", lang: "pt", expectedResponse: "Olá"},
+		{name: "without translation", lang: "fr", expectedResponse: "Hi"},
+		{name: "preference list", lang: "fr, es;q=0.8", expectedResponse: "Hola"},
+		{name: "invalid language", lang: "not a language", expectedResponse: "Hi"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			localized := localize(cmd, tc.lang)
+			if r := localized.GetResp().GetResponse(); r != tc.expectedResponse {
+				t.Fatalf("expected response %q. got=%q", tc.expectedResponse, r)
+			}
+
+			if cmd.GetResp().GetResponse() != "Hi" {
+				t.Fatalf("localize modified the received command")
+			}
+		})
+	}
+}
+
+func TestGetLocalizedCommand(t *testing.T) {
+	s := testServer(t)
+	if _, err := s.AddCommand(context.TODO(), &proto.BotCommand{
+		Cmd:  &proto.Command{Command: "start"},
+		Resp: &proto.Response{Response: "Hi", Translations: map[string]string{"es": "Hola"}},
+	}); err != nil {
+		t.Fatalf("while adding command: %v", err)
+	}
+
+	cmd, err := s.GetCommand(context.TODO(), &proto.Command{Command: "start", Lang: "es-ES"})
+	if err != nil {
+		t.Fatalf("while getting command: %v", err)
+	}
+
+	if cmd.GetResp().GetResponse() != "Hola" {
+		t.Fatalf("expected response %q. got=%q", "Hola", cmd.GetResp().GetResponse())
+	}
+
+	raw, err := s.GetCommand(context.TODO(), &proto.Command{Command: "start", Lang: "es-ES", Raw: true})
+	if err != nil {
+		t.Fatalf("while getting raw command: %v", err)
+	}
+
+	if raw.GetResp().GetResponse() != "Hi" || raw.GetResp().GetTranslations()["es"] != "Hola" {
+		t.Fatalf("expected the stored response and translations. got=%v", raw.GetResp())
+	}
+
+	_, err = s.AddCommand(context.TODO(), &proto.BotCommand{
+		Cmd:  &proto.Command{Command: "stop"},
+		Resp: &proto.Response{Response: "Bye", Translations: map[string]string{"not a language": "Adiós"}},
+	})
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Fatalf("expected code %v for an invalid language tag. got=%v", codes.InvalidArgument, code)
+	}
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lru keeps up to entries values whose sizes add up to at most size
+// bytes, evicting the least recently used ones to make room for new
+// ones. Values with an expiration time are removed once it passes.
+type lru struct {
+	mu      sync.Mutex
+	entries int
+	size    int
+	used    int
+	order   *list.List
+	keys    map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	value   interface{}
+	size    int
+	expires time.Time
+}
+
+func newLRU(entries, size int) *lru {
+	return &lru{
+		entries: entries,
+		size:    size,
+		order:   list.New(),
+		keys:    make(map[string]*list.Element),
+	}
+}
+
+// get returns the value kept under the key, if any and not expired.
+func (c *lru) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.keys[key]
+	if !ok {
+		return nil, false
+	}
+
+	e := el.Value.(*lruEntry)
+	if !e.expires.IsZero() && !time.Now().Before(e.expires) {
+		c.remove(el)
+		return nil, false
+	}
+
+	c.order.MoveToFront(el)
+	return e.value, true
+}
+
+// add keeps the value of the received size under the key until
+// expires, or until it's evicted if expires is zero. Values bigger
+// than the size of the lru are not kept.
+func (c *lru) add(key string, value interface{}, size int, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.keys[key]; ok {
+		c.remove(el)
+	}
+
+	size += len(key)
+	if size > c.size {
+		return
+	}
+
+	c.keys[key] = c.order.PushFront(&lruEntry{key: key, value: value, size: size, expires: expires})
+	c.used += size
+
+	for c.order.Len() > c.entries || c.used > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *lru) remove(el *list.Element) {
+	e := c.order.Remove(el).(*lruEntry)
+	delete(c.keys, e.key)
+	c.used -= e.size
+}
+
+// len returns the number of values kept, expired or not.
+func (c *lru) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	tt := []struct {
+		name         string
+		entries      int
+		size         int
+		get          string
+		expectedKept []string
+		expectedGone []string
+	}{
+		{
+			name:         "by entries",
+			entries:      2,
+			size:         1 << 10,
+			expectedKept: []string{"b", "c"},
+			expectedGone: []string{"a"},
+		},
+		{
+			name:         "by size",
+			entries:      10,
+			size:         4,
+			expectedKept: []string{"b", "c"},
+			expectedGone: []string{"a"},
+		},
+		{
+			name:         "least recently used",
+			entries:      2,
+			size:         1 << 10,
+			get:          "a",
+			expectedKept: []string{"a", "c"},
+			expectedGone: []string{"b"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newLRU(tc.entries, tc.size)
+			c.add("a", "a", 1, time.Time{})
+			c.add("b", "b", 1, time.Time{})
+			if tc.get != "" {
+				c.get(tc.get)
+			}
+			c.add("c", "c", 1, time.Time{})
+
+			for _, key := range tc.expectedKept {
+				if v, ok := c.get(key); !ok || v != key {
+					t.Fatalf("expected %q to be kept. got=%v", key, v)
+				}
+			}
+
+			for _, key := range tc.expectedGone {
+				if _, ok := c.get(key); ok {
+					t.Fatalf("expected %q to be evicted", key)
+				}
+			}
+		})
+	}
+}
+
+func TestLRUExpiration(t *testing.T) {
+	c := newLRU(10, 1<<10)
+	c.add("a", "a", 1, time.Now().Add(20*time.Millisecond))
+	c.add("big", "big", 1<<10, time.Time{})
+
+	if _, ok := c.get("a"); !ok {
+		t.Fatalf("expected value to be kept until it expires")
+	}
+
+	if _, ok := c.get("big"); ok {
+		t.Fatalf("expected a value bigger than the lru not to be kept")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if _, ok := c.get("a"); ok {
+		t.Fatalf("expected value to expire")
+	}
+
+	if n := c.len(); n != 0 {
+		t.Fatalf("expected expired value to be removed. got %v values", n)
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/danielkvist/botio/proto"
+
+	swaggerFiles "github.com/swaggo/files"
+)
+
+// openAPI returns an http.Handler that serves
+// the OpenAPI specification of the JSON gateway.
+func openAPI() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		http.ServeContent(w, r, "openapi.json", time.Time{}, strings.NewReader(proto.OpenAPI))
+	})
+}
+
+// swaggerUI returns an http.Handler that serves a Swagger UI,
+// kept on the binary, that loads the OpenAPI specification
+// of the JSON gateway. The paths it receives must not contain
+// the prefix under which it is mounted.
+func swaggerUI() http.Handler {
+	files := http.FileServer(swaggerFiles.HTTP)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/", "/index.html":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			http.ServeContent(w, r, "index.html", time.Time{}, strings.NewReader(swaggerIndex))
+		case "/init.js":
+			w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+			http.ServeContent(w, r, "init.js", time.Time{}, strings.NewReader(swaggerInit))
+		default:
+			files.ServeHTTP(w, r)
+		}
+	})
+}
+
+// swaggerIndex replaces the page of the Swagger UI
+// so it doesn't load any specification from the internet.
+const swaggerIndex = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Botio API</title>
+<link rel="stylesheet" href="swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="swagger-ui-bundle.js"></script>
+<script src="swagger-ui-standalone-preset.js"></script>
+<script src="init.js"></script>
+</body>
+</html>
+`
+
+const swaggerInit = `window.onload = function () {
+	window.ui = SwaggerUIBundle({
+		url: "/api/v1/openapi.json",
+		dom_id: "#swagger-ui",
+		deepLinking: true,
+		presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+		layout: "StandaloneLayout",
+		validatorUrl: null
+	});
+};
+`
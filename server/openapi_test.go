@@ -0,0 +1,98 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPI(t *testing.T) {
+	rec := httptest.NewRecorder()
+	openAPI().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status code %v. got=%v", http.StatusOK, rec.Code)
+	}
+
+	var spec struct {
+		Swagger             string                     `json:"swagger"`
+		Paths               map[string]json.RawMessage `json:"paths"`
+		SecurityDefinitions map[string]struct {
+			Type string `json:"type"`
+			In   string `json:"in"`
+			Name string `json:"name"`
+		} `json:"securityDefinitions"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("while decoding OpenAPI specification: %v", err)
+	}
+
+	if spec.Swagger != "2.0" {
+		t.Fatalf("expected OpenAPI version %q. got=%q", "2.0", spec.Swagger)
+	}
+
+	for _, path := range []string{"/api/v1/commands", "/api/v1/commands/{command}", "/api/v1/usage"} {
+		if _, ok := spec.Paths[path]; !ok {
+			t.Fatalf("expected path %q on the OpenAPI specification", path)
+		}
+	}
+
+	token := spec.SecurityDefinitions["token"]
+	if token.Type != "apiKey" || token.In != "header" || !strings.EqualFold(token.Name, "token") {
+		t.Fatalf("expected the token header as security definition. got=%+v", token)
+	}
+}
+
+func TestSwaggerUI(t *testing.T) {
+	tt := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "index", path: "/api/docs/", expectedStatus: http.StatusOK, expectedBody: "swagger-ui-bundle.js"},
+		{name: "init", path: "/api/docs/init.js", expectedStatus: http.StatusOK, expectedBody: "/api/v1/openapi.json"},
+		{name: "bundle", path: "/api/docs/swagger-ui-bundle.js", expectedStatus: http.StatusOK},
+		{name: "unknown file", path: "/api/docs/missing.js", expectedStatus: http.StatusNotFound},
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/docs/", http.StripPrefix("/api/docs", swaggerUI()))
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			if rec.Code != tc.expectedStatus {
+				t.Fatalf("expected status code %v. got=%v", tc.expectedStatus, rec.Code)
+			}
+
+			if !strings.Contains(rec.Body.String(), tc.expectedBody) {
+				t.Fatalf("expected body to contain %q", tc.expectedBody)
+			}
+		})
+	}
+}
+
+func TestIncomingHeader(t *testing.T) {
+	tt := []struct {
+		header          string
+		expectedKey     string
+		expectedForward bool
+	}{
+		{header: "Token", expectedKey: "token", expectedForward: true},
+		{header: "Grpc-Metadata-Token", expectedKey: "token", expectedForward: true},
+		{header: "X-Request-Id", expectedKey: requestIDKey, expectedForward: true},
+		{header: "X-Unknown"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.header, func(t *testing.T) {
+			key, ok := incomingHeader(tc.header)
+			if ok != tc.expectedForward || (ok && !strings.EqualFold(key, tc.expectedKey)) {
+				t.Fatalf("expected header %q to be forwarded as %q (%v). got=%q (%v)", tc.header, tc.expectedKey, tc.expectedForward, key, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// rateLimits keeps a token bucket per RPC and client.
+type rateLimits struct {
+	mu        sync.Mutex
+	def       rateLimit
+	rpcs      map[string]rateLimit
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type rateLimit struct {
+	limit rate.Limit
+	burst int
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+const bucketsIdleTTL = 10 * time.Minute
+
+func newRateLimits() *rateLimits {
+	return &rateLimits{
+		rpcs:      make(map[string]rateLimit),
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// limitFor returns the limit configured for the received
+// full method name, if any.
+func (rl *rateLimits) limitFor(method string) (rateLimit, bool) {
+	l, ok := rl.rpcs[method[strings.LastIndex(method, "/")+1:]]
+	if !ok {
+		l = rl.def
+	}
+
+	return l, l.limit > 0
+}
+
+// reserve takes a token from the bucket of the received method and key
+// and returns for how long the caller should wait before retrying if
+// there were no tokens left.
+func (rl *rateLimits) reserve(method, key string, l rateLimit) time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(rl.lastSweep) > bucketsIdleTTL {
+		for k, b := range rl.buckets {
+			if now.Sub(b.lastSeen) > bucketsIdleTTL {
+				delete(rl.buckets, k)
+			}
+		}
+
+		rl.lastSweep = now
+	}
+
+	id := method + "|" + key
+	b, ok := rl.buckets[id]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(l.limit, l.burst)}
+		rl.buckets[id] = b
+	}
+	b.lastSeen = now
+
+	r := b.limiter.ReserveN(now, 1)
+	if !r.OK() {
+		return time.Second
+	}
+
+	if d := r.DelayFrom(now); d > 0 {
+		r.CancelAt(now)
+		return d
+	}
+
+	return 0
+}
+
+// rateLimit is a unary interceptor that limits the requests per RPC
+// of each client, identified by its JWT subject or, if it has none,
+// by its IP address. Requests over the limit fail with a
+// codes.ResourceExhausted error with information about when to retry.
+func (s *server) rateLimit(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	l, ok := s.limits.limitFor(info.FullMethod)
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	key := clientKey(ctx)
+	delay := s.limits.reserve(info.FullMethod, key, l)
+	if delay == 0 {
+		return handler(ctx, req)
+	}
+
+	s.logWarning(
+		"server",
+		"rateLimit",
+		"rate limit exceeded",
+		fmt.Sprintf("request to %q from %q rejected (limit %v req/s, burst %v), retry in %v", info.FullMethod, key, float64(l.limit), l.burst, delay),
+	)
+
+	grpc.SetHeader(ctx, metadata.Pairs("retry-after", fmt.Sprintf("%.0f", delay.Seconds()+0.5)))
+
+	st := status.New(codes.ResourceExhausted, fmt.Sprintf("rate limit exceeded, retry in %v", delay))
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(delay)}); err == nil {
+		st = detailed
+	}
+
+	return nil, st.Err()
+}
+
+// clientKey identifies the client of a request by its JWT subject. If it
+// has none it uses the IP address of the client, see clientHost.
+func clientKey(ctx context.Context) string {
+	if sub := subjectFromContext(ctx); sub != "" {
+		return "sub:" + sub
+	}
+
+	host := clientHost(ctx)
+	if host == "" {
+		return "unknown"
+	}
+
+	return "ip:" + host
+}
+
+// clientHost returns the IP address of the client of a request. Requests
+// coming from the JSON gateway are identified by the address the gateway
+// received them from, which is only trusted when the request comes from
+// the loopback interface so other clients can't forge it.
+func clientHost(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if fwd := md.Get("x-forwarded-for"); len(fwd) > 0 {
+			addrs := strings.Split(fwd[len(fwd)-1], ",")
+			host = strings.TrimSpace(addrs[len(addrs)-1])
+		}
+	}
+
+	return host
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimit(t *testing.T) {
+	srv, err := New(
+		WithTestDB(),
+		WithRistrettoCache(262144000),
+		WithListener(":0"),
+		WithInsecureGRPCServer(),
+		WithJWTAuthToken("testing"),
+		WithTextLogger(&bytes.Buffer{}),
+		WithRateLimit(0.001, 2),
+		WithRPCRateLimit("AddCommand", 0.001, 1),
+		WithRPCRateLimit("ListCommands", 0, 0),
+	)
+	if err != nil {
+		t.Fatalf("while creating a new Server for testing: %v", err)
+	}
+	s := srv.(*server)
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	tt := []struct {
+		name     string
+		method   string
+		subject  string
+		expected []codes.Code
+	}{
+		{
+			name:     "default limit",
+			method:   "/proto.Botio/GetCommand",
+			subject:  "alice",
+			expected: []codes.Code{codes.OK, codes.OK, codes.ResourceExhausted},
+		},
+		{
+			name:     "default limit for another client",
+			method:   "/proto.Botio/GetCommand",
+			subject:  "bob",
+			expected: []codes.Code{codes.OK, codes.OK, codes.ResourceExhausted},
+		},
+		{
+			name:     "RPC limit",
+			method:   "/proto.Botio/AddCommand",
+			subject:  "alice",
+			expected: []codes.Code{codes.OK, codes.ResourceExhausted},
+		},
+		{
+			name:     "RPC without limit",
+			method:   "/proto.Botio/ListCommands",
+			subject:  "alice",
+			expected: []codes.Code{codes.OK, codes.OK, codes.OK, codes.OK},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), subjectKey, tc.subject)
+			info := &grpc.UnaryServerInfo{FullMethod: tc.method}
+
+			for i, expected := range tc.expected {
+				_, err := s.rateLimit(ctx, nil, info, handler)
+				if status.Code(err) != expected {
+					t.Fatalf("(%v) expected code %v. got=%v", i, expected, status.Code(err))
+				}
+
+				if expected == codes.ResourceExhausted && len(status.Convert(err).Details()) == 0 {
+					t.Fatalf("(%v) expected error to have retry information", i)
+				}
+			}
+		})
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	tt := []struct {
+		name        string
+		subject     string
+		addr        net.Addr
+		forwarded   string
+		expectedKey string
+	}{
+		{
+			name:        "with subject",
+			subject:     "alice",
+			addr:        &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234},
+			expectedKey: "sub:alice",
+		},
+		{
+			name:        "without subject",
+			addr:        &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234},
+			expectedKey: "ip:10.0.0.1",
+		},
+		{
+			name:        "from gateway",
+			addr:        &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1234},
+			forwarded:   "1.1.1.1, 10.0.0.2",
+			expectedKey: "ip:10.0.0.2",
+		},
+		{
+			name:        "forwarded from non-local peer",
+			addr:        &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234},
+			forwarded:   "10.0.0.2",
+			expectedKey: "ip:10.0.0.1",
+		},
+		{
+			name:        "without peer",
+			expectedKey: "unknown",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.addr != nil {
+				ctx = peer.NewContext(ctx, &peer.Peer{Addr: tc.addr})
+			}
+			if tc.subject != "" {
+				ctx = context.WithValue(ctx, subjectKey, tc.subject)
+			}
+
+			if tc.forwarded != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-forwarded-for", tc.forwarded))
+			}
+
+			if key := clientKey(ctx); key != tc.expectedKey {
+				t.Fatalf("expected key %q. got=%q", tc.expectedKey, key)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/danielkvist/botio/access"
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultResolveAlgorithm = "levenshtein"
+	defaultResolveThreshold = 2
+)
+
+// distances are the algorithms available to measure how
+// different a mistyped command is from a stored one.
+var distances = map[string]func(a, b string) int{
+	"levenshtein": levenshtein,
+	"damerau":     damerau,
+}
+
+// resolver finds the commands similar to a mistyped one. Commands
+// are similar if their distance is less or equal than threshold.
+type resolver struct {
+	distance  func(a, b string) int
+	threshold int
+}
+
+func newResolver(algorithm string, threshold int) (*resolver, error) {
+	distance, ok := distances[algorithm]
+	if !ok {
+		return nil, errors.Errorf("unknown resolve algorithm %q", algorithm)
+	}
+
+	if threshold < 0 {
+		return nil, errors.Errorf("invalid resolve threshold %v", threshold)
+	}
+
+	return &resolver{distance: distance, threshold: threshold}, nil
+}
+
+// ResolveCommand looks for the command whose name or alias matches the
+// received one exactly and, if there is none, for a command that matches
+// it ignoring the case. If neither exists it returns the visible commands
+// whose name or aliases are similar to it ranked from the most to the
+// least similar. Commands that the caller of the request can't use, or
+// the ones with access rules if there is no caller, are left out. The
+// match is returned as GetCommand does with the arguments of the request.
+func (s *server) ResolveCommand(ctx context.Context, req *proto.ResolveRequest) (*proto.ResolveResponse, error) {
+	var commands *proto.BotCommands
+	var err error
+
+	start := time.Now()
+
+	query := strings.TrimPrefix(strings.TrimSpace(req.GetCommand()), "/")
+	if query == "" {
+		return &proto.ResolveResponse{}, status.Error(codes.InvalidArgument, "no command to resolve provided")
+	}
+
+	select {
+	case <-ctx.Done():
+		return &proto.ResolveResponse{}, status.Error(codes.Canceled, ctx.Err().Error())
+	default:
+		commands, err = s.db.GetAll()
+		if err != nil {
+			s.logError(
+				"db",
+				"GetAll",
+				err.Error(),
+				"get BotCommands failed",
+			)
+
+			return &proto.ResolveResponse{}, status.Error(codes.Internal, "error while resolving command")
+		}
+	}
+
+	var usable []*proto.BotCommand
+	for _, c := range commands.GetCommands() {
+		if access.Check(c, req.GetCaller()) == nil {
+			usable = append(usable, c)
+		}
+	}
+
+	resp := s.resolver.resolve(query, usable, int(req.GetLimit()))
+	if resp.Match != nil {
+		resp.Match, err = s.GetCommand(ctx, &proto.Command{
+			Command: resp.GetMatch().GetCmd().GetCommand(),
+			Lang:    req.GetLang(),
+			Args:    req.GetArgs(),
+			Caller:  req.GetCaller(),
+		})
+		if err != nil {
+			return &proto.ResolveResponse{}, err
+		}
+	}
+
+	s.logInfo(
+		"server",
+		"ResolveCommand",
+		fmt.Sprintf("command %q resolved with %v suggestions", query, len(resp.GetSuggestions())),
+		time.Since(start),
+	)
+	return resp, nil
+}
+
+func (r *resolver) resolve(query string, commands []*proto.BotCommand, limit int) *proto.ResolveResponse {
+	sorted := make([]*proto.BotCommand, len(commands))
+	copy(sorted, commands)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].GetCmd().GetCommand() < sorted[j].GetCmd().GetCommand()
+	})
+
+	for _, cmd := range sorted {
+		for _, name := range names(cmd) {
+			if name == query {
+				return &proto.ResolveResponse{Match: cmd}
+			}
+		}
+	}
+
+	for _, cmd := range sorted {
+		for _, name := range names(cmd) {
+			if strings.EqualFold(name, query) {
+				return &proto.ResolveResponse{Match: cmd}
+			}
+		}
+	}
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+
+	var candidates []candidate
+	lower := strings.ToLower(query)
+	for _, cmd := range sorted {
+		if cmd.GetHidden() {
+			continue
+		}
+
+		best := -1
+		for _, name := range names(cmd) {
+			if d := r.distance(lower, strings.ToLower(name)); best < 0 || d < best {
+				best = d
+			}
+		}
+
+		if best <= r.threshold {
+			candidates = append(candidates, candidate{name: cmd.GetCmd().GetCommand(), distance: best})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	resp := &proto.ResolveResponse{}
+	for _, c := range candidates {
+		resp.Suggestions = append(resp.Suggestions, c.name)
+	}
+
+	return resp
+}
+
+// names returns the name and the aliases of the command.
+func names(cmd *proto.BotCommand) []string {
+	return append([]string{cmd.GetCmd().GetCommand()}, cmd.GetAliases()...)
+}
+
+// levenshtein returns the number of insertions, deletions
+// and substitutions needed to turn a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// damerau works like levenshtein but also counts the transposition
+// of two adjacent characters as a single edit, so "strat" is only
+// one edit away from "start".
+func damerau(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
+
+func min(n int, rest ...int) int {
+	for _, m := range rest {
+		if m < n {
+			n = m
+		}
+	}
+
+	return n
+}
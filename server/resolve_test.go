@@ -0,0 +1,155 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+)
+
+func TestResolveCommand(t *testing.T) {
+	s := testServer(t)
+	for _, c := range []*proto.BotCommand{
+		{Cmd: &proto.Command{Command: "start"}, Resp: &proto.Response{Response: "hi"}},
+		{Cmd: &proto.Command{Command: "stop"}, Resp: &proto.Response{Response: "bye"}},
+		{Cmd: &proto.Command{Command: "Weather"}, Resp: &proto.Response{Response: "sunny"}, Aliases: []string{"forecast"}},
+		{Cmd: &proto.Command{Command: "stat"}, Resp: &proto.Response{Response: "secret"}, Hidden: true},
+		{Cmd: &proto.Command{Command: "kick"}, Resp: &proto.Response{Response: "kicked"}, Access: &proto.Access{AllowedUsers: []string{"1"}}},
+		{Cmd: &proto.Command{Command: "Echo"}, Script: "def respond(ctx):\n    return \" \".join(ctx.args)"},
+	} {
+		if _, err := s.AddCommand(context.TODO(), c); err != nil {
+			t.Fatalf("while adding command %q: %v", c.GetCmd().GetCommand(), err)
+		}
+	}
+
+	tt := []struct {
+		name                string
+		request             *proto.ResolveRequest
+		expectedMatch       string
+		expectedResponse    string
+		expectedSuggestions []string
+		expectedToFail      bool
+	}{
+		{
+			name:             "exact",
+			request:          &proto.ResolveRequest{Command: "/start"},
+			expectedMatch:    "start",
+			expectedResponse: "hi",
+		},
+		{
+			name:             "script",
+			request:          &proto.ResolveRequest{Command: "echo", Args: []string{"hello", "there"}},
+			expectedMatch:    "Echo",
+			expectedResponse: "hello there",
+		},
+		{
+			name:          "ignoring case",
+			request:       &proto.ResolveRequest{Command: "weather"},
+			expectedMatch: "Weather",
+		},
+		{
+			name:          "hidden",
+			request:       &proto.ResolveRequest{Command: "stat"},
+			expectedMatch: "stat",
+		},
+		{
+			name:                "mistyped",
+			request:             &proto.ResolveRequest{Command: "stap"},
+			expectedSuggestions: []string{"stop", "start"},
+		},
+		{
+			name:                "mistyped with limit",
+			request:             &proto.ResolveRequest{Command: "stap", Limit: 1},
+			expectedSuggestions: []string{"stop"},
+		},
+		{
+			name:          "alias",
+			request:       &proto.ResolveRequest{Command: "Forecast"},
+			expectedMatch: "Weather",
+		},
+		{
+			name:                "mistyped alias",
+			request:             &proto.ResolveRequest{Command: "forcast"},
+			expectedSuggestions: []string{"Weather"},
+		},
+		{
+			name:          "allowed caller",
+			request:       &proto.ResolveRequest{Command: "Kick", Caller: &proto.Caller{UserId: "1"}},
+			expectedMatch: "kick",
+		},
+		{
+			name:    "denied caller",
+			request: &proto.ResolveRequest{Command: "Kick", Caller: &proto.Caller{UserId: "2"}},
+		},
+		{
+			name:    "without caller",
+			request: &proto.ResolveRequest{Command: "Kick"},
+		},
+		{
+			name:    "unknown",
+			request: &proto.ResolveRequest{Command: "rain"},
+		},
+		{
+			name:           "empty",
+			request:        &proto.ResolveRequest{Command: "/"},
+			expectedToFail: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := s.ResolveCommand(context.TODO(), tc.request)
+			if err != nil {
+				if tc.expectedToFail {
+					t.Skipf("resolve command operation failed as expected: %v", err)
+				}
+
+				t.Fatalf("while resolving command: %v", err)
+			}
+
+			if tc.expectedToFail {
+				t.Fatalf("resolve command operation not failed as expected")
+			}
+
+			if m := resp.GetMatch().GetCmd().GetCommand(); m != tc.expectedMatch {
+				t.Fatalf("expected match %q. got=%q", tc.expectedMatch, m)
+			}
+
+			if tc.expectedResponse != "" && resp.GetMatch().GetResp().GetResponse() != tc.expectedResponse {
+				t.Fatalf("expected response %q. got=%q", tc.expectedResponse, resp.GetMatch().GetResp().GetResponse())
+			}
+
+			if strings.Join(resp.GetSuggestions(), ",") != strings.Join(tc.expectedSuggestions, ",") {
+				t.Fatalf("expected suggestions %v. got=%v", tc.expectedSuggestions, resp.GetSuggestions())
+			}
+		})
+	}
+}
+
+func TestDistances(t *testing.T) {
+	tt := []struct {
+		a, b        string
+		levenshtein int
+		damerau     int
+	}{
+		{a: "start", b: "start"},
+		{a: "", b: "stop", levenshtein: 4, damerau: 4},
+		{a: "strat", b: "start", levenshtein: 2, damerau: 1},
+		{a: "stpo", b: "start", levenshtein: 3, damerau: 3},
+		{a: "kitten", b: "sitting", levenshtein: 3, damerau: 3},
+		{a: "ñandú", b: "nandu", levenshtein: 2, damerau: 2},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.a+"-"+tc.b, func(t *testing.T) {
+			if d := levenshtein(tc.a, tc.b); d != tc.levenshtein {
+				t.Fatalf("expected Levenshtein distance %v. got=%v", tc.levenshtein, d)
+			}
+
+			if d := damerau(tc.a, tc.b); d != tc.damerau {
+				t.Fatalf("expected Damerau distance %v. got=%v", tc.damerau, d)
+			}
+		})
+	}
+}
@@ -0,0 +1,623 @@
+package server
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+)
+
+// Names of the built-ins to which the operators and calls of the
+// scripts are rewritten so the sizes of the values they make are
+// checked before they are allocated. Scripts can't use these names.
+const (
+	guardOp   = "__op__"
+	guardGrow = "__grow__"
+	guardCall = "__call__"
+)
+
+// guardedOps are the binary operators that can make a string,
+// bytes, int or collection bigger than their operands.
+var guardedOps = map[syntax.Token]syntax.Token{
+	syntax.PLUS:    syntax.PLUS,
+	syntax.STAR:    syntax.STAR,
+	syntax.PERCENT: syntax.PERCENT,
+	syntax.PIPE:    syntax.PIPE,
+
+	syntax.PLUS_EQ:    syntax.PLUS,
+	syntax.STAR_EQ:    syntax.STAR,
+	syntax.PERCENT_EQ: syntax.PERCENT,
+	syntax.PIPE_EQ:    syntax.PIPE,
+}
+
+// guard rewrites the syntax tree of a script so every guarded operator
+// goes through guardOp, or through guardGrow for augmented assignments,
+// and every call goes through guardCall. It returns an error if the
+// script uses the names of those built-ins.
+func guard(f *syntax.File) error {
+	var err error
+	syntax.Walk(f, func(n syntax.Node) bool {
+		if id, ok := n.(*syntax.Ident); ok && err == nil {
+			switch id.Name {
+			case guardOp, guardGrow, guardCall:
+				err = errors.Errorf("%s: name %q is reserved", id.NamePos, id.Name)
+			}
+		}
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+
+	guardStmts(f.Stmts)
+	return nil
+}
+
+func guardStmts(stmts []syntax.Stmt) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *syntax.AssignStmt:
+			s.LHS = guardExpr(s.LHS)
+			s.RHS = guardExpr(s.RHS)
+			if op, ok := guardedOps[s.Op]; ok {
+				// x op= y checks x op y and leaves y as
+				// it is, so lists are still extended in place.
+				s.RHS = guardedCall(guardGrow, s.OpPos, opLiteral(op, s.OpPos), rvalue(s.LHS), s.RHS)
+			}
+		case *syntax.DefStmt:
+			guardParams(s.Params)
+			guardStmts(s.Body)
+		case *syntax.ExprStmt:
+			s.X = guardExpr(s.X)
+		case *syntax.ForStmt:
+			s.X = guardExpr(s.X)
+			guardStmts(s.Body)
+		case *syntax.WhileStmt:
+			s.Cond = guardExpr(s.Cond)
+			guardStmts(s.Body)
+		case *syntax.IfStmt:
+			s.Cond = guardExpr(s.Cond)
+			guardStmts(s.True)
+			guardStmts(s.False)
+		case *syntax.ReturnStmt:
+			if s.Result != nil {
+				s.Result = guardExpr(s.Result)
+			}
+		}
+	}
+}
+
+func guardParams(params []syntax.Expr) {
+	for _, p := range params {
+		if p, ok := p.(*syntax.BinaryExpr); ok && p.Op == syntax.EQ {
+			p.Y = guardExpr(p.Y)
+		}
+	}
+}
+
+func guardExprs(exprs []syntax.Expr) {
+	for i, e := range exprs {
+		exprs[i] = guardExpr(e)
+	}
+}
+
+func guardExpr(e syntax.Expr) syntax.Expr {
+	switch x := e.(type) {
+	case *syntax.BinaryExpr:
+		if x.Op == syntax.EQ {
+			// A keyword argument, whose name is left as it is.
+			x.Y = guardExpr(x.Y)
+			return x
+		}
+
+		x.X = guardExpr(x.X)
+		x.Y = guardExpr(x.Y)
+		if _, ok := guardedOps[x.Op]; ok {
+			return guardedCall(guardOp, x.OpPos, opLiteral(x.Op, x.OpPos), x.X, x.Y)
+		}
+	case *syntax.CallExpr:
+		fn := guardExpr(x.Fn)
+		guardExprs(x.Args)
+		return &syntax.CallExpr{
+			Fn:     &syntax.Ident{NamePos: x.Lparen, Name: guardCall},
+			Lparen: x.Lparen,
+			Args:   append([]syntax.Expr{fn}, x.Args...),
+			Rparen: x.Rparen,
+		}
+	case *syntax.Comprehension:
+		x.Body = guardExpr(x.Body)
+		for _, c := range x.Clauses {
+			switch c := c.(type) {
+			case *syntax.ForClause:
+				c.X = guardExpr(c.X)
+			case *syntax.IfClause:
+				c.Cond = guardExpr(c.Cond)
+			}
+		}
+	case *syntax.CondExpr:
+		x.Cond = guardExpr(x.Cond)
+		x.True = guardExpr(x.True)
+		x.False = guardExpr(x.False)
+	case *syntax.DictEntry:
+		x.Key = guardExpr(x.Key)
+		x.Value = guardExpr(x.Value)
+	case *syntax.DictExpr:
+		guardExprs(x.List)
+	case *syntax.DotExpr:
+		x.X = guardExpr(x.X)
+	case *syntax.IndexExpr:
+		x.X = guardExpr(x.X)
+		x.Y = guardExpr(x.Y)
+	case *syntax.LambdaExpr:
+		guardParams(x.Params)
+		x.Body = guardExpr(x.Body)
+	case *syntax.ListExpr:
+		guardExprs(x.List)
+	case *syntax.ParenExpr:
+		x.X = guardExpr(x.X)
+	case *syntax.SliceExpr:
+		for _, part := range []*syntax.Expr{&x.X, &x.Lo, &x.Hi, &x.Step} {
+			if *part != nil {
+				*part = guardExpr(*part)
+			}
+		}
+	case *syntax.TupleExpr:
+		guardExprs(x.List)
+	case *syntax.UnaryExpr:
+		if x.X != nil {
+			x.X = guardExpr(x.X)
+		}
+	}
+
+	return e
+}
+
+func guardedCall(name string, pos syntax.Position, args ...syntax.Expr) *syntax.CallExpr {
+	return &syntax.CallExpr{
+		Fn:     &syntax.Ident{NamePos: pos, Name: name},
+		Lparen: pos,
+		Args:   args,
+		Rparen: pos,
+	}
+}
+
+func opLiteral(op syntax.Token, pos syntax.Position) *syntax.Literal {
+	return &syntax.Literal{
+		Token:    syntax.STRING,
+		TokenPos: pos,
+		Raw:      strconv.Quote(op.String()),
+		Value:    op.String(),
+	}
+}
+
+// rvalue returns a copy of the target of an augmented assignment
+// to be read on its right-hand side.
+func rvalue(e syntax.Expr) syntax.Expr {
+	switch x := e.(type) {
+	case *syntax.Ident:
+		return &syntax.Ident{NamePos: x.NamePos, Name: x.Name}
+	case *syntax.IndexExpr:
+		return &syntax.IndexExpr{X: x.X, Lbrack: x.Lbrack, Y: x.Y, Rbrack: x.Rbrack}
+	case *syntax.DotExpr:
+		return &syntax.DotExpr{X: x.X, Dot: x.Dot, NamePos: x.NamePos, Name: x.Name}
+	case *syntax.ParenExpr:
+		return rvalue(x.X)
+	}
+
+	return e
+}
+
+// sandbox returns the built-ins to which guard rewrites the scripts.
+func (sc *scripts) sandbox() starlark.StringDict {
+	return starlark.StringDict{
+		guardOp:   starlark.NewBuiltin(guardOp, sc.op),
+		guardGrow: starlark.NewBuiltin(guardGrow, sc.grow),
+		guardCall: starlark.NewBuiltin(guardCall, sc.call),
+	}
+}
+
+// op applies a guarded operator after checking the size of its result.
+func (sc *scripts) op(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	op, x, y, err := sc.operands(b, args)
+	if err != nil {
+		return nil, err
+	}
+
+	z, err := starlark.Binary(op, x, y)
+	if err != nil {
+		return nil, err
+	}
+
+	return z, sc.fits(z)
+}
+
+// grow checks the size of the result of an augmented
+// assignment and returns its right-hand side.
+func (sc *scripts) grow(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	_, _, y, err := sc.operands(b, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return y, nil
+}
+
+// operands unpacks the arguments of guardOp and guardGrow and returns an
+// error if the result of the operator would be bigger than allowed.
+func (sc *scripts) operands(b *starlark.Builtin, args starlark.Tuple) (syntax.Token, starlark.Value, starlark.Value, error) {
+	if len(args) != 3 {
+		return 0, nil, nil, errors.Errorf("%s: got %v arguments, want 3", b.Name(), len(args))
+	}
+
+	name, _ := starlark.AsString(args[0])
+	op := syntax.ILLEGAL
+	for tok := range guardedOps {
+		if tok.String() == name {
+			op = tok
+		}
+	}
+
+	x, y := args[1], args[2]
+	switch op {
+	case syntax.PLUS, syntax.PIPE:
+		if err := sc.sum(op, x, y); err != nil {
+			return 0, nil, nil, err
+		}
+	case syntax.STAR:
+		if err := sc.product(x, y); err != nil {
+			return 0, nil, nil, err
+		}
+	case syntax.PERCENT:
+		if format, ok := x.(starlark.String); ok {
+			if n := strings.Count(string(format), "%"); n > 0 {
+				if err := sc.bytes(len(format) + n*repr(y, sc.result/n)); err != nil {
+					return 0, nil, nil, errors.Wrap(err, "%")
+				}
+			}
+		}
+	default:
+		return 0, nil, nil, errors.Errorf("%s: unknown operator %q", b.Name(), name)
+	}
+
+	return op, x, y, nil
+}
+
+// sum checks the size of x + y or x | y.
+func (sc *scripts) sum(op syntax.Token, x, y starlark.Value) error {
+	switch x := x.(type) {
+	case starlark.String:
+		if y, ok := y.(starlark.String); ok {
+			return errors.Wrap(sc.bytes(len(x)+len(y)), op.String())
+		}
+	case starlark.Bytes:
+		if y, ok := y.(starlark.Bytes); ok {
+			return errors.Wrap(sc.bytes(len(x)+len(y)), op.String())
+		}
+	case starlark.Int:
+		if y, ok := y.(starlark.Int); ok {
+			return errors.Wrap(sc.bits(max(bitLen(x), bitLen(y))+1), op.String())
+		}
+	case starlark.Sequence:
+		if y, ok := y.(starlark.Sequence); ok {
+			return errors.Wrap(sc.elems(x.Len()+y.Len()), op.String())
+		}
+	}
+
+	return nil
+}
+
+// product checks the size of x * y, which repeats
+// a string, bytes or sequence if one of them is an int.
+func (sc *scripts) product(x, y starlark.Value) error {
+	if _, ok := x.(starlark.Int); ok {
+		x, y = y, x
+	}
+
+	n, ok := y.(starlark.Int)
+	if !ok {
+		return nil
+	}
+
+	times, ok := n.Int64()
+	if !ok {
+		times = 1 << 62
+	}
+
+	if x, ok := x.(starlark.Int); ok {
+		return errors.Wrap(sc.bits(bitLen(x)+bitLen(n)), "*")
+	}
+
+	size, check := 0, sc.elems
+	switch x := x.(type) {
+	case starlark.String:
+		size, check = len(x), sc.bytes
+	case starlark.Bytes:
+		size, check = len(x), sc.bytes
+	case starlark.Sequence:
+		size = x.Len()
+	default:
+		return nil
+	}
+
+	if times <= 0 || size == 0 {
+		return nil
+	}
+
+	if int64(size) > (1<<62)/times {
+		return errors.Wrap(check(1<<62), "*")
+	}
+
+	return errors.Wrap(check(size*int(times)), "*")
+}
+
+// call calls the function with the rest of the arguments after
+// checking the sizes of the values that it makes, when they can be
+// known beforehand, and checks the size of its result and of the
+// collection that it changes if it is a method.
+func (sc *scripts) call(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if len(args) == 0 {
+		return nil, errors.Errorf("%s: missing function", b.Name())
+	}
+
+	fn, args := args[0], args[1:]
+	builtin, ok := fn.(*starlark.Builtin)
+	if ok {
+		if err := sc.precall(builtin, args, kwargs); err != nil {
+			return nil, err
+		}
+	}
+
+	v, err := starlark.Call(thread, fn, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sc.fits(v); err != nil {
+		return nil, errors.Wrap(err, builtinName(fn))
+	}
+
+	if ok && builtin.Receiver() != nil {
+		if err := sc.fits(builtin.Receiver()); err != nil {
+			return nil, errors.Wrap(err, builtin.Name())
+		}
+	}
+
+	return v, nil
+}
+
+// precall checks the sizes of the values made by the built-ins
+// that can make values much bigger than their arguments.
+func (sc *scripts) precall(b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) error {
+	var err error
+	switch recv := b.Receiver(); {
+	case recv == nil:
+		switch b.Name() {
+		case "str", "repr", "print", "json.encode", "json.indent":
+			err = sc.bytes(reprAll(args, kwargs, sc.result))
+		case "list", "tuple", "set", "sorted", "reversed", "enumerate", "dict", "zip":
+			for _, arg := range args {
+				if arg, ok := arg.(starlark.Sequence); ok && err == nil {
+					err = sc.elems(arg.Len())
+				}
+			}
+		}
+	case b.Name() == "replace":
+		err = sc.replace(recv, args)
+	case b.Name() == "join":
+		err = sc.join(recv, args)
+	case b.Name() == "format":
+		if format, ok := recv.(starlark.String); ok {
+			if n := strings.Count(string(format), "{"); n > 0 {
+				err = sc.bytes(len(format) + n*reprAll(args, kwargs, sc.result/n))
+			}
+		}
+	case b.Name() == "extend":
+		recv, ok := recv.(starlark.Sequence)
+		if len(args) == 1 && ok {
+			if arg, ok := args[0].(starlark.Sequence); ok {
+				err = sc.elems(recv.Len() + arg.Len())
+			}
+		}
+	}
+
+	return errors.Wrap(err, b.Name())
+}
+
+// replace checks the size of s.replace(old, new, count).
+func (sc *scripts) replace(recv starlark.Value, args starlark.Tuple) error {
+	s, ok := recv.(starlark.String)
+	if !ok || len(args) < 2 {
+		return nil
+	}
+
+	old, ok1 := args[0].(starlark.String)
+	repl, ok2 := args[1].(starlark.String)
+	if !ok1 || !ok2 || len(repl) <= len(old) {
+		return nil
+	}
+
+	n := strings.Count(string(s), string(old))
+	if len(args) > 2 {
+		if count, err := starlark.AsInt32(args[2]); err == nil && count >= 0 && count < n {
+			n = count
+		}
+	}
+
+	return sc.bytes(len(s) + n*(len(repl)-len(old)))
+}
+
+// join checks the size of sep.join(iterable) stopping at the
+// first element that is not a string, which join rejects.
+func (sc *scripts) join(recv starlark.Value, args starlark.Tuple) error {
+	sep, ok := recv.(starlark.String)
+	if !ok || len(args) != 1 {
+		return nil
+	}
+
+	iter := starlark.Iterate(args[0])
+	if iter == nil {
+		return nil
+	}
+	defer iter.Done()
+
+	size := 0
+	var x starlark.Value
+	for iter.Next(&x) {
+		s, ok := x.(starlark.String)
+		if !ok {
+			return nil
+		}
+
+		if size += len(s) + len(sep); size > sc.result+len(sep) {
+			return sc.bytes(size)
+		}
+	}
+
+	return nil
+}
+
+// fits returns an error if the value is a string, bytes or int bigger
+// than allowed or a collection with more elements than allowed,
+// without looking into its elements.
+func (sc *scripts) fits(v starlark.Value) error {
+	switch v := v.(type) {
+	case starlark.String:
+		return sc.bytes(len(v))
+	case starlark.Bytes:
+		return sc.bytes(len(v))
+	case starlark.Int:
+		return sc.bits(bitLen(v))
+	case starlark.Sequence:
+		return sc.elems(v.Len())
+	}
+
+	return nil
+}
+
+func (sc *scripts) bytes(n int) error {
+	if n > sc.result {
+		return errors.Errorf("more than the %v bytes allowed", sc.result)
+	}
+
+	return nil
+}
+
+func (sc *scripts) bits(n int) error {
+	if n > 8*sc.result {
+		return errors.Errorf("an int of more than the %v bytes allowed", sc.result)
+	}
+
+	return nil
+}
+
+func (sc *scripts) elems(n int) error {
+	if n > sc.elements {
+		return errors.Errorf("more than the %v elements allowed", sc.elements)
+	}
+
+	return nil
+}
+
+// repr returns the number of bytes of the string representation of
+// the value, approximately, or a number greater than limit as soon
+// as it is known to be longer.
+func repr(v starlark.Value, limit int) int {
+	n := 0
+	var walk func(v starlark.Value)
+	walk = func(v starlark.Value) {
+		if n > limit {
+			return
+		}
+
+		switch v := v.(type) {
+		case starlark.String:
+			n += len(v) + 2
+		case starlark.Bytes:
+			n += len(v) + 3
+		case starlark.Int:
+			n += bitLen(v)/3 + 2
+		case starlark.Indexable:
+			n += 2
+			for i := 0; i < v.Len() && n <= limit; i++ {
+				walk(v.Index(i))
+				n += 2
+			}
+		case starlark.IterableMapping:
+			n += 2
+			for _, item := range v.Items() {
+				walk(item[0])
+				walk(item[1])
+				n += 4
+				if n > limit {
+					return
+				}
+			}
+		case *starlark.Set:
+			n += 5
+			iter := v.Iterate()
+			defer iter.Done()
+
+			var x starlark.Value
+			for n <= limit && iter.Next(&x) {
+				walk(x)
+				n += 2
+			}
+		case *starlarkstruct.Struct:
+			n += 8
+			for _, name := range v.AttrNames() {
+				attr, _ := v.Attr(name)
+				n += len(name) + 3
+				walk(attr)
+				if n > limit {
+					return
+				}
+			}
+		default:
+			n += len(v.String())
+		}
+	}
+
+	walk(v)
+	return n
+}
+
+// reprAll returns the sum of repr of the arguments.
+func reprAll(args starlark.Tuple, kwargs []starlark.Tuple, limit int) int {
+	n := 0
+	for _, arg := range args {
+		n += repr(arg, limit-n)
+	}
+
+	for _, kwarg := range kwargs {
+		n += repr(kwarg[1], limit-n)
+	}
+
+	return n
+}
+
+func bitLen(i starlark.Int) int {
+	if _, ok := i.Int64(); ok {
+		return 64
+	}
+
+	return i.BigInt().BitLen()
+}
+
+func builtinName(fn starlark.Value) string {
+	if fn, ok := fn.(starlark.Callable); ok {
+		return fn.Name()
+	}
+
+	return fn.Type()
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/danielkvist/botio/proto"
+	"github.com/danielkvist/botio/schedule"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AddSchedule stores the received scheduled message and returns it with its ID. It returns
+// a non-nil error if the schedule is invalid, if something went wrong or if the context
+// was cancelled.
+func (s *server) AddSchedule(ctx context.Context, sch *proto.Schedule) (*proto.Schedule, error) {
+	var added *proto.Schedule
+	var err error
+
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return &proto.Schedule{}, status.Error(codes.Canceled, ctx.Err().Error())
+	default:
+		added, err = s.schedules.Add(sch)
+		if errors.Cause(err) == schedule.ErrInvalid {
+			return &proto.Schedule{}, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		if err != nil {
+			s.logError(
+				"schedule",
+				"Add",
+				err.Error(),
+				fmt.Sprintf("add schedule for %v %s chats failed", len(sch.GetChats()), sch.GetPlatform()),
+			)
+			return &proto.Schedule{}, status.Error(codes.Internal, "error while adding schedule")
+		}
+	}
+
+	s.logInfo(
+		"server",
+		"AddSchedule",
+		fmt.Sprintf("schedule %q for %v %s chats added successfully", added.GetId(), len(added.GetChats()), added.GetPlatform()),
+		time.Since(start),
+	)
+	return added, nil
+}
+
+// ListSchedules returns the stored scheduled messages.
+// It returns a non-nil error if the context was cancelled.
+func (s *server) ListSchedules(ctx context.Context, _ *empty.Empty) (*proto.Schedules, error) {
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return &proto.Schedules{}, status.Error(codes.Canceled, ctx.Err().Error())
+	default:
+	}
+
+	schedules := s.schedules.List()
+
+	s.logInfo(
+		"server",
+		"ListSchedules",
+		fmt.Sprintf("%v schedules gotten successfully", len(schedules.GetSchedules())),
+		time.Since(start),
+	)
+	return schedules, nil
+}
+
+// DeleteSchedule removes the scheduled message with the ID of the received one. It returns a
+// non-nil error if the schedule is not stored, if something went wrong or if the context was
+// cancelled.
+func (s *server) DeleteSchedule(ctx context.Context, sch *proto.Schedule) (*empty.Empty, error) {
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return &empty.Empty{}, status.Error(codes.Canceled, ctx.Err().Error())
+	default:
+		err := s.schedules.Remove(sch.GetId())
+		if errors.Cause(err) == schedule.ErrNotFound {
+			return &empty.Empty{}, status.Error(codes.NotFound, err.Error())
+		}
+
+		if err != nil {
+			s.logError(
+				"schedule",
+				"Remove",
+				err.Error(),
+				fmt.Sprintf("remove schedule %q failed", sch.GetId()),
+			)
+			return &empty.Empty{}, status.Error(codes.Internal, "error while removing schedule")
+		}
+	}
+
+	s.logInfo(
+		"server",
+		"DeleteSchedule",
+		fmt.Sprintf("schedule %q removed successfully", sch.GetId()),
+		time.Since(start),
+	)
+	return &empty.Empty{}, nil
+}
+
+// ClaimSchedule claims the sending of the scheduled message with the ID of the received claim at
+// its minute, so only one replica of its bot sends it. It returns a non-nil error if the minute
+// is invalid, if the schedule is not stored, if the minute was already claimed, if something went
+// wrong or if the context was cancelled.
+func (s *server) ClaimSchedule(ctx context.Context, c *proto.ScheduleClaim) (*empty.Empty, error) {
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return &empty.Empty{}, status.Error(codes.Canceled, ctx.Err().Error())
+	default:
+		minute, err := ptypes.Timestamp(c.GetMinute())
+		if err != nil {
+			return &empty.Empty{}, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		err = s.schedules.Claim(c.GetId(), minute)
+		switch errors.Cause(err) {
+		case schedule.ErrNotFound:
+			return &empty.Empty{}, status.Error(codes.NotFound, err.Error())
+		case schedule.ErrClaimed:
+			return &empty.Empty{}, status.Error(codes.AlreadyExists, err.Error())
+		}
+
+		if err != nil {
+			s.logError(
+				"schedule",
+				"Claim",
+				err.Error(),
+				fmt.Sprintf("claim schedule %q failed", c.GetId()),
+			)
+			return &empty.Empty{}, status.Error(codes.Internal, "error while claiming schedule")
+		}
+	}
+
+	s.logInfo(
+		"server",
+		"ClaimSchedule",
+		fmt.Sprintf("schedule %q claimed successfully", c.GetId()),
+		time.Since(start),
+	)
+	return &empty.Empty{}, nil
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSchedules(t *testing.T) {
+	s := testServer(t)
+	ctx := context.TODO()
+
+	if _, err := s.AddSchedule(ctx, &proto.Schedule{Cron: "every day", Platform: "telegram", Chats: []string{"1"}, Response: "hi"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected %v adding an invalid schedule. got=%v", codes.InvalidArgument, err)
+	}
+
+	added, err := s.AddSchedule(ctx, &proto.Schedule{Cron: "0 9 * * mon", Platform: "telegram", Chats: []string{"1"}, Command: "start"})
+	if err != nil {
+		t.Fatalf("while adding schedule: %v", err)
+	}
+
+	schedules, err := s.ListSchedules(ctx, &empty.Empty{})
+	if err != nil {
+		t.Fatalf("while listing schedules: %v", err)
+	}
+
+	if len(schedules.GetSchedules()) != 1 || schedules.GetSchedules()[0].GetId() != added.GetId() {
+		t.Fatalf("expected only schedule %q. got=%v", added.GetId(), schedules)
+	}
+
+	claim := &proto.ScheduleClaim{Id: added.GetId(), Minute: ptypes.TimestampNow()}
+	if _, err := s.ClaimSchedule(ctx, claim); err != nil {
+		t.Fatalf("while claiming schedule: %v", err)
+	}
+
+	if _, err := s.ClaimSchedule(ctx, claim); status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("expected %v claiming schedule twice. got=%v", codes.AlreadyExists, err)
+	}
+
+	if _, err := s.DeleteSchedule(ctx, added); err != nil {
+		t.Fatalf("while deleting schedule: %v", err)
+	}
+
+	if _, err := s.DeleteSchedule(ctx, added); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected %v deleting schedule twice. got=%v", codes.NotFound, err)
+	}
+
+	if _, err := s.ClaimSchedule(ctx, claim); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected %v claiming a deleted schedule. got=%v", codes.NotFound, err)
+	}
+}
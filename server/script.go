@@ -0,0 +1,317 @@
+package server
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/danielkvist/botio/proto"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"go.starlark.net/lib/json"
+	"go.starlark.net/lib/math"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+)
+
+const (
+	// defaultScriptSteps is the maximum number of
+	// steps that a script can execute by default.
+	defaultScriptSteps = 100000
+	// defaultScriptTimeout is the maximum time
+	// that a script can run by default.
+	defaultScriptTimeout = time.Second
+	// maxScriptResult is the maximum number of bytes
+	// of the response returned by a script.
+	maxScriptResult = 16 << 10
+	// maxScriptElements is the maximum number of elements, counting
+	// the ones of the nested collections, of the value returned
+	// by a script or of any of its global variables.
+	maxScriptElements = 10000
+)
+
+// scriptModules are the only modules that the scripts can use
+// besides the Starlark's built-ins. None of them gives access to
+// the filesystem or the network, and load statements are rejected.
+var scriptModules = starlark.StringDict{
+	"json": json.Module,
+	"math": math.Module,
+	"random": &starlarkstruct.Module{
+		Name: "random",
+		Members: starlark.StringDict{
+			"randint": starlark.NewBuiltin("randint", randint),
+			"choice":  starlark.NewBuiltin("choice", choice),
+		},
+	},
+}
+
+// scripts runs the scripts of the commands stopping them when they
+// exceed the number of steps or the time allowed. The operators and
+// built-ins that can make strings or collections much bigger than their
+// operands, like repetition, concatenation or str, are checked before
+// they allocate, so no value made by a script, global or local, can have
+// more than result bytes or elements elements and the memory of a
+// script is bounded by its steps.
+type scripts struct {
+	steps    uint64
+	timeout  time.Duration
+	result   int
+	elements int
+}
+
+func newScripts() *scripts {
+	return &scripts{
+		steps:    defaultScriptSteps,
+		timeout:  defaultScriptTimeout,
+		result:   maxScriptResult,
+		elements: maxScriptElements,
+	}
+}
+
+// resolve returns a copy of the received command whose response is
+// the one returned by its script for the request. Commands without
+// script are returned as they are.
+func (sc *scripts) resolve(ctx context.Context, cmd *proto.BotCommand, req *proto.Command) (*proto.BotCommand, error) {
+	if cmd.GetScript() == "" {
+		return cmd, nil
+	}
+
+	text, err := sc.run(ctx, cmd.GetScript(), req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while running the script of command %q", req.GetCommand())
+	}
+
+	resolved := pb.Clone(cmd).(*proto.BotCommand)
+	if resolved.Resp == nil {
+		resolved.Resp = &proto.Response{}
+	}
+	resolved.Resp.Response = text
+
+	return resolved, nil
+}
+
+// validate checks that the script of the received command, if any,
+// compiles, doesn't load other modules and defines a respond function.
+func (sc *scripts) validate(cmd *proto.BotCommand) error {
+	if cmd.GetScript() == "" {
+		return nil
+	}
+
+	if cmd.GetCallout() != nil {
+		return errors.New("a command can't have both a callout and a script")
+	}
+
+	_, _, stop, err := sc.start(context.Background(), cmd.GetScript())
+	if err != nil {
+		return err
+	}
+	stop()
+
+	return nil
+}
+
+// run calls the respond function of the script with
+// the context of the request and returns its result.
+func (sc *scripts) run(ctx context.Context, script string, req *proto.Command) (string, error) {
+	thread, respond, stop, err := sc.start(ctx, script)
+	if err != nil {
+		return "", err
+	}
+	defer stop()
+
+	args := make([]starlark.Value, 0, len(req.GetArgs()))
+	for _, arg := range req.GetArgs() {
+		args = append(args, starlark.String(arg))
+	}
+
+	request := starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"args":     starlark.NewList(args),
+		"user":     starlark.String(req.GetCaller().GetUserId()),
+		"chat":     starlark.String(req.GetCaller().GetChatId()),
+		"platform": starlark.String(req.GetCaller().GetPlatform()),
+		"lang":     starlark.String(req.GetLang()),
+		"command":  starlark.String(req.GetCommand()),
+	})
+
+	v, err := starlark.Call(thread, respond, starlark.Tuple{request}, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "while calling respond")
+	}
+
+	if _, ok := v.(starlark.NoneType); ok {
+		return "", errors.New("respond returned None")
+	}
+
+	if err := sc.check(v); err != nil {
+		return "", errors.Wrap(err, "while checking the result of respond")
+	}
+
+	text := v.String()
+	if s, ok := v.(starlark.String); ok {
+		text = string(s)
+	}
+
+	if len(text) > sc.result {
+		return "", errors.Errorf("respond returned %v bytes, more than the %v allowed", len(text), sc.result)
+	}
+
+	return text, nil
+}
+
+// check returns an error if the value has more elements, counting
+// the ones of the nested collections, or more bytes on its strings
+// than the scripts can keep.
+func (sc *scripts) check(v starlark.Value) error {
+	n, size := 0, 0
+	var count func(v starlark.Value) error
+	count = func(v starlark.Value) error {
+		var elems []starlark.Value
+		switch v := v.(type) {
+		case *starlark.List:
+			for i := 0; i < v.Len(); i++ {
+				elems = append(elems, v.Index(i))
+			}
+		case starlark.Tuple:
+			elems = v
+		case *starlark.Dict:
+			for _, item := range v.Items() {
+				elems = append(elems, item...)
+			}
+		case *starlark.Set:
+			iter := v.Iterate()
+			defer iter.Done()
+
+			var x starlark.Value
+			for iter.Next(&x) {
+				elems = append(elems, x)
+			}
+		case starlark.String:
+			if size += len(v); size > sc.result {
+				return errors.Errorf("more than the %v bytes of strings allowed", sc.result)
+			}
+		}
+
+		n += len(elems)
+		if n > sc.elements {
+			return errors.Errorf("more than the %v elements allowed", sc.elements)
+		}
+
+		for _, e := range elems {
+			if err := count(e); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return count(v)
+}
+
+// start compiles the script and executes its top level on a new thread
+// whose limits are enforced until stop is called. It returns the thread
+// and the respond function of the script.
+func (sc *scripts) start(ctx context.Context, script string) (*starlark.Thread, starlark.Callable, func(), error) {
+	f, err := syntax.Parse("script.star", script, 0)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "while parsing")
+	}
+
+	if err := guard(f); err != nil {
+		return nil, nil, nil, errors.Wrap(err, "while parsing")
+	}
+
+	predeclared := sc.sandbox()
+	for name, v := range scriptModules {
+		predeclared[name] = v
+	}
+
+	prog, err := starlark.FileProgram(f, predeclared.Has)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "while compiling")
+	}
+
+	if prog.NumLoads() > 0 {
+		module, _ := prog.Load(0)
+		return nil, nil, nil, errors.Errorf("load of module %q is not allowed", module)
+	}
+
+	thread := &starlark.Thread{
+		Name:  "script",
+		Print: func(*starlark.Thread, string) {},
+	}
+	thread.SetMaxExecutionSteps(sc.steps)
+
+	stop := sc.watch(ctx, thread)
+
+	globals, err := prog.Init(thread, predeclared)
+	if err != nil {
+		stop()
+		return nil, nil, nil, errors.Wrap(err, "while executing")
+	}
+
+	for name, v := range globals {
+		if err := sc.check(v); err != nil {
+			stop()
+			return nil, nil, nil, errors.Wrapf(err, "while checking global %q", name)
+		}
+	}
+
+	respond, ok := globals["respond"].(starlark.Callable)
+	if !ok {
+		stop()
+		return nil, nil, nil, errors.New("no respond function defined")
+	}
+
+	return thread, respond, stop, nil
+}
+
+// watch cancels the thread when the context is done or when
+// the timeout expires, until the returned function is called.
+func (sc *scripts) watch(ctx context.Context, thread *starlark.Thread) func() {
+	ctx, cancel := context.WithTimeout(ctx, sc.timeout)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			thread.Cancel(ctx.Err().Error())
+		}
+	}()
+
+	return func() {
+		close(done)
+		cancel()
+	}
+}
+
+// randint returns a random integer between lo and hi, both included.
+func randint(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var lo, hi int
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &lo, &hi); err != nil {
+		return nil, err
+	}
+
+	if lo > hi || hi-lo+1 <= 0 {
+		return nil, errors.Errorf("%s: invalid range [%v, %v]", b.Name(), lo, hi)
+	}
+
+	return starlark.MakeInt(lo + rand.Intn(hi-lo+1)), nil
+}
+
+// choice returns a random element of a non-empty sequence.
+func choice(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var seq starlark.Indexable
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &seq); err != nil {
+		return nil, err
+	}
+
+	if seq.Len() == 0 {
+		return nil, errors.Errorf("%s: empty sequence", b.Name())
+	}
+
+	return seq.Index(rand.Intn(seq.Len())), nil
+}
@@ -0,0 +1,273 @@
+package server
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielkvist/botio/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestScripts(t *testing.T) {
+	s := testServer(t)
+	ctx := context.TODO()
+
+	invalid := []struct {
+		name string
+		cmd  *proto.BotCommand
+	}{
+		{
+			name: "syntax error",
+			cmd:  &proto.BotCommand{Script: "def respond(ctx):\nreturn 1"},
+		},
+		{
+			name: "load",
+			cmd:  &proto.BotCommand{Script: "load(\"os.star\", \"os\")\ndef respond(ctx):\n    return os"},
+		},
+		{
+			name: "without respond",
+			cmd:  &proto.BotCommand{Script: "x = 1"},
+		},
+		{
+			name: "allocation at top level",
+			cmd:  &proto.BotCommand{Script: "n = len(\"x\" * (1 << 29))\ndef respond(ctx):\n    return n"},
+		},
+		{
+			name: "reserved name",
+			cmd:  &proto.BotCommand{Script: "def respond(ctx):\n    return __op__(\"*\", \"x\", 1 << 29)"},
+		},
+		{
+			name: "with callout",
+			cmd: &proto.BotCommand{
+				Script:  "def respond(ctx):\n    return 1",
+				Callout: &proto.Callout{Url: "http://localhost/"},
+			},
+		},
+	}
+
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.cmd.Cmd = &proto.Command{Command: "invalid"}
+			if _, err := s.AddCommand(ctx, tc.cmd); status.Code(err) != codes.InvalidArgument {
+				t.Fatalf("expected %v. got=%v", codes.InvalidArgument, err)
+			}
+		})
+	}
+
+	commands := []*proto.BotCommand{
+		{
+			Cmd:    &proto.Command{Command: "roll"},
+			Script: "def respond(ctx):\n    sides = int(ctx.args[0]) if ctx.args else 6\n    return \"You rolled a %d\" % random.randint(1, sides)",
+		},
+		{
+			Cmd:    &proto.Command{Command: "whoami"},
+			Script: "def respond(ctx):\n    return \"%s on %s:%s\" % (ctx.user, ctx.platform, ctx.chat)",
+		},
+		{
+			Cmd:    &proto.Command{Command: "count"},
+			Script: "def respond(ctx):\n    n = 0\n    for i in range(int(ctx.args[0])):\n        n += 1\n    return n",
+		},
+		{
+			Cmd:    &proto.Command{Command: "nothing"},
+			Script: "def respond(ctx):\n    pass",
+		},
+	}
+
+	for _, cmd := range commands {
+		if _, err := s.AddCommand(ctx, cmd); err != nil {
+			t.Fatalf("while adding command %q: %v", cmd.GetCmd().GetCommand(), err)
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		c, err := s.GetCommand(ctx, &proto.Command{Command: "roll", Args: []string{"3"}})
+		if err != nil {
+			t.Fatalf("while getting command: %v", err)
+		}
+
+		n, err := strconv.Atoi(strings.TrimPrefix(c.GetResp().GetResponse(), "You rolled a "))
+		if err != nil || n < 1 || n > 3 {
+			t.Fatalf("expected a roll between 1 and 3. got=%q", c.GetResp().GetResponse())
+		}
+	}
+
+	tt := []struct {
+		name             string
+		cmd              *proto.Command
+		expectedResponse string
+		expectedCode     codes.Code
+	}{
+		{
+			name:             "caller",
+			cmd:              &proto.Command{Command: "whoami", Caller: &proto.Caller{Platform: "telegram", ChatId: "1", UserId: "42"}},
+			expectedResponse: "42 on telegram:1",
+		},
+		{
+			name:             "not a string",
+			cmd:              &proto.Command{Command: "count", Args: []string{"10"}},
+			expectedResponse: "10",
+		},
+		{
+			name:         "too many steps",
+			cmd:          &proto.Command{Command: "count", Args: []string{"100000000"}},
+			expectedCode: codes.Internal,
+		},
+		{
+			name:         "none",
+			cmd:          &proto.Command{Command: "nothing"},
+			expectedCode: codes.Internal,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := s.GetCommand(ctx, tc.cmd)
+			if status.Code(err) != tc.expectedCode {
+				t.Fatalf("expected code %v. got=%v", tc.expectedCode, err)
+			}
+
+			if err == nil && c.GetResp().GetResponse() != tc.expectedResponse {
+				t.Fatalf("expected response %q. got=%q", tc.expectedResponse, c.GetResp().GetResponse())
+			}
+		})
+	}
+}
+
+func TestScriptLimits(t *testing.T) {
+	tt := []struct {
+		name           string
+		script         string
+		expectedToFail bool
+	}{
+		{
+			name:   "within limits",
+			script: "def respond(ctx):\n    return \"x\" * 1024",
+		},
+		{
+			name:   "augmented assignments and calls",
+			script: "def respond(ctx):\n    l = []\n    for i in range(10):\n        l += [i]\n    d = {\"a\": 1}\n    d[\"a\"] += 1\n    d |= {\"b\": 2}\n    return \"%d %d %s\" % (len(l), d[\"a\"], \"-\".join([str(x) for x in sorted(l, reverse = True)]))",
+		},
+		{
+			name:           "result",
+			script:         "def respond(ctx):\n    return \"x\" * 100000",
+			expectedToFail: true,
+		},
+		{
+			name:           "strings",
+			script:         "def respond(ctx):\n    return [\"x\" * 1000 for i in range(100)]",
+			expectedToFail: true,
+		},
+		{
+			name:           "elements",
+			script:         "def respond(ctx):\n    return [[i] for i in range(10000)]",
+			expectedToFail: true,
+		},
+		{
+			name:           "repetition",
+			script:         "def respond(ctx):\n    s = \"x\" * (1 << 29)\n    return len(s)",
+			expectedToFail: true,
+		},
+		{
+			name:           "doubling",
+			script:         "def respond(ctx):\n    s = \"x\"\n    for _ in range(40):\n        s += s\n    return len(s)",
+			expectedToFail: true,
+		},
+		{
+			name:           "list repetition",
+			script:         "def respond(ctx):\n    l = [0] * (1 << 29)\n    return len(l)",
+			expectedToFail: true,
+		},
+		{
+			name:           "list doubling",
+			script:         "def respond(ctx):\n    l = [0]\n    for _ in range(40):\n        l = l + l\n    return len(l)",
+			expectedToFail: true,
+		},
+		{
+			name:           "int squaring",
+			script:         "def respond(ctx):\n    n = 2\n    for _ in range(40):\n        n = n * n\n    return n % 10",
+			expectedToFail: true,
+		},
+		{
+			name:           "join",
+			script:         "def respond(ctx):\n    return len(\",\".join([\"x\" * 10000] * 1000))",
+			expectedToFail: true,
+		},
+		{
+			name:           "replace",
+			script:         "def respond(ctx):\n    return len((\"x\" * 10000).replace(\"x\", \"xxxxxxxxxx\"))",
+			expectedToFail: true,
+		},
+		{
+			name:           "str of aliased lists",
+			script:         "def respond(ctx):\n    l = [0] * 100\n    for _ in range(40):\n        l = [l, l]\n    return len(str(l))",
+			expectedToFail: true,
+		},
+		{
+			name:           "local at top level",
+			script:         "def blow():\n    return len(\"x\" * (1 << 29))\n\nn = blow()\n\ndef respond(ctx):\n    return n",
+			expectedToFail: true,
+		},
+		{
+			name:           "global",
+			script:         "table = list(range(100000))\n\ndef respond(ctx):\n    return \"x\"",
+			expectedToFail: true,
+		},
+		{
+			name:           "time",
+			script:         "def respond(ctx):\n    for i in range(1000000000):\n        pass",
+			expectedToFail: true,
+		},
+	}
+
+	sc := newScripts()
+	sc.steps = 1 << 62
+	sc.timeout = 100 * time.Millisecond
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			start := time.Now()
+			_, err := sc.run(context.TODO(), tc.script, &proto.Command{Command: "test"})
+			if tc.expectedToFail && err == nil {
+				t.Fatalf("expected script to be stopped")
+			}
+
+			if !tc.expectedToFail && err != nil {
+				t.Fatalf("while running script: %v", err)
+			}
+
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Fatalf("expected script to be stopped in time. took %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestScriptsConcurrently(t *testing.T) {
+	sc := newScripts()
+	sc.steps = 1 << 62
+	sc.timeout = 500 * time.Millisecond
+
+	slow := make(chan error, 1)
+	go func() {
+		_, err := sc.run(context.TODO(), "def respond(ctx):\n    for i in range(1000000000):\n        pass", &proto.Command{Command: "slow"})
+		slow <- err
+	}()
+
+	start := time.Now()
+	if _, err := sc.run(context.TODO(), "def respond(ctx):\n    return \"hi\"", &proto.Command{Command: "fast"}); err != nil {
+		t.Fatalf("while running script: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Fatalf("expected script to run alongside a slow one. took %v", elapsed)
+	}
+
+	if err := <-slow; err == nil {
+		t.Fatalf("expected slow script to be stopped")
+	}
+}
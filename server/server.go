@@ -11,9 +11,13 @@ import (
 	"net"
 	"time"
 
+	"github.com/danielkvist/botio/audit"
 	"github.com/danielkvist/botio/cache"
 	"github.com/danielkvist/botio/db"
 	"github.com/danielkvist/botio/proto"
+	"github.com/danielkvist/botio/schedule"
+	"github.com/danielkvist/botio/usage"
+	"github.com/danielkvist/botio/webhook"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/golang/protobuf/ptypes/empty"
@@ -22,6 +26,7 @@ import (
 	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 )
@@ -32,8 +37,27 @@ type Server interface {
 	AddCommand(context.Context, *proto.BotCommand) (*empty.Empty, error)
 	GetCommand(context.Context, *proto.Command) (*proto.BotCommand, error)
 	ListCommands(context.Context, *empty.Empty) (*proto.BotCommands, error)
+	GetChangeToken(context.Context, *empty.Empty) (*proto.ChangeToken, error)
+	SearchCommands(context.Context, *proto.SearchRequest) (*proto.BotCommands, error)
+	ResolveCommand(context.Context, *proto.ResolveRequest) (*proto.ResolveResponse, error)
+	Converse(context.Context, *proto.ConverseRequest) (*proto.ConverseResponse, error)
 	UpdateCommand(context.Context, *proto.BotCommand) (*empty.Empty, error)
 	DeleteCommand(context.Context, *proto.Command) (*empty.Empty, error)
+	ListCommandRevisions(context.Context, *proto.Command) (*proto.Revisions, error)
+	RollbackCommand(context.Context, *proto.RollbackRequest) (*proto.BotCommand, error)
+	ListAuditEvents(context.Context, *proto.AuditFilter) (*proto.AuditEvents, error)
+	AddWebhook(context.Context, *proto.Webhook) (*proto.Webhook, error)
+	ListWebhooks(context.Context, *empty.Empty) (*proto.Webhooks, error)
+	DeleteWebhook(context.Context, *proto.Webhook) (*empty.Empty, error)
+	ListWebhookDeliveries(context.Context, *proto.DeliveryFilter) (*proto.WebhookDeliveries, error)
+	RedeliverWebhook(context.Context, *proto.WebhookDelivery) (*proto.WebhookDelivery, error)
+	AddSchedule(context.Context, *proto.Schedule) (*proto.Schedule, error)
+	ListSchedules(context.Context, *empty.Empty) (*proto.Schedules, error)
+	DeleteSchedule(context.Context, *proto.Schedule) (*empty.Empty, error)
+	ClaimSchedule(context.Context, *proto.ScheduleClaim) (*empty.Empty, error)
+	ReportUsage(context.Context, *proto.UsageReport) (*empty.Empty, error)
+	GetUsageStats(context.Context, *proto.UsageStatsRequest) (*proto.UsageStats, error)
+	MatchMessage(context.Context, *proto.MatchRequest) (*proto.BotCommand, error)
 	Connect() error
 	Serve() error
 	CloseList()
@@ -48,9 +72,22 @@ type server struct {
 	ssl           bool
 	listener      net.Listener
 	httpPort      string
+	docs          bool
 	key           string
 	jwt           string
 	log           *logrus.Logger
+	limits        *rateLimits
+	conversations *conversations
+	resolver      *resolver
+	callouts      *callouts
+	scripts       *scripts
+	variants      *variants
+	triggers      *triggers
+	changes       *changes
+	auditor       audit.Sink
+	webhooks      *webhook.Dispatcher
+	schedules     *schedule.Store
+	usage         *usage.Store
 }
 
 // Option represents an option for a new *server.
@@ -167,6 +204,15 @@ func WithHTTPPort(port string) Option {
 	}
 }
 
+// WithSwaggerUI returns an Option to a new Server that, if enabled,
+// serves a Swagger UI for the JSON gateway on /api/docs/.
+func WithSwaggerUI(enabled bool) Option {
+	return func(s *server) error {
+		s.docs = enabled
+		return nil
+	}
+}
+
 // WithListener returns an Option to a new Server that assigns to its
 // listener field a TCP listener with the received address.
 func WithListener(addr string) Option {
@@ -211,6 +257,7 @@ func WithSecuredGRPCServer(crt, key, ca string) Option {
 			grpc.UnaryInterceptor(
 				grpc_middleware.ChainUnaryServer(
 					grpc_auth.UnaryServerInterceptor(s.jwtAuth),
+					s.rateLimit,
 					grpc_recovery.UnaryServerInterceptor(),
 				),
 			),
@@ -228,6 +275,7 @@ func WithInsecureGRPCServer() Option {
 			grpc.UnaryInterceptor(
 				grpc_middleware.ChainUnaryServer(
 					grpc_auth.UnaryServerInterceptor(s.jwtAuth),
+					s.rateLimit,
 					grpc_recovery.UnaryServerInterceptor(),
 				),
 			),
@@ -236,6 +284,170 @@ func WithInsecureGRPCServer() Option {
 	}
 }
 
+// WithRateLimit returns an Option to a new Server that limits
+// the requests per second that each client can make to every RPC,
+// allowing bursts of up to burst requests. A rate of zero disables the limit.
+func WithRateLimit(r float64, burst int) Option {
+	return func(s *server) error {
+		if r < 0 || burst < 0 {
+			return errors.Errorf("invalid rate limit of %v requests per second with a burst of %v", r, burst)
+		}
+
+		if burst == 0 {
+			burst = 1
+		}
+
+		s.limits.def = rateLimit{limit: rate.Limit(r), burst: burst}
+		return nil
+	}
+}
+
+// WithRPCRateLimit returns an Option to a new Server that overrides
+// the rate limit for the RPC with the received name, such as "AddCommand".
+// A rate of zero disables the limit for that RPC.
+func WithRPCRateLimit(rpc string, r float64, burst int) Option {
+	return func(s *server) error {
+		if rpc == "" || r < 0 || burst < 0 {
+			return errors.Errorf("invalid rate limit for RPC %q of %v requests per second with a burst of %v", rpc, r, burst)
+		}
+
+		if burst == 0 {
+			burst = 1
+		}
+
+		s.limits.rpcs[rpc] = rateLimit{limit: rate.Limit(r), burst: burst}
+		return nil
+	}
+}
+
+// WithConversationTTL returns an Option to a new Server that ends
+// the conversations that don't receive an answer for the received duration.
+func WithConversationTTL(ttl time.Duration) Option {
+	return func(s *server) error {
+		if ttl <= 0 {
+			return errors.Errorf("invalid conversation TTL %v", ttl)
+		}
+
+		s.conversations = newConversations(ttl)
+		return nil
+	}
+}
+
+// WithResolver returns an Option to a new Server that resolves the
+// mistyped commands using the received algorithm, "levenshtein" or
+// "damerau", suggesting the commands whose distance to them is less
+// or equal than threshold. A threshold of zero disables the suggestions.
+func WithResolver(algorithm string, threshold int) Option {
+	return func(s *server) error {
+		r, err := newResolver(algorithm, threshold)
+		if err != nil {
+			return err
+		}
+
+		s.resolver = r
+		return nil
+	}
+}
+
+// WithAudit returns an Option to a new Server that writes the audit
+// events to the sink of the received kind: "db" to keep them on the
+// Server's database, "file" to append them to the file on path or
+// "stdout". Without this Option they are kept on the database.
+func WithAudit(kind, path string) Option {
+	return func(s *server) error {
+		switch sink := audit.Create(kind).(type) {
+		case nil:
+			return errors.Errorf("unknown audit sink %q", kind)
+		case *audit.File:
+			if path == "" {
+				return errors.New("no file for the audit events provided")
+			}
+
+			sink.Path = path
+			s.auditor = sink
+		default:
+			s.auditor = sink
+		}
+
+		return nil
+	}
+}
+
+// WithWebhooks returns an Option to a new Server that keeps the
+// registered webhooks on the file on path and their deliveries on the
+// file on deliveriesPath, retrying every failed delivery up to retries
+// times and waiting backoff before the first retry and twice as long
+// before each next one. Without this Option the webhooks and their
+// deliveries are only kept in memory.
+func WithWebhooks(path, deliveriesPath string, retries int, backoff time.Duration) Option {
+	return func(s *server) error {
+		if retries < 0 || backoff < 0 {
+			return errors.Errorf("invalid %v webhook retries with a backoff of %v", retries, backoff)
+		}
+
+		d, err := webhook.New(path, deliveriesPath)
+		if err != nil {
+			return err
+		}
+
+		d.Retries = retries
+		d.Backoff = backoff
+
+		s.webhooks = d
+		return nil
+	}
+}
+
+// WithSchedules returns an Option to a new Server that keeps the
+// scheduled messages on the file on path. Without this Option
+// they are only kept in memory.
+func WithSchedules(path string) Option {
+	return func(s *server) error {
+		store, err := schedule.New(path)
+		if err != nil {
+			return err
+		}
+
+		s.schedules = store
+		return nil
+	}
+}
+
+// WithUsage returns an Option to a new Server that keeps the usage
+// of the commands reported by the bots on the file on path for the
+// received number of days, or forever if it is zero. Without this
+// Option the usage is only kept in memory.
+func WithUsage(path string, retention int) Option {
+	return func(s *server) error {
+		if retention < 0 {
+			return errors.Errorf("invalid usage retention of %v days", retention)
+		}
+
+		store, err := usage.New(path, retention)
+		if err != nil {
+			return err
+		}
+
+		s.usage = store
+		return nil
+	}
+}
+
+// WithScriptLimits returns an Option to a new Server whose command
+// scripts are stopped when they execute more than the received number
+// of steps or run for longer than timeout.
+func WithScriptLimits(steps uint64, timeout time.Duration) Option {
+	return func(s *server) error {
+		if steps == 0 || timeout <= 0 {
+			return errors.Errorf("invalid script limits of %v steps and %v", steps, timeout)
+		}
+
+		s.scripts.steps = steps
+		s.scripts.timeout = timeout
+		return nil
+	}
+}
+
 // WithTextLogger returns an Option to a new Server with a text
 // based logger.
 func WithTextLogger(out io.Writer) Option {
@@ -297,7 +509,16 @@ func New(options ...Option) (Server, error) {
 		return nil, errors.Errorf("%s: no options provided", errMsg)
 	}
 
-	s := &server{}
+	s := &server{
+		limits:        newRateLimits(),
+		conversations: newConversations(defaultConversationTTL),
+		resolver:      &resolver{distance: distances[defaultResolveAlgorithm], threshold: defaultResolveThreshold},
+		callouts:      newCallouts(),
+		scripts:       newScripts(),
+		variants:      newVariants(),
+		triggers:      &triggers{},
+		changes:       newChanges(),
+	}
 	for _, opt := range options {
 		if err := opt(s); err != nil {
 			return nil, errors.Wrapf(err, "%s", errMsg)
@@ -319,6 +540,45 @@ func New(options ...Option) (Server, error) {
 		return nil, errors.Errorf("%s: no gRPC server provided", errMsg)
 	}
 
+	if s.auditor == nil {
+		s.auditor = audit.Create("db")
+	}
+
+	if sink, ok := s.auditor.(*audit.DB); ok {
+		sink.Store = s.db
+	}
+
+	if s.webhooks == nil {
+		d, err := webhook.New("", "")
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s", errMsg)
+		}
+
+		s.webhooks = d
+	}
+
+	s.webhooks.OnError = func(err error) {
+		s.logError("webhook", "deliver", err.Error(), "keep webhook delivery failed")
+	}
+
+	if s.schedules == nil {
+		store, err := schedule.New("")
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s", errMsg)
+		}
+
+		s.schedules = store
+	}
+
+	if s.usage == nil {
+		store, err := usage.New("", 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s", errMsg)
+		}
+
+		s.usage = store
+	}
+
 	proto.RegisterBotioServer(s.srv, s)
 
 	s.logInfo(
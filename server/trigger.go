@@ -0,0 +1,460 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/danielkvist/botio/proto"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Types of the triggers of a command.
+const (
+	triggerExact   = "exact"
+	triggerPrefix  = "prefix"
+	triggerKeyword = "keyword"
+	triggerRegex   = "regex"
+)
+
+// literalKey is the number of bytes of the literals required by the
+// regular expressions by which they are indexed. Regular expressions
+// without a literal of at least this length are always tried.
+const literalKey = 3
+
+// triggerIndex finds the command whose trigger matches a message. The
+// exact, prefix and keyword triggers are kept on maps so matching a
+// message takes a lookup per prefix or sequence of words of the message,
+// no matter how many triggers there are. The regular expressions are
+// compiled once and indexed by a literal that every match contains, so
+// matching a message takes a lookup per position of the message and
+// only the regular expressions whose literal it contains are tried.
+type triggerIndex struct {
+	exact     map[string]string
+	prefixes  map[string]string
+	maxPrefix int
+	keywords  map[string]string
+	maxWords  int
+	regexes   []regexTrigger
+	// literals maps the first literalKey bytes of the
+	// required literals to the literals that start with them.
+	literals map[string][]string
+	// required maps each literal to the
+	// regular expressions that require it.
+	required map[string][]int
+	// unfiltered are the regular expressions without a literal.
+	unfiltered []int
+}
+
+type regexTrigger struct {
+	re      *regexp.Regexp
+	command string
+}
+
+// newTriggerIndex returns the index of the triggers of the received
+// commands. When several commands have the same trigger the first one
+// by name wins. Invalid triggers are ignored.
+func newTriggerIndex(commands []*proto.BotCommand) *triggerIndex {
+	sorted := make([]*proto.BotCommand, len(commands))
+	copy(sorted, commands)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].GetCmd().GetCommand() < sorted[j].GetCmd().GetCommand()
+	})
+
+	ix := &triggerIndex{
+		exact:    make(map[string]string),
+		prefixes: make(map[string]string),
+		keywords: make(map[string]string),
+		literals: make(map[string][]string),
+		required: make(map[string][]int),
+	}
+
+	set := func(m map[string]string, key, command string) {
+		if _, ok := m[key]; !ok {
+			m[key] = command
+		}
+	}
+
+	for _, c := range sorted {
+		command := c.GetCmd().GetCommand()
+		for _, t := range c.GetTriggers() {
+			switch t.GetType() {
+			case triggerExact:
+				set(ix.exact, normalize(t.GetPattern()), command)
+			case triggerPrefix:
+				prefix := normalize(t.GetPattern())
+				set(ix.prefixes, prefix, command)
+				if len(prefix) > ix.maxPrefix {
+					ix.maxPrefix = len(prefix)
+				}
+			case triggerKeyword:
+				kw := words(t.GetPattern())
+				if len(kw) == 0 {
+					continue
+				}
+
+				set(ix.keywords, strings.Join(kw, " "), command)
+				if len(kw) > ix.maxWords {
+					ix.maxWords = len(kw)
+				}
+			case triggerRegex:
+				re, err := regexp.Compile(t.GetPattern())
+				if err != nil {
+					continue
+				}
+
+				ix.addRegex(re, command)
+			}
+		}
+	}
+
+	return ix
+}
+
+// addRegex adds the regular expression indexing
+// it by its required literal, if it has one.
+func (ix *triggerIndex) addRegex(re *regexp.Regexp, command string) {
+	i := len(ix.regexes)
+	ix.regexes = append(ix.regexes, regexTrigger{re: re, command: command})
+
+	lit := ""
+	if parsed, err := syntax.Parse(re.String(), syntax.Perl); err == nil {
+		lit = requiredLiteral(parsed)
+	}
+
+	if len(lit) < literalKey {
+		ix.unfiltered = append(ix.unfiltered, i)
+		return
+	}
+
+	if _, ok := ix.required[lit]; !ok {
+		ix.literals[lit[:literalKey]] = append(ix.literals[lit[:literalKey]], lit)
+	}
+	ix.required[lit] = append(ix.required[lit], i)
+}
+
+// candidates returns, in the order in which they were added, the
+// regular expressions without a literal and the ones whose literal
+// is contained in the text.
+func (ix *triggerIndex) candidates(text string) []int {
+	if len(ix.required) == 0 {
+		return ix.unfiltered
+	}
+
+	candidates := append([]int(nil), ix.unfiltered...)
+	lower := strings.ToLower(text)
+	found := make(map[string]bool)
+	for i := 0; i+literalKey <= len(lower); i++ {
+		for _, lit := range ix.literals[lower[i:i+literalKey]] {
+			if !found[lit] && strings.HasPrefix(lower[i:], lit) {
+				found[lit] = true
+				candidates = append(candidates, ix.required[lit]...)
+			}
+		}
+	}
+
+	sort.Ints(candidates)
+	return candidates
+}
+
+// requiredLiteral returns the longest literal, lowercased, that the
+// parsed regular expression requires on every match, or an empty
+// string if there is none.
+func requiredLiteral(re *syntax.Regexp) string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return lowerLiteral(re)
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiteral(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min > 0 {
+			return requiredLiteral(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		longest := ""
+		for _, sub := range re.Sub {
+			if lit := requiredLiteral(sub); len(lit) > len(longest) {
+				longest = lit
+			}
+		}
+
+		return longest
+	}
+
+	return ""
+}
+
+// lowerLiteral returns the literal lowercased. Since lowercasing the
+// text isn't the same as case folding it, case-insensitive literals
+// are cut to their longest run of runes that only fold to their upper
+// and lower case.
+func lowerLiteral(re *syntax.Regexp) string {
+	if re.Flags&syntax.FoldCase == 0 {
+		return strings.ToLower(string(re.Rune))
+	}
+
+	var longest, run []rune
+	for _, r := range append(re.Rune, -1) {
+		folded := unicode.SimpleFold(r)
+		if r >= 0 && unicode.SimpleFold(folded) == r && unicode.ToLower(folded) == unicode.ToLower(r) {
+			run = append(run, unicode.ToLower(r))
+			continue
+		}
+
+		if len(run) > len(longest) {
+			longest = run
+		}
+		run = nil
+	}
+
+	return string(longest)
+}
+
+// match returns the command whose trigger matches the received text
+// and the named groups captured by its regular expression, if any.
+// Exact triggers take precedence over prefix triggers, which take
+// precedence over keyword triggers and these over regular expressions.
+// Among prefix and keyword triggers the longest one wins.
+func (ix *triggerIndex) match(text string) (string, map[string]string, bool) {
+	norm := normalize(text)
+	if command, ok := ix.exact[norm]; ok {
+		return command, nil, true
+	}
+
+	for i := min(len(norm), ix.maxPrefix); i > 0; i-- {
+		if i < len(norm) && !utf8.RuneStart(norm[i]) {
+			continue
+		}
+
+		if command, ok := ix.prefixes[norm[:i]]; ok {
+			return command, nil, true
+		}
+	}
+
+	ws := words(text)
+	for n := min(len(ws), ix.maxWords); n > 0; n-- {
+		for i := 0; i+n <= len(ws); i++ {
+			if command, ok := ix.keywords[strings.Join(ws[i:i+n], " ")]; ok {
+				return command, nil, true
+			}
+		}
+	}
+
+	for _, i := range ix.candidates(text) {
+		rt := ix.regexes[i]
+		groups := rt.re.FindStringSubmatch(text)
+		if groups == nil {
+			continue
+		}
+
+		captures := make(map[string]string)
+		for i, name := range rt.re.SubexpNames() {
+			if name != "" {
+				captures[name] = groups[i]
+			}
+		}
+
+		return rt.command, captures, true
+	}
+
+	return "", nil, false
+}
+
+// normalize lowercases the text and collapses its spaces.
+func normalize(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// words returns the lowercased words of the
+// text without the surrounding punctuation.
+func words(text string) []string {
+	var ws []string
+	for _, f := range strings.Fields(strings.ToLower(text)) {
+		w := strings.TrimFunc(f, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+
+		if w != "" {
+			ws = append(ws, w)
+		}
+	}
+
+	return ws
+}
+
+// triggerIndexTTL is the maximum time that the index of the
+// triggers is used before it's built again, so changes made
+// through other servers sharing the same database, which don't
+// change the change token, are picked up.
+const triggerIndexTTL = time.Minute
+
+// triggers keeps the index of the triggers of the stored commands,
+// which is built again the first time it's needed after the change
+// token changes or after triggerIndexTTL.
+type triggers struct {
+	mu    sync.Mutex
+	token string
+	built time.Time
+	index *triggerIndex
+}
+
+// get returns the index for the received change token, building
+// it from the commands returned by load if it was built for another
+// token or longer than triggerIndexTTL ago.
+func (t *triggers) get(token string, load func() (*proto.BotCommands, error)) (*triggerIndex, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.index == nil || t.token != token || time.Since(t.built) >= triggerIndexTTL {
+		commands, err := load()
+		if err != nil {
+			return nil, err
+		}
+
+		t.index = newTriggerIndex(commands.GetCommands())
+		t.token = token
+		t.built = time.Now()
+	}
+
+	return t.index, nil
+}
+
+// MatchMessage looks for the command with a trigger that matches the received message and returns it
+// as GetCommand does, rendering its stored response as a template with the named groups captured by
+// the regular expression of the trigger, if any. It returns a non-nil error if no command matches the
+// message, if something went wrong or if the context was cancelled.
+func (s *server) MatchMessage(ctx context.Context, req *proto.MatchRequest) (*proto.BotCommand, error) {
+	var ix *triggerIndex
+	var err error
+
+	start := time.Now()
+
+	text := strings.TrimSpace(req.GetText())
+	if text == "" {
+		return &proto.BotCommand{}, status.Error(codes.InvalidArgument, "no text provided")
+	}
+
+	select {
+	case <-ctx.Done():
+		return &proto.BotCommand{}, status.Error(codes.Canceled, ctx.Err().Error())
+	default:
+		ix, err = s.triggers.get(s.changes.token(), s.db.GetAll)
+		if err != nil {
+			s.logError(
+				"db",
+				"GetAll",
+				err.Error(),
+				"get BotCommands failed",
+			)
+
+			return &proto.BotCommand{}, status.Error(codes.Internal, "error while matching message")
+		}
+	}
+
+	command, captures, ok := ix.match(text)
+	if !ok {
+		return &proto.BotCommand{}, status.Error(codes.NotFound, "no command matches the message")
+	}
+
+	c, err := s.getCommand(ctx, &proto.Command{
+		Command: command,
+		Lang:    req.GetLang(),
+		Args:    strings.Fields(text),
+		Caller:  req.GetCaller(),
+	}, captures)
+	if err != nil {
+		return &proto.BotCommand{}, err
+	}
+
+	s.logInfo(
+		"server",
+		"MatchMessage",
+		fmt.Sprintf("BotCommand %q matched %q", command, text),
+		time.Since(start),
+	)
+	return c, nil
+}
+
+// renderCaptures returns a copy of the received command whose response
+// is rendered as a template with the named groups captured by the regular
+// expression of a trigger. Commands are returned as they are if there are
+// no captures or if their response can't be rendered.
+func (s *server) renderCaptures(cmd *proto.BotCommand, captures map[string]string) *proto.BotCommand {
+	if len(captures) == 0 || cmd.GetResp() == nil {
+		return cmd
+	}
+
+	resp, err := render(cmd.GetResp().GetResponse(), captures)
+	if err != nil {
+		s.logError(
+			"trigger",
+			"renderCaptures",
+			err.Error(),
+			fmt.Sprintf("render response of BotCommand %q with the captured groups failed", cmd.GetCmd().GetCommand()),
+		)
+		return cmd
+	}
+
+	rendered := pb.Clone(cmd).(*proto.BotCommand)
+	rendered.Resp.Response = resp
+	return rendered
+}
+
+// render executes the response as a template
+// with the named groups captured by a regular expression.
+func render(resp string, captures map[string]string) (string, error) {
+	t, err := template.New("trigger").Option("missingkey=zero").Parse(resp)
+	if err != nil {
+		return "", errors.Wrap(err, "while parsing response")
+	}
+
+	var out bytes.Buffer
+	if err := t.Execute(&out, captures); err != nil {
+		return "", errors.Wrap(err, "while executing response")
+	}
+
+	return out.String(), nil
+}
+
+// validateTriggers checks that the triggers of the received command
+// have a known type and a pattern, which must be a valid regular
+// expression for regex triggers.
+func validateTriggers(cmd *proto.BotCommand) error {
+	for i, t := range cmd.GetTriggers() {
+		switch t.GetType() {
+		case triggerExact, triggerPrefix:
+			if normalize(t.GetPattern()) == "" {
+				return errors.Errorf("empty pattern for trigger %v", i+1)
+			}
+		case triggerKeyword:
+			if len(words(t.GetPattern())) == 0 {
+				return errors.Errorf("no words on the pattern of trigger %v", i+1)
+			}
+		case triggerRegex:
+			if t.GetPattern() == "" {
+				return errors.Errorf("empty pattern for trigger %v", i+1)
+			}
+
+			if _, err := regexp.Compile(t.GetPattern()); err != nil {
+				return errors.Wrapf(err, "invalid regular expression for trigger %v", i+1)
+			}
+		default:
+			return errors.Errorf("unknown type %q of trigger %v", t.GetType(), i+1)
+		}
+	}
+
+	return nil
+}
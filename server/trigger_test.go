@@ -0,0 +1,301 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"regexp/syntax"
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTriggerIndex(t *testing.T) {
+	commands := []*proto.BotCommand{
+		{
+			Cmd:      &proto.Command{Command: "hello"},
+			Triggers: []*proto.Trigger{{Type: triggerExact, Pattern: "Good  morning"}},
+		},
+		{
+			Cmd:      &proto.Command{Command: "status"},
+			Triggers: []*proto.Trigger{{Type: triggerPrefix, Pattern: "status"}},
+		},
+		{
+			Cmd:      &proto.Command{Command: "status-build"},
+			Triggers: []*proto.Trigger{{Type: triggerPrefix, Pattern: "status of build"}},
+		},
+		{
+			Cmd: &proto.Command{Command: "deploy"},
+			Triggers: []*proto.Trigger{
+				{Type: triggerKeyword, Pattern: "deploy"},
+				{Type: triggerRegex, Pattern: `^deploy (?P<service>\w+) to (?P<env>\w+)$`},
+			},
+		},
+		{
+			Cmd:      &proto.Command{Command: "oncall"},
+			Triggers: []*proto.Trigger{{Type: triggerKeyword, Pattern: "on call"}},
+		},
+		{
+			Cmd:      &proto.Command{Command: "ticket"},
+			Triggers: []*proto.Trigger{{Type: triggerRegex, Pattern: `(?i)ticket #(?P<id>\d+)`}},
+		},
+	}
+
+	tt := []struct {
+		name             string
+		text             string
+		expectedCommand  string
+		expectedCaptures map[string]string
+	}{
+		{name: "exact", text: "good morning", expectedCommand: "hello"},
+		{name: "exact with extra words", text: "good morning all"},
+		{name: "prefix", text: "Status please", expectedCommand: "status"},
+		{name: "longest prefix", text: "status of build 42", expectedCommand: "status-build"},
+		{name: "keyword", text: "Can we deploy?", expectedCommand: "deploy"},
+		{name: "keyword inside a word", text: "redeployment"},
+		{name: "keyword of several words", text: "who is on  call today", expectedCommand: "oncall"},
+		{
+			name:             "regex",
+			text:             "see Ticket #123",
+			expectedCommand:  "ticket",
+			expectedCaptures: map[string]string{"id": "123"},
+		},
+		{name: "keyword before regex", text: "deploy api to production", expectedCommand: "deploy"},
+		{name: "no match", text: "nothing to see here"},
+	}
+
+	ix := newTriggerIndex(commands)
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			command, captures, ok := ix.match(tc.text)
+			if ok != (tc.expectedCommand != "") || command != tc.expectedCommand {
+				t.Fatalf("expected command %q. got=%q", tc.expectedCommand, command)
+			}
+
+			if len(captures) != len(tc.expectedCaptures) {
+				t.Fatalf("expected captures %v. got=%v", tc.expectedCaptures, captures)
+			}
+
+			for name, value := range tc.expectedCaptures {
+				if captures[name] != value {
+					t.Fatalf("expected captures %v. got=%v", tc.expectedCaptures, captures)
+				}
+			}
+		})
+	}
+}
+
+func TestTriggerIndexManyPatterns(t *testing.T) {
+	var commands []*proto.BotCommand
+	for i := 0; i < 5000; i++ {
+		commands = append(commands, &proto.BotCommand{
+			Cmd: &proto.Command{Command: fmt.Sprintf("command%v", i)},
+			Triggers: []*proto.Trigger{
+				{Type: triggerKeyword, Pattern: fmt.Sprintf("keyword%v", i)},
+				{Type: triggerPrefix, Pattern: fmt.Sprintf("prefix%v:", i)},
+			},
+		})
+	}
+
+	ix := newTriggerIndex(commands)
+	if command, _, _ := ix.match("is keyword4321 here?"); command != "command4321" {
+		t.Fatalf("expected command %q. got=%q", "command4321", command)
+	}
+
+	if command, _, _ := ix.match("prefix99: something"); command != "command99" {
+		t.Fatalf("expected command %q. got=%q", "command99", command)
+	}
+}
+
+func TestTriggerIndexManyRegexes(t *testing.T) {
+	commands := []*proto.BotCommand{{
+		Cmd:      &proto.Command{Command: "any"},
+		Triggers: []*proto.Trigger{{Type: triggerRegex, Pattern: `^\w+ #(?P<id>7)$`}},
+	}}
+
+	for i := 0; i < 5000; i++ {
+		commands = append(commands, &proto.BotCommand{
+			Cmd:      &proto.Command{Command: fmt.Sprintf("order%v", i)},
+			Triggers: []*proto.Trigger{{Type: triggerRegex, Pattern: fmt.Sprintf(`(?i)^order #(?P<id>\d+) from outlet%v$`, i)}},
+		})
+	}
+
+	ix := newTriggerIndex(commands)
+	// Besides the one without literal, only the ones for
+	// outlet4321, outlet432, outlet43 and outlet4 are tried.
+	if n := len(ix.candidates("Order #42 from Outlet4321")); n != 5 {
+		t.Fatalf("expected %v regular expressions tried. got=%v", 5, n)
+	}
+
+	command, captures, _ := ix.match("Order #42 from Outlet4321")
+	if command != "order4321" || captures["id"] != "42" {
+		t.Fatalf("expected command %q with id %q. got=%q with %v", "order4321", "42", command, captures)
+	}
+
+	// The regular expressions are tried in order whether they have a literal or not.
+	if command, _, _ := ix.match("order #7"); command != "any" {
+		t.Fatalf("expected command %q. got=%q", "any", command)
+	}
+}
+
+func TestRequiredLiteral(t *testing.T) {
+	tt := []struct {
+		pattern  string
+		expected string
+	}{
+		{pattern: `^deploy (?P<service>\w+) to production$`, expected: " to production"},
+		{pattern: `Ticket #\d+`, expected: "ticket #"},
+		{pattern: `(?i)ticket #\d+`, expected: "et #"},
+		{pattern: `(?:abc)+d`, expected: "abc"},
+		{pattern: `(?:abc)*d`, expected: "d"},
+		{pattern: `abc|def`},
+		{pattern: `\d{3}`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.pattern, func(t *testing.T) {
+			re, err := syntax.Parse(tc.pattern, syntax.Perl)
+			if err != nil {
+				t.Fatalf("while parsing %q: %v", tc.pattern, err)
+			}
+
+			if lit := requiredLiteral(re); lit != tc.expected {
+				t.Fatalf("expected literal %q. got=%q", tc.expected, lit)
+			}
+		})
+	}
+}
+
+func TestValidateTriggers(t *testing.T) {
+	tt := []struct {
+		name           string
+		trigger        *proto.Trigger
+		expectedToFail bool
+	}{
+		{name: "exact", trigger: &proto.Trigger{Type: triggerExact, Pattern: "hi"}},
+		{name: "regex", trigger: &proto.Trigger{Type: triggerRegex, Pattern: `^deploy (?P<env>\w+)`}},
+		{name: "unknown type", trigger: &proto.Trigger{Type: "glob", Pattern: "*"}, expectedToFail: true},
+		{name: "empty pattern", trigger: &proto.Trigger{Type: triggerPrefix, Pattern: " "}, expectedToFail: true},
+		{name: "keyword without words", trigger: &proto.Trigger{Type: triggerKeyword, Pattern: "?!"}, expectedToFail: true},
+		{name: "invalid regex", trigger: &proto.Trigger{Type: triggerRegex, Pattern: "(deploy"}, expectedToFail: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateTriggers(&proto.BotCommand{Triggers: []*proto.Trigger{tc.trigger}})
+			if tc.expectedToFail && err == nil {
+				t.Fatalf("expected validation to fail")
+			}
+
+			if !tc.expectedToFail && err != nil {
+				t.Fatalf("while validating triggers: %v", err)
+			}
+		})
+	}
+}
+
+func TestMatchMessage(t *testing.T) {
+	s := testServer(t)
+	ctx := context.TODO()
+
+	if _, err := s.AddCommand(ctx, &proto.BotCommand{
+		Cmd:      &proto.Command{Command: "invalid"},
+		Resp:     &proto.Response{Response: "invalid"},
+		Triggers: []*proto.Trigger{{Type: triggerRegex, Pattern: "(deploy"}},
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected %v adding a command with an invalid trigger. got=%v", codes.InvalidArgument, err)
+	}
+
+	deploy := &proto.BotCommand{
+		Cmd:      &proto.Command{Command: "deploy"},
+		Resp:     &proto.Response{Response: "Deploying {{.service}} to {{.env}}"},
+		Triggers: []*proto.Trigger{{Type: triggerRegex, Pattern: `^deploy (?P<service>\w+) to (?P<env>\w+)$`}},
+	}
+	if _, err := s.AddCommand(ctx, deploy); err != nil {
+		t.Fatalf("while adding command: %v", err)
+	}
+
+	c, err := s.MatchMessage(ctx, &proto.MatchRequest{Text: "deploy api to staging"})
+	if err != nil {
+		t.Fatalf("while matching message: %v", err)
+	}
+
+	if r := c.GetResp().GetResponse(); r != "Deploying api to staging" {
+		t.Fatalf("expected response %q. got=%q", "Deploying api to staging", r)
+	}
+
+	if _, err := s.MatchMessage(ctx, &proto.MatchRequest{Text: "deploy?"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected %v. got=%v", codes.NotFound, err)
+	}
+
+	greet := &proto.BotCommand{
+		Cmd:      &proto.Command{Command: "greet"},
+		Script:   "def respond(ctx):\n    return \"Hello {{.who}}, \" + ctx.args[1]",
+		Triggers: []*proto.Trigger{{Type: triggerRegex, Pattern: `^greet (?P<who>\w+)$`}},
+	}
+	if _, err := s.AddCommand(ctx, greet); err != nil {
+		t.Fatalf("while adding command: %v", err)
+	}
+
+	c, err = s.MatchMessage(ctx, &proto.MatchRequest{Text: "greet bob"})
+	if err != nil {
+		t.Fatalf("while matching message: %v", err)
+	}
+
+	if r := c.GetResp().GetResponse(); r != "Hello {{.who}}, bob" {
+		t.Fatalf("expected the response of the script %q not to be rendered. got=%q", "Hello {{.who}}, bob", r)
+	}
+
+	deploy.Triggers = append(deploy.Triggers, &proto.Trigger{Type: triggerKeyword, Pattern: "deploy"})
+	deploy.Resp.Response = "Deploys are done with /deploy"
+	if _, err := s.UpdateCommand(ctx, deploy); err != nil {
+		t.Fatalf("while updating command: %v", err)
+	}
+
+	c, err = s.MatchMessage(ctx, &proto.MatchRequest{Text: "deploy?"})
+	if err != nil {
+		t.Fatalf("while matching message after updating the triggers: %v", err)
+	}
+
+	if r := c.GetResp().GetResponse(); r != "Deploys are done with /deploy" {
+		t.Fatalf("expected response %q. got=%q", "Deploys are done with /deploy", r)
+	}
+
+	if _, err := s.DeleteCommand(ctx, &proto.Command{Command: "deploy"}); err != nil {
+		t.Fatalf("while deleting command: %v", err)
+	}
+
+	if _, err := s.MatchMessage(ctx, &proto.MatchRequest{Text: "deploy?"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected %v after deleting the command. got=%v", codes.NotFound, err)
+	}
+}
+
+func TestTriggersGet(t *testing.T) {
+	loads := 0
+	load := func() (*proto.BotCommands, error) {
+		loads++
+		return &proto.BotCommands{}, nil
+	}
+
+	tr := &triggers{}
+	for _, token := range []string{"a", "a", "b", "b"} {
+		if _, err := tr.get(token, load); err != nil {
+			t.Fatalf("while getting the index for token %q: %v", token, err)
+		}
+	}
+
+	if loads != 2 {
+		t.Fatalf("expected the index to be built %v times. got=%v", 2, loads)
+	}
+
+	tr.built = tr.built.Add(-triggerIndexTTL)
+	if _, err := tr.get("b", load); err != nil {
+		t.Fatalf("while getting the index after %v: %v", triggerIndexTTL, err)
+	}
+
+	if loads != 3 {
+		t.Fatalf("expected the index to be built again after %v. got=%v builds", triggerIndexTTL, loads)
+	}
+}
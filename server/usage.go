@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/danielkvist/botio/proto"
+	"github.com/danielkvist/botio/usage"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ReportUsage counts the outcomes of the lookups of the commands reported by a bot. It returns
+// a non-nil error if any of the events is invalid, in which case none is counted, if something
+// went wrong or if the context was cancelled.
+func (s *server) ReportUsage(ctx context.Context, report *proto.UsageReport) (*empty.Empty, error) {
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return &empty.Empty{}, status.Error(codes.Canceled, ctx.Err().Error())
+	default:
+		err := s.usage.Add(report.GetEvents())
+		if errors.Cause(err) == usage.ErrInvalid {
+			return &empty.Empty{}, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		if err != nil {
+			s.logError(
+				"usage",
+				"Add",
+				err.Error(),
+				fmt.Sprintf("add %v usage events failed", len(report.GetEvents())),
+			)
+			return &empty.Empty{}, status.Error(codes.Internal, "error while reporting usage")
+		}
+	}
+
+	s.logInfo(
+		"server",
+		"ReportUsage",
+		fmt.Sprintf("%v usage events reported successfully", len(report.GetEvents())),
+		time.Since(start),
+	)
+	return &empty.Empty{}, nil
+}
+
+// GetUsageStats returns the most used commands, the most requested commands that don't exist
+// and the lookups of each day of the requested period of time. It returns a non-nil error if
+// the context was cancelled.
+func (s *server) GetUsageStats(ctx context.Context, req *proto.UsageStatsRequest) (*proto.UsageStats, error) {
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return &proto.UsageStats{}, status.Error(codes.Canceled, ctx.Err().Error())
+	default:
+	}
+
+	stats := s.usage.Stats(req, time.Now())
+
+	s.logInfo(
+		"server",
+		"GetUsageStats",
+		fmt.Sprintf("usage stats of %v days gotten successfully", len(stats.GetDays())),
+		time.Since(start),
+	)
+	return stats, nil
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUsage(t *testing.T) {
+	s := testServer(t)
+	ctx := context.TODO()
+
+	if _, err := s.ReportUsage(ctx, &proto.UsageReport{Events: []*proto.UsageEvent{{Command: "start", Outcome: "found"}}}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected %v reporting an invalid event. got=%v", codes.InvalidArgument, err)
+	}
+
+	if _, err := s.ReportUsage(ctx, &proto.UsageReport{Events: []*proto.UsageEvent{
+		{Command: "start", Outcome: "hit", Chat: "a"},
+		{Command: "start", Outcome: "hit", Chat: "b"},
+		{Command: "strat", Outcome: "miss", Chat: "a"},
+	}}); err != nil {
+		t.Fatalf("while reporting usage: %v", err)
+	}
+
+	stats, err := s.GetUsageStats(ctx, &proto.UsageStatsRequest{Days: 1})
+	if err != nil {
+		t.Fatalf("while getting usage stats: %v", err)
+	}
+
+	if top := stats.GetTopCommands(); len(top) != 1 || top[0].GetCommand() != "start" || top[0].GetHits() != 2 || top[0].GetChats() != 2 {
+		t.Fatalf("expected %q used twice from 2 chats. got=%v", "start", top)
+	}
+
+	if missing := stats.GetTopMissing(); len(missing) != 1 || missing[0].GetCommand() != "strat" {
+		t.Fatalf("expected %q to be missing. got=%v", "strat", missing)
+	}
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sync"
+
+	"github.com/danielkvist/botio/proto"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// Selection modes of the variants of a response.
+const (
+	selectRandom     = "random"
+	selectRoundRobin = "round-robin"
+	selectWeighted   = "weighted"
+	selectSticky     = "sticky"
+)
+
+// variants picks one of the variants of the responses of the
+// commands, keeping the position of each command using the
+// round-robin selection.
+type variants struct {
+	mu   sync.Mutex
+	next map[string]int
+}
+
+func newVariants() *variants {
+	return &variants{next: make(map[string]int)}
+}
+
+// pick returns a copy of the received command whose response is one of its
+// variants, picked following its selection mode for the request no matter
+// its language. If the variant has translations they replace the ones of
+// the response, so localize translates the variant and not the response.
+// Commands without variants are returned as they are.
+func (v *variants) pick(cmd *proto.BotCommand, req *proto.Command) *proto.BotCommand {
+	vs := cmd.GetResp().GetVariants()
+	if len(vs) == 0 {
+		return cmd
+	}
+
+	var i int
+	switch cmd.GetResp().GetSelection() {
+	case selectRoundRobin:
+		v.mu.Lock()
+		name := cmd.GetCmd().GetCommand()
+		i = v.next[name] % len(vs)
+		v.next[name] = i + 1
+		v.mu.Unlock()
+	case selectWeighted:
+		i = weighted(vs, rand.Int63n(totalWeight(vs)))
+	case selectSticky:
+		caller := req.GetCaller()
+		if caller.GetUserId() == "" {
+			i = weighted(vs, rand.Int63n(totalWeight(vs)))
+			break
+		}
+
+		h := fnv.New64a()
+		h.Write([]byte(cmd.GetCmd().GetCommand() + ":" + caller.GetPlatform() + ":" + caller.GetUserId()))
+		i = weighted(vs, int64(h.Sum64()%uint64(totalWeight(vs))))
+	default:
+		i = rand.Intn(len(vs))
+	}
+
+	picked := pb.Clone(cmd).(*proto.BotCommand)
+	picked.Resp.Response = vs[i].GetResponse()
+	if translations := picked.Resp.Variants[i].GetTranslations(); len(translations) > 0 {
+		picked.Resp.Translations = translations
+	}
+
+	return picked
+}
+
+// weighted returns the index of the variant on which
+// n falls when laying out the variants by weight.
+func weighted(vs []*proto.Variant, n int64) int {
+	for i, v := range vs {
+		n -= weight(v)
+		if n < 0 {
+			return i
+		}
+	}
+
+	return len(vs) - 1
+}
+
+func totalWeight(vs []*proto.Variant) int64 {
+	var total int64
+	for _, v := range vs {
+		total += weight(v)
+	}
+
+	return total
+}
+
+func weight(v *proto.Variant) int64 {
+	if v.GetWeight() == 0 {
+		return 1
+	}
+
+	return v.GetWeight()
+}
+
+// validateVariants checks that the variants of the received command
+// have a response, a non-negative weight and valid translations and
+// that its selection mode is known.
+func validateVariants(cmd *proto.BotCommand) error {
+	switch s := cmd.GetResp().GetSelection(); s {
+	case "", selectRandom, selectRoundRobin, selectWeighted, selectSticky:
+	default:
+		return errors.Errorf("unknown selection %q", s)
+	}
+
+	var total int64
+	for i, v := range cmd.GetResp().GetVariants() {
+		if v.GetResponse() == "" {
+			return errors.Errorf("empty response for variant %v", i+1)
+		}
+
+		if v.GetWeight() < 0 {
+			return errors.Errorf("negative weight %v for variant %v", v.GetWeight(), i+1)
+		}
+
+		if err := checkTranslations(v.GetTranslations()); err != nil {
+			return errors.Wrapf(err, "variant %v", i+1)
+		}
+
+		total += weight(v)
+		if total < 0 {
+			return errors.New("weights are too large")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,237 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPickVariant(t *testing.T) {
+	variants := []*proto.Variant{
+		{Response: "Hi"},
+		{Response: "Hello", Weight: 3},
+		{Response: "Hey"},
+	}
+
+	tt := []struct {
+		name      string
+		selection string
+		check     func(t *testing.T, pick func(user string) string)
+	}{
+		{
+			name:      "random",
+			selection: selectRandom,
+			check: func(t *testing.T, pick func(string) string) {
+				seen := make(map[string]bool)
+				for i := 0; i < 200; i++ {
+					seen[pick("")] = true
+				}
+
+				if len(seen) != len(variants) {
+					t.Fatalf("expected every variant to be picked. got=%v", seen)
+				}
+			},
+		},
+		{
+			name:      "round-robin",
+			selection: selectRoundRobin,
+			check: func(t *testing.T, pick func(string) string) {
+				for i := 0; i < 6; i++ {
+					if r, expected := pick(""), variants[i%len(variants)].GetResponse(); r != expected {
+						t.Fatalf("expected pick %v to be %q. got=%q", i, expected, r)
+					}
+				}
+			},
+		},
+		{
+			name:      "weighted",
+			selection: selectWeighted,
+			check: func(t *testing.T, pick func(string) string) {
+				counts := make(map[string]int)
+				for i := 0; i < 5000; i++ {
+					counts[pick("")]++
+				}
+
+				if counts["Hello"] < 2*counts["Hi"] || counts["Hello"] < 2*counts["Hey"] {
+					t.Fatalf("expected the heaviest variant to be picked more often. got=%v", counts)
+				}
+			},
+		},
+		{
+			name:      "sticky",
+			selection: selectSticky,
+			check: func(t *testing.T, pick func(string) string) {
+				seen := make(map[string]bool)
+				for u := 0; u < 50; u++ {
+					user := fmt.Sprint(u)
+					first := pick(user)
+					for i := 0; i < 5; i++ {
+						if r := pick(user); r != first {
+							t.Fatalf("expected user %q to always get %q. got=%q", user, first, r)
+						}
+					}
+
+					seen[first] = true
+				}
+
+				if len(seen) < 2 {
+					t.Fatalf("expected users to get different variants. got=%v", seen)
+				}
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v := newVariants()
+			cmd := &proto.BotCommand{
+				Cmd:  &proto.Command{Command: "greet"},
+				Resp: &proto.Response{Response: "Hi", Variants: variants, Selection: tc.selection},
+			}
+
+			tc.check(t, func(user string) string {
+				picked := v.pick(cmd, &proto.Command{Command: "greet", Caller: &proto.Caller{Platform: "telegram", UserId: user}})
+				if cmd.GetResp().GetResponse() != "Hi" {
+					t.Fatalf("pick modified the received command")
+				}
+
+				return picked.GetResp().GetResponse()
+			})
+		})
+	}
+}
+
+func TestValidateVariants(t *testing.T) {
+	tt := []struct {
+		name           string
+		resp           *proto.Response
+		expectedToFail bool
+	}{
+		{
+			name: "without variants",
+			resp: &proto.Response{Response: "Hi"},
+		},
+		{
+			name: "valid",
+			resp: &proto.Response{Variants: []*proto.Variant{{Response: "Hi"}, {Response: "Hello", Weight: 2}}, Selection: selectWeighted},
+		},
+		{
+			name:           "unknown selection",
+			resp:           &proto.Response{Variants: []*proto.Variant{{Response: "Hi"}}, Selection: "shuffle"},
+			expectedToFail: true,
+		},
+		{
+			name:           "empty variant",
+			resp:           &proto.Response{Variants: []*proto.Variant{{Response: "Hi"}, {}}},
+			expectedToFail: true,
+		},
+		{
+			name:           "invalid translation",
+			resp:           &proto.Response{Variants: []*proto.Variant{{Response: "Hi", Translations: map[string]string{"not a tag": "Hola"}}}},
+			expectedToFail: true,
+		},
+		{
+			name:           "negative weight",
+			resp:           &proto.Response{Variants: []*proto.Variant{{Response: "Hi", Weight: -1}}},
+			expectedToFail: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateVariants(&proto.BotCommand{Resp: tc.resp})
+			if tc.expectedToFail && err == nil {
+				t.Fatalf("expected validation to fail")
+			}
+
+			if !tc.expectedToFail && err != nil {
+				t.Fatalf("while validating variants: %v", err)
+			}
+		})
+	}
+}
+
+func TestGetCommandVariants(t *testing.T) {
+	s := testServer(t)
+	ctx := context.TODO()
+
+	if _, err := s.AddCommand(ctx, &proto.BotCommand{
+		Cmd:  &proto.Command{Command: "greet"},
+		Resp: &proto.Response{Variants: []*proto.Variant{{Response: "Hi"}}, Selection: "shuffle"},
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected %v adding a command with invalid variants. got=%v", codes.InvalidArgument, err)
+	}
+
+	if _, err := s.AddCommand(ctx, &proto.BotCommand{
+		Cmd: &proto.Command{Command: "greet"},
+		Resp: &proto.Response{
+			Variants:  []*proto.Variant{{Response: "Hi"}, {Response: "Hello"}},
+			Selection: selectRoundRobin,
+		},
+	}); err != nil {
+		t.Fatalf("while adding command: %v", err)
+	}
+
+	// Lookups after the first one are served from the cache,
+	// which must keep every variant to keep picking them.
+	for i, expected := range []string{"Hi", "Hello", "Hi", "Hello"} {
+		c, err := s.GetCommand(ctx, &proto.Command{Command: "greet"})
+		if err != nil {
+			t.Fatalf("while getting command: %v", err)
+		}
+
+		if r := c.GetResp().GetResponse(); r != expected {
+			t.Fatalf("expected lookup %v to get %q. got=%q", i, expected, r)
+		}
+	}
+}
+
+func TestGetCommandTranslatedVariants(t *testing.T) {
+	s := testServer(t)
+	ctx := context.TODO()
+
+	if _, err := s.AddCommand(ctx, &proto.BotCommand{
+		Cmd: &proto.Command{Command: "greet"},
+		Resp: &proto.Response{
+			Response:     "Hi",
+			Translations: map[string]string{"es": "Hola"},
+			Variants: []*proto.Variant{
+				{Response: "Hello", Translations: map[string]string{"es": "Buenas", "fr": "Bonjour"}},
+				{Response: "Hey"},
+			},
+			Selection: selectRoundRobin,
+		},
+	}); err != nil {
+		t.Fatalf("while adding command: %v", err)
+	}
+
+	// The variant is picked no matter the language and translated with
+	// its translations or, if it has none, with the ones of the response.
+	tt := []struct {
+		lang     string
+		expected string
+	}{
+		{lang: "es-AR", expected: "Buenas"},
+		{lang: "es-AR", expected: "Hola"},
+		{lang: "fr", expected: "Bonjour"},
+		{lang: "fr", expected: "Hey"},
+		{lang: "de", expected: "Hello"},
+		{lang: "de", expected: "Hey"},
+	}
+
+	for i, tc := range tt {
+		c, err := s.GetCommand(ctx, &proto.Command{Command: "greet", Lang: tc.lang})
+		if err != nil {
+			t.Fatalf("while getting command: %v", err)
+		}
+
+		if r := c.GetResp().GetResponse(); r != tc.expected {
+			t.Fatalf("expected lookup %v in %q to get %q. got=%q", i, tc.lang, tc.expected, r)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/danielkvist/botio/proto"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ifMatchKey is the metadata key under which the
+// JSON gateway forwards the If-Match header.
+const ifMatchKey = runtime.MetadataPrefix + "If-Match"
+
+// expectedVersion returns the version that a command is expected to have
+// when it's changed. If the request doesn't carry one it falls back to the
+// If-Match header forwarded by the JSON gateway, where "*" matches any version.
+func expectedVersion(ctx context.Context, version int64) (int64, error) {
+	if version != 0 {
+		return version, nil
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0, nil
+	}
+
+	values := md.Get(ifMatchKey)
+	if len(values) == 0 {
+		return 0, nil
+	}
+
+	etag := strings.TrimPrefix(strings.TrimSpace(values[0]), "W/")
+	if etag == "*" {
+		return 0, nil
+	}
+
+	v, err := strconv.ParseInt(strings.Trim(etag, `"`), 10, 64)
+	if err != nil || v < 1 {
+		return 0, errors.Errorf("invalid If-Match header %q", values[0])
+	}
+
+	return v, nil
+}
+
+// etag returns the entity tag of the received version.
+func etag(version int64) string {
+	return fmt.Sprintf("%q", strconv.FormatInt(version, 10))
+}
+
+// setETag sets the ETag header of the JSON gateway
+// responses that return a single *proto.BotCommand.
+func setETag(_ context.Context, w http.ResponseWriter, msg pb.Message) error {
+	if cmd, ok := msg.(*proto.BotCommand); ok && cmd.GetVersion() != 0 {
+		w.Header().Set("ETag", etag(cmd.GetVersion()))
+	}
+
+	return nil
+}
+
+// httpError replies to the JSON gateway requests that failed like the
+// default handler, except for a version mismatch that is replied with
+// 412 Precondition Failed instead of 409 Conflict.
+func httpError(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	if status.Code(err) == codes.Aborted {
+		w = &statusWriter{ResponseWriter: w, status: http.StatusPreconditionFailed}
+	}
+
+	runtime.DefaultHTTPError(ctx, mux, marshaler, w, r, err)
+}
+
+// statusWriter is a http.ResponseWriter
+// that always replies with its status.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(int) {
+	w.ResponseWriter.WriteHeader(w.status)
+}
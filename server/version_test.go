@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestVersions(t *testing.T) {
+	s := testServer(t)
+	if _, err := s.AddCommand(context.TODO(), &proto.BotCommand{
+		Cmd:  &proto.Command{Command: "start"},
+		Resp: &proto.Response{Response: "hi"},
+	}); err != nil {
+		t.Fatalf("while adding command: %v", err)
+	}
+
+	ifMatch := func(etag string) context.Context {
+		return metadata.NewIncomingContext(context.TODO(), metadata.Pairs(ifMatchKey, etag))
+	}
+
+	tt := []struct {
+		name         string
+		ctx          context.Context
+		version      int64
+		expectedCode codes.Code
+	}{
+		{
+			name:         "current version",
+			ctx:          context.TODO(),
+			version:      1,
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "stale version",
+			ctx:          context.TODO(),
+			version:      1,
+			expectedCode: codes.Aborted,
+		},
+		{
+			name:         "current version on If-Match",
+			ctx:          ifMatch(`"2"`),
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "stale version on If-Match",
+			ctx:          ifMatch(`W/"2"`),
+			expectedCode: codes.Aborted,
+		},
+		{
+			name:         "any version on If-Match",
+			ctx:          ifMatch("*"),
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "invalid If-Match",
+			ctx:          ifMatch("latest"),
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "without version",
+			ctx:          context.TODO(),
+			expectedCode: codes.OK,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := s.UpdateCommand(tc.ctx, &proto.BotCommand{
+				Cmd:     &proto.Command{Command: "start"},
+				Resp:    &proto.Response{Response: tc.name},
+				Version: tc.version,
+			})
+			if code := status.Code(err); code != tc.expectedCode {
+				t.Fatalf("expected code %v. got=%v (%v)", tc.expectedCode, code, err)
+			}
+		})
+	}
+
+	if _, err := s.DeleteCommand(context.TODO(), &proto.Command{Command: "start", Version: 1}); status.Code(err) != codes.Aborted {
+		t.Fatalf("expected code %v deleting a stale command. got=%v", codes.Aborted, status.Code(err))
+	}
+
+	cmd, err := s.GetCommand(context.TODO(), &proto.Command{Command: "start"})
+	if err != nil {
+		t.Fatalf("while getting command: %v", err)
+	}
+
+	if cmd.GetVersion() != 5 {
+		t.Fatalf("expected version %v. got=%v", 5, cmd.GetVersion())
+	}
+
+	if _, err := s.DeleteCommand(ifMatch(`"5"`), &proto.Command{Command: "start"}); err != nil {
+		t.Fatalf("while deleting command: %v", err)
+	}
+}
+
+func TestGatewayVersions(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := setETag(context.TODO(), w, &proto.BotCommand{Version: 3}); err != nil {
+		t.Fatalf("while setting ETag: %v", err)
+	}
+
+	if etag := w.Header().Get("ETag"); etag != `"3"` {
+		t.Fatalf("expected ETag %q. got=%q", `"3"`, etag)
+	}
+
+	tt := []struct {
+		name           string
+		code           codes.Code
+		expectedStatus int
+	}{
+		{
+			name:           "version mismatch",
+			code:           codes.Aborted,
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:           "alias in use",
+			code:           codes.AlreadyExists,
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPut, "/api/v1/commands/start", nil)
+			httpError(context.TODO(), runtime.NewServeMux(), &runtime.JSONPb{}, w, r, status.Error(tc.code, tc.name))
+
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("expected status %v. got=%v", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/danielkvist/botio/proto"
+	"github.com/danielkvist/botio/webhook"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AddWebhook registers the received webhook to be notified of the changes made to the commands
+// and returns it with its ID but without its secret. It returns a non-nil error if the webhook
+// is invalid, if something went wrong or if the context was cancelled.
+func (s *server) AddWebhook(ctx context.Context, h *proto.Webhook) (*proto.Webhook, error) {
+	var hook *proto.Webhook
+	var err error
+
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return &proto.Webhook{}, status.Error(codes.Canceled, ctx.Err().Error())
+	default:
+		hook, err = s.webhooks.Add(h)
+		if errors.Cause(err) == webhook.ErrInvalid {
+			return &proto.Webhook{}, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		if err != nil {
+			s.logError(
+				"webhook",
+				"Add",
+				err.Error(),
+				fmt.Sprintf("add webhook for %q failed", h.GetUrl()),
+			)
+			return &proto.Webhook{}, status.Error(codes.Internal, "error while adding webhook")
+		}
+	}
+
+	s.logInfo(
+		"server",
+		"AddWebhook",
+		fmt.Sprintf("webhook %q for %q added successfully", hook.GetId(), hook.GetUrl()),
+		time.Since(start),
+	)
+	return hook, nil
+}
+
+// ListWebhooks returns the registered webhooks without their secrets.
+// It returns a non-nil error if the context was cancelled.
+func (s *server) ListWebhooks(ctx context.Context, _ *empty.Empty) (*proto.Webhooks, error) {
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return &proto.Webhooks{}, status.Error(codes.Canceled, ctx.Err().Error())
+	default:
+	}
+
+	hooks := s.webhooks.List()
+
+	s.logInfo(
+		"server",
+		"ListWebhooks",
+		fmt.Sprintf("%v webhooks gotten successfully", len(hooks.GetWebhooks())),
+		time.Since(start),
+	)
+	return hooks, nil
+}
+
+// DeleteWebhook unregisters the webhook with the ID of the received one. It returns a non-nil
+// error if the webhook is not registered, if something went wrong or if the context was cancelled.
+func (s *server) DeleteWebhook(ctx context.Context, h *proto.Webhook) (*empty.Empty, error) {
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return &empty.Empty{}, status.Error(codes.Canceled, ctx.Err().Error())
+	default:
+		err := s.webhooks.Remove(h.GetId())
+		if errors.Cause(err) == webhook.ErrNotFound {
+			return &empty.Empty{}, status.Error(codes.NotFound, err.Error())
+		}
+
+		if err != nil {
+			s.logError(
+				"webhook",
+				"Remove",
+				err.Error(),
+				fmt.Sprintf("remove webhook %q failed", h.GetId()),
+			)
+			return &empty.Empty{}, status.Error(codes.Internal, "error while removing webhook")
+		}
+	}
+
+	s.logInfo(
+		"server",
+		"DeleteWebhook",
+		fmt.Sprintf("webhook %q removed successfully", h.GetId()),
+		time.Since(start),
+	)
+	return &empty.Empty{}, nil
+}
+
+// ListWebhookDeliveries returns the deliveries made to the webhooks that meet the conditions
+// of the received filter, from the oldest to the newest, including the dead ones that failed
+// after every retry. It returns a non-nil error if the context was cancelled.
+func (s *server) ListWebhookDeliveries(ctx context.Context, f *proto.DeliveryFilter) (*proto.WebhookDeliveries, error) {
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return &proto.WebhookDeliveries{}, status.Error(codes.Canceled, ctx.Err().Error())
+	default:
+	}
+
+	deliveries := s.webhooks.Deliveries(f)
+
+	s.logInfo(
+		"server",
+		"ListWebhookDeliveries",
+		fmt.Sprintf("%v webhook deliveries gotten successfully", len(deliveries.GetDeliveries())),
+		time.Since(start),
+	)
+	return deliveries, nil
+}
+
+// RedeliverWebhook makes again the dead delivery with the ID of the received one and returns it.
+// It returns a non-nil error if the delivery is not dead, if its webhook is no longer registered,
+// if something went wrong or if the context was cancelled.
+func (s *server) RedeliverWebhook(ctx context.Context, dl *proto.WebhookDelivery) (*proto.WebhookDelivery, error) {
+	var delivery *proto.WebhookDelivery
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return &proto.WebhookDelivery{}, status.Error(codes.Canceled, ctx.Err().Error())
+	default:
+		var err error
+		delivery, err = s.webhooks.Redeliver(dl.GetId())
+		switch errors.Cause(err) {
+		case webhook.ErrNoDelivery, webhook.ErrNotFound:
+			return &proto.WebhookDelivery{}, status.Error(codes.NotFound, err.Error())
+		}
+
+		if err != nil {
+			s.logError(
+				"webhook",
+				"Redeliver",
+				err.Error(),
+				fmt.Sprintf("keep redelivery of delivery %v failed", dl.GetId()),
+			)
+		}
+	}
+
+	s.logInfo(
+		"server",
+		"RedeliverWebhook",
+		fmt.Sprintf("delivery %v to webhook %q redelivered successfully", delivery.GetId(), delivery.GetWebhook()),
+		time.Since(start),
+	)
+	return delivery, nil
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/danielkvist/botio/proto"
+	"github.com/danielkvist/botio/webhook"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWebhooks(t *testing.T) {
+	var mu sync.Mutex
+	var events []*proto.AuditEvent
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get(webhook.SignatureHeader) != webhook.Sign("s3cr3t", body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		ev := &proto.AuditEvent{}
+		if err := jsonpb.UnmarshalString(string(body), ev); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		events = append(events, ev)
+		mu.Unlock()
+	}))
+	defer receiver.Close()
+
+	s := testServer(t)
+	ctx := context.TODO()
+
+	if _, err := s.AddWebhook(ctx, &proto.Webhook{Url: "not an url"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected %v adding an invalid webhook. got=%v", codes.InvalidArgument, err)
+	}
+
+	hook, err := s.AddWebhook(ctx, &proto.Webhook{Url: receiver.URL, Events: []string{actionAdd, actionDelete}, Secret: "s3cr3t"})
+	if err != nil {
+		t.Fatalf("while adding webhook: %v", err)
+	}
+
+	start := &proto.BotCommand{Cmd: &proto.Command{Command: "start"}, Resp: &proto.Response{Response: "hi"}}
+	if _, err := s.AddCommand(ctx, start); err != nil {
+		t.Fatalf("while adding command: %v", err)
+	}
+
+	if _, err := s.UpdateCommand(ctx, &proto.BotCommand{Cmd: start.GetCmd(), Resp: &proto.Response{Response: "hello"}}); err != nil {
+		t.Fatalf("while updating command: %v", err)
+	}
+
+	if _, err := s.DeleteCommand(ctx, start.GetCmd()); err != nil {
+		t.Fatalf("while deleting command: %v", err)
+	}
+
+	s.(*server).webhooks.Wait()
+
+	// Deliveries are made concurrently, so they may arrive in any order.
+	sort.Slice(events, func(i, j int) bool { return events[i].GetId() < events[j].GetId() })
+
+	if len(events) != 2 || events[0].GetAction() != actionAdd || events[1].GetAction() != actionDelete {
+		t.Fatalf("expected %q and %q events. got=%v", actionAdd, actionDelete, events)
+	}
+
+	if events[0].GetAfter().GetResp().GetResponse() != "hi" || events[1].GetBefore().GetResp().GetResponse() != "hello" {
+		t.Fatalf("expected events with the command before and after the change. got=%v", events)
+	}
+
+	deliveries, err := s.ListWebhookDeliveries(ctx, &proto.DeliveryFilter{Webhook: hook.GetId()})
+	if err != nil {
+		t.Fatalf("while listing webhook deliveries: %v", err)
+	}
+
+	for _, dl := range deliveries.GetDeliveries() {
+		if !dl.GetDelivered() {
+			t.Fatalf("expected delivery %v to be delivered. got=%v", dl.GetId(), dl)
+		}
+
+		if _, err := s.RedeliverWebhook(ctx, dl); status.Code(err) != codes.NotFound {
+			t.Fatalf("expected %v redelivering delivered delivery %v. got=%v", codes.NotFound, dl.GetId(), err)
+		}
+	}
+
+	hooks, err := s.ListWebhooks(ctx, &empty.Empty{})
+	if err != nil {
+		t.Fatalf("while listing webhooks: %v", err)
+	}
+
+	if len(hooks.GetWebhooks()) != 1 || hooks.GetWebhooks()[0].GetSecret() != "" {
+		t.Fatalf("expected only webhook %q without secret. got=%v", hook.GetId(), hooks)
+	}
+
+	if _, err := s.DeleteWebhook(ctx, hook); err != nil {
+		t.Fatalf("while deleting webhook: %v", err)
+	}
+
+	if _, err := s.DeleteWebhook(ctx, hook); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected %v deleting webhook twice. got=%v", codes.NotFound, err)
+	}
+}
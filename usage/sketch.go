@@ -0,0 +1,85 @@
+package usage
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+// sketchBits is the number of bits of the hash of a
+// chat that select the register of a sketch for it.
+const sketchBits = 10
+
+// sketch is a HyperLogLog sketch that estimates the number
+// of distinct chats added to it, with an error of about 3%,
+// in a fixed size no matter how many chats are added.
+type sketch []byte
+
+// add adds the chat to the sketch allocating its registers if needed.
+func (s *sketch) add(chat string) {
+	if *s == nil {
+		*s = make(sketch, 1<<sketchBits)
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(chat))
+	x := mix(h.Sum64())
+
+	i := x >> (64 - sketchBits)
+	rank := uint8(bits.LeadingZeros64(x<<sketchBits|1<<(sketchBits-1))) + 1
+	if rank > (*s)[i] {
+		(*s)[i] = rank
+	}
+}
+
+// merge adds the chats added to other to the sketch.
+func (s *sketch) merge(other sketch) {
+	if len(other) != 1<<sketchBits {
+		return
+	}
+
+	if *s == nil {
+		*s = make(sketch, 1<<sketchBits)
+	}
+
+	for i, rank := range other {
+		if rank > (*s)[i] {
+			(*s)[i] = rank
+		}
+	}
+}
+
+// count returns the estimated number of distinct chats added to the sketch.
+func (s sketch) count() int64 {
+	if len(s) != 1<<sketchBits {
+		return 0
+	}
+
+	m := float64(len(s))
+	sum, zeros := 0.0, 0
+	for _, rank := range s {
+		sum += math.Ldexp(1, -int(rank))
+		if rank == 0 {
+			zeros++
+		}
+	}
+
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// Linear counting is more accurate for few chats.
+		estimate = m * math.Log(m/float64(zeros))
+	}
+
+	return int64(estimate + 0.5)
+}
+
+// mix spreads the bits of the hash since FNV barely
+// changes the high bits of the hash of short chats.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,427 @@
+// Package usage exports a Store that aggregates the lookups of the
+// commands reported by the bots into counts per command and day.
+package usage
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/pkg/errors"
+)
+
+// Outcomes of the lookup of a command.
+const (
+	Hit   = "hit"
+	Miss  = "miss"
+	Error = "error"
+)
+
+// Defaults of the usage stats requests.
+const (
+	defaultDays  = 7
+	defaultLimit = 10
+)
+
+const (
+	// maxMissing is the maximum number of commands counted on a day only
+	// because they were requested without existing. The lookups of the
+	// rest of the missing commands of the day are counted on otherCommand.
+	maxMissing = 1000
+	// otherCommand is the name on which the lookups of the missing
+	// commands are counted once a day has maxMissing of them.
+	otherCommand = "(other)"
+	// compactRecords is the number of records appended to the
+	// usage file after which it's rewritten with a record for
+	// each command and day.
+	compactRecords = 10000
+)
+
+// dayLayout is the layout of the days on which the lookups are counted.
+const dayLayout = "2006-01-02"
+
+// ErrInvalid is returned when a reported
+// event has no command or an unknown outcome.
+var ErrInvalid = errors.New("invalid usage event")
+
+// counter holds the lookups of a command on a day
+// and a sketch of the chats in which it was requested.
+type counter struct {
+	Hits    int64  `json:"hits"`
+	Misses  int64  `json:"misses"`
+	Errors  int64  `json:"errors"`
+	Latency int64  `json:"latency_ms"`
+	Chats   sketch `json:"chats,omitempty"`
+}
+
+// add adds the lookups and chats of other to the counter.
+func (c *counter) add(other counter) {
+	c.Hits += other.Hits
+	c.Misses += other.Misses
+	c.Errors += other.Errors
+	c.Latency += other.Latency
+	c.Chats.merge(other.Chats)
+}
+
+// record is a line of the usage file with lookups of a command on
+// a day, which are added to the ones of the previous lines for the
+// same command and day. The chats of the lookups are kept as they
+// are, or as a sketch once the file is compacted.
+type record struct {
+	Day     string `json:"day"`
+	Command string `json:"command"`
+	counter
+	ChatIDs []string `json:"chat_ids,omitempty"`
+}
+
+// Store aggregates the reported events into counts per command and day,
+// in UTC, keeping the days of the last Retention days on the file on Path
+// as JSON lines, or only in memory if Path is empty. A Retention of zero
+// keeps every day. The lookups of each report are appended to the file,
+// which is compacted when the Store is created and every compactRecords.
+type Store struct {
+	Path      string
+	Retention int
+	mu        sync.Mutex
+	days      map[string]map[string]*counter
+	// missing is the number of commands counted on each day
+	// only because they were requested without existing.
+	missing    map[string]int
+	maxMissing int
+	// records is the number of records appended
+	// since the file was last compacted.
+	records int
+}
+
+// New returns a Store with the counts kept
+// on the file on path, if any.
+func New(path string, retention int) (*Store, error) {
+	s := &Store{
+		Path:       path,
+		Retention:  retention,
+		days:       make(map[string]map[string]*counter),
+		missing:    make(map[string]int),
+		maxMissing: maxMissing,
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Add counts the received events and appends them to the file. Events
+// without time are counted on the current day. If any event is invalid
+// none is counted.
+func (s *Store) Add(events []*proto.UsageEvent) error {
+	for _, ev := range events {
+		if ev.GetCommand() == "" {
+			return errors.Wrap(ErrInvalid, "no command provided")
+		}
+
+		switch ev.GetOutcome() {
+		case Hit, Miss, Error:
+		default:
+			return errors.Wrapf(ErrInvalid, "unknown outcome %q of command %q", ev.GetOutcome(), ev.GetCommand())
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	var records []*record
+	added := make(map[string]*record)
+	for _, ev := range events {
+		t, err := ptypes.Timestamp(ev.GetTime())
+		if err != nil {
+			t = now
+		}
+
+		day := t.UTC().Format(dayLayout)
+		name := s.name(day, ev.GetCommand(), ev.GetOutcome())
+
+		r := added[day+"/"+name]
+		if r == nil {
+			r = &record{Day: day, Command: name}
+			added[day+"/"+name] = r
+			records = append(records, r)
+		}
+
+		switch ev.GetOutcome() {
+		case Hit:
+			r.Hits++
+		case Miss:
+			r.Misses++
+		case Error:
+			r.Errors++
+		}
+
+		r.Latency += ev.GetLatencyMs()
+		if ev.GetChat() != "" {
+			r.ChatIDs = append(r.ChatIDs, ev.GetChat())
+		}
+	}
+
+	for _, r := range records {
+		s.apply(r)
+	}
+
+	s.prune(now)
+	return s.append(records)
+}
+
+// name returns the name on which a lookup of the command on the
+// day is counted, which is otherCommand if the command is missing
+// and the day already has the maximum of missing commands.
+func (s *Store) name(day, command, outcome string) string {
+	if _, ok := s.days[day][command]; !ok && outcome == Miss {
+		if s.missing[day] >= s.maxMissing {
+			return otherCommand
+		}
+
+		s.missing[day]++
+	}
+
+	// The counter is created now so the next events
+	// of the report are counted on the same name.
+	s.counter(day, command)
+	return command
+}
+
+// counter returns the counter of the command on the day creating it if needed.
+func (s *Store) counter(day, command string) *counter {
+	if s.days[day] == nil {
+		s.days[day] = make(map[string]*counter)
+	}
+
+	c := s.days[day][command]
+	if c == nil {
+		c = &counter{}
+		s.days[day][command] = c
+	}
+
+	return c
+}
+
+// apply adds the lookups of the record to the counter of its command and day.
+func (s *Store) apply(r *record) {
+	c := s.counter(r.Day, r.Command)
+	c.add(r.counter)
+	for _, chat := range r.ChatIDs {
+		c.Chats.add(chat)
+	}
+}
+
+// Stats returns the usage stats of the requested
+// number of days before now, including today.
+func (s *Store) Stats(req *proto.UsageStatsRequest, now time.Time) *proto.UsageStats {
+	days, limit := int(req.GetDays()), int(req.GetLimit())
+	if days <= 0 {
+		days = defaultDays
+	}
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := &proto.UsageStats{}
+	commands := make(map[string]*proto.CommandUsage)
+	chats := make(map[string]sketch)
+	lookups := make(map[string]int64)
+
+	for i := days - 1; i >= 0; i-- {
+		day := now.UTC().AddDate(0, 0, -i).Format(dayLayout)
+		total := &proto.DayUsage{Day: day}
+
+		for name, c := range s.days[day] {
+			u := commands[name]
+			if u == nil {
+				u = &proto.CommandUsage{Command: name}
+				commands[name] = u
+			}
+
+			u.Hits += c.Hits
+			u.Misses += c.Misses
+			u.Errors += c.Errors
+			u.AvgLatencyMs += c.Latency
+			lookups[name] += c.Hits + c.Misses + c.Errors
+			sk := chats[name]
+			sk.merge(c.Chats)
+			chats[name] = sk
+
+			total.Hits += c.Hits
+			total.Misses += c.Misses
+			total.Errors += c.Errors
+		}
+
+		stats.Days = append(stats.Days, total)
+	}
+
+	for name, u := range commands {
+		u.Chats = chats[name].count()
+		if n := lookups[name]; n > 0 {
+			u.AvgLatencyMs /= n
+		}
+
+		if u.Hits > 0 {
+			stats.TopCommands = append(stats.TopCommands, u)
+		}
+
+		if u.Misses > 0 {
+			stats.TopMissing = append(stats.TopMissing, u)
+		}
+	}
+
+	stats.TopCommands = top(stats.TopCommands, limit, func(u *proto.CommandUsage) int64 { return u.GetHits() })
+	stats.TopMissing = top(stats.TopMissing, limit, func(u *proto.CommandUsage) int64 { return u.GetMisses() })
+	return stats
+}
+
+// top sorts the commands by count in descending
+// order, and by name, keeping the first limit ones.
+func top(commands []*proto.CommandUsage, limit int, count func(*proto.CommandUsage) int64) []*proto.CommandUsage {
+	sort.Slice(commands, func(i, j int) bool {
+		if ci, cj := count(commands[i]), count(commands[j]); ci != cj {
+			return ci > cj
+		}
+
+		return commands[i].GetCommand() < commands[j].GetCommand()
+	})
+
+	if len(commands) > limit {
+		commands = commands[:limit]
+	}
+
+	return commands
+}
+
+// prune removes the days older than the retention.
+func (s *Store) prune(now time.Time) {
+	if s.Retention <= 0 {
+		return
+	}
+
+	oldest := now.AddDate(0, 0, -(s.Retention - 1)).Format(dayLayout)
+	for day := range s.days {
+		if day < oldest {
+			delete(s.days, day)
+			delete(s.missing, day)
+		}
+	}
+}
+
+// load replays the records of the file and compacts it.
+func (s *Store) load() error {
+	if s.Path == "" {
+		return nil
+	}
+
+	file, err := os.Open(s.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "while reading usage file %q", s.Path)
+	}
+	defer file.Close()
+
+	dec := json.NewDecoder(file)
+	for {
+		r := &record{}
+		err := dec.Decode(r)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			// A record cut off while it was appended is dropped.
+			break
+		}
+
+		if err != nil {
+			return errors.Wrapf(err, "while decoding usage file %q", s.Path)
+		}
+
+		if r.Day == "" || r.Command == "" {
+			return errors.Errorf("while decoding usage file %q: record without day or command", s.Path)
+		}
+
+		s.apply(r)
+	}
+
+	for day, commands := range s.days {
+		for _, c := range commands {
+			if c.Hits == 0 && c.Errors == 0 {
+				s.missing[day]++
+			}
+		}
+	}
+
+	s.prune(time.Now().UTC())
+	return s.compact()
+}
+
+// append appends the records to the file,
+// compacting it after compactRecords.
+func (s *Store) append(records []*record) error {
+	if s.Path == "" || len(records) == 0 {
+		return nil
+	}
+
+	if s.records+len(records) > compactRecords {
+		return s.compact()
+	}
+
+	file, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "while opening usage file %q", s.Path)
+	}
+	defer file.Close()
+
+	enc := json.NewEncoder(file)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return errors.Wrapf(err, "while writing usage file %q", s.Path)
+		}
+	}
+
+	s.records += len(records)
+	return nil
+}
+
+// compact rewrites the file with a record for each command and day.
+func (s *Store) compact() error {
+	tmp := s.Path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "while writing usage file %q", tmp)
+	}
+
+	enc := json.NewEncoder(file)
+	for day, commands := range s.days {
+		for name, c := range commands {
+			if err := enc.Encode(&record{Day: day, Command: name, counter: *c}); err != nil {
+				file.Close()
+				return errors.Wrapf(err, "while writing usage file %q", tmp)
+			}
+		}
+	}
+
+	if err := file.Close(); err != nil {
+		return errors.Wrapf(err, "while writing usage file %q", tmp)
+	}
+
+	if err := os.Rename(tmp, s.Path); err != nil {
+		return errors.Wrapf(err, "while replacing usage file %q", s.Path)
+	}
+
+	s.records = 0
+	return nil
+}
@@ -0,0 +1,255 @@
+package usage
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/pkg/errors"
+)
+
+func TestAdd(t *testing.T) {
+	tt := []struct {
+		name           string
+		events         []*proto.UsageEvent
+		expectedToFail bool
+	}{
+		{
+			name:   "valid",
+			events: []*proto.UsageEvent{{Command: "start", Outcome: Hit}, {Command: "strat", Outcome: Miss}},
+		},
+		{
+			name:           "without command",
+			events:         []*proto.UsageEvent{{Command: "start", Outcome: Hit}, {Outcome: Hit}},
+			expectedToFail: true,
+		},
+		{
+			name:           "unknown outcome",
+			events:         []*proto.UsageEvent{{Command: "start", Outcome: "found"}},
+			expectedToFail: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := New("", 0)
+			if err != nil {
+				t.Fatalf("while creating store: %v", err)
+			}
+
+			err = s.Add(tc.events)
+			if tc.expectedToFail {
+				if errors.Cause(err) != ErrInvalid {
+					t.Fatalf("expected ErrInvalid. got=%v", err)
+				}
+
+				if days := s.Stats(&proto.UsageStatsRequest{}, time.Now()).GetDays(); days[len(days)-1].GetHits() != 0 {
+					t.Fatalf("expected no events counted. got=%v", days)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("while adding events: %v", err)
+			}
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	now := time.Date(2020, time.December, 7, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(n int) *timestamp.Timestamp {
+		ts, _ := ptypes.TimestampProto(now.AddDate(0, 0, -n))
+		return ts
+	}
+
+	dir, err := ioutil.TempDir("", "botio")
+	if err != nil {
+		t.Fatalf("while creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "usage.jsonl")
+	s, err := New(path, 0)
+	if err != nil {
+		t.Fatalf("while creating store: %v", err)
+	}
+
+	if err := s.Add([]*proto.UsageEvent{
+		{Command: "start", Outcome: Hit, LatencyMs: 10, Chat: "a", Time: daysAgo(0)},
+		{Command: "start", Outcome: Hit, LatencyMs: 30, Chat: "a", Time: daysAgo(1)},
+		{Command: "start", Outcome: Error, LatencyMs: 50, Chat: "b", Time: daysAgo(1)},
+		{Command: "help", Outcome: Hit, Chat: "b", Time: daysAgo(0)},
+		{Command: "strat", Outcome: Miss, Chat: "c", Time: daysAgo(0)},
+		{Command: "strat", Outcome: Miss, Chat: "d", Time: daysAgo(2)},
+		{Command: "stop", Outcome: Miss, Chat: "c", Time: daysAgo(0)},
+		{Command: "old", Outcome: Hit, Chat: "e", Time: daysAgo(10)},
+	}); err != nil {
+		t.Fatalf("while adding events: %v", err)
+	}
+
+	// The counts are kept on the file.
+	s, err = New(path, 0)
+	if err != nil {
+		t.Fatalf("while reloading store: %v", err)
+	}
+
+	stats := s.Stats(&proto.UsageStatsRequest{}, now)
+
+	expectedCommands := []struct {
+		command string
+		hits    int64
+		chats   int64
+		latency int64
+	}{
+		{command: "start", hits: 2, chats: 2, latency: 30},
+		{command: "help", hits: 1, chats: 1},
+	}
+
+	if len(stats.GetTopCommands()) != len(expectedCommands) {
+		t.Fatalf("expected %v top commands. got=%v", len(expectedCommands), stats.GetTopCommands())
+	}
+
+	for i, e := range expectedCommands {
+		u := stats.GetTopCommands()[i]
+		if u.GetCommand() != e.command || u.GetHits() != e.hits || u.GetChats() != e.chats || u.GetAvgLatencyMs() != e.latency {
+			t.Fatalf("expected %q with %v hits from %v chats in %vms. got=%v", e.command, e.hits, e.chats, e.latency, u)
+		}
+	}
+
+	missing := stats.GetTopMissing()
+	if len(missing) != 2 || missing[0].GetCommand() != "strat" || missing[0].GetMisses() != 2 || missing[1].GetCommand() != "stop" {
+		t.Fatalf("expected %q missed twice and %q once. got=%v", "strat", "stop", missing)
+	}
+
+	days := stats.GetDays()
+	if len(days) != defaultDays || days[len(days)-1].GetDay() != "2020-12-07" || days[len(days)-1].GetHits() != 2 || days[len(days)-2].GetErrors() != 1 {
+		t.Fatalf("expected %v days ending on 2020-12-07 with 2 hits. got=%v", defaultDays, days)
+	}
+
+	limited := s.Stats(&proto.UsageStatsRequest{Days: 1, Limit: 1}, now)
+	if len(limited.GetTopCommands()) != 1 || limited.GetTopCommands()[0].GetHits() != 1 || len(limited.GetDays()) != 1 {
+		t.Fatalf("expected a single command with the hits of a single day. got=%v", limited)
+	}
+}
+
+func TestRetention(t *testing.T) {
+	s, err := New("", 2)
+	if err != nil {
+		t.Fatalf("while creating store: %v", err)
+	}
+
+	old, _ := ptypes.TimestampProto(time.Now().AddDate(0, 0, -2))
+	if err := s.Add([]*proto.UsageEvent{{Command: "start", Outcome: Hit, Time: old}, {Command: "start", Outcome: Hit}}); err != nil {
+		t.Fatalf("while adding events: %v", err)
+	}
+
+	stats := s.Stats(&proto.UsageStatsRequest{}, time.Now())
+	if len(stats.GetTopCommands()) != 1 || stats.GetTopCommands()[0].GetHits() != 1 {
+		t.Fatalf("expected only the hit of today. got=%v", stats.GetTopCommands())
+	}
+}
+
+func TestMissing(t *testing.T) {
+	s, err := New("", 0)
+	if err != nil {
+		t.Fatalf("while creating store: %v", err)
+	}
+	s.maxMissing = 2
+
+	events := []*proto.UsageEvent{{Command: "start", Outcome: Hit}}
+	for _, command := range []string{"strat", "strat", "stpo", "sart", "tsart", "start"} {
+		events = append(events, &proto.UsageEvent{Command: command, Outcome: Miss})
+	}
+
+	if err := s.Add(events); err != nil {
+		t.Fatalf("while adding events: %v", err)
+	}
+
+	expected := map[string]int64{"strat": 2, "stpo": 1, otherCommand: 2, "start": 1}
+	missing := s.Stats(&proto.UsageStatsRequest{}, time.Now()).GetTopMissing()
+	if len(missing) != len(expected) {
+		t.Fatalf("expected %v missing commands. got=%v", len(expected), missing)
+	}
+
+	for _, u := range missing {
+		if u.GetMisses() != expected[u.GetCommand()] {
+			t.Fatalf("expected %q missed %v times. got=%v", u.GetCommand(), expected[u.GetCommand()], u.GetMisses())
+		}
+	}
+}
+
+func TestSketch(t *testing.T) {
+	for _, n := range []int{1, 10, 1000, 100000} {
+		var sk sketch
+		for i := 0; i < n; i++ {
+			sk.add(fmt.Sprintf("chat-%v", i))
+			sk.add(fmt.Sprintf("chat-%v", i))
+		}
+
+		if c := sk.count(); float64(c) < float64(n)*0.9 || float64(c) > float64(n)*1.1 {
+			t.Fatalf("expected about %v chats. got=%v", n, c)
+		}
+	}
+}
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "botio")
+	if err != nil {
+		t.Fatalf("while creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "usage.jsonl")
+	s, err := New(path, 0)
+	if err != nil {
+		t.Fatalf("while creating store: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := s.Add([]*proto.UsageEvent{{Command: "start", Outcome: Hit, Chat: fmt.Sprint(i)}}); err != nil {
+			t.Fatalf("while adding events: %v", err)
+		}
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("while reading usage file: %v", err)
+	}
+
+	if lines := strings.Count(string(b), "\n"); lines != 3 {
+		t.Fatalf("expected a line appended for each report. got=%v", lines)
+	}
+
+	// A record cut off while it was appended is dropped.
+	if err := ioutil.WriteFile(path, append(b, `{"day":"2020-`...), 0600); err != nil {
+		t.Fatalf("while writing usage file: %v", err)
+	}
+
+	s, err = New(path, 0)
+	if err != nil {
+		t.Fatalf("while reloading store: %v", err)
+	}
+
+	top := s.Stats(&proto.UsageStatsRequest{}, time.Now()).GetTopCommands()
+	if len(top) != 1 || top[0].GetHits() != 3 || top[0].GetChats() != 3 {
+		t.Fatalf("expected %q with 3 hits from 3 chats. got=%v", "start", top)
+	}
+
+	b, err = ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("while reading usage file: %v", err)
+	}
+
+	if lines := strings.Count(string(b), "\n"); lines != 1 {
+		t.Fatalf("expected the file compacted to a line. got=%v", lines)
+	}
+}
@@ -0,0 +1,564 @@
+// Package webhook exports a Dispatcher that sends the changes
+// made to the commands to the registered webhooks.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/danielkvist/botio/jsonl"
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/jsonpb"
+	pb "github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/pkg/errors"
+)
+
+// Headers sent with every payload.
+const (
+	EventHeader     = "X-Botio-Event"
+	DeliveryHeader  = "X-Botio-Delivery"
+	SignatureHeader = "X-Botio-Signature"
+)
+
+// Events are the actions that a webhook can be registered for.
+var Events = []string{"add", "update", "delete", "rollback"}
+
+// maxDeliveries is the number of deliveries kept, after
+// which the oldest are dropped. The dead ones are kept
+// apart until they are redelivered or their webhook removed.
+const maxDeliveries = 1000
+
+// compactDeliveries is the number of deliveries appended to
+// the deliveries file after which it is rewritten with only
+// the ones that are kept.
+const compactDeliveries = 10000
+
+// ErrNotFound is returned when the requested webhook is not registered.
+var ErrNotFound = errors.New("webhook not found")
+
+// ErrNoDelivery is returned when the delivery
+// to redeliver is not kept as dead.
+var ErrNoDelivery = errors.New("dead delivery not found")
+
+// ErrInvalid is returned when a webhook can't be registered
+// because it has no valid URL or an unknown event.
+var ErrInvalid = errors.New("invalid webhook")
+
+// Dispatcher keeps the registered webhooks and POSTs to each one of them
+// the audit events of the changes they were registered for as JSON, signed
+// with their secrets. A delivery that fails is retried up to Retries times,
+// doubling Backoff after every attempt, and kept as dead if it never succeeds
+// until it is redelivered.
+type Dispatcher struct {
+	// Path of the file on which the webhooks are kept.
+	// If empty they are only kept in memory.
+	Path string
+	// DeliveriesPath of the file on which every change of the deliveries
+	// is appended. If empty they are only kept in memory.
+	DeliveriesPath string
+	Client         *http.Client
+	Retries        int
+	Backoff        time.Duration
+	// OnError, if not nil, is called with the errors keeping
+	// on the file the deliveries made in the background.
+	OnError func(error)
+
+	mu         sync.Mutex
+	hooks      []*proto.Webhook
+	deliveries []*proto.WebhookDelivery
+	dead       []*proto.WebhookDelivery
+	last       int64
+	records    int
+	inflight   sync.WaitGroup
+}
+
+// New returns a Dispatcher with the webhooks kept on the file on
+// path and the deliveries kept on the file on deliveriesPath, if any.
+// The deliveries that were being made when they were kept are
+// loaded as dead so they can be redelivered.
+func New(path, deliveriesPath string) (*Dispatcher, error) {
+	d := &Dispatcher{
+		Path:           path,
+		DeliveriesPath: deliveriesPath,
+		Client:         &http.Client{Timeout: 10 * time.Second},
+		Retries:        3,
+		Backoff:        time.Second,
+	}
+
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+
+	if err := d.loadDeliveries(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// Add registers the received webhook, assigning it an ID, and
+// returns it without its secret.
+func (d *Dispatcher) Add(h *proto.Webhook) (*proto.Webhook, error) {
+	if err := validate(h); err != nil {
+		return nil, err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	hook := pb.Clone(h).(*proto.Webhook)
+	hook.Id = id
+	hook.Created = ptypes.TimestampNow()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	hooks := append(d.hooks[:len(d.hooks):len(d.hooks)], hook)
+	if err := d.save(hooks); err != nil {
+		return nil, err
+	}
+
+	d.hooks = hooks
+	return redact(hook), nil
+}
+
+// List returns the registered webhooks without their secrets.
+func (d *Dispatcher) List() *proto.Webhooks {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	hooks := make([]*proto.Webhook, 0, len(d.hooks))
+	for _, h := range d.hooks {
+		hooks = append(hooks, redact(h))
+	}
+
+	return &proto.Webhooks{Webhooks: hooks}
+}
+
+// Remove unregisters the webhook with the received ID. Its pending
+// deliveries are still made but its dead ones are dropped.
+func (d *Dispatcher) Remove(id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	hooks := make([]*proto.Webhook, 0, len(d.hooks))
+	for _, h := range d.hooks {
+		if h.GetId() != id {
+			hooks = append(hooks, h)
+		}
+	}
+
+	if len(hooks) == len(d.hooks) {
+		return errors.Wrapf(ErrNotFound, "while removing webhook %q", id)
+	}
+
+	if err := d.save(hooks); err != nil {
+		return err
+	}
+
+	d.hooks = hooks
+
+	dead := d.dead[:0]
+	for _, dl := range d.dead {
+		if dl.GetWebhook() != id {
+			dead = append(dead, dl)
+		}
+	}
+	d.dead = dead
+
+	return nil
+}
+
+// Deliveries returns the deliveries, from the oldest to the
+// newest, that meet the conditions of the received filter.
+func (d *Dispatcher) Deliveries(f *proto.DeliveryFilter) *proto.WebhookDeliveries {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var deliveries []*proto.WebhookDelivery
+	for _, dl := range append(d.deliveries[:len(d.deliveries):len(d.deliveries)], d.dead...) {
+		if f.GetWebhook() != "" && f.GetWebhook() != dl.GetWebhook() {
+			continue
+		}
+
+		if f.GetDead() && !dl.GetDead() {
+			continue
+		}
+
+		deliveries = append(deliveries, pb.Clone(dl).(*proto.WebhookDelivery))
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].GetId() < deliveries[j].GetId()
+	})
+
+	return &proto.WebhookDeliveries{Deliveries: deliveries}
+}
+
+// Send delivers the received event in the background to every webhook
+// registered for its action. The deliveries are made even if they
+// can't be kept on the file.
+func (d *Dispatcher) Send(ev *proto.AuditEvent) error {
+	body, err := (&jsonpb.Marshaler{OrigName: true}).MarshalToString(ev)
+	if err != nil {
+		return errors.Wrapf(err, "while encoding %s event of command %q", ev.GetAction(), ev.GetCommand())
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var deliveries []*proto.WebhookDelivery
+	for _, h := range d.hooks {
+		if !subscribed(h, ev.GetAction()) {
+			continue
+		}
+
+		d.last++
+		dl := &proto.WebhookDelivery{
+			Id:      d.last,
+			Webhook: h.GetId(),
+			Action:  ev.GetAction(),
+			Command: ev.GetCommand(),
+			Time:    ptypes.TimestampNow(),
+			Payload: body,
+		}
+
+		d.keep(dl)
+		deliveries = append(deliveries, dl)
+
+		d.inflight.Add(1)
+		go d.deliver(h, dl)
+	}
+
+	return d.record(deliveries...)
+}
+
+// Redeliver makes again in the background the dead delivery with the
+// received ID, with its payload and retries, and returns it. The
+// delivery is made even if it can't be kept on the file.
+func (d *Dispatcher) Redeliver(id int64) (*proto.WebhookDelivery, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i := -1
+	for j, dl := range d.dead {
+		if dl.GetId() == id {
+			i = j
+			break
+		}
+	}
+
+	if i < 0 {
+		return nil, errors.Wrapf(ErrNoDelivery, "while redelivering delivery %v", id)
+	}
+
+	dl := d.dead[i]
+	h := d.hook(dl.GetWebhook())
+	if h == nil {
+		return nil, errors.Wrapf(ErrNotFound, "while redelivering delivery %v to webhook %q", id, dl.GetWebhook())
+	}
+
+	d.dead = append(d.dead[:i], d.dead[i+1:]...)
+
+	dl.Attempts = 0
+	dl.Status = 0
+	dl.Error = ""
+	dl.Dead = false
+	d.keep(dl)
+
+	d.inflight.Add(1)
+	go d.deliver(h, dl)
+
+	return pb.Clone(dl).(*proto.WebhookDelivery), d.record(dl)
+}
+
+// Wait blocks until every pending delivery has finished.
+func (d *Dispatcher) Wait() {
+	d.inflight.Wait()
+}
+
+// Sign returns the signature of the body with the
+// secret as it is sent on the SignatureHeader.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (d *Dispatcher) deliver(h *proto.Webhook, dl *proto.WebhookDelivery) {
+	defer d.inflight.Done()
+
+	body := []byte(dl.GetPayload())
+	backoff := d.Backoff
+	for attempt := 1; ; attempt++ {
+		status, err := d.post(h, dl, body)
+
+		d.mu.Lock()
+		dl.Attempts = int32(attempt)
+		dl.Status = int32(status)
+		dl.Error = ""
+		if err != nil {
+			dl.Error = err.Error()
+		}
+		dl.Delivered = err == nil
+		dl.Dead = err != nil && attempt > d.Retries
+		if dl.Dead {
+			d.bury(dl)
+		}
+
+		if err := d.record(dl); err != nil && d.OnError != nil {
+			d.OnError(err)
+		}
+		d.mu.Unlock()
+
+		if err == nil || attempt > d.Retries {
+			return
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (d *Dispatcher) post(h *proto.Webhook, dl *proto.WebhookDelivery, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, h.GetUrl(), bytes.NewReader(body))
+	if err != nil {
+		return 0, errors.Wrapf(err, "while creating request to %q", h.GetUrl())
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, dl.GetAction())
+	req.Header.Set(DeliveryHeader, fmt.Sprint(dl.GetId()))
+	req.Header.Set(SignatureHeader, Sign(h.GetSecret(), body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, errors.Wrapf(err, "while posting to %q", h.GetUrl())
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, errors.Errorf("unexpected status %q from %q", resp.Status, h.GetUrl())
+	}
+
+	return resp.StatusCode, nil
+}
+
+// hook returns the registered webhook with the received ID, if any.
+func (d *Dispatcher) hook(id string) *proto.Webhook {
+	for _, h := range d.hooks {
+		if h.GetId() == id {
+			return h
+		}
+	}
+
+	return nil
+}
+
+// keep adds the delivery to the latest ones dropping the oldest.
+func (d *Dispatcher) keep(dl *proto.WebhookDelivery) {
+	d.deliveries = append(d.deliveries, dl)
+	if len(d.deliveries) > maxDeliveries {
+		d.deliveries = d.deliveries[len(d.deliveries)-maxDeliveries:]
+	}
+}
+
+// bury moves the delivery from the latest ones, if it was
+// not already dropped from them, to the dead ones.
+func (d *Dispatcher) bury(dl *proto.WebhookDelivery) {
+	for i, kept := range d.deliveries {
+		if kept == dl {
+			d.deliveries = append(d.deliveries[:i], d.deliveries[i+1:]...)
+			break
+		}
+	}
+
+	d.dead = append(d.dead, dl)
+}
+
+// record appends the received deliveries to the deliveries file,
+// rewriting it with only the kept ones every compactDeliveries.
+func (d *Dispatcher) record(deliveries ...*proto.WebhookDelivery) error {
+	if d.DeliveriesPath == "" || len(deliveries) == 0 {
+		return nil
+	}
+
+	if d.records+len(deliveries) > compactDeliveries {
+		return d.compact()
+	}
+
+	msgs := make([]pb.Message, 0, len(deliveries))
+	for _, dl := range deliveries {
+		msgs = append(msgs, dl)
+	}
+
+	if err := jsonl.Append(d.DeliveriesPath, msgs...); err != nil {
+		return errors.Wrap(err, "while recording webhook deliveries")
+	}
+
+	d.records += len(deliveries)
+	return nil
+}
+
+// compact replaces the deliveries file with the kept deliveries.
+func (d *Dispatcher) compact() error {
+	msgs := make([]pb.Message, 0, len(d.deliveries)+len(d.dead))
+	for _, dl := range d.dead {
+		msgs = append(msgs, dl)
+	}
+
+	for _, dl := range d.deliveries {
+		msgs = append(msgs, dl)
+	}
+
+	if err := jsonl.Save(d.DeliveriesPath, msgs); err != nil {
+		return errors.Wrap(err, "while compacting webhook deliveries")
+	}
+
+	d.records = 0
+	return nil
+}
+
+// load reads the webhooks kept on the file.
+func (d *Dispatcher) load() error {
+	if d.Path == "" {
+		return nil
+	}
+
+	err := jsonl.Load(d.Path, func() pb.Message { return &proto.Webhook{} }, func(msg pb.Message) {
+		d.hooks = append(d.hooks, msg.(*proto.Webhook))
+	})
+	return errors.Wrap(err, "while loading webhooks")
+}
+
+// save replaces the webhooks kept on the file with the received ones.
+func (d *Dispatcher) save(hooks []*proto.Webhook) error {
+	if d.Path == "" {
+		return nil
+	}
+
+	msgs := make([]pb.Message, 0, len(hooks))
+	for _, h := range hooks {
+		msgs = append(msgs, h)
+	}
+
+	return errors.Wrap(jsonl.Save(d.Path, msgs), "while saving webhooks")
+}
+
+// loadDeliveries replays the changes of the deliveries appended to the
+// deliveries file, the last one of each delivery being its state, and
+// rewrites the file with only the ones that are kept. The deliveries of
+// webhooks that are no longer registered are only kept if they are not dead.
+func (d *Dispatcher) loadDeliveries() error {
+	if d.DeliveriesPath == "" {
+		return nil
+	}
+
+	byID := make(map[int64]*proto.WebhookDelivery)
+	err := jsonl.Load(d.DeliveriesPath, func() pb.Message { return &proto.WebhookDelivery{} }, func(msg pb.Message) {
+		dl := msg.(*proto.WebhookDelivery)
+		byID[dl.GetId()] = dl
+	})
+	if err != nil {
+		return errors.Wrap(err, "while loading webhook deliveries")
+	}
+
+	deliveries := make([]*proto.WebhookDelivery, 0, len(byID))
+	for _, dl := range byID {
+		deliveries = append(deliveries, dl)
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].GetId() < deliveries[j].GetId()
+	})
+
+	for _, dl := range deliveries {
+		if dl.GetId() > d.last {
+			d.last = dl.GetId()
+		}
+
+		if !dl.GetDelivered() && !dl.GetDead() {
+			dl.Dead = true
+			dl.Error = "interrupted by a restart"
+		}
+
+		switch {
+		case !dl.GetDead():
+			d.keep(dl)
+		case d.hook(dl.GetWebhook()) != nil:
+			d.dead = append(d.dead, dl)
+		}
+	}
+
+	return d.compact()
+}
+
+func validate(h *proto.Webhook) error {
+	u, err := url.Parse(h.GetUrl())
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Wrapf(ErrInvalid, "URL %q is not an absolute HTTP URL", h.GetUrl())
+	}
+
+	for _, ev := range h.GetEvents() {
+		if !known(ev) {
+			return errors.Wrapf(ErrInvalid, "unknown event %q", ev)
+		}
+	}
+
+	return nil
+}
+
+func known(event string) bool {
+	for _, ev := range Events {
+		if ev == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+func subscribed(h *proto.Webhook, action string) bool {
+	if len(h.GetEvents()) == 0 {
+		return true
+	}
+
+	for _, ev := range h.GetEvents() {
+		if ev == action {
+			return true
+		}
+	}
+
+	return false
+}
+
+func redact(h *proto.Webhook) *proto.Webhook {
+	hook := pb.Clone(h).(*proto.Webhook)
+	hook.Secret = ""
+	return hook
+}
+
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "while generating webhook ID")
+	}
+
+	return hex.EncodeToString(b), nil
+}
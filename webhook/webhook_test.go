@@ -0,0 +1,341 @@
+package webhook
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danielkvist/botio/jsonl"
+	"github.com/danielkvist/botio/proto"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/pkg/errors"
+)
+
+// receiver is an httptest server that fails the
+// first failures requests it receives.
+type receiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	failures int
+	bodies   [][]byte
+	headers  []http.Header
+}
+
+func newReceiver(failures int) *receiver {
+	r := &receiver{failures: failures}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.bodies = append(r.bodies, body)
+		r.headers = append(r.headers, req.Header)
+		if len(r.bodies) <= r.failures {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+
+	return r
+}
+
+func newDispatcher(t *testing.T, path, deliveriesPath string) *Dispatcher {
+	t.Helper()
+
+	d, err := New(path, deliveriesPath)
+	if err != nil {
+		t.Fatalf("while creating dispatcher: %v", err)
+	}
+
+	d.Backoff = time.Millisecond
+	d.Retries = 2
+	return d
+}
+
+func TestAdd(t *testing.T) {
+	tt := []struct {
+		name           string
+		hook           *proto.Webhook
+		expectedToFail bool
+	}{
+		{
+			name: "valid",
+			hook: &proto.Webhook{Url: "https://example.com/hook", Events: []string{"add", "delete"}, Secret: "s3cr3t"},
+		},
+		{
+			name: "every event",
+			hook: &proto.Webhook{Url: "http://localhost:8080"},
+		},
+		{
+			name:           "relative URL",
+			hook:           &proto.Webhook{Url: "/hook"},
+			expectedToFail: true,
+		},
+		{
+			name:           "unknown scheme",
+			hook:           &proto.Webhook{Url: "ftp://example.com"},
+			expectedToFail: true,
+		},
+		{
+			name:           "unknown event",
+			hook:           &proto.Webhook{Url: "https://example.com", Events: []string{"create"}},
+			expectedToFail: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			d := newDispatcher(t, "", "")
+
+			h, err := d.Add(tc.hook)
+			if tc.expectedToFail {
+				if errors.Cause(err) != ErrInvalid {
+					t.Fatalf("expected ErrInvalid. got=%v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("while adding webhook: %v", err)
+			}
+
+			if h.GetId() == "" || h.GetCreated() == nil {
+				t.Fatalf("expected webhook with ID and creation time. got=%v", h)
+			}
+
+			if h.GetSecret() != "" {
+				t.Fatalf("expected webhook without secret. got=%q", h.GetSecret())
+			}
+
+			if hooks := d.List().GetWebhooks(); len(hooks) != 1 || hooks[0].GetId() != h.GetId() || hooks[0].GetSecret() != "" {
+				t.Fatalf("expected only webhook %q without secret. got=%v", h.GetId(), hooks)
+			}
+		})
+	}
+}
+
+func TestSend(t *testing.T) {
+	tt := []struct {
+		name              string
+		events            []string
+		failures          int
+		expectedRequests  int
+		expectedDelivered bool
+		expectedDead      bool
+	}{
+		{
+			name:              "delivered at first",
+			expectedRequests:  1,
+			expectedDelivered: true,
+		},
+		{
+			name:              "delivered after retries",
+			failures:          2,
+			expectedRequests:  3,
+			expectedDelivered: true,
+		},
+		{
+			name:             "dead",
+			failures:         5,
+			expectedRequests: 3,
+			expectedDead:     true,
+		},
+		{
+			name:   "not subscribed",
+			events: []string{"delete"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newReceiver(tc.failures)
+			defer r.Close()
+
+			d := newDispatcher(t, "", "")
+			h, err := d.Add(&proto.Webhook{Url: r.URL, Events: tc.events, Secret: "s3cr3t"})
+			if err != nil {
+				t.Fatalf("while adding webhook: %v", err)
+			}
+
+			ev := &proto.AuditEvent{Id: 1, Action: "add", Command: "start", Subject: "ops"}
+			if err := d.Send(ev); err != nil {
+				t.Fatalf("while sending event: %v", err)
+			}
+			d.Wait()
+
+			if len(r.bodies) != tc.expectedRequests {
+				t.Fatalf("expected %v requests. got=%v", tc.expectedRequests, len(r.bodies))
+			}
+
+			deliveries := d.Deliveries(&proto.DeliveryFilter{Webhook: h.GetId()}).GetDeliveries()
+			if tc.expectedRequests == 0 {
+				if len(deliveries) != 0 {
+					t.Fatalf("expected no deliveries. got=%v", deliveries)
+				}
+				return
+			}
+
+			if len(deliveries) != 1 {
+				t.Fatalf("expected 1 delivery. got=%v", deliveries)
+			}
+
+			dl := deliveries[0]
+			if dl.GetAttempts() != int32(tc.expectedRequests) || dl.GetDelivered() != tc.expectedDelivered || dl.GetDead() != tc.expectedDead {
+				t.Fatalf("expected delivery with %v attempts, delivered %v and dead %v. got=%v", tc.expectedRequests, tc.expectedDelivered, tc.expectedDead, dl)
+			}
+
+			dead := d.Deliveries(&proto.DeliveryFilter{Dead: true}).GetDeliveries()
+			if tc.expectedDead != (len(dead) == 1) {
+				t.Fatalf("expected dead letters to include the delivery %v. got=%v", tc.expectedDead, dead)
+			}
+
+			for i, body := range r.bodies {
+				header := r.headers[i]
+				if sig := header.Get(SignatureHeader); sig != Sign("s3cr3t", body) {
+					t.Fatalf("expected signature %q. got=%q", Sign("s3cr3t", body), sig)
+				}
+
+				if header.Get(EventHeader) != "add" || header.Get(DeliveryHeader) != "1" {
+					t.Fatalf("expected event %q and delivery %q headers. got=%q and %q", "add", "1", header.Get(EventHeader), header.Get(DeliveryHeader))
+				}
+
+				got := &proto.AuditEvent{}
+				if err := jsonpb.UnmarshalString(string(body), got); err != nil {
+					t.Fatalf("while decoding payload %q: %v", body, err)
+				}
+
+				if got.GetCommand() != "start" || got.GetSubject() != "ops" {
+					t.Fatalf("expected payload of command %q by %q. got=%v", "start", "ops", got)
+				}
+			}
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	dir, err := ioutil.TempDir("", "botio")
+	if err != nil {
+		t.Fatalf("while creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "webhooks.jsonl")
+	d := newDispatcher(t, path, "")
+
+	first, err := d.Add(&proto.Webhook{Url: "https://example.com/first", Secret: "one"})
+	if err != nil {
+		t.Fatalf("while adding webhook: %v", err)
+	}
+
+	second, err := d.Add(&proto.Webhook{Url: "https://example.com/second", Events: []string{"update"}})
+	if err != nil {
+		t.Fatalf("while adding webhook: %v", err)
+	}
+
+	if err := d.Remove(first.GetId()); err != nil {
+		t.Fatalf("while removing webhook %q: %v", first.GetId(), err)
+	}
+
+	if err := d.Remove(first.GetId()); errors.Cause(err) != ErrNotFound {
+		t.Fatalf("expected ErrNotFound removing webhook %q twice. got=%v", first.GetId(), err)
+	}
+
+	reloaded := newDispatcher(t, path, "")
+	hooks := reloaded.List().GetWebhooks()
+	if len(hooks) != 1 || hooks[0].GetId() != second.GetId() || hooks[0].GetEvents()[0] != "update" {
+		t.Fatalf("expected only webhook %q after reloading. got=%v", second.GetId(), hooks)
+	}
+}
+
+func TestRedeliver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "botio")
+	if err != nil {
+		t.Fatalf("while creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := newReceiver(3)
+	defer r.Close()
+
+	path := filepath.Join(dir, "webhooks.jsonl")
+	deliveriesPath := filepath.Join(dir, "deliveries.jsonl")
+	d := newDispatcher(t, path, deliveriesPath)
+
+	h, err := d.Add(&proto.Webhook{Url: r.URL, Secret: "s3cr3t"})
+	if err != nil {
+		t.Fatalf("while adding webhook: %v", err)
+	}
+
+	ev := &proto.AuditEvent{Id: 1, Action: "add", Command: "start", Subject: "ops"}
+	if err := d.Send(ev); err != nil {
+		t.Fatalf("while sending event: %v", err)
+	}
+	d.Wait()
+
+	for i := 0; i < maxDeliveries; i++ {
+		if err := d.Send(ev); err != nil {
+			t.Fatalf("while sending event: %v", err)
+		}
+	}
+	d.Wait()
+
+	// The dead delivery must outlive the newer ones
+	// and a restart, and the oldest delivered one not.
+	reloaded := newDispatcher(t, path, deliveriesPath)
+	if n := len(reloaded.Deliveries(nil).GetDeliveries()); n != maxDeliveries+1 {
+		t.Fatalf("expected %v deliveries after reloading. got=%v", maxDeliveries+1, n)
+	}
+
+	dead := reloaded.Deliveries(&proto.DeliveryFilter{Dead: true}).GetDeliveries()
+	if len(dead) != 1 || dead[0].GetId() != 1 || dead[0].GetPayload() == "" {
+		t.Fatalf("expected delivery %v with its payload as dead after reloading. got=%v", 1, dead)
+	}
+
+	dl, err := reloaded.Redeliver(1)
+	if err != nil {
+		t.Fatalf("while redelivering delivery %v: %v", 1, err)
+	}
+	reloaded.Wait()
+
+	if dl.GetId() != 1 || dl.GetWebhook() != h.GetId() {
+		t.Fatalf("expected delivery %v to webhook %q. got=%v", 1, h.GetId(), dl)
+	}
+
+	if dead := reloaded.Deliveries(&proto.DeliveryFilter{Dead: true}).GetDeliveries(); len(dead) != 0 {
+		t.Fatalf("expected no dead deliveries after redelivering. got=%v", dead)
+	}
+
+	last := r.headers[len(r.headers)-1]
+	if last.Get(DeliveryHeader) != "1" || last.Get(SignatureHeader) != Sign("s3cr3t", r.bodies[0]) {
+		t.Fatalf("expected delivery %q of the same payload. got=%q", "1", last.Get(DeliveryHeader))
+	}
+
+	if _, err := reloaded.Redeliver(1); errors.Cause(err) != ErrNoDelivery {
+		t.Fatalf("expected ErrNoDelivery redelivering a delivered delivery. got=%v", err)
+	}
+
+	// A delivery being made when the server stopped is loaded as
+	// dead, while a line cut short by a crash is ignored.
+	pending := &proto.WebhookDelivery{Id: 5000, Webhook: h.GetId(), Action: "add", Payload: "{}"}
+	if err := jsonl.Append(deliveriesPath, pending); err != nil {
+		t.Fatalf("while appending delivery: %v", err)
+	}
+
+	file, err := os.OpenFile(deliveriesPath, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatalf("while opening deliveries file: %v", err)
+	}
+	file.WriteString(`{"id": "5001", "webh`)
+	file.Close()
+
+	dead = newDispatcher(t, path, deliveriesPath).Deliveries(&proto.DeliveryFilter{Dead: true}).GetDeliveries()
+	if len(dead) != 1 || dead[0].GetId() != pending.GetId() {
+		t.Fatalf("expected interrupted delivery %v as dead. got=%v", pending.GetId(), dead)
+	}
+}